@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"pickup-queue/internal/domain"
 	"pickup-queue/internal/handler"
 	"pickup-queue/internal/middleware"
-	"pickup-queue/internal/repository"
+	"pickup-queue/internal/storage"
 	"pickup-queue/internal/usecase"
-	"pickup-queue/pkg/database"
+	"pickup-queue/pkg/config"
 	"pickup-queue/pkg/logger"
+	"regexp"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -20,36 +28,87 @@ func main() {
 		log.Println("No .env file found")
 	}
 
+	// Load configuration
+	args := os.Args[1:]
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatalln("Failed to load configuration:", err)
+	}
+
 	// Initialize logger
 	appLogger := logger.New()
+	appLogger.SetLevel(cfg.Log.Level)
+	appLogger.Debug("Loaded configuration:\n" + cfg.String())
 
-	// Initialize database
-	dbConfig := database.GetConfigFromEnv()
-	db, err := database.NewConnection(dbConfig)
+	// Initialize storage and repositories
+	store, err := storage.Open(cfg.Database)
 	if err != nil {
 		appLogger.Error("Failed to connect to database:", err)
 		os.Exit(1)
 	}
-
-	// Note: Database migrations should be handled separately with migration files
-
-	// Initialize repositories
-	packageRepo := repository.NewPackageRepository(db)
+	defer store.Close()
+	if store.Backend == config.StorageMemory {
+		appLogger.Warning("Using in-memory storage: all data is lost on restart")
+	}
+	packageRepo := store.Packages
+	unitOfWork := store.UnitOfWork
 
 	// Initialize use cases
-	packageUsecase := usecase.NewPackageUsecase(packageRepo)
+	packageUsecase := usecase.NewPackageUsecaseWithUnitOfWork(packageRepo, unitOfWork).WithArchive(store.Archive)
+	packageUsecase.SetExpiryWindow(cfg.Worker.ExpiryWindow.Duration)
+	packageUsecase.SetServiceLevels(serviceLevels(cfg.SLA))
+	packageUsecase.WithIdentifierRules(identifierRules(cfg.Validation))
+	packageUsecase.WithHighValueThreshold(cfg.Packages.HighValueThreshold)
+	packageUsecase.WithShipments(store.Shipments)
+	packageUsecase.WithQueueSettings(usecase.QueueSettings{
+		SampleSize:  cfg.Queue.SampleSize,
+		DefaultPace: cfg.Queue.DefaultPace.Duration,
+		IdleGap:     cfg.Queue.IdleGap.Duration,
+	})
+	appointmentUsecase := usecase.NewAppointmentUsecase(store.Appointments, packageUsecase, appointmentRules(cfg.Appointments))
+	pickupSessionUsecase := usecase.NewPickupSessionUsecase(store.PickupSessions, packageUsecase).WithAppointments(appointmentUsecase)
+	queueUsecase := usecase.NewQueueUsecase(store.PickupSessions, packageUsecase)
+	slaUsecase := usecase.NewSLAUsecase(store.SLABreaches, packageUsecase)
+	returnUsecase := usecase.NewReturnUsecase(packageUsecase)
+	shipmentUsecase := usecase.NewShipmentUsecase(store.Shipments, packageUsecase)
+	labelUsecase := usecase.NewLabelUsecase(packageUsecase, cfg.Tracking.LocationName, cfg.Packages.LabelDPI)
+	reassignmentUsecase := usecase.NewReassignmentUsecase(unitOfWork, store.DriverAssignments, packageUsecase)
+	trackingUsecase := usecase.NewTrackingUsecase(packageUsecase, domain.PickupLocation{
+		Name:    cfg.Tracking.LocationName,
+		Address: cfg.Tracking.Address,
+		Hours:   cfg.Tracking.Hours,
+	}, cfg.Tracking.MaxFailedAttempts, cfg.Tracking.LockoutPeriod.Duration)
+
+	rateLimiter := store.RateLimiter(cfg.RateLimit.Backend)
 
 	// Initialize handlers
 	packageHandler := handler.NewPackageHandler(packageUsecase)
+	pickupSessionHandler := handler.NewPickupSessionHandler(pickupSessionUsecase)
+	reassignmentHandler := handler.NewReassignmentHandler(reassignmentUsecase)
+	trackingHandler := handler.NewTrackingHandler(trackingUsecase)
+	shipmentHandler := handler.NewShipmentHandler(shipmentUsecase)
+	labelHandler := handler.NewLabelHandler(labelUsecase)
+	appointmentHandler := handler.NewAppointmentHandler(appointmentUsecase)
+	queueHandler := handler.NewQueueHandler(queueUsecase)
+	slaHandler := handler.NewSLAHandler(slaUsecase)
+	returnsHandler := handler.NewReturnsHandler(returnUsecase)
 
 	// Initialize Gin router
 	router := gin.New()
+	// Only the configured proxies may name the client in X-Forwarded-For,
+	// otherwise clients could pick their own rate limit bucket
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalln("Invalid trusted proxies:", err)
+	}
 
 	// Add middleware
 	router.Use(middleware.Logger())
+	router.Use(middleware.Errors())
 	router.Use(middleware.CORS())
 	router.Use(middleware.RequestID())
 	router.Use(gin.Recovery())
+	router.Use(middleware.BodyLimit(cfg.Server.MaxBodyBytes))
+	router.NoRoute(middleware.NoRoute)
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -59,8 +118,15 @@ func main() {
 		})
 	})
 
+	if len(cfg.Auth.APIKeys) == 0 {
+		appLogger.Warning("No API keys configured: role-guarded endpoints will reject every request")
+	}
+
 	// API routes
 	v1 := router.Group("/api/v1")
+	v1.Use(middleware.Authenticate(middleware.APIKeys(cfg.Auth)))
+	v1.Use(middleware.RateLimit(rateLimiter, cfg.RateLimit.Default, rateLimitRules(cfg)))
+	v1.Use(middleware.Idempotency(store.Idempotency, cfg.Server.IdempotencyTTL.Duration, cfg.Server.WriteTimeout.Duration))
 	{
 		packages := v1.Group("/packages")
 		{
@@ -70,19 +136,180 @@ func main() {
 			packages.GET("/:id", packageHandler.GetPackage)
 			packages.GET("/order/:orderRef", packageHandler.GetPackageByOrderRef)
 			packages.PATCH("/:id/status", packageHandler.UpdatePackageStatus)
+			packages.POST("/status:batch", packageHandler.BatchUpdatePackageStatus)
+			packages.POST("/labels:batch", labelHandler.BatchLabels)
+			packages.GET("/:id/label", labelHandler.GetPackageLabel)
 			packages.DELETE("/:id", packageHandler.DeletePackage)
+			packages.POST("/:id/restore", middleware.RequireRole(domain.RoleAdmin), packageHandler.RestorePackage)
+			packages.POST("/:id/rehydrate", middleware.RequireRole(domain.RoleAdmin), packageHandler.RehydratePackage)
+			packages.GET("/:id/driver-history", reassignmentHandler.GetDriverHistory)
+			packages.PATCH("/:id/driver", middleware.RequireRole(domain.RoleSupervisor), reassignmentHandler.ReassignDriver)
+		}
+
+		shipments := v1.Group("/shipments")
+		{
+			shipments.POST("", shipmentHandler.CreateShipment)
+			shipments.GET("", shipmentHandler.ListShipments)
+			shipments.GET("/:id", shipmentHandler.GetShipment)
+			shipments.GET("/order/:orderRef", shipmentHandler.GetShipmentByOrderRef)
+			shipments.PATCH("/:id/status", shipmentHandler.UpdateShipmentStatus)
 		}
+
+		drivers := v1.Group("/drivers")
+		{
+			drivers.POST("/:driverCode/reassign", middleware.RequireRole(domain.RoleSupervisor), reassignmentHandler.ReassignAll)
+		}
+
+		sessions := v1.Group("/pickup-sessions")
+		{
+			sessions.POST("", pickupSessionHandler.OpenSession)
+			sessions.GET("/:id", pickupSessionHandler.GetSession)
+			sessions.POST("/:id/scans", pickupSessionHandler.ScanPackage)
+			sessions.POST("/:id/close", pickupSessionHandler.CloseSession)
+		}
+
+		appointments := v1.Group("/appointments")
+		{
+			appointments.GET("/windows", appointmentHandler.ListWindows)
+			appointments.GET("/schedule", appointmentHandler.GetSchedule)
+			appointments.POST("", appointmentHandler.BookAppointment)
+			appointments.GET("", appointmentHandler.ListAppointments)
+			appointments.GET("/:id", appointmentHandler.GetAppointment)
+			appointments.POST("/:id/cancel", appointmentHandler.CancelAppointment)
+		}
+
+		// Lobby display board of drivers waiting at the counter
+		v1.GET("/queue", queueHandler.GetQueue)
+
+		// Packages that missed their priority's pickup target
+		v1.GET("/sla/breaches", slaHandler.GetBreachReport)
+
+		// Expired packages waiting to go back to their sender, by carrier and driver
+		v1.GET("/returns/manifest", returnsHandler.GetManifest)
+
+		// Public: recipients track their own parcel with a verification token
+		v1.POST("/tracking", trackingHandler.TrackPackage)
 	}
 
+	// Reload log level, expiry windows and service levels on SIGHUP or config file change
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	watcher := config.NewWatcher(args, cfg)
+	watcher.OnReload(func(c *config.Config) {
+		appLogger.SetLevel(c.Log.Level)
+		packageUsecase.SetExpiryWindow(c.Worker.ExpiryWindow.Duration)
+		packageUsecase.SetServiceLevels(serviceLevels(c.SLA))
+	})
+	go watcher.Run(watchCtx, 30*time.Second)
+
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	port := cfg.Server.Port
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout.Duration,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout.Duration,
+		WriteTimeout:      cfg.Server.WriteTimeout.Duration,
+		IdleTimeout:       cfg.Server.IdleTimeout.Duration,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
 
-	appLogger.Info("Starting server on port", port)
-	if err := router.Run(":" + port); err != nil {
+	// Channel to listen for interrupt signal
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		appLogger.Info("Starting server on port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
 		appLogger.Error("Failed to start server:", err)
+		store.Close()
 		os.Exit(1)
+	case <-interrupt:
+		appLogger.Info("Shutting down server...")
+	}
+
+	// Drain in-flight requests before closing the database pool
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		appLogger.Error("Server forced to shutdown:", err)
+	} else {
+		appLogger.Info("Server stopped gracefully")
+	}
+}
+
+// rateLimitRules adds the tracking endpoint's per-IP limit to the configured
+// route limits
+func rateLimitRules(cfg *config.Config) []middleware.RateLimitRule {
+	return append(middleware.RateLimitRules(cfg.RateLimit), middleware.RateLimitRule{
+		Method:    http.MethodPost,
+		Path:      "/api/v1/tracking",
+		PerMinute: cfg.Tracking.RateLimit,
+		Key:       config.RateLimitKeyIP,
+	})
+}
+
+// identifierRules converts the configured validation rules; config.Validate
+// has already checked that every pattern compiles
+func identifierRules(cfg config.ValidationConfig) (usecase.IdentifierRules, map[string]usecase.IdentifierRules) {
+	carriers := make(map[string]usecase.IdentifierRules, len(cfg.Carriers))
+	for _, c := range cfg.Carriers {
+		carriers[c.Carrier] = usecase.IdentifierRules{OrderRef: fieldRule(c.OrderRef), DriverCode: fieldRule(c.DriverCode)}
+	}
+	def := usecase.IdentifierRules{OrderRef: fieldRule(cfg.Default.OrderRef), DriverCode: fieldRule(cfg.Default.DriverCode)}
+	return def, carriers
+}
+
+// appointmentRules converts the configured pickup windows; config.Validate
+// has already checked that they parse and the time zone loads
+func appointmentRules(cfg config.AppointmentsConfig) usecase.AppointmentRules {
+	windows, _ := cfg.PickupWindows()
+	location, _ := cfg.Location()
+	rules := usecase.AppointmentRules{
+		Location:    location,
+		Grace:       cfg.Grace.Duration,
+		Enforcement: usecase.AppointmentEnforcement(cfg.Enforcement),
+	}
+	for _, w := range windows {
+		rules.Windows = append(rules.Windows, usecase.PickupWindowRule{Start: w.Start, End: w.End, Capacity: w.Capacity})
+	}
+	return rules
+}
+
+// serviceLevels converts the configured service level of each priority
+func serviceLevels(cfg config.SLAConfig) map[domain.Priority]usecase.ServiceLevel {
+	level := func(c config.ServiceLevelConfig) usecase.ServiceLevel {
+		return usecase.ServiceLevel{
+			ExpiryWindow:     c.ExpiryWindow.Duration,
+			PickupTarget:     c.PickupTarget.Duration,
+			ReminderInterval: c.ReminderInterval.Duration,
+		}
+	}
+	return map[domain.Priority]usecase.ServiceLevel{
+		domain.PriorityExpress:  level(cfg.Express),
+		domain.PriorityStandard: level(cfg.Standard),
+		domain.PriorityEconomy:  level(cfg.Economy),
+	}
+}
+
+func fieldRule(cfg config.FieldRule) usecase.FieldRule {
+	rule := usecase.FieldRule{
+		MinLength: cfg.MinLength,
+		MaxLength: cfg.MaxLength,
+		Checksum:  cfg.Checksum,
+		UpperCase: cfg.UpperCase,
+	}
+	if cfg.Pattern != "" {
+		// Patterns must match the whole value
+		rule.Pattern = regexp.MustCompile(`^(?:` + cfg.Pattern + `)$`)
 	}
+	return rule
 }
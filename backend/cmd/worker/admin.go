@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/handler"
+	"pickup-queue/internal/middleware"
+	"pickup-queue/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAdminServer builds the worker admin API. Every route except /health
+// requires an admin API key.
+func newAdminServer(cfg *config.Config, jobHandler *handler.JobHandler) *http.Server {
+	router := gin.New()
+	router.Use(middleware.Logger())
+	router.Use(middleware.Errors())
+	router.Use(middleware.RequestID())
+	router.Use(gin.Recovery())
+	router.NoRoute(middleware.NoRoute)
+
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"status":  "healthy",
+			"service": "pickup-queue-worker",
+		})
+	})
+
+	admin := router.Group("/admin")
+	admin.Use(middleware.Authenticate(middleware.APIKeys(cfg.Auth)))
+	admin.Use(middleware.RequireRole(domain.RoleAdmin))
+	{
+		admin.GET("/jobs", jobHandler.ListJobs)
+		admin.GET("/jobs/:name", jobHandler.GetJob)
+		admin.GET("/jobs/:name/runs", jobHandler.ListJobRuns)
+		admin.POST("/jobs/:name/run", jobHandler.TriggerJob)
+		admin.POST("/jobs/:name/pause", jobHandler.PauseJob)
+		admin.POST("/jobs/:name/resume", jobHandler.ResumeJob)
+		admin.GET("/expiry/dry-run", jobHandler.PreviewExpiry)
+	}
+
+	return &http.Server{
+		Addr:              cfg.Worker.AdminAddr,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout.Duration,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout.Duration,
+		WriteTimeout:      cfg.Server.WriteTimeout.Duration,
+		IdleTimeout:       cfg.Server.IdleTimeout.Duration,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+}
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
-	"pickup-queue/internal/repository"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/handler"
+	"pickup-queue/internal/scheduler"
+	"pickup-queue/internal/storage"
 	"pickup-queue/internal/usecase"
-	"pickup-queue/pkg/database"
+	"pickup-queue/pkg/config"
 	"pickup-queue/pkg/logger"
 	"syscall"
 	"time"
@@ -14,58 +21,232 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// Job names, also the keys of JOB_SCHEDULES and worker.jobs
+const (
+	jobExpirePackages      = "expire-packages"
+	jobArchivePackages     = "archive-packages"
+	jobPurgeDeleted        = "purge-deleted-packages"
+	jobPurgeIdempotencyKey = "purge-idempotency-keys"
+	jobDetectSLABreaches   = "detect-sla-breaches"
+	jobStartReturns        = "return-expired-packages"
+	jobSendReminders       = "send-pickup-reminders"
+)
+
+// defaultSchedules are used for jobs without an override in the config.
+// Expiry follows WORKER_INTERVAL so existing deployments keep their cadence.
+func defaultSchedules(cfg config.WorkerConfig) map[string]string {
+	return map[string]string{
+		jobExpirePackages:      "@every " + cfg.Interval.Duration.String(),
+		jobArchivePackages:     "0 3 * * *",
+		jobPurgeDeleted:        "30 3 * * *",
+		jobPurgeIdempotencyKey: "@hourly",
+		jobDetectSLABreaches:   "@every 15m",
+		jobStartReturns:        "@hourly",
+		jobSendReminders:       "@every 15m",
+	}
+}
+
+// jobSchedule resolves the schedule and timeout of one job from the config
+func jobSchedule(cfg config.WorkerConfig, name string) (scheduler.Schedule, time.Duration, error) {
+	spec := defaultSchedules(cfg)[name]
+	override := cfg.Jobs[name]
+	if override.Schedule != "" {
+		spec = override.Schedule
+	}
+	schedule, err := scheduler.Parse(spec)
+	if err != nil {
+		return nil, 0, fmt.Errorf("job %s: %w", name, err)
+	}
+	return schedule, override.Timeout.Duration, nil
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
 
+	// Load configuration
+	args := os.Args[1:]
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatalln("Failed to load configuration:", err)
+	}
+
 	// Initialize logger
 	appLogger := logger.New()
+	appLogger.SetLevel(cfg.Log.Level)
+	appLogger.Debug("Loaded configuration:\n" + cfg.String())
 
-	// Initialize database
-	dbConfig := database.GetConfigFromEnv()
-	db, err := database.NewConnection(dbConfig)
+	if cfg.Database.Storage == config.StorageMemory {
+		log.Fatalln("The worker cannot share in-memory storage with the API; use postgres or sqlite")
+	}
+
+	// Initialize storage and repositories
+	store, err := storage.Open(cfg.Database)
 	if err != nil {
 		appLogger.Error("Failed to connect to database:", err)
 		os.Exit(1)
 	}
-
-	// Initialize repositories
-	packageRepo := repository.NewPackageRepository(db)
+	defer store.Close()
+	packageRepo := store.Packages
+	unitOfWork := store.UnitOfWork
 
 	// Initialize use cases
-	packageUsecase := usecase.NewPackageUsecase(packageRepo)
+	packageUsecase := usecase.NewPackageUsecaseWithUnitOfWork(packageRepo, unitOfWork).WithArchive(store.Archive)
+	packageUsecase.SetExpiryWindow(cfg.Worker.ExpiryWindow.Duration)
+	packageUsecase.SetServiceLevels(serviceLevels(cfg.SLA))
+	slaUsecase := usecase.NewSLAUsecase(store.SLABreaches, packageUsecase)
+	returnUsecase := usecase.NewReturnUsecase(packageUsecase)
+	reminderUsecase := usecase.NewReminderUsecase(store.PickupReminders, packageUsecase)
+
+	watcher := config.NewWatcher(args, cfg)
+
+	// Register the jobs; each reads the current config so reloads apply on the next run.
+	// Statistics rollups and webhook retries are not jobs: the service stores
+	// no statistics and sends no webhooks yet.
+	sched := scheduler.New(store.JobLocker, store.JobRuns, appLogger).
+		WithSettings(store.JobSettings).
+		WithLeader(store.JobLeader)
+	sched.SetDefaultTimeout(cfg.Worker.JobTimeout.Duration)
+	jobs := []scheduler.Job{
+		{
+			Name: jobExpirePackages,
+			Run: func(ctx context.Context) (string, error) {
+				if err := packageUsecase.MarkExpiredPackages(ctx); err != nil {
+					return "", err
+				}
+				return "expired packages check completed", nil
+			},
+		},
+		{
+			Name: jobArchivePackages,
+			Run: func(ctx context.Context) (string, error) {
+				archived, err := packageUsecase.ArchiveTerminalPackages(ctx, watcher.Current().Worker.ArchiveAfter.Duration)
+				return fmt.Sprintf("archived %d terminal packages", archived), err
+			},
+		},
+		{
+			Name: jobPurgeDeleted,
+			Run: func(ctx context.Context) (string, error) {
+				purged, err := packageUsecase.PurgeDeletedPackages(ctx, watcher.Current().Worker.RetentionPeriod.Duration)
+				return fmt.Sprintf("purged %d deleted packages", purged), err
+			},
+		},
+		{
+			Name: jobPurgeIdempotencyKey,
+			Run: func(ctx context.Context) (string, error) {
+				purged, err := store.Idempotency.DeleteExpired(time.Now())
+				return fmt.Sprintf("purged %d idempotency keys", purged), err
+			},
+		},
+		{
+			Name: jobDetectSLABreaches,
+			Run: func(ctx context.Context) (string, error) {
+				recorded, err := slaUsecase.DetectBreaches(ctx)
+				return fmt.Sprintf("recorded %d SLA breaches", recorded), err
+			},
+		},
+		{
+			Name: jobStartReturns,
+			Run: func(ctx context.Context) (string, error) {
+				moved, err := returnUsecase.StartReturns(ctx, watcher.Current().Worker.ReturnHoldPeriod.Duration)
+				return fmt.Sprintf("marked %d expired packages for return", moved), err
+			},
+		},
+		{
+			Name: jobSendReminders,
+			Run: func(ctx context.Context) (string, error) {
+				sent, err := reminderUsecase.SendReminders(ctx)
+				return fmt.Sprintf("sent %d pickup reminders", sent), err
+			},
+		},
+	}
+	for _, job := range jobs {
+		job.Schedule, job.Timeout, err = jobSchedule(cfg.Worker, job.Name)
+		if err != nil {
+			log.Fatalln("Invalid job schedule:", err)
+		}
+		if err := sched.Register(job); err != nil {
+			log.Fatalln("Failed to register job:", err)
+		}
+	}
+	for name := range cfg.Worker.Jobs {
+		if _, ok := defaultSchedules(cfg.Worker)[name]; !ok {
+			appLogger.Warning("Ignoring schedule for unknown job:", name)
+		}
+	}
+
+	// Reload schedules, timeouts, expiry windows, service levels and log level on SIGHUP or config file change
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	watcher.OnReload(func(c *config.Config) {
+		appLogger.SetLevel(c.Log.Level)
+		packageUsecase.SetExpiryWindow(c.Worker.ExpiryWindow.Duration)
+		packageUsecase.SetServiceLevels(serviceLevels(c.SLA))
+		sched.SetDefaultTimeout(c.Worker.JobTimeout.Duration)
+		for _, name := range sched.Names() {
+			schedule, timeout, err := jobSchedule(c.Worker, name)
+			if err != nil {
+				appLogger.Error("Keeping previous schedule:", err)
+				continue
+			}
+			if err := sched.Reschedule(name, schedule, timeout); err != nil {
+				appLogger.Error("Failed to reschedule job:", err)
+			}
+		}
+	})
+	go watcher.Run(watchCtx, 30*time.Second)
 
-	// Create a ticker that runs every hour
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
+	// Stop scheduling on interrupt; runs in progress get to finish or time out
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Channel to listen for interrupt signal
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	// Serve the admin API alongside the scheduler
+	if cfg.Worker.AdminAddr != "" {
+		if len(cfg.Auth.APIKeys) == 0 {
+			appLogger.Warning("No API keys configured: the worker admin API will reject every request")
+		}
+		adminServer := newAdminServer(cfg, handler.NewJobHandler(ctx, sched, packageUsecase))
+		go func() {
+			appLogger.Info("Starting worker admin API on", cfg.Worker.AdminAddr)
+			if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				appLogger.Error("Worker admin API stopped:", err)
+			}
+		}()
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+			defer cancel()
+			if err := adminServer.Shutdown(shutdownCtx); err != nil {
+				appLogger.Error("Worker admin API forced to shutdown:", err)
+			}
+		}()
+	}
 
-	appLogger.Info("Package expiry worker started")
+	appLogger.Info("Worker started with jobs:", sched.Names())
 
-	// Run initial check
-	if err := packageUsecase.MarkExpiredPackages(); err != nil {
-		appLogger.Error("Error marking expired packages:", err)
-	} else {
-		appLogger.Info("Initial expired packages check completed")
+	// Run initial expiry check
+	if _, err := sched.RunNow(ctx, jobExpirePackages, domain.TriggerSchedule); err != nil &&
+		!errors.Is(err, scheduler.ErrNotLeader) {
+		appLogger.Error("Error running initial expiry check:", err)
 	}
 
-	for {
-		select {
-		case <-ticker.C:
-			appLogger.Info("Running expired packages check...")
-			if err := packageUsecase.MarkExpiredPackages(); err != nil {
-				appLogger.Error("Error marking expired packages:", err)
-			} else {
-				appLogger.Info("Expired packages check completed")
-			}
-		case <-interrupt:
-			appLogger.Info("Shutting down worker...")
-			return
+	sched.Run(ctx)
+	appLogger.Info("Shutting down worker...")
+}
+
+// serviceLevels converts the configured service level of each priority
+func serviceLevels(cfg config.SLAConfig) map[domain.Priority]usecase.ServiceLevel {
+	level := func(c config.ServiceLevelConfig) usecase.ServiceLevel {
+		return usecase.ServiceLevel{
+			ExpiryWindow:     c.ExpiryWindow.Duration,
+			PickupTarget:     c.PickupTarget.Duration,
+			ReminderInterval: c.ReminderInterval.Duration,
 		}
 	}
+	return map[domain.Priority]usecase.ServiceLevel{
+		domain.PriorityExpress:  level(cfg.Express),
+		domain.PriorityStandard: level(cfg.Standard),
+		domain.PriorityEconomy:  level(cfg.Economy),
+	}
 }
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AppointmentStatus represents the status of a pickup appointment
+type AppointmentStatus string
+
+const (
+	AppointmentBooked    AppointmentStatus = "BOOKED"
+	AppointmentCancelled AppointmentStatus = "CANCELLED"
+)
+
+var (
+	// ErrWindowFull is returned when a pickup window has no capacity left
+	ErrWindowFull = NewError(KindConflict, "WINDOW_FULL", "pickup window is fully booked")
+	// ErrAppointmentExists is returned when a driver books a second window
+	// on the same day
+	ErrAppointmentExists = NewError(KindConflict, "APPOINTMENT_EXISTS", "driver already has a pickup appointment that day")
+)
+
+// Appointment is a driver's booking of one of the site's daily pickup
+// windows. The window is stored as absolute times, so changing the configured
+// windows later does not move existing bookings.
+type Appointment struct {
+	ID          uuid.UUID         `json:"id"`
+	DriverCode  string            `json:"driver_code"`
+	WindowStart time.Time         `json:"window_start"`
+	WindowEnd   time.Time         `json:"window_end"`
+	Status      AppointmentStatus `json:"status"`
+	// BookedBy is the API key that made the booking, if any
+	BookedBy    string     `json:"booked_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+
+	// WaitingPackages is counted from the driver's WAITING packages when the
+	// appointment is read through the usecase; it is not stored
+	WaitingPackages int `json:"waiting_packages"`
+}
+
+// AppointmentFilter selects booked appointments whose window starts in
+// [From, To), optionally for one driver
+type AppointmentFilter struct {
+	DriverCode string
+	From       time.Time
+	To         time.Time
+}
+
+// AppointmentRepository defines the interface for appointment storage
+type AppointmentRepository interface {
+	// Create stores a booked appointment, or returns ErrWindowFull if its
+	// window already holds capacity booked appointments and
+	// ErrAppointmentExists if the driver already booked a window starting
+	// on the same day, which begins at day, the site's local midnight. Both
+	// checks and the insert are atomic.
+	Create(appointment *Appointment, capacity int, day time.Time) error
+	GetByID(id uuid.UUID) (*Appointment, error)
+	// ListBooked returns booked appointments ordered by window start, then
+	// booking time
+	ListBooked(filter AppointmentFilter) ([]*Appointment, error)
+	Cancel(id uuid.UUID, cancelledAt time.Time) error
+}
+
+// PickupWindow is one of the site's pickup windows on a given day
+type PickupWindow struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Capacity  int       `json:"capacity"`
+	Booked    int       `json:"booked"`
+	Available int       `json:"available"`
+}
+
+// ScheduledWindow is a pickup window with the appointments booked in it
+type ScheduledWindow struct {
+	PickupWindow
+	Appointments []*Appointment `json:"appointments"`
+}
+
+// PickupSchedule is the site's booking plan for one day
+type PickupSchedule struct {
+	Date    string             `json:"date"`
+	Windows []*ScheduledWindow `json:"windows"`
+}
+
+// BookAppointmentRequest books the window starting at WindowStart for a
+// driver
+type BookAppointmentRequest struct {
+	DriverCode  string    `json:"driver_code" binding:"required"`
+	WindowStart time.Time `json:"window_start" binding:"required"`
+}
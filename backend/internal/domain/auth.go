@@ -0,0 +1,23 @@
+package domain
+
+// Role is what an authenticated caller is allowed to do
+type Role string
+
+const (
+	RoleClerk      Role = "clerk"
+	RoleSupervisor Role = "supervisor"
+	RoleAdmin      Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleClerk: 1, RoleSupervisor: 2, RoleAdmin: 3}
+
+// AtLeast reports whether r grants everything min does
+func (r Role) AtLeast(min Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[min]
+}
+
+// Principal is the caller behind an API key
+type Principal struct {
+	Name string
+	Role Role
+}
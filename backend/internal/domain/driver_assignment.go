@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DriverAssignment records one change of a package's driver
+type DriverAssignment struct {
+	ID         uuid.UUID `json:"id"`
+	PackageID  uuid.UUID `json:"package_id"`
+	FromDriver string    `json:"from_driver_code"`
+	ToDriver   string    `json:"to_driver_code"`
+	Reason     string    `json:"reason"`
+	ChangedBy  string    `json:"changed_by"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// DriverAssignmentRepository stores the driver reassignment history
+type DriverAssignmentRepository interface {
+	Create(assignment *DriverAssignment) error
+	// ListByPackage returns the history of one package, oldest first
+	ListByPackage(packageID uuid.UUID) ([]*DriverAssignment, error)
+}
+
+// ReassignDriverRequest moves one WAITING package to another driver
+type ReassignDriverRequest struct {
+	DriverCode string `json:"driver_code" binding:"required"`
+	Reason     string `json:"reason" binding:"required"`
+}
+
+// BulkReassignDriverRequest moves every WAITING package of one driver to another
+type BulkReassignDriverRequest struct {
+	ToDriverCode string `json:"to_driver_code" binding:"required"`
+	Reason       string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "strings"
+
+// ErrorKind classifies an Error; the API maps each kind to an HTTP status
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooLarge
+	KindUnprocessable
+	KindTooManyRequests
+)
+
+// Error is an error whose Code and Message are safe to show to API clients.
+// Any other error reaching the API is reported as an internal error without
+// its text.
+type Error struct {
+	Kind ErrorKind
+	// Code is a stable, machine-readable identifier such as PACKAGE_NOT_FOUND
+	Code    string
+	Message string
+	// Fields lists the request fields that failed validation, if any
+	Fields []FieldError
+}
+
+// FieldError describes one invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// ErrValidation is the generic error for requests with invalid fields
+var ErrValidation = NewError(KindValidation, "VALIDATION_FAILED", "request is invalid")
+
+// NewValidationError reports invalid request fields
+func NewValidationError(fields ...FieldError) *Error {
+	return ErrValidation.WithFields(fields...)
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	details := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		details[i] = f.Field + ": " + f.Message
+	}
+	return e.Message + ": " + strings.Join(details, "; ")
+}
+
+// Is matches errors with the same code, so a copy made by WithFields still
+// matches the sentinel it came from
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithFields returns a copy of e that also reports the given field errors
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	out := *e
+	out.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &out
+}
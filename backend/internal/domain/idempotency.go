@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyKeyExists is returned when reserving a key that is already stored
+var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+// IdempotencyRecord stores the outcome of a request made with an Idempotency-Key
+// header so that retries can be answered with the original response
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	// StatusCode is zero while the original request is still being processed
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// InProgress reports whether the original request has not finished yet
+func (r *IdempotencyRecord) InProgress() bool {
+	return r.StatusCode == 0
+}
+
+// IdempotencyRepository defines the interface for idempotency key storage
+type IdempotencyRepository interface {
+	// Reserve stores a new in-progress record or returns ErrIdempotencyKeyExists
+	Reserve(record *IdempotencyRecord) error
+	Get(key string) (*IdempotencyRecord, error)
+	// Complete stores the response and keeps the record until expiresAt
+	Complete(key string, statusCode int, contentType string, body []byte, expiresAt time.Time) error
+	Delete(key string) error
+	DeleteExpired(now time.Time) (int64, error)
+}
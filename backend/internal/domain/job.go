@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobRunStatus is the outcome of one worker job run
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "RUNNING"
+	JobRunSucceeded JobRunStatus = "SUCCEEDED"
+	JobRunFailed    JobRunStatus = "FAILED"
+)
+
+// JobTrigger records what started a job run
+type JobTrigger string
+
+const (
+	TriggerSchedule JobTrigger = "schedule"
+	TriggerManual   JobTrigger = "manual"
+)
+
+// JobRun is one execution of a scheduled worker job
+type JobRun struct {
+	ID         uuid.UUID    `json:"id"`
+	Job        string       `json:"job"`
+	Trigger    JobTrigger   `json:"trigger"`
+	Status     JobRunStatus `json:"status"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Result     string       `json:"result,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// JobRunRepository stores the job run history
+type JobRunRepository interface {
+	Create(run *JobRun) error
+	// Finish records the final status, result, error and finish time of a run
+	Finish(run *JobRun) error
+	// ListByJob returns the latest runs of one job, newest first
+	ListByJob(job string, limit int) ([]*JobRun, error)
+}
+
+// JobLocker elects which worker replica runs a job. TryLock returns false,
+// without error, when another replica holds the lock; unlock must be called
+// once the run is over.
+type JobLocker interface {
+	TryLock(ctx context.Context, job string) (unlock func(), ok bool, err error)
+}
+
+// LeaderElector elects the one worker replica that runs scheduled jobs.
+// IsLeader reports whether this replica leads, taking the lead when no
+// replica holds it; Resign gives the lead up so another replica can take it.
+type LeaderElector interface {
+	IsLeader(ctx context.Context) (bool, error)
+	Resign()
+}
+
+// JobSettingsRepository stores job settings shared by every worker replica,
+// so a job paused through one replica's admin API is paused on all of them
+type JobSettingsRepository interface {
+	SetPaused(job string, paused bool) error
+	// Paused reports whether job is paused; jobs never paused are not
+	Paused(job string) (bool, error)
+}
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"context"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,8 +16,32 @@ const (
 	StatusPicked     PackageStatus = "PICKED"
 	StatusHandedOver PackageStatus = "HANDED_OVER"
 	StatusExpired    PackageStatus = "EXPIRED"
+	// An expired package waits on the shelf until the hold period passes,
+	// then goes back to its carrier or is disposed of
+	StatusReturnPending PackageStatus = "RETURN_PENDING"
+	StatusReturned      PackageStatus = "RETURNED"
+	StatusDisposed      PackageStatus = "DISPOSED"
 )
 
+// PackageStatuses lists every package status in lifecycle order
+var PackageStatuses = []PackageStatus{
+	StatusWaiting, StatusPicked, StatusHandedOver, StatusExpired,
+	StatusReturnPending, StatusReturned, StatusDisposed,
+}
+
+// Valid reports whether s is a known package status
+func (s PackageStatus) Valid() bool {
+	for _, status := range PackageStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrDuplicateOrderRef is returned when an order reference is already taken
+var ErrDuplicateOrderRef = NewError(KindConflict, "DUPLICATE_ORDER_REF", "order reference already exists")
+
 // Package represents a package in the pickup queue
 type Package struct {
 	ID           uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
@@ -27,37 +53,269 @@ type Package struct {
 	PickedUpAt   *time.Time    `json:"picked_up_at,omitempty"`
 	HandedOverAt *time.Time    `json:"handed_over_at,omitempty"`
 	ExpiredAt    *time.Time    `json:"expired_at,omitempty"`
+	// ReturnPendingAt, ReturnedAt and DisposedAt stamp the post-expiry statuses
+	ReturnPendingAt *time.Time `json:"return_pending_at,omitempty"`
+	ReturnedAt      *time.Time `json:"returned_at,omitempty"`
+	DisposedAt      *time.Time `json:"disposed_at,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+	// RecipientPhone lets the recipient verify themselves on the public
+	// tracking endpoint
+	RecipientPhone string `json:"recipient_phone,omitempty"`
+	// Carrier names the carrier whose identifier rules the package was
+	// validated against; empty for the site's default rules
+	Carrier string `json:"carrier,omitempty"`
+
+	// Physical attributes, all optional. Dimensions are given together or
+	// not at all; SizeClass is derived from them on create.
+	LengthCm    *int      `json:"length_cm,omitempty"`
+	WidthCm     *int      `json:"width_cm,omitempty"`
+	HeightCm    *int      `json:"height_cm,omitempty"`
+	WeightGrams *int      `json:"weight_grams,omitempty"`
+	SizeClass   SizeClass `json:"size_class"`
+	// DeclaredValue is in minor units of the site's currency
+	DeclaredValue *int64      `json:"declared_value,omitempty"`
+	Fragile       bool        `json:"fragile"`
+	Temperature   Temperature `json:"temperature"`
+	// ParcelCount is how many physical parcels make up the package
+	ParcelCount int `json:"parcel_count"`
+
+	// ShipmentID is set on the parcels of a multi-parcel shipment;
+	// ParcelNumber is the parcel's position in it, counting from 1
+	ShipmentID   *uuid.UUID `json:"shipment_id,omitempty"`
+	ParcelNumber int        `json:"parcel_number,omitempty"`
+
+	// SlotLocation is the shelf slot the package is stored in, as printed
+	// on its label
+	SlotLocation string `json:"slot_location,omitempty"`
+
+	// Priority is the package's service class; it orders lists and the
+	// pickup queue and sets the expiry window and pickup target
+	Priority Priority `json:"priority"`
+
+	// QueuePosition and EstimatedPickupAt are filled in for WAITING packages
+	// when they are read through the API; they are not stored
+	QueuePosition     int        `json:"queue_position,omitempty"`
+	EstimatedPickupAt *time.Time `json:"estimated_pickup_at,omitempty"`
 }
 
-// PackageRepository defines the interface for package data operations
+// SizeClass buckets packages by the shelf space they need
+type SizeClass string
+
+const (
+	SizeSmall  SizeClass = "SMALL"
+	SizeMedium SizeClass = "MEDIUM"
+	SizeLarge  SizeClass = "LARGE"
+	SizeXLarge SizeClass = "XLARGE"
+	// SizeUnknown is used for packages created without dimensions
+	SizeUnknown SizeClass = "UNKNOWN"
+)
+
+// SizeClasses lists every size class, smallest first
+var SizeClasses = []SizeClass{SizeSmall, SizeMedium, SizeLarge, SizeXLarge, SizeUnknown}
+
+// sizeClassLimits are the largest boxes, longest side first, that still fit
+// each class
+var sizeClassLimits = []struct {
+	class  SizeClass
+	limits [3]int
+}{
+	{SizeSmall, [3]int{35, 25, 10}},
+	{SizeMedium, [3]int{45, 35, 20}},
+	{SizeLarge, [3]int{65, 45, 40}},
+}
+
+// ClassifySize returns the smallest size class a box of the given dimensions
+// fits in, in any orientation
+func ClassifySize(lengthCm, widthCm, heightCm int) SizeClass {
+	sides := [3]int{lengthCm, widthCm, heightCm}
+	sort.Sort(sort.Reverse(sort.IntSlice(sides[:])))
+	for _, size := range sizeClassLimits {
+		if sides[0] <= size.limits[0] && sides[1] <= size.limits[1] && sides[2] <= size.limits[2] {
+			return size.class
+		}
+	}
+	return SizeXLarge
+}
+
+// Temperature is the storage a package needs
+type Temperature string
+
+const (
+	TemperatureAmbient Temperature = "AMBIENT"
+	TemperatureChilled Temperature = "CHILLED"
+	TemperatureFrozen  Temperature = "FROZEN"
+)
+
+// Valid reports whether t is a known temperature
+func (t Temperature) Valid() bool {
+	switch t {
+	case TemperatureAmbient, TemperatureChilled, TemperatureFrozen:
+		return true
+	}
+	return false
+}
+
+// Priority is the service class a package was shipped with
+type Priority string
+
+const (
+	PriorityExpress  Priority = "EXPRESS"
+	PriorityStandard Priority = "STANDARD"
+	PriorityEconomy  Priority = "ECONOMY"
+)
+
+// Priorities lists every priority, most urgent first
+var Priorities = []Priority{PriorityExpress, PriorityStandard, PriorityEconomy}
+
+// Valid reports whether p is a known priority
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityExpress, PriorityStandard, PriorityEconomy:
+		return true
+	}
+	return false
+}
+
+// Rank orders priorities, most urgent first; unknown priorities rank as
+// STANDARD
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityExpress:
+		return 0
+	case PriorityEconomy:
+		return 2
+	}
+	return 1
+}
+
+// PackageRepository defines the interface for package data operations.
+// Deleted packages are hidden from every read until they are restored.
 type PackageRepository interface {
 	Create(pkg *Package) error
 	GetByID(id uuid.UUID) (*Package, error)
 	GetByOrderRef(orderRef string) (*Package, error)
-	GetAll(limit, offset int, status *PackageStatus) ([]*Package, error)
+	// GetAll returns matching packages, most urgent priority first and
+	// newest first within a priority
+	GetAll(filter PackageFilter) ([]*Package, error)
 	Update(pkg *Package) error
+	// Delete soft-deletes a package; it stays restorable until purged
 	Delete(id uuid.UUID) error
-	GetExpiredPackages() ([]*Package, error)
+	// Restore undoes Delete; restoring a live or unknown package is a no-op
+	Restore(id uuid.UUID) error
+	// PurgeDeleted permanently removes packages deleted before the cutoff
+	// and returns how many were removed
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	GetExpiredPackages(ctx context.Context, cutoff time.Time) ([]*Package, error)
+	// GetExpiredBefore returns EXPIRED packages that expired before the
+	// cutoff, the ones due to go back to the sender
+	GetExpiredBefore(ctx context.Context, cutoff time.Time) ([]*Package, error)
 	UpdateStatus(id uuid.UUID, status PackageStatus) error
 	GetPackageStats() (*PackageStats, error)
+	// WaitingPositions returns the queue positions, counting from 1, of the
+	// given packages among live WAITING packages, most urgent priority first
+	// and oldest first within a priority. Packages that are not waiting are
+	// left out.
+	WaitingPositions(ids []uuid.UUID) (map[uuid.UUID]int, error)
+	// RecentPickupTimes returns up to limit of the latest picked_up_at
+	// times of live packages, newest first
+	RecentPickupTimes(limit int) ([]time.Time, error)
+}
+
+// PackageFilter selects packages for GetAll, newest first. A Limit of zero
+// or less returns every match.
+type PackageFilter struct {
+	Limit      int
+	Offset     int
+	Status     *PackageStatus
+	DriverCode string
+	SizeClass  SizeClass
+	Fragile    *bool
+	// Temperature matches packages needing that storage
+	Temperature Temperature
+	// MinDeclaredValue matches packages declared at least this valuable
+	MinDeclaredValue *int64
+	// ShipmentID matches the parcels of one shipment
+	ShipmentID *uuid.UUID
+	Priority   Priority
+	// HighValue is resolved into MinDeclaredValue by the package usecase
+	// from the site's high-value threshold; repositories ignore it
+	HighValue bool
 }
 
 // PackageStats represents aggregated package statistics
 type PackageStats struct {
-	Total      int64 `json:"total"`
-	Waiting    int64 `json:"waiting"`
-	Picked     int64 `json:"picked"`
-	HandedOver int64 `json:"handed_over"`
-	Expired    int64 `json:"expired"`
+	Total         int64 `json:"total"`
+	Waiting       int64 `json:"waiting"`
+	Picked        int64 `json:"picked"`
+	HandedOver    int64 `json:"handed_over"`
+	Expired       int64 `json:"expired"`
+	ReturnPending int64 `json:"return_pending"`
+	Returned      int64 `json:"returned"`
+	Disposed      int64 `json:"disposed"`
+	// BySizeClass counts packages per size class; every class is present
+	BySizeClass map[SizeClass]int64 `json:"by_size_class"`
 }
 
 // CreatePackageRequest represents the request to create a new package
 type CreatePackageRequest struct {
-	OrderRef   string `json:"order_reference" binding:"required"`
-	DriverCode string `json:"driver_code"`
+	OrderRef       string `json:"order_reference" binding:"required"`
+	DriverCode     string `json:"driver_code"`
+	RecipientPhone string `json:"recipient_phone"`
+	// Carrier selects that carrier's order reference and driver code rules
+	Carrier string `json:"carrier"`
+	// Priority defaults to STANDARD
+	Priority Priority `json:"priority"`
+
+	PackageAttributes
+	// ParcelCount defaults to 1
+	ParcelCount int `json:"parcel_count"`
+}
+
+// PackageAttributes are the optional physical attributes and shelf slot given
+// on create; dimensions must be given together
+type PackageAttributes struct {
+	LengthCm      *int        `json:"length_cm"`
+	WidthCm       *int        `json:"width_cm"`
+	HeightCm      *int        `json:"height_cm"`
+	WeightGrams   *int        `json:"weight_grams"`
+	DeclaredValue *int64      `json:"declared_value"`
+	Fragile       bool        `json:"fragile"`
+	Temperature   Temperature `json:"temperature"`
+	SlotLocation  string      `json:"slot_location"`
 }
 
 // UpdatePackageStatusRequest represents the request to update package status
 type UpdatePackageStatusRequest struct {
 	Status PackageStatus `json:"status" binding:"required"`
+	// VerificationToken is the last 4 digits of the recipient's phone; it is
+	// required to hand over high-value packages
+	VerificationToken string `json:"verification_token"`
+}
+
+// BatchUpdateStatusRequest moves several packages to the same status at once.
+// Packages may be addressed by ID, by order reference, or both.
+type BatchUpdateStatusRequest struct {
+	IDs          []uuid.UUID   `json:"ids"`
+	OrderRefs    []string      `json:"order_references"`
+	Status       PackageStatus `json:"status" binding:"required"`
+	AllOrNothing bool          `json:"all_or_nothing"`
+}
+
+// BatchLabelRequest asks for the labels of several packages in one
+// document, IDs first and then order references, in request order
+type BatchLabelRequest struct {
+	IDs       []uuid.UUID `json:"ids"`
+	OrderRefs []string    `json:"order_references"`
+	// Format is pdf or zpl; it defaults to pdf
+	Format string `json:"format"`
+}
+
+// BatchStatusResult is the outcome for one item of a batch status update
+type BatchStatusResult struct {
+	ID       *uuid.UUID `json:"id,omitempty"`
+	OrderRef string     `json:"order_reference,omitempty"`
+	Success  bool       `json:"success"`
+	Error    string     `json:"error,omitempty"`
+	Package  *Package   `json:"package,omitempty"`
 }
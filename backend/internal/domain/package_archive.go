@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchivableStatuses are the terminal statuses the archive job moves out of
+// the packages table. EXPIRED is not one of them: an expired package is still
+// on the shelf until it is returned or disposed of.
+var ArchivableStatuses = []PackageStatus{StatusHandedOver, StatusReturned, StatusDisposed}
+
+// PackageArchiveRepository holds terminal packages moved out of the packages
+// table. Archived packages keep their ID and order reference and carry
+// ArchivedAt when read back.
+type PackageArchiveRepository interface {
+	// ArchiveBefore moves up to limit terminal packages last updated before
+	// the cutoff into the archive and returns how many were moved
+	ArchiveBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	GetByID(id uuid.UUID) (*Package, error)
+	GetByOrderRef(orderRef string) (*Package, error)
+	// Rehydrate moves an archived package back into the packages table;
+	// rehydrating a package that is not archived is a no-op
+	Rehydrate(id uuid.UUID) error
+}
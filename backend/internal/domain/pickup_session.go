@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PickupSessionStatus represents the status of a driver pickup session
+type PickupSessionStatus string
+
+const (
+	SessionOpen   PickupSessionStatus = "OPEN"
+	SessionClosed PickupSessionStatus = "CLOSED"
+)
+
+// ScanResult is the outcome of scanning one order reference in a session
+type ScanResult string
+
+const (
+	// ScanPicked means the package was handed to the driver and marked PICKED
+	ScanPicked ScanResult = "PICKED"
+	// ScanExtra means the order reference is unknown or belongs to another driver
+	ScanExtra ScanResult = "EXTRA"
+	// ScanRejected means the package is the driver's but cannot be picked, e.g. it expired
+	ScanRejected ScanResult = "REJECTED"
+)
+
+// ErrOpenSessionExists is returned when a driver already has an open session
+var ErrOpenSessionExists = errors.New("driver already has an open pickup session")
+
+// PickupSession is one driver visit to the counter. The packages that were
+// WAITING for the driver at check-in are recorded as expected items; scans
+// fill them in or add extra items.
+type PickupSession struct {
+	ID         uuid.UUID            `json:"id"`
+	DriverCode string               `json:"driver_code"`
+	Status     PickupSessionStatus  `json:"status"`
+	OpenedAt   time.Time            `json:"opened_at"`
+	ClosedAt   *time.Time           `json:"closed_at,omitempty"`
+	Items      []*PickupSessionItem `json:"items"`
+
+	// Appointment and Warning are filled in when the driver checks in and
+	// are not stored: the booking that covers the check-in, or why none does
+	Appointment *Appointment `json:"appointment,omitempty"`
+	Warning     string       `json:"warning,omitempty"`
+}
+
+// PickupSessionItem is an expected package, a scan, or both
+type PickupSessionItem struct {
+	OrderRef  string     `json:"order_reference"`
+	PackageID *uuid.UUID `json:"package_id,omitempty"`
+	Expected  bool       `json:"expected"`
+	Result    ScanResult `json:"result,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ScannedAt *time.Time `json:"scanned_at,omitempty"`
+}
+
+// PickupSessionReport summarises a closed session against the check-in list.
+// Missing holds expected packages that were not picked, whether they were
+// never scanned or rejected at the counter.
+type PickupSessionReport struct {
+	Session  *PickupSession `json:"session"`
+	Expected int            `json:"expected"`
+	Picked   []string       `json:"picked"`
+	Missing  []string       `json:"missing"`
+	Extra    []string       `json:"extra"`
+	Rejected []string       `json:"rejected"`
+}
+
+// PickupSessionRepository defines the interface for pickup session storage
+type PickupSessionRepository interface {
+	// Create stores a session with its expected items, or returns
+	// ErrOpenSessionExists if the driver already has an open session
+	Create(session *PickupSession) error
+	GetByID(id uuid.UUID) (*PickupSession, error)
+	GetOpenByDriver(driverCode string) (*PickupSession, error)
+	// ListOpen returns every open session with its items, earliest check-in
+	// first
+	ListOpen() ([]*PickupSession, error)
+	// RecordScan stores the scan outcome on the item with the same order
+	// reference, adding the item if it was not expected
+	RecordScan(sessionID uuid.UUID, item *PickupSessionItem) error
+	Close(id uuid.UUID, closedAt time.Time) error
+}
+
+// OpenPickupSessionRequest checks a driver in. Badge scanners send the code
+// printed on the driver's badge, so either field may carry the driver code.
+type OpenPickupSessionRequest struct {
+	DriverCode string `json:"driver_code"`
+	Badge      string `json:"badge"`
+}
+
+// ScanPackageRequest scans one order reference into a session
+type ScanPackageRequest struct {
+	OrderRef string `json:"order_reference" binding:"required"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// QueueBoard is the lobby display: the drivers checked in at the counter in
+// the order they are served, with estimated waits from the recent pace
+type QueueBoard struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// PaceSeconds is the estimated time to hand over one package
+	PaceSeconds     float64       `json:"pace_seconds"`
+	WaitingPackages int64         `json:"waiting_packages"`
+	Drivers         []*QueueEntry `json:"drivers"`
+}
+
+// QueueEntry is one checked-in driver on the queue board. PackagesLeft counts
+// the expected packages not yet scanned; the driver's turn is estimated to
+// start once everyone ahead has collected theirs.
+type QueueEntry struct {
+	Position             int       `json:"position"`
+	DriverCode           string    `json:"driver_code"`
+	CheckedInAt          time.Time `json:"checked_in_at"`
+	PackagesLeft         int       `json:"packages_left"`
+	EstimatedStartAt     time.Time `json:"estimated_start_at"`
+	EstimatedWaitSeconds int64     `json:"estimated_wait_seconds"`
+}
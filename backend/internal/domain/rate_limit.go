@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// RateLimiter keeps one token bucket per key. A bucket holds up to perMinute
+// tokens and refills at perMinute tokens per minute; each request takes one.
+// When the bucket is empty Allow returns false and how long until the next
+// token is available.
+type RateLimiter interface {
+	Allow(key string, perMinute int) (ok bool, retryAfter time.Duration, err error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PickupReminder tracks the reminders sent to the recipient of a WAITING
+// package
+type PickupReminder struct {
+	PackageID uuid.UUID
+	// Count is how many reminders were sent
+	Count      int
+	LastSentAt time.Time
+}
+
+// PickupReminderRepository defines the interface for pickup reminder storage
+type PickupReminderRepository interface {
+	// Get returns the package's reminders, or nil if none were sent
+	Get(packageID uuid.UUID) (*PickupReminder, error)
+	// Record counts one more reminder for the package, sent at sentAt
+	Record(packageID uuid.UUID, sentAt time.Time) error
+}
+
+// PickupNotifier tells a package's recipient that it is waiting for pickup.
+// reminder is 1 for the first reminder of the package.
+type PickupNotifier interface {
+	RemindPickup(ctx context.Context, pkg *Package, reminder int) error
+}
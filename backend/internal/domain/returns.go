@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// ReturnsManifest lists the RETURN_PENDING packages to hand back, grouped by
+// the carrier and driver that brought them in
+type ReturnsManifest struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Total       int            `json:"total"`
+	Groups      []*ReturnGroup `json:"groups"`
+}
+
+// ReturnGroup is one carrier and driver's share of a returns manifest. An
+// empty Carrier stands for the site's default rules.
+type ReturnGroup struct {
+	Carrier    string     `json:"carrier"`
+	DriverCode string     `json:"driver_code"`
+	Count      int        `json:"count"`
+	Packages   []*Package `json:"packages"`
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShipmentStatus is derived from the statuses of a shipment's parcels
+type ShipmentStatus string
+
+const (
+	ShipmentWaiting             ShipmentStatus = "WAITING"
+	ShipmentPartiallyPicked     ShipmentStatus = "PARTIALLY_PICKED"
+	ShipmentPicked              ShipmentStatus = "PICKED"
+	ShipmentPartiallyHandedOver ShipmentStatus = "PARTIALLY_HANDED_OVER"
+	ShipmentHandedOver          ShipmentStatus = "HANDED_OVER"
+	ShipmentPartiallyExpired    ShipmentStatus = "PARTIALLY_EXPIRED"
+	ShipmentExpired             ShipmentStatus = "EXPIRED"
+	// ShipmentArchived means no parcel is left in the live table
+	ShipmentArchived ShipmentStatus = "ARCHIVED"
+)
+
+// Shipment groups the parcels of one order. The order reference belongs to
+// the shipment; each parcel is a Package with its own parcel reference.
+type Shipment struct {
+	ID         uuid.UUID `json:"id"`
+	OrderRef   string    `json:"order_reference"`
+	DriverCode string    `json:"driver_code"`
+	Carrier    string    `json:"carrier,omitempty"`
+	// ParcelCount is how many parcels the shipment was created with
+	ParcelCount int       `json:"parcel_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Status and Parcels are filled in from the live parcels when the
+	// shipment is read through the usecase; they are not stored
+	Status  ShipmentStatus `json:"status,omitempty"`
+	Parcels []*Package     `json:"parcels,omitempty"`
+}
+
+// DeriveShipmentStatus summarizes parcel statuses. A status reached by every
+// parcel is the shipment's status; one reached by only some makes it partial,
+// with handed over parcels taking precedence over expired and picked ones.
+// Parcels being returned, returned or disposed of count as expired. Without
+// parcels the shipment has been archived.
+func DeriveShipmentStatus(parcels []*Package) ShipmentStatus {
+	if len(parcels) == 0 {
+		return ShipmentArchived
+	}
+
+	counts := make(map[PackageStatus]int, 4)
+	for _, parcel := range parcels {
+		switch parcel.Status {
+		case StatusReturnPending, StatusReturned, StatusDisposed:
+			counts[StatusExpired]++
+		default:
+			counts[parcel.Status]++
+		}
+	}
+	all := func(status PackageStatus) bool { return counts[status] == len(parcels) }
+
+	switch {
+	case all(StatusHandedOver):
+		return ShipmentHandedOver
+	case counts[StatusHandedOver] > 0:
+		return ShipmentPartiallyHandedOver
+	case all(StatusExpired):
+		return ShipmentExpired
+	case counts[StatusExpired] > 0:
+		return ShipmentPartiallyExpired
+	case all(StatusPicked):
+		return ShipmentPicked
+	case counts[StatusPicked] > 0:
+		return ShipmentPartiallyPicked
+	default:
+		return ShipmentWaiting
+	}
+}
+
+// ShipmentRepository stores shipments; their parcels live in the
+// PackageRepository and point back with Package.ShipmentID
+type ShipmentRepository interface {
+	Create(shipment *Shipment) error
+	GetByID(id uuid.UUID) (*Shipment, error)
+	GetByOrderRef(orderRef string) (*Shipment, error)
+	// GetAll returns shipments newest first; a Limit of zero or less
+	// returns every match
+	GetAll(filter ShipmentFilter) ([]*Shipment, error)
+}
+
+// ShipmentFilter selects shipments for GetAll
+type ShipmentFilter struct {
+	Limit      int
+	Offset     int
+	DriverCode string
+}
+
+// CreateShipmentRequest creates a shipment together with its parcels
+type CreateShipmentRequest struct {
+	OrderRef       string `json:"order_reference" binding:"required"`
+	DriverCode     string `json:"driver_code"`
+	RecipientPhone string `json:"recipient_phone"`
+	Carrier        string `json:"carrier"`
+	// Priority applies to every parcel and defaults to STANDARD
+	Priority Priority              `json:"priority"`
+	Parcels  []CreateParcelRequest `json:"parcels" binding:"required,min=1"`
+}
+
+// CreateParcelRequest describes one parcel of a shipment. Its reference
+// defaults to the shipment's order reference followed by "/" and the
+// parcel's position, counting from 1.
+type CreateParcelRequest struct {
+	ParcelRef string `json:"parcel_reference"`
+	PackageAttributes
+}
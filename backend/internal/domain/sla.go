@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SLABreach records a package that was still WAITING when its priority's
+// pickup target passed. The worker records each package once; the record
+// stays after the package is picked up, archived or purged.
+type SLABreach struct {
+	PackageID  uuid.UUID `json:"package_id"`
+	OrderRef   string    `json:"order_reference"`
+	DriverCode string    `json:"driver_code"`
+	Priority   Priority  `json:"priority"`
+	// DueAt is when the package should have been picked up
+	DueAt      time.Time `json:"due_at"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// SLABreachFilter selects breaches detected in [From, To), optionally of one
+// priority
+type SLABreachFilter struct {
+	From     time.Time
+	To       time.Time
+	Priority Priority
+}
+
+// SLABreachRepository defines the interface for SLA breach storage
+type SLABreachRepository interface {
+	// Record stores the breach unless its package already has one and
+	// reports whether it was stored
+	Record(breach *SLABreach) (bool, error)
+	// List returns matching breaches, most recently detected first
+	List(filter SLABreachFilter) ([]*SLABreach, error)
+}
+
+// SLABreachReport lists the breaches detected in a period with a count per
+// priority; every priority is present in ByPriority
+type SLABreachReport struct {
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Total      int              `json:"total"`
+	ByPriority map[Priority]int `json:"by_priority"`
+	Breaches   []*SLABreach     `json:"breaches"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// TrackingRequest asks for the status of a parcel on behalf of its recipient.
+// VerificationToken is the last 4 digits of the recipient phone number.
+type TrackingRequest struct {
+	OrderRef          string `json:"order_reference" binding:"required"`
+	VerificationToken string `json:"verification_token" binding:"required"`
+}
+
+// TrackingEvent is one step of a parcel's history as shown to its recipient
+type TrackingEvent struct {
+	Status      PackageStatus `json:"status"`
+	Description string        `json:"description"`
+	At          time.Time     `json:"at"`
+}
+
+// PickupLocation tells the recipient where and when to collect the parcel
+type PickupLocation struct {
+	Name    string   `json:"name"`
+	Address string   `json:"address,omitempty"`
+	Hours   []string `json:"hours,omitempty"`
+}
+
+// TrackingInfo is the public view of a package. It deliberately leaves out
+// internal IDs, the driver and the recipient's phone number.
+type TrackingInfo struct {
+	OrderRef    string          `json:"order_reference"`
+	Status      PackageStatus   `json:"status"`
+	Description string          `json:"description"`
+	Timeline    []TrackingEvent `json:"timeline"`
+	// CollectBy is when an uncollected parcel expires; only set while it
+	// can still be collected
+	CollectBy      *time.Time      `json:"collect_by,omitempty"`
+	PickupLocation *PickupLocation `json:"pickup_location,omitempty"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+// IsolationLevel is the transaction isolation level requested by a usecase
+type IsolationLevel int
+
+const (
+	IsolationDefault IsolationLevel = iota
+	IsolationReadCommitted
+	IsolationRepeatableRead
+	IsolationSerializable
+)
+
+// TxOptions configures a unit of work
+type TxOptions struct {
+	Isolation IsolationLevel
+	ReadOnly  bool
+}
+
+// Tx gives access to repositories that all share one transaction
+type Tx interface {
+	Packages() PackageRepository
+	DriverAssignments() DriverAssignmentRepository
+	Shipments() ShipmentRepository
+}
+
+// UnitOfWork runs several repository calls atomically. If fn returns an error
+// or panics, every write made through tx is rolled back.
+type UnitOfWork interface {
+	Do(opts TxOptions, fn func(tx Tx) error) error
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
+	"pickup-queue/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AppointmentHandler struct {
+	appointmentUsecase *usecase.AppointmentUsecase
+}
+
+func NewAppointmentHandler(appointmentUsecase *usecase.AppointmentUsecase) *AppointmentHandler {
+	return &AppointmentHandler{
+		appointmentUsecase: appointmentUsecase,
+	}
+}
+
+// ListWindows lists the pickup windows of a day
+// @Summary List pickup windows
+// @Description List the site's pickup windows of a day with their capacity and the places left
+// @Tags appointments
+// @Produce json
+// @Param date query string false "Day as YYYY-MM-DD in the site's time zone, today by default"
+// @Success 200 {object} PickupWindowListResponse
+// @Failure 400 {object} middleware.Problem
+// @Router /appointments/windows [get]
+func (h *AppointmentHandler) ListWindows(c *gin.Context) {
+	windows, err := h.appointmentUsecase.Windows(c.Query("date"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, PickupWindowListResponse{Data: windows})
+}
+
+// GetSchedule shows the booking plan of a day
+// @Summary Get the daily pickup schedule
+// @Description List the pickup windows of a day with the drivers booked in each and how many packages they have waiting
+// @Tags appointments
+// @Produce json
+// @Param date query string false "Day as YYYY-MM-DD in the site's time zone, today by default"
+// @Success 200 {object} domain.PickupSchedule
+// @Failure 400 {object} middleware.Problem
+// @Router /appointments/schedule [get]
+func (h *AppointmentHandler) GetSchedule(c *gin.Context) {
+	schedule, err := h.appointmentUsecase.Schedule(c.Query("date"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: schedule})
+}
+
+// BookAppointment books a pickup window for a driver
+// @Summary Book a pickup window
+// @Description Book a pickup window for a driver with WAITING packages. A driver holds at most one appointment per day.
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param appointment body domain.BookAppointmentRequest true "Driver and window start"
+// @Success 201 {object} domain.Appointment
+// @Failure 400 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Router /appointments [post]
+func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
+	var req domain.BookAppointmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	appointment, err := h.appointmentUsecase.BookAppointment(&req, principal.Name)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{Data: appointment})
+}
+
+// ListAppointments lists a driver's upcoming appointments
+// @Summary List a driver's appointments
+// @Tags appointments
+// @Produce json
+// @Param driver_code query string true "Driver code"
+// @Success 200 {object} AppointmentListResponse
+// @Failure 400 {object} middleware.Problem
+// @Router /appointments [get]
+func (h *AppointmentHandler) ListAppointments(c *gin.Context) {
+	appointments, err := h.appointmentUsecase.ListDriverAppointments(c.Query("driver_code"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AppointmentListResponse{Data: appointments})
+}
+
+// GetAppointment gets an appointment by ID
+// @Summary Get an appointment
+// @Tags appointments
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} domain.Appointment
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /appointments/{id} [get]
+func (h *AppointmentHandler) GetAppointment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	appointment, err := h.appointmentUsecase.GetAppointment(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: appointment})
+}
+
+// CancelAppointment cancels an appointment
+// @Summary Cancel an appointment
+// @Description Cancel an appointment and free its place in the window. Cancelling twice is harmless.
+// @Tags appointments
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} domain.Appointment
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /appointments/{id}/cancel [post]
+func (h *AppointmentHandler) CancelAppointment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	appointment, err := h.appointmentUsecase.CancelAppointment(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: appointment})
+}
+
+type PickupWindowListResponse struct {
+	Data []*domain.PickupWindow `json:"data"`
+}
+
+type AppointmentListResponse struct {
+	Data []*domain.Appointment `json:"data"`
+}
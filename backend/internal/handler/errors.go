@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var errMalformedBody = domain.NewError(domain.KindValidation, "MALFORMED_BODY", "request body is not valid JSON")
+
+// bindJSON binds the request body into obj. When that fails it records a
+// problem naming the invalid fields by their JSON names and returns false.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	var invalid validator.ValidationErrors
+	switch {
+	case errors.As(err, &tooLarge):
+		_ = c.Error(middleware.ErrBodyTooLarge)
+	case errors.As(err, &invalid):
+		fields := make([]domain.FieldError, 0, len(invalid))
+		for _, fe := range invalid {
+			name := jsonFieldName(obj, fe.StructField())
+			fields = append(fields, domain.FieldError{
+				Field:   name,
+				Code:    fe.Tag(),
+				Message: validationMessage(name, fe),
+			})
+		}
+		_ = c.Error(domain.NewValidationError(fields...))
+	default:
+		_ = c.Error(errMalformedBody)
+	}
+	return false
+}
+
+// parseID parses the UUID path parameter param, recording a problem if it is
+// not one
+func parseID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		_ = c.Error(domain.NewError(domain.KindValidation, "INVALID_ID", "invalid "+param).WithFields(domain.FieldError{
+			Field:   param,
+			Code:    "invalid",
+			Message: param + " must be a UUID",
+		}))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// jsonFieldName returns the JSON name of the struct field of obj called field
+func jsonFieldName(obj interface{}, field string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return field
+	}
+	f, ok := t.FieldByName(field)
+	if !ok {
+		return field
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field
+	}
+	return name
+}
+
+func validationMessage(name string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return name + " is required"
+	case "oneof":
+		return name + " must be one of " + fe.Param()
+	case "min":
+		return name + " must be at least " + fe.Param()
+	case "max":
+		return name + " must be at most " + fe.Param()
+	default:
+		return name + " is invalid"
+	}
+}
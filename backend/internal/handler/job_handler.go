@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/scheduler"
+	"pickup-queue/internal/usecase"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler serves the worker admin API
+type JobHandler struct {
+	scheduler      *scheduler.Scheduler
+	packageUsecase *usecase.PackageUsecase
+	// runCtx bounds runs triggered through the API; it outlives the request
+	runCtx context.Context
+}
+
+func NewJobHandler(runCtx context.Context, sched *scheduler.Scheduler, packageUsecase *usecase.PackageUsecase) *JobHandler {
+	return &JobHandler{
+		scheduler:      sched,
+		packageUsecase: packageUsecase,
+		runCtx:         runCtx,
+	}
+}
+
+// ListJobs lists the worker's jobs
+// @Summary List worker jobs
+// @Description Schedule, pause state, next run and last recorded run of every job. Requires the admin role.
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} scheduler.JobStatus
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Router /admin/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	statuses, err := h.scheduler.Statuses()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: statuses})
+}
+
+// GetJob gets one worker job
+// @Summary Get a worker job
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Job name"
+// @Success 200 {object} scheduler.JobStatus
+// @Failure 404 {object} middleware.Problem
+// @Router /admin/jobs/{name} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	status, err := h.scheduler.Status(c.Param("name"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: status})
+}
+
+// ListJobRuns lists the run history of a job
+// @Summary List job runs
+// @Description Latest runs of a job across all worker replicas, newest first
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Job name"
+// @Param limit query int false "Limit" default(20)
+// @Success 200 {array} domain.JobRun
+// @Failure 404 {object} middleware.Problem
+// @Router /admin/jobs/{name}/runs [get]
+func (h *JobHandler) ListJobRuns(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	runs, err := h.scheduler.Runs(c.Param("name"), limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if runs == nil {
+		runs = []*domain.JobRun{}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: runs})
+}
+
+// TriggerJob starts a job run now
+// @Summary Trigger a job run
+// @Description Start the job immediately, outside its schedule, even when it is paused. Returns the RUNNING run; poll the run history for the outcome.
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Job name"
+// @Success 202 {object} domain.JobRun
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Router /admin/jobs/{name}/run [post]
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	run, err := h.scheduler.Trigger(h.runCtx, c.Param("name"), domain.TriggerManual)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{Data: run})
+}
+
+// PauseJob stops scheduled runs of a job
+// @Summary Pause a job
+// @Description Skip the job's scheduled runs on every worker replica until it is resumed. Manual runs still work.
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Job name"
+// @Success 200 {object} scheduler.JobStatus
+// @Failure 404 {object} middleware.Problem
+// @Router /admin/jobs/{name}/pause [post]
+func (h *JobHandler) PauseJob(c *gin.Context) {
+	h.setPaused(c, h.scheduler.Pause)
+}
+
+// ResumeJob restarts scheduled runs of a paused job
+// @Summary Resume a job
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Job name"
+// @Success 200 {object} scheduler.JobStatus
+// @Failure 404 {object} middleware.Problem
+// @Router /admin/jobs/{name}/resume [post]
+func (h *JobHandler) ResumeJob(c *gin.Context) {
+	h.setPaused(c, h.scheduler.Resume)
+}
+
+func (h *JobHandler) setPaused(c *gin.Context, apply func(name string) error) {
+	name := c.Param("name")
+	if err := apply(name); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	status, err := h.scheduler.Status(name)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: status})
+}
+
+// PreviewExpiry lists the packages the expiry job would expire now
+// @Summary Dry-run package expiry
+// @Description Return the packages MarkExpiredPackages would mark EXPIRED with the current expiry window, without changing them
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} ExpiryPreviewResponse
+// @Router /admin/expiry/dry-run [get]
+func (h *JobHandler) PreviewExpiry(c *gin.Context) {
+	packages, cutoff, err := h.packageUsecase.PreviewExpiredPackages(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if packages == nil {
+		packages = []*domain.Package{}
+	}
+
+	c.JSON(http.StatusOK, ExpiryPreviewResponse{
+		Data:   packages,
+		Cutoff: cutoff,
+		Count:  len(packages),
+	})
+}
+
+type ExpiryPreviewResponse struct {
+	Data []*domain.Package `json:"data"`
+	// Cutoff is the creation time before which active packages expire
+	Cutoff time.Time `json:"cutoff"`
+	Count  int       `json:"count"`
+}
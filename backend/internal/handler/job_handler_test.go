@@ -0,0 +1,209 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/handler"
+	"pickup-queue/internal/middleware"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/scheduler"
+	"pickup-queue/internal/usecase"
+	"pickup-queue/pkg/logger"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJobRouter(t *testing.T, mockRepo *MockPackageRepository, jobs ...scheduler.Job) (*gin.Engine, *scheduler.Scheduler) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Errors())
+
+	sched := scheduler.New(repository.NewLocalJobLocker(), repository.NewMemoryJobRunRepository(), logger.New())
+	for _, job := range jobs {
+		require.NoError(t, sched.Register(job))
+	}
+	jobHandler := handler.NewJobHandler(context.Background(), sched, usecase.NewPackageUsecase(mockRepo))
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/jobs", jobHandler.ListJobs)
+		admin.GET("/jobs/:name", jobHandler.GetJob)
+		admin.GET("/jobs/:name/runs", jobHandler.ListJobRuns)
+		admin.POST("/jobs/:name/run", jobHandler.TriggerJob)
+		admin.POST("/jobs/:name/pause", jobHandler.PauseJob)
+		admin.POST("/jobs/:name/resume", jobHandler.ResumeJob)
+		admin.GET("/expiry/dry-run", jobHandler.PreviewExpiry)
+	}
+
+	return router, sched
+}
+
+func TestJobHandler_TriggerJob_HappyPath(t *testing.T) {
+	// Setup
+	router, _ := setupJobRouter(t, new(MockPackageRepository), scheduler.Job{
+		Name:     "expire-packages",
+		Schedule: scheduler.Every(time.Hour),
+		Run:      func(ctx context.Context) (string, error) { return "expired packages check completed", nil },
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/admin/jobs/expire-packages/run", nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response struct {
+		Data domain.JobRun `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, domain.TriggerManual, response.Data.Trigger)
+	assert.Equal(t, domain.JobRunRunning, response.Data.Status)
+
+	// The run finishes in the background and shows up as the job's last run
+	assert.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/admin/jobs/expire-packages", nil)
+		router.ServeHTTP(w, req)
+		var status struct {
+			Data scheduler.JobStatus `json:"data"`
+		}
+		return json.Unmarshal(w.Body.Bytes(), &status) == nil &&
+			status.Data.LastRun != nil && status.Data.LastRun.Status == domain.JobRunSucceeded
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestJobHandler_TriggerJob_EdgeCase_AlreadyRunning(t *testing.T) {
+	// Setup
+	release := make(chan struct{})
+	defer close(release)
+	router, _ := setupJobRouter(t, new(MockPackageRepository), scheduler.Job{
+		Name:     "archive-packages",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			<-release
+			return "", nil
+		},
+	})
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/admin/jobs/archive-packages/run", nil))
+	require.Equal(t, http.StatusAccepted, first.Code)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/jobs/archive-packages/run", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestJobHandler_EdgeCase_UnknownJob(t *testing.T) {
+	// Setup
+	router, _ := setupJobRouter(t, new(MockPackageRepository))
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/admin/jobs/missing"},
+		{http.MethodGet, "/admin/jobs/missing/runs"},
+		{http.MethodPost, "/admin/jobs/missing/run"},
+		{http.MethodPost, "/admin/jobs/missing/pause"},
+		{http.MethodPost, "/admin/jobs/missing/resume"},
+	} {
+		// Execute
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(route.method, route.path, nil))
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Code, route.path)
+	}
+}
+
+func TestJobHandler_PauseJob_HappyPath(t *testing.T) {
+	// Setup
+	router, sched := setupJobRouter(t, new(MockPackageRepository), scheduler.Job{
+		Name:     "purge-idempotency-keys",
+		Schedule: scheduler.Every(time.Hour),
+		Run:      func(ctx context.Context) (string, error) { return "", nil },
+	})
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/jobs/purge-idempotency-keys/pause", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data scheduler.JobStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Paused)
+
+	status, err := sched.Status("purge-idempotency-keys")
+	require.NoError(t, err)
+	assert.True(t, status.Paused)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/jobs/purge-idempotency-keys/resume", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	status, err = sched.Status("purge-idempotency-keys")
+	require.NoError(t, err)
+	assert.False(t, status.Paused)
+}
+
+func TestJobHandler_ListJobs_HappyPath(t *testing.T) {
+	// Setup
+	noop := func(ctx context.Context) (string, error) { return "", nil }
+	router, _ := setupJobRouter(t, new(MockPackageRepository),
+		scheduler.Job{Name: "purge-deleted-packages", Schedule: scheduler.Every(time.Hour), Run: noop},
+		scheduler.Job{Name: "archive-packages", Schedule: scheduler.Every(time.Hour), Run: noop},
+	)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []scheduler.JobStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "archive-packages", response.Data[0].Name)
+	assert.Equal(t, "purge-deleted-packages", response.Data[1].Name)
+}
+
+func TestJobHandler_PreviewExpiry_HappyPath(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	router, _ := setupJobRouter(t, mockRepo)
+	due := []*domain.Package{
+		{ID: uuid.New(), OrderRef: "OLD-001", DriverCode: "DRV-001", Status: domain.StatusWaiting, CreatedAt: time.Now().Add(-48 * time.Hour)},
+	}
+
+	// Mock expectations
+	mockRepo.On("GetExpiredPackages", mock.AnythingOfType("time.Time")).Return(due, nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/expiry/dry-run", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response handler.ExpiryPreviewResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "OLD-001", response.Data[0].OrderRef)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), response.Cutoff, time.Minute)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update")
+	mockRepo.AssertNotCalled(t, "UpdateStatus")
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/usecase"
+	"pickup-queue/pkg/label"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	labelUsecase *usecase.LabelUsecase
+}
+
+func NewLabelHandler(labelUsecase *usecase.LabelUsecase) *LabelHandler {
+	return &LabelHandler{
+		labelUsecase: labelUsecase,
+	}
+}
+
+// GetPackageLabel renders the label of a package
+// @Summary Print a package label
+// @Description Render a 4x6 inch label with the order reference as Code 128, the package ID as a QR code, the shelf slot and the driver code
+// @Tags packages
+// @Produce application/pdf
+// @Produce image/png
+// @Produce application/zpl
+// @Param id path string true "Package ID"
+// @Param format query string false "Output format" Enums(pdf, png, zpl) default(pdf)
+// @Success 200 {file} binary
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Router /packages/{id}/label [get]
+func (h *LabelHandler) GetPackageLabel(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	out, format, err := h.labelUsecase.PackageLabel(id, c.Query("format"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	writeLabel(c, "label-"+id.String(), format, out)
+}
+
+// BatchLabels renders the labels of several packages in one document
+// @Summary Print labels for several packages
+// @Description Render the labels of up to 100 packages, e.g. after an import, as one multi-page PDF or one ZPL stream
+// @Tags packages
+// @Accept json
+// @Produce application/pdf
+// @Produce application/zpl
+// @Param labels body domain.BatchLabelRequest true "Packages and format"
+// @Success 200 {file} binary
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Router /packages/labels:batch [post]
+func (h *LabelHandler) BatchLabels(c *gin.Context) {
+	var req domain.BatchLabelRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	out, format, err := h.labelUsecase.BatchLabels(&req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	writeLabel(c, "labels", format, out)
+}
+
+func writeLabel(c *gin.Context, name string, format label.Format, out []byte) {
+	c.Header("Content-Disposition", `inline; filename="`+name+"."+string(format)+`"`)
+	c.Data(http.StatusOK, format.ContentType(), out)
+}
@@ -3,11 +3,12 @@ package handler
 import (
 	"net/http"
 	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
 	"pickup-queue/internal/usecase"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type PackageHandler struct {
@@ -28,23 +29,18 @@ func NewPackageHandler(packageUsecase *usecase.PackageUsecase) *PackageHandler {
 // @Produce json
 // @Param package body domain.CreatePackageRequest true "Package details"
 // @Success 201 {object} domain.Package
-// @Failure 400 {object} ErrorResponse
-// @Failure 409 {object} ErrorResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
 // @Router /packages [post]
 func (h *PackageHandler) CreatePackage(c *gin.Context) {
 	var req domain.CreatePackageRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	pkg, err := h.packageUsecase.CreatePackage(&req)
 	if err != nil {
-		if err == usecase.ErrDuplicateOrderRef {
-			c.JSON(http.StatusConflict, ErrorResponse{Error: "Order reference already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -58,24 +54,22 @@ func (h *PackageHandler) CreatePackage(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Package ID"
 // @Success 200 {object} domain.Package
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /packages/{id} [get]
 func (h *PackageHandler) GetPackage(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid package ID"})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	pkg, err := h.packageUsecase.GetPackage(id)
 	if err != nil {
-		if err == usecase.ErrPackageNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		_ = c.Error(err)
+		return
+	}
+	if err := h.packageUsecase.EstimateQueue(pkg); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -84,23 +78,24 @@ func (h *PackageHandler) GetPackage(c *gin.Context) {
 
 // GetPackageByOrderRef gets a package by order reference
 // @Summary Get a package by order reference
-// @Description Get package details by order reference
+// @Description Get package details by order reference. The reference is normalized with the site's order reference rules.
 // @Tags packages
 // @Produce json
 // @Param orderRef path string true "Order Reference"
 // @Success 200 {object} domain.Package
-// @Failure 404 {object} ErrorResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /packages/order/{orderRef} [get]
 func (h *PackageHandler) GetPackageByOrderRef(c *gin.Context) {
 	orderRef := c.Param("orderRef")
 
 	pkg, err := h.packageUsecase.GetPackageByOrderRef(orderRef)
 	if err != nil {
-		if err == usecase.ErrPackageNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		_ = c.Error(err)
+		return
+	}
+	if err := h.packageUsecase.EstimateQueue(pkg); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -115,8 +110,14 @@ func (h *PackageHandler) GetPackageByOrderRef(c *gin.Context) {
 // @Param limit query int false "Limit" default(50)
 // @Param offset query int false "Offset" default(0)
 // @Param status query string false "Filter by status"
+// @Param driver_code query string false "Filter by driver code"
+// @Param size_class query string false "Filter by size class" Enums(SMALL, MEDIUM, LARGE, XLARGE, UNKNOWN)
+// @Param fragile query bool false "Filter by fragility"
+// @Param temperature query string false "Filter by storage temperature" Enums(AMBIENT, CHILLED, FROZEN)
+// @Param high_value query bool false "Only packages at or above the high-value threshold"
+// @Param priority query string false "Filter by priority" Enums(EXPRESS, STANDARD, ECONOMY)
 // @Success 200 {object} PackageListResponse
-// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} middleware.Problem
 // @Router /packages [get]
 func (h *PackageHandler) ListPackages(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "50")
@@ -139,15 +140,32 @@ func (h *PackageHandler) ListPackages(c *gin.Context) {
 	var status *domain.PackageStatus
 	if statusStr != "" {
 		s := domain.PackageStatus(statusStr)
-		if s == domain.StatusWaiting || s == domain.StatusPicked ||
-			s == domain.StatusHandedOver || s == domain.StatusExpired {
+		if s.Valid() {
 			status = &s
 		}
 	}
 
-	packages, err := h.packageUsecase.ListPackages(limit, offset, status)
+	filter := domain.PackageFilter{
+		Limit:       limit,
+		Offset:      offset,
+		Status:      status,
+		DriverCode:  c.Query("driver_code"),
+		SizeClass:   domain.SizeClass(strings.ToUpper(c.Query("size_class"))),
+		Temperature: domain.Temperature(strings.ToUpper(c.Query("temperature"))),
+		Priority:    domain.Priority(strings.ToUpper(c.Query("priority"))),
+	}
+	if fragile, err := strconv.ParseBool(c.Query("fragile")); err == nil {
+		filter.Fragile = &fragile
+	}
+	filter.HighValue, _ = strconv.ParseBool(c.Query("high_value"))
+
+	packages, err := h.packageUsecase.ListPackages(filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		_ = c.Error(err)
+		return
+	}
+	if err := h.packageUsecase.EstimateQueue(packages...); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -163,75 +181,162 @@ func (h *PackageHandler) ListPackages(c *gin.Context) {
 
 // UpdatePackageStatus updates package status
 // @Summary Update package status
-// @Description Update the status of a package
+// @Description Update the status of a package. Handing over a high-value package needs verification_token, the last 4 digits of the recipient's phone.
 // @Tags packages
 // @Accept json
 // @Produce json
 // @Param id path string true "Package ID"
 // @Param status body domain.UpdatePackageStatusRequest true "New status"
 // @Success 200 {object} domain.Package
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
 // @Router /packages/{id}/status [patch]
 func (h *PackageHandler) UpdatePackageStatus(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid package ID"})
+	id, ok := parseID(c, "id")
+	if !ok {
 		return
 	}
 
 	var req domain.UpdatePackageStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	pkg, err := h.packageUsecase.UpdatePackageStatus(id, req.Status)
+	var pkg *domain.Package
+	var err error
+	if req.Status == domain.StatusHandedOver {
+		pkg, err = h.packageUsecase.HandOverPackage(id, req.VerificationToken)
+	} else {
+		pkg, err = h.packageUsecase.UpdatePackageStatus(id, req.Status)
+	}
 	if err != nil {
-		if err == usecase.ErrPackageNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
-			return
-		}
-		if err == usecase.ErrInvalidStatusTransition {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid status transition"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{Data: pkg})
 }
 
-// DeletePackage deletes a package
+// BatchUpdatePackageStatus updates the status of several packages at once
+// @Summary Batch update package status
+// @Description Move several packages, addressed by ID or order reference, to the same status. Best-effort by default; set all_or_nothing to apply every update or none.
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param batch body domain.BatchUpdateStatusRequest true "Packages and target status"
+// @Success 200 {object} BatchStatusResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} BatchStatusResponse
+// @Router /packages/status:batch [post]
+func (h *PackageHandler) BatchUpdatePackageStatus(c *gin.Context) {
+	// gin cannot escape ':' in a route, so "/status:batch" is registered as the
+	// wildcard "batch" after "status"; only the literal action is accepted
+	if c.Param("batch") != ":batch" {
+		middleware.NoRoute(c)
+		return
+	}
+
+	var req domain.BatchUpdateStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	results, err := h.packageUsecase.BatchUpdatePackageStatus(&req)
+	if err != nil && err != usecase.ErrBatchRejected {
+		_ = c.Error(err)
+		return
+	}
+
+	response := BatchStatusResponse{Data: results, Total: len(results)}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	if err == usecase.ErrBatchRejected {
+		response.Error = err.Error()
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeletePackage soft-deletes a package
 // @Summary Delete a package
-// @Description Delete a package from the system
+// @Description Hide a package from every listing and lookup; an admin can restore it until the retention job purges it
 // @Tags packages
 // @Param id path string true "Package ID"
 // @Success 204
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
 // @Router /packages/{id} [delete]
 func (h *PackageHandler) DeletePackage(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.packageUsecase.DeletePackage(id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestorePackage restores a soft-deleted package
+// @Summary Restore a deleted package
+// @Description Undo a delete; requires an admin API key
+// @Tags packages
+// @Produce json
+// @Param id path string true "Package ID"
+// @Success 200 {object} domain.Package
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /packages/{id}/restore [post]
+func (h *PackageHandler) RestorePackage(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	pkg, err := h.packageUsecase.RestorePackage(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid package ID"})
+		_ = c.Error(err)
 		return
 	}
 
-	err = h.packageUsecase.DeletePackage(id)
+	c.JSON(http.StatusOK, SuccessResponse{Data: pkg})
+}
+
+// RehydratePackage moves an archived package back into the packages table
+// @Summary Rehydrate an archived package
+// @Description Move a package from the cold archive back into the live table; requires an admin API key
+// @Tags packages
+// @Produce json
+// @Param id path string true "Package ID"
+// @Success 200 {object} domain.Package
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Router /packages/{id}/rehydrate [post]
+func (h *PackageHandler) RehydratePackage(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	pkg, err := h.packageUsecase.RehydratePackage(id)
 	if err != nil {
-		if err == usecase.ErrPackageNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, SuccessResponse{Data: pkg})
 }
 
 // GetPackageStats gets package statistics
@@ -240,12 +345,12 @@ func (h *PackageHandler) DeletePackage(c *gin.Context) {
 // @Tags packages
 // @Produce json
 // @Success 200 {object} domain.PackageStats
-// @Failure 500 {object} ErrorResponse
+// @Failure 500 {object} middleware.Problem
 // @Router /packages/stats [get]
 func (h *PackageHandler) GetPackageStats(c *gin.Context) {
 	stats, err := h.packageUsecase.GetPackageStats()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -253,10 +358,6 @@ func (h *PackageHandler) GetPackageStats(c *gin.Context) {
 }
 
 // Response models
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
 type SuccessResponse struct {
 	Data interface{} `json:"data"`
 }
@@ -267,3 +368,11 @@ type PackageListResponse struct {
 	Offset int               `json:"offset"`
 	Count  int               `json:"count"`
 }
+
+type BatchStatusResponse struct {
+	Data      []*domain.BatchStatusResult `json:"data"`
+	Total     int                         `json:"total"`
+	Succeeded int                         `json:"succeeded"`
+	Failed    int                         `json:"failed"`
+	Error     string                      `json:"error,omitempty"`
+}
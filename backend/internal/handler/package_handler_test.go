@@ -2,13 +2,16 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"pickup-queue/internal/domain"
 	"pickup-queue/internal/handler"
+	"pickup-queue/internal/middleware"
 	"pickup-queue/internal/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -43,8 +46,8 @@ func (m *MockPackageRepository) GetByOrderRef(orderRef string) (*domain.Package,
 	return args.Get(0).(*domain.Package), args.Error(1)
 }
 
-func (m *MockPackageRepository) GetAll(limit, offset int, status *domain.PackageStatus) ([]*domain.Package, error) {
-	args := m.Called(limit, offset, status)
+func (m *MockPackageRepository) GetAll(filter domain.PackageFilter) ([]*domain.Package, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]*domain.Package), args.Error(1)
 }
 
@@ -58,13 +61,28 @@ func (m *MockPackageRepository) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockPackageRepository) Restore(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPackageRepository) PurgeDeleted(_ context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockPackageRepository) UpdateStatus(id uuid.UUID, status domain.PackageStatus) error {
 	args := m.Called(id, status)
 	return args.Error(0)
 }
 
-func (m *MockPackageRepository) GetExpiredPackages() ([]*domain.Package, error) {
-	args := m.Called()
+func (m *MockPackageRepository) GetExpiredPackages(_ context.Context, cutoff time.Time) ([]*domain.Package, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).([]*domain.Package), args.Error(1)
+}
+
+func (m *MockPackageRepository) GetExpiredBefore(_ context.Context, cutoff time.Time) ([]*domain.Package, error) {
+	args := m.Called(cutoff)
 	return args.Get(0).([]*domain.Package), args.Error(1)
 }
 
@@ -73,9 +91,20 @@ func (m *MockPackageRepository) GetPackageStats() (*domain.PackageStats, error)
 	return args.Get(0).(*domain.PackageStats), args.Error(1)
 }
 
+// WaitingPositions and RecentPickupTimes only feed queue estimates, which
+// these tests do not cover, so they answer without expectations
+func (m *MockPackageRepository) WaitingPositions(ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	return map[uuid.UUID]int{}, nil
+}
+
+func (m *MockPackageRepository) RecentPickupTimes(limit int) ([]time.Time, error) {
+	return []time.Time{}, nil
+}
+
 func setupRouterWithMockRepo(mockRepo *MockPackageRepository) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middleware.Errors())
 
 	// Create real usecase with mock repository
 	packageUsecase := usecase.NewPackageUsecase(mockRepo)
@@ -87,7 +116,9 @@ func setupRouterWithMockRepo(mockRepo *MockPackageRepository) *gin.Engine {
 		api.GET("/packages", packageHandler.ListPackages)
 		api.GET("/packages/:id", packageHandler.GetPackage)
 		api.PATCH("/packages/:id/status", packageHandler.UpdatePackageStatus)
+		api.POST("/packages/status:batch", packageHandler.BatchUpdatePackageStatus)
 		api.DELETE("/packages/:id", packageHandler.DeletePackage)
+		api.POST("/packages/:id/restore", packageHandler.RestorePackage)
 		api.GET("/packages/stats", packageHandler.GetPackageStats)
 	}
 
@@ -153,6 +184,30 @@ func TestPackageHandler_CreatePackage_EdgeCase_InvalidJSON(t *testing.T) {
 	mockRepo.AssertNotCalled(t, "Create")
 }
 
+func TestPackageHandler_CreatePackage_EdgeCase_MissingField(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	router := setupRouterWithMockRepo(mockRepo)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/packages", bytes.NewBufferString(`{"driver_code":"DRV-001"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem middleware.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "VALIDATION_FAILED", problem.Code)
+	assert.Equal(t, []domain.FieldError{{Field: "order_reference", Code: "required", Message: "order_reference is required"}}, problem.Errors)
+
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
 func TestPackageHandler_GetPackage_HappyPath(t *testing.T) {
 	// Setup
 	mockRepo := new(MockPackageRepository)
@@ -231,3 +286,114 @@ func TestPackageHandler_GetPackageStats_HappyPath(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestPackageHandler_BatchUpdatePackageStatus_HappyPath(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	router := setupRouterWithMockRepo(mockRepo)
+
+	existingPkg := &domain.Package{
+		ID:       uuid.New(),
+		OrderRef: "TEST-001",
+		Status:   domain.StatusWaiting,
+	}
+
+	// Mock expectations
+	mockRepo.On("GetByOrderRef", "TEST-001").Return(existingPkg, nil)
+	mockRepo.On("GetByOrderRef", "TEST-404").Return(nil, nil)
+	mockRepo.On("GetByID", existingPkg.ID).Return(existingPkg, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*domain.Package")).Return(nil)
+
+	// Prepare request
+	body := `{"order_references":["TEST-001","TEST-404"],"status":"PICKED"}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/packages/status:batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handler.BatchStatusResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, 1, response.Succeeded)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, domain.StatusPicked, response.Data[0].Package.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageHandler_BatchUpdatePackageStatus_EdgeCase_UnknownAction(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	router := setupRouterWithMockRepo(mockRepo)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/packages/status:purge", bytes.NewBufferString(`{"status":"PICKED"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPackageHandler_RestorePackage_HappyPath(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	router := setupRouterWithMockRepo(mockRepo)
+
+	packageID := uuid.New()
+	restored := &domain.Package{
+		ID:         packageID,
+		OrderRef:   "TEST-001",
+		DriverCode: "DRV-001",
+		Status:     domain.StatusWaiting,
+	}
+
+	// Mock expectations - deleted at first, live after Restore
+	mockRepo.On("GetByID", packageID).Return(nil, nil).Once()
+	mockRepo.On("Restore", packageID).Return(nil)
+	mockRepo.On("GetByID", packageID).Return(restored, nil).Once()
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/packages/"+packageID.String()+"/restore", nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	packageData := response["data"].(map[string]interface{})
+	assert.Equal(t, "TEST-001", packageData["order_reference"])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageHandler_RestorePackage_EdgeCase_NotFound(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	router := setupRouterWithMockRepo(mockRepo)
+
+	packageID := uuid.New()
+
+	// Mock expectations - purged packages cannot be restored
+	mockRepo.On("GetByID", packageID).Return(nil, nil)
+	mockRepo.On("Restore", packageID).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/packages/"+packageID.String()+"/restore", nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PickupSessionHandler struct {
+	sessionUsecase *usecase.PickupSessionUsecase
+}
+
+func NewPickupSessionHandler(sessionUsecase *usecase.PickupSessionUsecase) *PickupSessionHandler {
+	return &PickupSessionHandler{
+		sessionUsecase: sessionUsecase,
+	}
+}
+
+// OpenSession checks a driver in
+// @Summary Open a pickup session
+// @Description Check a driver in by driver code or badge and list the packages waiting for them. Returns the open session if the driver is already checked in. A new session shows the driver's pickup appointment, or a warning when none covers the check-in.
+// @Tags pickup-sessions
+// @Accept json
+// @Produce json
+// @Param session body domain.OpenPickupSessionRequest true "Driver code or badge"
+// @Success 201 {object} PickupSessionResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Router /pickup-sessions [post]
+func (h *PickupSessionHandler) OpenSession(c *gin.Context) {
+	var req domain.OpenPickupSessionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	session, waiting, err := h.sessionUsecase.OpenSession(&req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if waiting == nil {
+		waiting = []*domain.Package{}
+	}
+	c.JSON(http.StatusCreated, PickupSessionResponse{Data: session, Packages: waiting})
+}
+
+// GetSession gets a pickup session with its expected and scanned items
+// @Summary Get a pickup session
+// @Tags pickup-sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} domain.PickupSession
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /pickup-sessions/{id} [get]
+func (h *PickupSessionHandler) GetSession(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	session, err := h.sessionUsecase.GetSession(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: session})
+}
+
+// ScanPackage scans an order reference into a pickup session
+// @Summary Scan a package
+// @Description Mark a package waiting for the session's driver as PICKED. Unknown parcels and parcels for other drivers are recorded as extra scans.
+// @Tags pickup-sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param scan body domain.ScanPackageRequest true "Scanned order reference"
+// @Success 200 {object} domain.PickupSessionItem
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Router /pickup-sessions/{id}/scans [post]
+func (h *PickupSessionHandler) ScanPackage(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.ScanPackageRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item, err := h.sessionUsecase.ScanPackage(id, req.OrderRef)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: item})
+}
+
+// CloseSession closes a pickup session
+// @Summary Close a pickup session
+// @Description Close the session and report picked, missing, extra and rejected parcels
+// @Tags pickup-sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} domain.PickupSessionReport
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /pickup-sessions/{id}/close [post]
+func (h *PickupSessionHandler) CloseSession(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.sessionUsecase.CloseSession(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: report})
+}
+
+type PickupSessionResponse struct {
+	Data     *domain.PickupSession `json:"data"`
+	Packages []*domain.Package     `json:"packages"`
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueueHandler struct {
+	queueUsecase *usecase.QueueUsecase
+}
+
+func NewQueueHandler(queueUsecase *usecase.QueueUsecase) *QueueHandler {
+	return &QueueHandler{
+		queueUsecase: queueUsecase,
+	}
+}
+
+// GetQueue returns the lobby display board
+// @Summary Get the pickup queue board
+// @Description List the checked-in drivers in the order they are served, with estimated start times from the counter's recent pickup pace
+// @Tags queue
+// @Produce json
+// @Success 200 {object} domain.QueueBoard
+// @Router /queue [get]
+func (h *QueueHandler) GetQueue(c *gin.Context) {
+	board, err := h.queueUsecase.Board()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: board})
+}
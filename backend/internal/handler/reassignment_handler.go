@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
+	"pickup-queue/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReassignmentHandler struct {
+	reassignmentUsecase *usecase.ReassignmentUsecase
+}
+
+func NewReassignmentHandler(reassignmentUsecase *usecase.ReassignmentUsecase) *ReassignmentHandler {
+	return &ReassignmentHandler{
+		reassignmentUsecase: reassignmentUsecase,
+	}
+}
+
+// ReassignDriver moves a package to another driver
+// @Summary Reassign a package to another driver
+// @Description Change the driver of a WAITING package. Requires the supervisor role; the change is recorded in the package's driver history.
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Package ID"
+// @Param reassignment body domain.ReassignDriverRequest true "New driver and reason"
+// @Success 200 {object} domain.Package
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Router /packages/{id}/driver [patch]
+func (h *ReassignmentHandler) ReassignDriver(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.ReassignDriverRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	pkg, err := h.reassignmentUsecase.ReassignDriver(id, &req, principal.Name)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: pkg})
+}
+
+// ReassignAll moves every waiting package of a driver to another driver
+// @Summary Reassign all waiting packages of a driver
+// @Description Move every WAITING package of the driver in the path to another driver, e.g. when a driver calls in sick. Requires the supervisor role.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param driverCode path string true "Current driver code"
+// @Param reassignment body domain.BulkReassignDriverRequest true "New driver and reason"
+// @Success 200 {object} PackageListResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 401 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Router /drivers/{driverCode}/reassign [post]
+func (h *ReassignmentHandler) ReassignAll(c *gin.Context) {
+	var req domain.BulkReassignDriverRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	packages, err := h.reassignmentUsecase.ReassignAll(c.Param("driverCode"), &req, principal.Name)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, PackageListResponse{Data: packages, Count: len(packages)})
+}
+
+// GetDriverHistory lists the driver reassignments of a package
+// @Summary Get driver history of a package
+// @Tags packages
+// @Produce json
+// @Param id path string true "Package ID"
+// @Success 200 {array} domain.DriverAssignment
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /packages/{id}/driver-history [get]
+func (h *ReassignmentHandler) GetDriverHistory(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	history, err := h.reassignmentUsecase.GetDriverHistory(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: history})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReturnsHandler struct {
+	returnUsecase *usecase.ReturnUsecase
+}
+
+func NewReturnsHandler(returnUsecase *usecase.ReturnUsecase) *ReturnsHandler {
+	return &ReturnsHandler{
+		returnUsecase: returnUsecase,
+	}
+}
+
+// GetManifest lists the packages waiting to be returned to their sender
+// @Summary Get the returns manifest
+// @Description List the RETURN_PENDING packages grouped by carrier and driver, for drivers to take back
+// @Tags returns
+// @Produce json
+// @Param carrier query string false "Filter by carrier"
+// @Param driver_code query string false "Filter by driver code"
+// @Success 200 {object} domain.ReturnsManifest
+// @Failure 500 {object} middleware.Problem
+// @Router /returns/manifest [get]
+func (h *ReturnsHandler) GetManifest(c *gin.Context) {
+	manifest, err := h.returnUsecase.Manifest(c.Query("carrier"), c.Query("driver_code"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: manifest})
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShipmentHandler struct {
+	shipmentUsecase *usecase.ShipmentUsecase
+}
+
+func NewShipmentHandler(shipmentUsecase *usecase.ShipmentUsecase) *ShipmentHandler {
+	return &ShipmentHandler{
+		shipmentUsecase: shipmentUsecase,
+	}
+}
+
+// CreateShipment creates a shipment with its parcels
+// @Summary Create a multi-parcel shipment
+// @Description Create a shipment and one WAITING package per parcel. Parcel references default to the order reference followed by /1, /2 and so on.
+// @Tags shipments
+// @Accept json
+// @Produce json
+// @Param shipment body domain.CreateShipmentRequest true "Shipment and parcels"
+// @Success 201 {object} domain.Shipment
+// @Failure 400 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Router /shipments [post]
+func (h *ShipmentHandler) CreateShipment(c *gin.Context) {
+	var req domain.CreateShipmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	shipment, err := h.shipmentUsecase.CreateShipment(&req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{Data: shipment})
+}
+
+// GetShipment gets a shipment by ID
+// @Summary Get a shipment by ID
+// @Description Get a shipment with its parcels and derived status
+// @Tags shipments
+// @Produce json
+// @Param id path string true "Shipment ID"
+// @Success 200 {object} domain.Shipment
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /shipments/{id} [get]
+func (h *ShipmentHandler) GetShipment(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	shipment, err := h.shipmentUsecase.GetShipment(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: shipment})
+}
+
+// GetShipmentByOrderRef gets a shipment by order reference
+// @Summary Get a shipment by order reference
+// @Description Get a shipment with its parcels by the order reference it was created with
+// @Tags shipments
+// @Produce json
+// @Param orderRef path string true "Order Reference"
+// @Success 200 {object} domain.Shipment
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Router /shipments/order/{orderRef} [get]
+func (h *ShipmentHandler) GetShipmentByOrderRef(c *gin.Context) {
+	shipment, err := h.shipmentUsecase.GetShipmentByOrderRef(c.Param("orderRef"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: shipment})
+}
+
+// ListShipments lists shipments with pagination
+// @Summary List shipments
+// @Description Get shipments newest first, each with its parcels and derived status
+// @Tags shipments
+// @Produce json
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Param driver_code query string false "Filter by driver code"
+// @Success 200 {object} ShipmentListResponse
+// @Failure 400 {object} middleware.Problem
+// @Router /shipments [get]
+func (h *ShipmentHandler) ListShipments(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	shipments, err := h.shipmentUsecase.ListShipments(domain.ShipmentFilter{
+		Limit:      limit,
+		Offset:     offset,
+		DriverCode: c.Query("driver_code"),
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ShipmentListResponse{
+		Data:   shipments,
+		Limit:  limit,
+		Offset: offset,
+		Count:  len(shipments),
+	})
+}
+
+// UpdateShipmentStatus moves every parcel of a shipment to a new status
+// @Summary Update the status of a whole shipment
+// @Description Move every parcel of the shipment to the new status in one transaction. Handing over needs every parcel picked, and the verification token when a parcel is high value.
+// @Tags shipments
+// @Accept json
+// @Produce json
+// @Param id path string true "Shipment ID"
+// @Param status body domain.UpdatePackageStatusRequest true "New status"
+// @Success 200 {object} domain.Shipment
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Router /shipments/{id}/status [patch]
+func (h *ShipmentHandler) UpdateShipmentStatus(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdatePackageStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	shipment, err := h.shipmentUsecase.UpdateShipmentStatus(id, req.Status, req.VerificationToken)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: shipment})
+}
+
+type ShipmentListResponse struct {
+	Data   []*domain.Shipment `json:"data"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+	Count  int                `json:"count"`
+}
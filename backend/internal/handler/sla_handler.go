@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/usecase"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SLAHandler struct {
+	slaUsecase *usecase.SLAUsecase
+}
+
+func NewSLAHandler(slaUsecase *usecase.SLAUsecase) *SLAHandler {
+	return &SLAHandler{
+		slaUsecase: slaUsecase,
+	}
+}
+
+// GetBreachReport lists the packages that missed their pickup target
+// @Summary Get the SLA breach report
+// @Description List the packages recorded as still waiting past their priority's pickup target, with a count per priority
+// @Tags sla
+// @Produce json
+// @Param from query string false "Start of the period as RFC 3339, a week before to by default"
+// @Param to query string false "End of the period as RFC 3339, exclusive, now by default"
+// @Param priority query string false "Filter by priority" Enums(EXPRESS, STANDARD, ECONOMY)
+// @Success 200 {object} domain.SLABreachReport
+// @Failure 400 {object} middleware.Problem
+// @Router /sla/breaches [get]
+func (h *SLAHandler) GetBreachReport(c *gin.Context) {
+	filter := domain.SLABreachFilter{Priority: domain.Priority(c.Query("priority"))}
+	var ok bool
+	if filter.From, ok = parseTimeQuery(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = parseTimeQuery(c, "to"); !ok {
+		return
+	}
+
+	report, err := h.slaUsecase.Report(filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: report})
+}
+
+// parseTimeQuery parses the RFC 3339 query parameter param, returning the
+// zero time when it is absent and recording a problem if it is malformed
+func parseTimeQuery(c *gin.Context, param string) (time.Time, bool) {
+	value := c.Query(param)
+	if value == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		_ = c.Error(domain.NewValidationError(domain.FieldError{
+			Field:   param,
+			Code:    "invalid",
+			Message: param + " must be an RFC 3339 timestamp",
+		}))
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrackingHandler serves the public tracking endpoint used by recipients
+type TrackingHandler struct {
+	trackingUsecase *usecase.TrackingUsecase
+}
+
+func NewTrackingHandler(trackingUsecase *usecase.TrackingUsecase) *TrackingHandler {
+	return &TrackingHandler{
+		trackingUsecase: trackingUsecase,
+	}
+}
+
+// TrackPackage shows a recipient the status of their parcel
+// @Summary Track a parcel
+// @Description Public endpoint. Returns the status timeline and pickup location of a parcel when the verification token matches the last 4 digits of the recipient phone number. Unknown order references and wrong tokens get the same 404. Rate limited per client IP.
+// @Tags tracking
+// @Accept json
+// @Produce json
+// @Param tracking body domain.TrackingRequest true "Order reference and verification token"
+// @Success 200 {object} domain.TrackingInfo
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 429 {object} middleware.Problem
+// @Router /tracking [post]
+func (h *TrackingHandler) TrackPackage(c *gin.Context) {
+	var req domain.TrackingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	info, err := h.trackingUsecase.Track(req.OrderRef, req.VerificationToken)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Data: info})
+}
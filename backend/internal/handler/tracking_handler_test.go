@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/handler"
+	"pickup-queue/internal/middleware"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+	"pickup-queue/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTrackingRouter(t *testing.T, rateLimit int) (*gin.Engine, *usecase.PackageUsecase) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Errors())
+
+	packageUsecase := usecase.NewPackageUsecase(repository.NewMemoryPackageRepository())
+	trackingUsecase := usecase.NewTrackingUsecase(packageUsecase, domain.PickupLocation{Name: "Central Depot"}, 5, time.Minute)
+	trackingHandler := handler.NewTrackingHandler(trackingUsecase)
+
+	router.Use(middleware.RateLimit(repository.NewMemoryRateLimiter(), 0, []middleware.RateLimitRule{
+		{Method: http.MethodPost, Path: "/api/v1/tracking", PerMinute: rateLimit, Key: config.RateLimitKeyIP},
+	}))
+	router.POST("/api/v1/tracking", trackingHandler.TrackPackage)
+
+	return router, packageUsecase
+}
+
+func postTracking(router *gin.Engine, orderRef, token string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(domain.TrackingRequest{OrderRef: orderRef, VerificationToken: token})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tracking", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTrackingHandler_TrackPackage_HappyPath_HidesInternalFields(t *testing.T) {
+	// Setup
+	router, packages := setupTrackingRouter(t, 10)
+	_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "600100234"})
+	require.NoError(t, err)
+
+	// Execute
+	w := postTracking(router, "ORD-001", "0234")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"]
+	assert.Equal(t, "ORD-001", data["order_reference"])
+	assert.Equal(t, "WAITING", data["status"])
+	assert.NotNil(t, data["pickup_location"])
+	assert.NotContains(t, data, "id")
+	assert.NotContains(t, data, "driver_code")
+	assert.NotContains(t, w.Body.String(), "600100234")
+}
+
+func TestTrackingHandler_TrackPackage_EdgeCase_WrongToken(t *testing.T) {
+	// Setup
+	router, packages := setupTrackingRouter(t, 10)
+	_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "600100234"})
+	require.NoError(t, err)
+
+	// Execute
+	wrong := postTracking(router, "ORD-001", "1111")
+	unknown := postTracking(router, "ORD-404", "0234")
+
+	// Assert - both look the same to the caller
+	assert.Equal(t, http.StatusNotFound, wrong.Code)
+	assert.Equal(t, http.StatusNotFound, unknown.Code)
+	assert.Equal(t, wrong.Body.String(), unknown.Body.String())
+}
+
+func TestTrackingHandler_TrackPackage_EdgeCase_RateLimited(t *testing.T) {
+	// Setup
+	router, _ := setupTrackingRouter(t, 2)
+
+	// Execute
+	postTracking(router, "ORD-404", "0000")
+	postTracking(router, "ORD-404", "0000")
+	w := postTracking(router, "ORD-404", "0000")
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
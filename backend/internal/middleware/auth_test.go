@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAuthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(middleware.Authenticate(map[string]domain.Principal{
+		"clerk-key":      {Name: "desk-1", Role: domain.RoleClerk},
+		"supervisor-key": {Name: "alice", Role: domain.RoleSupervisor},
+	}))
+	router.GET("/open", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/guarded", middleware.RequireRole(domain.RoleSupervisor), func(c *gin.Context) {
+		principal, _ := middleware.CurrentPrincipal(c)
+		c.String(http.StatusOK, principal.Name)
+	})
+	return router
+}
+
+func getWithHeader(router *gin.Engine, path, header, value string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuth_HappyPath_SupervisorAllowed(t *testing.T) {
+	// Setup
+	router := setupAuthRouter()
+
+	// Execute
+	bearer := getWithHeader(router, "/guarded", "Authorization", "Bearer supervisor-key")
+	apiKey := getWithHeader(router, "/guarded", middleware.APIKeyHeader, "supervisor-key")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, bearer.Code)
+	assert.Equal(t, "alice", bearer.Body.String())
+	assert.Equal(t, http.StatusOK, apiKey.Code)
+}
+
+func TestAuth_EdgeCase_Rejections(t *testing.T) {
+	// Setup
+	router := setupAuthRouter()
+
+	// Execute & Assert
+	assert.Equal(t, http.StatusOK, getWithHeader(router, "/open", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, getWithHeader(router, "/open", middleware.APIKeyHeader, "wrong").Code)
+	assert.Equal(t, http.StatusUnauthorized, getWithHeader(router, "/guarded", "", "").Code)
+	assert.Equal(t, http.StatusForbidden, getWithHeader(router, "/guarded", middleware.APIKeyHeader, "clerk-key").Code)
+}
@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"pickup-queue/internal/domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client-chosen key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLength = 255
+
+// DefaultIdempotencyLease is how long an unfinished request holds its key
+// when no lease is given
+const DefaultIdempotencyLease = time.Minute
+
+var (
+	errIdempotencyKeyTooLong = domain.NewError(domain.KindValidation, "IDEMPOTENCY_KEY_TOO_LONG", "Idempotency-Key is too long")
+	errUnreadableBody        = domain.NewError(domain.KindValidation, "UNREADABLE_BODY", "failed to read request body")
+	errIdempotencyKeyReused  = domain.NewError(domain.KindUnprocessable, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+	errIdempotencyInProgress = domain.NewError(domain.KindConflict, "IDEMPOTENCY_IN_PROGRESS", "a request with this Idempotency-Key is still being processed")
+)
+
+// Idempotency middleware makes POST and PATCH requests carrying an
+// Idempotency-Key header safe to retry. The first request is executed and its
+// response stored for ttl; replays with the same key and body get the stored
+// response back with an Idempotent-Replayed header instead of running again.
+// Keys are scoped to the caller, so one principal can never replay another's
+// response, and 401/403 responses are not stored.
+//
+// While the first request runs, its key is only leased for lease, which
+// should cover the longest a request may take. If the process dies before
+// the response is stored, retries can run the request again once the lease
+// is over rather than wait out ttl.
+func Idempotency(repo domain.IdempotencyRepository, ttl, lease time.Duration) gin.HandlerFunc {
+	if lease <= 0 {
+		lease = DefaultIdempotencyLease
+	}
+	return func(c *gin.Context) {
+		clientKey := c.GetHeader(IdempotencyKeyHeader)
+		if clientKey == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) {
+			c.Next()
+			return
+		}
+		if len(clientKey) > maxIdempotencyKeyLength {
+			AbortWithError(c, errIdempotencyKeyTooLong)
+			return
+		}
+		caller := idempotencyScope(c)
+		key := scopeIdempotencyKey(caller, clientKey)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			AbortWithError(c, errUnreadableBody)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hashRequest(caller, c.Request.Method, c.Request.URL.Path, body)
+
+		now := time.Now()
+		record := &domain.IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(lease),
+		}
+
+		err = reserveIdempotencyKey(repo, record, now)
+		if errors.Is(err, domain.ErrIdempotencyKeyExists) {
+			replayIdempotentResponse(c, repo, key, requestHash)
+			return
+		}
+		if err != nil {
+			AbortWithError(c, fmt.Errorf("idempotency: failed to reserve key %q: %w", key, err))
+			return
+		}
+
+		// Release the key if the handler panics so the request can be retried
+		defer func() {
+			if p := recover(); p != nil {
+				repo.Delete(key)
+				panic(p)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		// The stored response must be the final one, so render a recorded
+		// error now rather than leave it to Errors
+		writePendingError(c)
+
+		// Server errors are not stored so the client can retry them, and
+		// auth failures so a later authorized request is not answered with them
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusUnauthorized || status == http.StatusForbidden {
+			if err := repo.Delete(key); err != nil {
+				log.Printf("Idempotency: failed to release key %q: %v", key, err)
+			}
+			return
+		}
+		if err := repo.Complete(key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes(), time.Now().Add(ttl)); err != nil {
+			log.Printf("Idempotency: failed to store response for key %q: %v", key, err)
+		}
+	}
+}
+
+// reserveIdempotencyKey stores an in-progress record, replacing an expired one
+func reserveIdempotencyKey(repo domain.IdempotencyRepository, record *domain.IdempotencyRecord, now time.Time) error {
+	err := repo.Reserve(record)
+	if !errors.Is(err, domain.ErrIdempotencyKeyExists) {
+		return err
+	}
+
+	existing, getErr := repo.Get(record.Key)
+	if getErr != nil {
+		return getErr
+	}
+	if existing == nil || existing.ExpiresAt.Before(now) {
+		if err := repo.Delete(record.Key); err != nil {
+			return err
+		}
+		return repo.Reserve(record)
+	}
+	return err
+}
+
+func replayIdempotentResponse(c *gin.Context, repo domain.IdempotencyRepository, key, requestHash string) {
+	record, err := repo.Get(key)
+	if err != nil || record == nil {
+		AbortWithError(c, fmt.Errorf("idempotency: failed to load key %q: %v", key, err))
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		AbortWithError(c, errIdempotencyKeyReused)
+		return
+	}
+	if record.InProgress() {
+		c.Header("Retry-After", "1")
+		AbortWithError(c, errIdempotencyInProgress)
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(record.StatusCode, contentType, record.ResponseBody)
+	c.Abort()
+}
+
+// idempotencyScope names the caller a key belongs to: the principal, or for
+// anonymous callers their client IP, so one anonymous client cannot replay
+// or block another's key
+func idempotencyScope(c *gin.Context) string {
+	principal, ok := CurrentPrincipal(c)
+	if !ok {
+		return "anonymous:" + c.ClientIP()
+	}
+	return "principal:" + string(principal.Role) + ":" + principal.Name
+}
+
+// scopeIdempotencyKey derives the stored key from the caller's scope and the
+// client's key; hashing keeps it within the key column whatever the name length
+func scopeIdempotencyKey(scope, key string) string {
+	h := sha256.New()
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashRequest(scope, method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the client into body
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,277 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
+	"pickup-queue/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdempotentRouter(status *int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(middleware.Idempotency(repository.NewMemoryIdempotencyRepository(), time.Hour, time.Minute))
+	router.POST("/packages", func(c *gin.Context) {
+		*calls++
+		c.JSON(*status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func postWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/packages", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_HappyPath_ReplaysStoredResponse(t *testing.T) {
+	// Setup
+	status, calls := http.StatusCreated, 0
+	router := setupIdempotentRouter(&status, &calls)
+
+	// Execute
+	first := postWithKey(router, "key-1", `{"order_ref":"A"}`)
+	second := postWithKey(router, "key-1", `{"order_ref":"A"}`)
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_EdgeCase_DifferentBodyRejected(t *testing.T) {
+	// Setup
+	status, calls := http.StatusCreated, 0
+	router := setupIdempotentRouter(&status, &calls)
+	postWithKey(router, "key-1", `{"order_ref":"A"}`)
+
+	// Execute
+	w := postWithKey(router, "key-1", `{"order_ref":"B"}`)
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_EdgeCase_ServerErrorReleasesKey(t *testing.T) {
+	// Setup
+	status, calls := http.StatusInternalServerError, 0
+	router := setupIdempotentRouter(&status, &calls)
+	first := postWithKey(router, "key-1", `{}`)
+	require.Equal(t, http.StatusInternalServerError, first.Code)
+
+	// Execute
+	status = http.StatusCreated
+	w := postWithKey(router, "key-1", `{}`)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_EdgeCase_ReplaysRecordedError(t *testing.T) {
+	// Setup
+	calls := 0
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(middleware.Idempotency(repository.NewMemoryIdempotencyRepository(), time.Hour, time.Minute))
+	router.POST("/packages", func(c *gin.Context) {
+		calls++
+		_ = c.Error(domain.ErrDuplicateOrderRef)
+	})
+
+	// Execute
+	first := postWithKey(router, "key-1", `{"order_ref":"A"}`)
+	second := postWithKey(router, "key-1", `{"order_ref":"A"}`)
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusConflict, first.Code)
+	assert.Equal(t, http.StatusConflict, second.Code)
+	assert.Equal(t, middleware.ProblemContentType, second.Header().Get("Content-Type"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_EdgeCase_NoKeyPassesThrough(t *testing.T) {
+	// Setup
+	status, calls := http.StatusCreated, 0
+	router := setupIdempotentRouter(&status, &calls)
+
+	// Execute
+	postWithKey(router, "", `{}`)
+	postWithKey(router, "", `{}`)
+
+	// Assert
+	assert.Equal(t, 2, calls)
+}
+
+// setupGuardedIdempotentRouter mirrors the API: authentication and
+// idempotency on the group, the role check on the route
+func setupGuardedIdempotentRouter(calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(middleware.Authenticate(map[string]domain.Principal{
+		"sup-key": {Name: "supervisor", Role: domain.RoleSupervisor},
+	}))
+	router.Use(middleware.Idempotency(repository.NewMemoryIdempotencyRepository(), time.Hour, time.Minute))
+	router.POST("/packages", middleware.RequireRole(domain.RoleSupervisor), func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusOK, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func postAs(router *gin.Engine, apiKey, key string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/packages", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	if apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_EdgeCase_UnauthorizedNotStored(t *testing.T) {
+	// Setup
+	calls := 0
+	router := setupGuardedIdempotentRouter(&calls)
+	anonymous := postAs(router, "", "k1")
+
+	// Execute
+	w := postAs(router, "sup-key", "k1")
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, anonymous.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_EdgeCase_KeysScopedToCaller(t *testing.T) {
+	// Setup
+	calls := 0
+	router := setupGuardedIdempotentRouter(&calls)
+	supervisor := postAs(router, "sup-key", "k2")
+
+	// Execute
+	anonymous := postAs(router, "", "k2")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, supervisor.Code)
+	assert.Equal(t, http.StatusUnauthorized, anonymous.Code)
+	assert.Empty(t, anonymous.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+}
+
+func postFrom(router *gin.Engine, remoteAddr, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/packages", bytes.NewBufferString(`{}`))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_EdgeCase_AnonymousKeysScopedToClientIP(t *testing.T) {
+	// Setup
+	status, calls := http.StatusCreated, 0
+	router := setupIdempotentRouter(&status, &calls)
+	require.NoError(t, router.SetTrustedProxies(nil))
+	first := postFrom(router, "192.0.2.1:1234", "shared-key")
+
+	// Execute
+	other := postFrom(router, "192.0.2.2:1234", "shared-key")
+	retry := postFrom(router, "192.0.2.1:5678", "shared-key")
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, other.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_EdgeCase_AbandonedReservationLeased(t *testing.T) {
+	// Setup - the first request never finishes within the lease, as if its
+	// process had died
+	gin.SetMode(gin.TestMode)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(middleware.Idempotency(repository.NewMemoryIdempotencyRepository(), time.Hour, 20*time.Millisecond))
+	router.POST("/packages", func(c *gin.Context) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			<-release
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+	done := make(chan struct{})
+	go func() {
+		postWithKey(router, "key-1", `{}`)
+		close(done)
+	}()
+	defer func() { close(release); <-done }()
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls == 1
+	}, time.Second, time.Millisecond)
+
+	// Execute
+	inLease := postWithKey(router, "key-1", `{}`)
+	time.Sleep(30 * time.Millisecond)
+	afterLease := postWithKey(router, "key-1", `{}`)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, inLease.Code)
+	assert.Equal(t, http.StatusCreated, afterLease.Code)
+}
+
+func TestIdempotency_HappyPath_StoredResponseOutlivesLease(t *testing.T) {
+	// Setup
+	calls := 0
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(middleware.Idempotency(repository.NewMemoryIdempotencyRepository(), time.Hour, time.Millisecond))
+	router.POST("/packages", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+	postWithKey(router, "key-1", `{}`)
+	time.Sleep(5 * time.Millisecond)
+
+	// Execute
+	w := postWithKey(router, "key-1", `{}`)
+
+	// Assert
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"fmt"
+	"net/http"
+	"pickup-queue/internal/domain"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,7 +14,7 @@ func CORS() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, Idempotency-Key, accept, origin, Cache-Control, X-Requested-With")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -41,6 +43,8 @@ func Logger() gin.HandlerFunc {
 	})
 }
 
+const requestIDKey = "RequestID"
+
 // RequestID middleware
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -49,7 +53,7 @@ func RequestID() gin.HandlerFunc {
 			requestID = generateRequestID()
 		}
 		c.Header("X-Request-ID", requestID)
-		c.Set("RequestID", requestID)
+		c.Set(requestIDKey, requestID)
 		c.Next()
 	}
 }
@@ -57,3 +61,18 @@ func RequestID() gin.HandlerFunc {
 func generateRequestID() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
 }
+
+// ErrBodyTooLarge is reported for request bodies over the BodyLimit
+var ErrBodyTooLarge = domain.NewError(domain.KindTooLarge, "BODY_TOO_LARGE", "request body too large")
+
+// BodyLimit middleware caps the size of request bodies
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			AbortWithError(c, ErrBodyTooLarge)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"pickup-queue/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of error responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the request path the problem occurred on
+	Instance string `json:"instance,omitempty"`
+	// Code is a stable, machine-readable error code such as PACKAGE_NOT_FOUND
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
+}
+
+var kindStatus = map[domain.ErrorKind]int{
+	domain.KindValidation:      http.StatusBadRequest,
+	domain.KindUnauthorized:    http.StatusUnauthorized,
+	domain.KindForbidden:       http.StatusForbidden,
+	domain.KindNotFound:        http.StatusNotFound,
+	domain.KindConflict:        http.StatusConflict,
+	domain.KindTooLarge:        http.StatusRequestEntityTooLarge,
+	domain.KindUnprocessable:   http.StatusUnprocessableEntity,
+	domain.KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+// Errors renders the last error a handler recorded with c.Error as a problem
+// response, unless the handler already wrote one. Register it right after
+// Logger so it covers errors recorded by every later middleware and the
+// access log still sees the final status.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		writePendingError(c)
+	}
+}
+
+// AbortWithError records err and stops the chain; Errors renders it
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// NoRoute answers unknown paths with a problem response
+func NoRoute(c *gin.Context) {
+	_ = c.Error(domain.NewError(domain.KindNotFound, "ROUTE_NOT_FOUND", "no such endpoint"))
+}
+
+// writePendingError renders the last recorded error if nothing was written yet
+func writePendingError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	WriteProblem(c, c.Errors.Last().Err)
+}
+
+// WriteProblem maps err to a problem response. Only domain.Error text reaches
+// the client; anything else is logged and reported as an internal error.
+func WriteProblem(c *gin.Context, err error) {
+	problem := Problem{
+		Type:      "about:blank",
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString(requestIDKey),
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) && domainErr.Kind != domain.KindInternal {
+		problem.Status = kindStatus[domainErr.Kind]
+		problem.Code = domainErr.Code
+		problem.Detail = domainErr.Message
+		problem.Errors = domainErr.Fields
+	} else {
+		log.Printf("Request %s %s failed (request ID %s): %v", c.Request.Method, c.Request.URL.Path, problem.RequestID, err)
+		problem.Status = http.StatusInternalServerError
+		problem.Code = "INTERNAL_ERROR"
+		problem.Detail = "An unexpected error occurred"
+	}
+	problem.Title = http.StatusText(problem.Status)
+
+	c.Abort()
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(problem.Status, problem)
+}
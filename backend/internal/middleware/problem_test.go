@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupProblemRouter(err error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(middleware.RequestID())
+	router.NoRoute(middleware.NoRoute)
+	router.GET("/fail", func(c *gin.Context) { _ = c.Error(err) })
+	return router
+}
+
+func getProblem(t *testing.T, router *gin.Engine, path string) (*httptest.ResponseRecorder, middleware.Problem) {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var problem middleware.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return w, problem
+}
+
+func TestProblem_HappyPath_DomainError(t *testing.T) {
+	// Setup
+	err := domain.NewValidationError(domain.FieldError{Field: "driver_code", Code: "required", Message: "driver code is required"})
+	router := setupProblemRouter(err)
+
+	// Execute
+	w, problem := getProblem(t, router, "/fail")
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, "VALIDATION_FAILED", problem.Code)
+	assert.Equal(t, "/fail", problem.Instance)
+	assert.Equal(t, "req-1", problem.RequestID)
+	assert.Equal(t, []domain.FieldError{{Field: "driver_code", Code: "required", Message: "driver code is required"}}, problem.Errors)
+}
+
+func TestProblem_EdgeCase_InternalErrorHidden(t *testing.T) {
+	// Setup
+	router := setupProblemRouter(errors.New("pq: connection refused"))
+
+	// Execute
+	w, problem := getProblem(t, router, "/fail")
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "INTERNAL_ERROR", problem.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+}
+
+func TestProblem_EdgeCase_UnknownRoute(t *testing.T) {
+	// Setup
+	router := setupProblemRouter(nil)
+
+	// Execute
+	w, problem := getProblem(t, router, "/missing")
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "ROUTE_NOT_FOUND", problem.Code)
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/config"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DriverCodeHeader lets authenticated scanners and driver apps name the
+// driver a request is made for, so per-driver limits apply
+const DriverCodeHeader = "X-Driver-Code"
+
+// maxKeyPartLength caps the client-supplied part of a bucket key; longer
+// values are hashed so keys always fit the shared limiter's key column
+const maxKeyPartLength = 64
+
+var errRateLimited = domain.NewError(domain.KindTooManyRequests, "RATE_LIMITED", "too many requests, try again later")
+
+// RateLimitRule limits one route, matched by method and gin route pattern
+type RateLimitRule struct {
+	Method    string
+	Path      string
+	PerMinute int
+	// Key is config.RateLimitKeyClient, RateLimitKeyDriver or RateLimitKeyIP
+	Key string
+}
+
+// RateLimitRules converts the configured route limits
+func RateLimitRules(cfg config.RateLimitConfig) []RateLimitRule {
+	rules := make([]RateLimitRule, 0, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		method, path, _ := strings.Cut(route.Route, " ")
+		rules = append(rules, RateLimitRule{
+			Method:    strings.ToUpper(method),
+			Path:      strings.TrimSpace(path),
+			PerMinute: route.Limit,
+			Key:       route.Key,
+		})
+	}
+	return rules
+}
+
+// RateLimit limits each client to defaultPerMinute requests per minute across
+// every route it guards, and applies every matching rule on top. Clients are
+// told how long to wait with 429 and Retry-After. It must run after
+// Authenticate so API key callers are counted by key. If the limiter fails,
+// the request is let through rather than turned away.
+func RateLimit(limiter domain.RateLimiter, defaultPerMinute int, rules []RateLimitRule) gin.HandlerFunc {
+	byRoute := make(map[string][]RateLimitRule)
+	for _, rule := range rules {
+		route := rule.Method + " " + rule.Path
+		byRoute[route] = append(byRoute[route], rule)
+	}
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		for _, rule := range byRoute[route] {
+			if !takeToken(c, limiter, "route:"+route+":"+rateLimitKey(c, rule.Key), rule.PerMinute) {
+				return
+			}
+		}
+		if defaultPerMinute > 0 && !takeToken(c, limiter, "all:"+rateLimitKey(c, config.RateLimitKeyClient), defaultPerMinute) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// takeToken reports whether the request may go on, aborting it with 429 when not
+func takeToken(c *gin.Context, limiter domain.RateLimiter, key string, perMinute int) bool {
+	ok, retryAfter, err := limiter.Allow(key, perMinute)
+	if err != nil {
+		log.Printf("RateLimit: failed to check %q: %v", key, err)
+		return true
+	}
+	if ok {
+		return true
+	}
+
+	c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(retryAfter)))
+	AbortWithError(c, errRateLimited)
+	return false
+}
+
+// rateLimitKey identifies who a request is counted against
+func rateLimitKey(c *gin.Context, key string) string {
+	switch key {
+	case config.RateLimitKeyIP:
+		return "ip:" + c.ClientIP()
+	case config.RateLimitKeyDriver:
+		if driver := requestDriverCode(c); driver != "" {
+			return "driver:" + boundKeyPart(driver)
+		}
+	}
+	if principal, ok := CurrentPrincipal(c); ok {
+		return "key:" + principal.Name
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// requestDriverCode returns the driver a request is made for. The header and
+// query parameter are only trusted from authenticated callers: anonymous ones
+// could rotate them to get a fresh bucket on every request.
+func requestDriverCode(c *gin.Context) string {
+	if driver := c.Param("driverCode"); driver != "" {
+		return driver
+	}
+	if _, ok := CurrentPrincipal(c); !ok {
+		return ""
+	}
+	if driver := c.GetHeader(DriverCodeHeader); driver != "" {
+		return driver
+	}
+	return c.Query("driver_code")
+}
+
+// boundKeyPart hashes values longer than maxKeyPartLength
+func boundKeyPart(value string) string {
+	if len(value) <= maxKeyPartLength {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// retryAfterSeconds rounds up so clients never retry before a token is back
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
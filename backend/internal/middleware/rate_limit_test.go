@@ -0,0 +1,220 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/middleware"
+	"pickup-queue/internal/repository"
+	"pickup-queue/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRateLimitRouter(limiter domain.RateLimiter, defaultPerMinute int, rules ...middleware.RateLimitRule) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Like the API, trust no proxy unless configured
+	_ = router.SetTrustedProxies(nil)
+	router.Use(middleware.Errors())
+	router.Use(middleware.Authenticate(map[string]domain.Principal{
+		"scanner-key": {Name: "scanner-1", Role: domain.RoleClerk},
+	}))
+	router.Use(middleware.RateLimit(limiter, defaultPerMinute, rules))
+	router.GET("/limited", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/packages", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	router.POST("/drivers/:driverCode/reassign", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func requestFrom(router *gin.Engine, method, path, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func getFrom(router *gin.Engine, remoteAddr string) *httptest.ResponseRecorder {
+	return requestFrom(router, http.MethodGet, "/limited", remoteAddr, nil)
+}
+
+// recordingRateLimiter allows every request and records the bucket keys
+type recordingRateLimiter struct {
+	keys []string
+}
+
+func (r *recordingRateLimiter) Allow(key string, _ int) (bool, time.Duration, error) {
+	r.keys = append(r.keys, key)
+	return true, 0, nil
+}
+
+type failingRateLimiter struct{}
+
+func (failingRateLimiter) Allow(string, int) (bool, time.Duration, error) {
+	return false, 0, errors.New("connection refused")
+}
+
+func TestRateLimit_HappyPath_AllowsBurst(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 3)
+
+	// Execute & Assert
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, getFrom(router, "203.0.113.7:1234").Code)
+	}
+}
+
+func TestRateLimit_EdgeCase_RejectsWithRetryAfter(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 2)
+	getFrom(router, "203.0.113.7:1234")
+	getFrom(router, "203.0.113.7:1234")
+
+	// Execute
+	w := getFrom(router, "203.0.113.7:1234")
+
+	// Assert - one token refills every 30 seconds
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_EdgeCase_KeyedByClientIP(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 1)
+	assert.Equal(t, http.StatusOK, getFrom(router, "203.0.113.7:1234").Code)
+
+	// Execute
+	other := getFrom(router, "198.51.100.9:4321")
+	again := getFrom(router, "203.0.113.7:5555")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, other.Code)
+	assert.Equal(t, http.StatusTooManyRequests, again.Code)
+}
+
+func TestRateLimit_EdgeCase_IgnoresSpoofedForwardedFor(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 1)
+	assert.Equal(t, http.StatusOK, requestFrom(router, http.MethodGet, "/limited", "203.0.113.7:1234", map[string]string{"X-Forwarded-For": "10.0.0.1"}).Code)
+
+	// Execute - a new X-Forwarded-For must not mean a new bucket
+	w := requestFrom(router, http.MethodGet, "/limited", "203.0.113.7:1234", map[string]string{"X-Forwarded-For": "10.0.0.2"})
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRateLimit_HappyPath_TrustedProxyForwardsClientIP(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 1)
+	assert.NoError(t, router.SetTrustedProxies([]string{"192.0.2.1"}))
+	assert.Equal(t, http.StatusOK, requestFrom(router, http.MethodGet, "/limited", "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "203.0.113.7"}).Code)
+
+	// Execute - clients behind the proxy are told apart
+	other := requestFrom(router, http.MethodGet, "/limited", "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.9"})
+	again := requestFrom(router, http.MethodGet, "/limited", "192.0.2.1:5555", map[string]string{"X-Forwarded-For": "203.0.113.7"})
+
+	// Assert
+	assert.Equal(t, http.StatusOK, other.Code)
+	assert.Equal(t, http.StatusTooManyRequests, again.Code)
+}
+
+func TestRateLimit_HappyPath_KeyedByAPIKeyAcrossIPs(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 1)
+	withKey := map[string]string{middleware.APIKeyHeader: "scanner-key"}
+	assert.Equal(t, http.StatusOK, requestFrom(router, http.MethodGet, "/limited", "203.0.113.7:1234", withKey).Code)
+
+	// Execute - the same key from another address shares the bucket
+	w := requestFrom(router, http.MethodGet, "/limited", "198.51.100.9:4321", withKey)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRateLimit_HappyPath_RouteRuleOnlyAppliesToItsRoute(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 100, middleware.RateLimitRule{
+		Method: http.MethodPost, Path: "/packages", PerMinute: 1, Key: config.RateLimitKeyClient,
+	})
+	assert.Equal(t, http.StatusCreated, requestFrom(router, http.MethodPost, "/packages", "203.0.113.7:1234", nil).Code)
+
+	// Execute
+	limited := requestFrom(router, http.MethodPost, "/packages", "203.0.113.7:1234", nil)
+	other := getFrom(router, "203.0.113.7:1234")
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, http.StatusOK, other.Code)
+}
+
+func TestRateLimit_HappyPath_KeyedByDriver(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 0, middleware.RateLimitRules(config.RateLimitConfig{
+		Routes: []config.RouteRateLimit{{Route: "POST /drivers/:driverCode/reassign", Limit: 1, Key: config.RateLimitKeyDriver}},
+	})...)
+	assert.Equal(t, http.StatusOK, requestFrom(router, http.MethodPost, "/drivers/DRV-001/reassign", "203.0.113.7:1234", nil).Code)
+
+	// Execute - the driver is limited from any address, other drivers are not
+	sameDriver := requestFrom(router, http.MethodPost, "/drivers/DRV-001/reassign", "198.51.100.9:4321", nil)
+	otherDriver := requestFrom(router, http.MethodPost, "/drivers/DRV-002/reassign", "203.0.113.7:1234", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, sameDriver.Code)
+	assert.Equal(t, http.StatusOK, otherDriver.Code)
+}
+
+func TestRateLimit_EdgeCase_AnonymousDriverHeaderIgnored(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(repository.NewMemoryRateLimiter(), 0, middleware.RateLimitRules(config.RateLimitConfig{
+		Routes: []config.RouteRateLimit{{Route: "POST /packages", Limit: 1, Key: config.RateLimitKeyDriver}},
+	})...)
+	assert.Equal(t, http.StatusCreated, requestFrom(router, http.MethodPost, "/packages", "203.0.113.7:1234", map[string]string{middleware.DriverCodeHeader: "DRV-001"}).Code)
+
+	// Execute - rotating the header falls back to the client's address
+	w := requestFrom(router, http.MethodPost, "/packages", "203.0.113.7:1234", map[string]string{middleware.DriverCodeHeader: "DRV-002"})
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRateLimit_EdgeCase_LongDriverCodeBounded(t *testing.T) {
+	// Setup
+	limiter := &recordingRateLimiter{}
+	router := setupRateLimitRouter(limiter, 0, middleware.RateLimitRules(config.RateLimitConfig{
+		Routes: []config.RouteRateLimit{{Route: "POST /packages", Limit: 1, Key: config.RateLimitKeyDriver}},
+	})...)
+	headers := map[string]string{
+		middleware.APIKeyHeader:     "scanner-key",
+		middleware.DriverCodeHeader: strings.Repeat("D", 4096),
+	}
+
+	// Execute
+	w := requestFrom(router, http.MethodPost, "/packages", "203.0.113.7:1234", headers)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Len(t, limiter.keys, 1)
+	assert.Less(t, len(limiter.keys[0]), 200)
+}
+
+func TestRateLimit_EdgeCase_LimiterFailureLetsRequestsThrough(t *testing.T) {
+	// Setup
+	router := setupRateLimitRouter(failingRateLimiter{}, 1)
+
+	// Execute
+	w := getFrom(router, "203.0.113.7:1234")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+}
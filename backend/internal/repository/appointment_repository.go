@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const appointmentColumns = `id, driver_code, window_start, window_end, status, booked_by, created_at, cancelled_at`
+
+// windowLockPrefix and driverDayLockPrefix namespace the advisory lock keys
+// taken while booking a pickup window
+const (
+	windowLockPrefix    = "pickup-queue:window:"
+	driverDayLockPrefix = "pickup-queue:driver-day:"
+)
+
+type AppointmentRepository struct {
+	db    *sql.DB
+	retry database.RetryPolicy
+}
+
+func NewAppointmentRepository(db *sql.DB) domain.AppointmentRepository {
+	return &AppointmentRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+// Create counts the driver's bookings that day and the window's bookings,
+// then inserts the appointment under transaction-level advisory locks on the
+// driver's day and on the window, so concurrent bookings can neither overfill
+// the window nor give the driver two appointments on one day. The driver's
+// lock is always taken first, so two bookings cannot deadlock.
+func (ar *AppointmentRepository) Create(appointment *domain.Appointment, capacity int, day time.Time) error {
+	query := `
+		INSERT INTO pickup_appointments (id, driver_code, window_start, window_end, status, booked_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	args := []interface{}{
+		appointment.ID,
+		appointment.DriverCode,
+		appointment.WindowStart,
+		appointment.WindowEnd,
+		appointment.Status,
+		nullString(appointment.BookedBy),
+		appointment.CreatedAt,
+	}
+
+	startTime := time.Now()
+	err := ar.retry.DoWrite(func() error {
+		tx, err := ar.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		driverKey := driverDayLockPrefix + appointment.DriverCode + ":" + day.UTC().Format(time.RFC3339)
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, driverKey); err != nil {
+			return err
+		}
+		var sameDay int
+		err = tx.QueryRow(`SELECT COUNT(*) FROM pickup_appointments WHERE driver_code = $1 AND status = $2 AND window_start >= $3 AND window_start < $4`,
+			appointment.DriverCode, domain.AppointmentBooked, day, day.AddDate(0, 0, 1)).Scan(&sameDay)
+		if err != nil {
+			return err
+		}
+		if sameDay > 0 {
+			return domain.ErrAppointmentExists
+		}
+
+		key := windowLockPrefix + appointment.WindowStart.UTC().Format(time.RFC3339)
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+			return err
+		}
+		var booked int
+		err = tx.QueryRow(`SELECT COUNT(*) FROM pickup_appointments WHERE window_start = $1 AND status = $2`,
+			appointment.WindowStart, domain.AppointmentBooked).Scan(&booked)
+		if err != nil {
+			return err
+		}
+		if booked >= capacity {
+			return domain.ErrWindowFull
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+
+	if err != nil {
+		if errors.Is(err, domain.ErrWindowFull) || errors.Is(err, domain.ErrAppointmentExists) {
+			database.LogQuery(query, args, startTime)
+			return err
+		}
+		database.LogQueryError(query, args, err, startTime)
+		if isUniqueViolation(err) {
+			return domain.ErrAppointmentExists
+		}
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (ar *AppointmentRepository) GetByID(id uuid.UUID) (*domain.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM pickup_appointments WHERE id = $1`
+	args := []interface{}{id}
+
+	startTime := time.Now()
+	var appointment *domain.Appointment
+	err := ar.retry.Do(func() (err error) {
+		appointment, err = scanAppointment(ar.db.QueryRow(query, args...))
+		return err
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+	return appointment, nil
+}
+
+func (ar *AppointmentRepository) ListBooked(filter domain.AppointmentFilter) ([]*domain.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM pickup_appointments
+		WHERE status = $1 AND window_start >= $2 AND window_start < $3`
+	args := []interface{}{domain.AppointmentBooked, filter.From, filter.To}
+	if filter.DriverCode != "" {
+		args = append(args, filter.DriverCode)
+		query += fmt.Sprintf(" AND driver_code = $%d", len(args))
+	}
+	query += " ORDER BY window_start, created_at, id"
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := ar.retry.Do(func() (err error) {
+		rows, err = ar.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	appointments := []*domain.Appointment{}
+	for rows.Next() {
+		appointment, err := scanAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, appointment)
+	}
+	return appointments, rows.Err()
+}
+
+func (ar *AppointmentRepository) Cancel(id uuid.UUID, cancelledAt time.Time) error {
+	query := `UPDATE pickup_appointments SET status = $2, cancelled_at = $3 WHERE id = $1 AND status = $4`
+	args := []interface{}{id, domain.AppointmentCancelled, cancelledAt, domain.AppointmentBooked}
+
+	startTime := time.Now()
+	err := ar.retry.Do(func() error {
+		_, err := ar.db.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+// scanAppointment reads one row selected with appointmentColumns
+func scanAppointment(row rowScanner) (*domain.Appointment, error) {
+	var appointment domain.Appointment
+	var bookedBy sql.NullString
+	var cancelledAt sql.NullTime
+	err := row.Scan(
+		&appointment.ID,
+		&appointment.DriverCode,
+		&appointment.WindowStart,
+		&appointment.WindowEnd,
+		&appointment.Status,
+		&bookedBy,
+		&appointment.CreatedAt,
+		&cancelledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	appointment.BookedBy = bookedBy.String
+	if cancelledAt.Valid {
+		appointment.CancelledAt = &cancelledAt.Time
+	}
+	return &appointment, nil
+}
@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/repository/repositorytest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppointmentRepository_Create_EdgeCase_WindowFull(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAppointmentRepository(db)
+	start := time.Date(2025, 8, 25, 9, 0, 0, 0, time.UTC)
+	day := repositorytest.DayOf(start)
+	appointment := repositorytest.NewAppointment("DRV-001", start)
+
+	// Mock expectations - the counts run under the driver's and the window's advisory locks
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("pickup-queue:driver-day:DRV-001:2025-08-25T00:00:00Z").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COUNT").WithArgs("DRV-001", domain.AppointmentBooked, day, day.AddDate(0, 0, 1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("pickup-queue:window:2025-08-25T09:00:00Z").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COUNT").WithArgs(start, domain.AppointmentBooked).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	// Execute
+	err = repo.Create(appointment, 2, day)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrWindowFull)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
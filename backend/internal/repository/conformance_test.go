@@ -0,0 +1,130 @@
+package repository_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/repository/repositorytest"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPackageRepository_Conformance(t *testing.T) {
+	repositorytest.RunPackageRepositorySuite(t, func(t *testing.T) domain.PackageRepository {
+		return repository.NewMemoryPackageRepository()
+	})
+}
+
+func TestMemoryPickupSessionRepository_Conformance(t *testing.T) {
+	repositorytest.RunPickupSessionRepositorySuite(t, func(t *testing.T) domain.PickupSessionRepository {
+		return repository.NewMemoryPickupSessionRepository()
+	})
+}
+
+func TestMemoryPackageArchiveRepository_Conformance(t *testing.T) {
+	repositorytest.RunPackageArchiveRepositorySuite(t, func(t *testing.T) (domain.PackageRepository, domain.PackageArchiveRepository) {
+		packages := repository.NewMemoryPackageRepository()
+		return packages, repository.NewMemoryPackageArchiveRepository(packages)
+	})
+}
+
+func TestMemoryJobRunRepository_Conformance(t *testing.T) {
+	repositorytest.RunJobRunRepositorySuite(t, func(t *testing.T) domain.JobRunRepository {
+		return repository.NewMemoryJobRunRepository()
+	})
+}
+
+func TestMemoryJobSettingsRepository_Conformance(t *testing.T) {
+	repositorytest.RunJobSettingsRepositorySuite(t, func(t *testing.T) domain.JobSettingsRepository {
+		return repository.NewMemoryJobSettingsRepository()
+	})
+}
+
+func TestMemoryShipmentRepository_Conformance(t *testing.T) {
+	repositorytest.RunShipmentRepositorySuite(t, func(t *testing.T) (domain.ShipmentRepository, domain.PackageRepository) {
+		return repository.NewMemoryShipmentRepository(), repository.NewMemoryPackageRepository()
+	})
+}
+
+func TestMemoryAppointmentRepository_Conformance(t *testing.T) {
+	repositorytest.RunAppointmentRepositorySuite(t, func(t *testing.T) domain.AppointmentRepository {
+		return repository.NewMemoryAppointmentRepository()
+	})
+}
+
+func TestMemorySLABreachRepository_Conformance(t *testing.T) {
+	repositorytest.RunSLABreachRepositorySuite(t, func(t *testing.T) domain.SLABreachRepository {
+		return repository.NewMemorySLABreachRepository()
+	})
+}
+
+func TestMemoryPickupReminderRepository_Conformance(t *testing.T) {
+	repositorytest.RunPickupReminderRepositorySuite(t, func(t *testing.T) domain.PickupReminderRepository {
+		return repository.NewMemoryPickupReminderRepository()
+	})
+}
+
+// TestPackageRepository_Conformance runs the suite against a real Postgres
+// database with the migrations applied. Set TEST_DATABASE_URL to enable it;
+// the tables under test are truncated before every subtest.
+func TestPackageRepository_Conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Ping())
+
+	repositorytest.RunPackageRepositorySuite(t, func(t *testing.T) domain.PackageRepository {
+		_, err := db.Exec("TRUNCATE packages")
+		require.NoError(t, err)
+		return repository.NewPackageRepository(db)
+	})
+	repositorytest.RunPickupSessionRepositorySuite(t, func(t *testing.T) domain.PickupSessionRepository {
+		_, err := db.Exec("TRUNCATE pickup_sessions CASCADE")
+		require.NoError(t, err)
+		return repository.NewPickupSessionRepository(db)
+	})
+	repositorytest.RunPackageArchiveRepositorySuite(t, func(t *testing.T) (domain.PackageRepository, domain.PackageArchiveRepository) {
+		_, err := db.Exec("TRUNCATE packages, packages_archive")
+		require.NoError(t, err)
+		return repository.NewPackageRepository(db), repository.NewPackageArchiveRepository(db)
+	})
+	repositorytest.RunShipmentRepositorySuite(t, func(t *testing.T) (domain.ShipmentRepository, domain.PackageRepository) {
+		_, err := db.Exec("TRUNCATE packages, shipments")
+		require.NoError(t, err)
+		return repository.NewShipmentRepository(db), repository.NewPackageRepository(db)
+	})
+	repositorytest.RunAppointmentRepositorySuite(t, func(t *testing.T) domain.AppointmentRepository {
+		_, err := db.Exec("TRUNCATE pickup_appointments")
+		require.NoError(t, err)
+		return repository.NewAppointmentRepository(db)
+	})
+	repositorytest.RunSLABreachRepositorySuite(t, func(t *testing.T) domain.SLABreachRepository {
+		_, err := db.Exec("TRUNCATE sla_breaches")
+		require.NoError(t, err)
+		return repository.NewSLABreachRepository(db)
+	})
+	repositorytest.RunPickupReminderRepositorySuite(t, func(t *testing.T) domain.PickupReminderRepository {
+		_, err := db.Exec("TRUNCATE pickup_reminders")
+		require.NoError(t, err)
+		return repository.NewPickupReminderRepository(db)
+	})
+	repositorytest.RunJobRunRepositorySuite(t, func(t *testing.T) domain.JobRunRepository {
+		_, err := db.Exec("TRUNCATE job_runs")
+		require.NoError(t, err)
+		return repository.NewJobRunRepository(db)
+	})
+	repositorytest.RunJobSettingsRepositorySuite(t, func(t *testing.T) domain.JobSettingsRepository {
+		_, err := db.Exec("TRUNCATE job_settings")
+		require.NoError(t, err)
+		return repository.NewJobSettingsRepository(db)
+	})
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DriverAssignmentRepository struct {
+	db    dbtx
+	retry database.RetryPolicy
+}
+
+func NewDriverAssignmentRepository(db *sql.DB) domain.DriverAssignmentRepository {
+	return &DriverAssignmentRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (dr *DriverAssignmentRepository) Create(assignment *domain.DriverAssignment) error {
+	query := `
+		INSERT INTO driver_assignments (id, package_id, from_driver, to_driver, reason, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	args := []interface{}{
+		assignment.ID,
+		assignment.PackageID,
+		assignment.FromDriver,
+		assignment.ToDriver,
+		assignment.Reason,
+		assignment.ChangedBy,
+		assignment.ChangedAt,
+	}
+
+	startTime := time.Now()
+	err := dr.retry.DoWrite(func() error {
+		_, err := dr.db.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (dr *DriverAssignmentRepository) ListByPackage(packageID uuid.UUID) ([]*domain.DriverAssignment, error) {
+	query := `
+		SELECT id, package_id, from_driver, to_driver, reason, changed_by, changed_at
+		FROM driver_assignments
+		WHERE package_id = $1
+		ORDER BY changed_at, id`
+	args := []interface{}{packageID}
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := dr.retry.Do(func() (err error) {
+		rows, err = dr.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+
+	database.LogQuery(query, args, startTime)
+
+	history := []*domain.DriverAssignment{}
+	for rows.Next() {
+		var a domain.DriverAssignment
+		if err := rows.Scan(&a.ID, &a.PackageID, &a.FromDriver, &a.ToDriver, &a.Reason, &a.ChangedBy, &a.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, &a)
+	}
+
+	return history, rows.Err()
+}
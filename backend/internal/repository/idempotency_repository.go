@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+)
+
+type IdempotencyRepository struct {
+	db    dbtx
+	retry database.RetryPolicy
+}
+
+func NewIdempotencyRepository(db *sql.DB) domain.IdempotencyRepository {
+	return &IdempotencyRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (ir *IdempotencyRepository) Reserve(record *domain.IdempotencyRecord) error {
+	query := `
+		INSERT INTO idempotency_keys (key, request_hash, status_code, created_at, expires_at)
+		VALUES ($1, $2, 0, $3, $4)
+		ON CONFLICT (key) DO NOTHING`
+	args := []interface{}{record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt}
+
+	startTime := time.Now()
+	var result sql.Result
+	err := ir.retry.DoWrite(func() (err error) {
+		result, err = ir.db.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func (ir *IdempotencyRepository) Get(key string) (*domain.IdempotencyRecord, error) {
+	query := `
+		SELECT key, request_hash, status_code, content_type, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1`
+	args := []interface{}{key}
+
+	var record domain.IdempotencyRecord
+	startTime := time.Now()
+	err := ir.retry.Do(func() error {
+		return ir.db.QueryRow(query, args...).Scan(
+			&record.Key,
+			&record.RequestHash,
+			&record.StatusCode,
+			&record.ContentType,
+			&record.ResponseBody,
+			&record.CreatedAt,
+			&record.ExpiresAt,
+		)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	return &record, nil
+}
+
+func (ir *IdempotencyRepository) Complete(key string, statusCode int, contentType string, body []byte, expiresAt time.Time) error {
+	query := `UPDATE idempotency_keys SET status_code = $2, content_type = $3, response_body = $4, expires_at = $5 WHERE key = $1`
+	return ir.exec(query, key, statusCode, contentType, body, expiresAt)
+}
+
+func (ir *IdempotencyRepository) Delete(key string) error {
+	return ir.exec(`DELETE FROM idempotency_keys WHERE key = $1`, key)
+}
+
+func (ir *IdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < $1`
+	args := []interface{}{now}
+
+	startTime := time.Now()
+	var result sql.Result
+	err := ir.retry.Do(func() (err error) {
+		result, err = ir.db.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return 0, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	return result.RowsAffected()
+}
+
+func (ir *IdempotencyRepository) exec(query string, args ...interface{}) error {
+	startTime := time.Now()
+	err := ir.retry.Do(func() error {
+		_, err := ir.db.Exec(query, args...)
+		return err
+	})
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+
+	return err
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"pickup-queue/internal/domain"
+	"sync"
+	"time"
+)
+
+// advisoryLockPrefix namespaces the advisory lock keys taken for worker jobs
+const advisoryLockPrefix = "pickup-queue:job:"
+
+// AdvisoryJobLocker elects a job leader with Postgres session-level advisory
+// locks. Each held lock pins one pooled connection until it is released, and
+// a replica that dies loses its locks with its connections.
+type AdvisoryJobLocker struct {
+	db *sql.DB
+}
+
+func NewAdvisoryJobLocker(db *sql.DB) domain.JobLocker {
+	return &AdvisoryJobLocker{db: db}
+}
+
+func (l *AdvisoryJobLocker) TryLock(ctx context.Context, job string) (func(), bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := advisoryLockPrefix + job
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// The run's context may be done by now; unlocking must still happen
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(unlockCtx, `SELECT pg_advisory_unlock(hashtext($1))`, key); err != nil {
+			// Drop the connection rather than return a locked session to the pool
+			discard(conn)
+			return
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// advisoryLeaderKey is the advisory lock key held by the leading worker replica
+const advisoryLeaderKey = "pickup-queue:leader"
+
+// AdvisoryLeaderElector makes the worker replica holding a session-level
+// advisory lock the leader for as long as its session lives. The lock pins
+// one pooled connection; if that connection breaks, the replica stops
+// leading and another one takes over on its next check.
+type AdvisoryLeaderElector struct {
+	db *sql.DB
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func NewAdvisoryLeaderElector(db *sql.DB) *AdvisoryLeaderElector {
+	return &AdvisoryLeaderElector{db: db}
+}
+
+func (l *AdvisoryLeaderElector) IsLeader(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		// The lock lasts as long as the session that took it
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		discard(l.conn)
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, advisoryLeaderKey).Scan(&locked); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *AdvisoryLeaderElector) Resign() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := l.conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, advisoryLeaderKey); err != nil {
+		discard(l.conn)
+	} else {
+		l.conn.Close()
+	}
+	l.conn = nil
+}
+
+// discard closes conn without returning its session, and any advisory locks
+// it holds, to the pool
+func discard(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+// LocalJobLocker elects within one process. It suits SQLite deployments,
+// which run a single worker by design.
+type LocalJobLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func NewLocalJobLocker() *LocalJobLocker {
+	return &LocalJobLocker{held: make(map[string]bool)}
+}
+
+func (l *LocalJobLocker) TryLock(_ context.Context, job string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held[job] {
+		return nil, false, nil
+	}
+	l.held[job] = true
+
+	unlock := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, job)
+	}
+	return unlock, true, nil
+}
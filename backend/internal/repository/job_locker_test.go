@@ -0,0 +1,137 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pickup-queue/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvisoryJobLocker_TryLock_HappyPath(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	locker := repository.NewAdvisoryJobLocker(db)
+
+	// Mock expectations - lock and unlock run on the same session
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs("pickup-queue:job:expire-packages").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs("pickup-queue:job:expire-packages").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	unlock, ok, err := locker.TryLock(context.Background(), "expire-packages")
+	require.NoError(t, err)
+	require.True(t, ok)
+	unlock()
+
+	// Assert
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdvisoryJobLocker_TryLock_EdgeCase_HeldElsewhere(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	locker := repository.NewAdvisoryJobLocker(db)
+
+	// Mock expectations
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs("pickup-queue:job:expire-packages").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+
+	// Execute
+	unlock, ok, err := locker.TryLock(context.Background(), "expire-packages")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, unlock)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdvisoryLeaderElector_IsLeader_HappyPath_KeepsSession(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	leader := repository.NewAdvisoryLeaderElector(db)
+
+	// Mock expectations - the lock is taken once, then the session is checked
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs("pickup-queue:leader").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectPing()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs("pickup-queue:leader").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	first, err := leader.IsLeader(context.Background())
+	require.NoError(t, err)
+	second, err := leader.IsLeader(context.Background())
+	require.NoError(t, err)
+	leader.Resign()
+
+	// Assert
+	assert.True(t, first)
+	assert.True(t, second)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdvisoryLeaderElector_IsLeader_EdgeCase_LostSession(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	leader := repository.NewAdvisoryLeaderElector(db)
+
+	// Mock expectations - another replica leads at first; once this one
+	// leads, its session breaks and the lock goes with it
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs("pickup-queue:leader").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs("pickup-queue:leader").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectPing().WillReturnError(errors.New("connection reset"))
+
+	// Execute
+	following, err := leader.IsLeader(context.Background())
+	require.NoError(t, err)
+	leading, err := leader.IsLeader(context.Background())
+	require.NoError(t, err)
+	lost, lostErr := leader.IsLeader(context.Background())
+
+	// Assert
+	assert.False(t, following)
+	assert.True(t, leading)
+	// The mock cannot reconnect, so taking the lead again fails
+	assert.Error(t, lostErr)
+	assert.False(t, lost)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocalJobLocker_TryLock_EdgeCase_AlreadyHeld(t *testing.T) {
+	// Setup
+	locker := repository.NewLocalJobLocker()
+	unlock, ok, err := locker.TryLock(context.Background(), "archive-packages")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Execute
+	_, second, err := locker.TryLock(context.Background(), "archive-packages")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, second)
+	unlock()
+	_, again, _ := locker.TryLock(context.Background(), "archive-packages")
+	assert.True(t, again)
+}
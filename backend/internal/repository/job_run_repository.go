@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+)
+
+type JobRunRepository struct {
+	db    dbtx
+	retry database.RetryPolicy
+}
+
+func NewJobRunRepository(db *sql.DB) domain.JobRunRepository {
+	return &JobRunRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (jr *JobRunRepository) Create(run *domain.JobRun) error {
+	query := `
+		INSERT INTO job_runs (id, job, trigger, status, started_at)
+		VALUES ($1, $2, $3, $4, $5)`
+	args := []interface{}{run.ID, run.Job, run.Trigger, run.Status, run.StartedAt}
+	return jr.exec(query, args...)
+}
+
+func (jr *JobRunRepository) Finish(run *domain.JobRun) error {
+	query := `UPDATE job_runs SET status = $2, finished_at = $3, result = $4, error = $5 WHERE id = $1`
+	args := []interface{}{run.ID, run.Status, run.FinishedAt, run.Result, run.Error}
+	return jr.exec(query, args...)
+}
+
+func (jr *JobRunRepository) ListByJob(job string, limit int) ([]*domain.JobRun, error) {
+	query := `
+		SELECT id, job, trigger, status, started_at, finished_at, result, error
+		FROM job_runs
+		WHERE job = $1
+		ORDER BY started_at DESC, id
+		LIMIT $2`
+	args := []interface{}{job, limit}
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := jr.retry.Do(func() (err error) {
+		rows, err = jr.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+
+	database.LogQuery(query, args, startTime)
+
+	runs := []*domain.JobRun{}
+	for rows.Next() {
+		var run domain.JobRun
+		var finishedAt sql.NullTime
+		if err := rows.Scan(&run.ID, &run.Job, &run.Trigger, &run.Status, &run.StartedAt, &finishedAt, &run.Result, &run.Error); err != nil {
+			return nil, err
+		}
+		if finishedAt.Valid {
+			run.FinishedAt = &finishedAt.Time
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (jr *JobRunRepository) exec(query string, args ...interface{}) error {
+	startTime := time.Now()
+	err := jr.retry.DoWrite(func() error {
+		_, err := jr.db.Exec(query, args...)
+		return err
+	})
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+
+	return err
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+)
+
+type JobSettingsRepository struct {
+	db    dbtx
+	retry database.RetryPolicy
+}
+
+func NewJobSettingsRepository(db *sql.DB) domain.JobSettingsRepository {
+	return &JobSettingsRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (jr *JobSettingsRepository) SetPaused(job string, paused bool) error {
+	query := `
+		INSERT INTO job_settings (job, paused, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (job) DO UPDATE SET paused = EXCLUDED.paused, updated_at = EXCLUDED.updated_at`
+	args := []interface{}{job, paused, time.Now()}
+
+	startTime := time.Now()
+	err := jr.retry.Do(func() error {
+		_, err := jr.db.Exec(query, args...)
+		return err
+	})
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+	return err
+}
+
+func (jr *JobSettingsRepository) Paused(job string) (bool, error) {
+	query := `SELECT paused FROM job_settings WHERE job = $1`
+	args := []interface{}{job}
+
+	startTime := time.Now()
+	var paused bool
+	err := jr.retry.Do(func() error {
+		return jr.db.QueryRow(query, args...).Scan(&paused)
+	})
+
+	if err != nil && err != sql.ErrNoRows {
+		database.LogQueryError(query, args, err, startTime)
+		return false, err
+	}
+	database.LogQuery(query, args, startTime)
+	return paused, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryAppointmentRepository is a thread-safe in-memory domain.AppointmentRepository
+type MemoryAppointmentRepository struct {
+	mu           sync.RWMutex
+	appointments map[uuid.UUID]domain.Appointment
+}
+
+func NewMemoryAppointmentRepository() *MemoryAppointmentRepository {
+	return &MemoryAppointmentRepository{appointments: make(map[uuid.UUID]domain.Appointment)}
+}
+
+func (mr *MemoryAppointmentRepository) Create(appointment *domain.Appointment, capacity int, day time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	nextDay := day.AddDate(0, 0, 1)
+	booked := 0
+	for _, existing := range mr.appointments {
+		if existing.Status != domain.AppointmentBooked {
+			continue
+		}
+		if existing.DriverCode == appointment.DriverCode && !existing.WindowStart.Before(day) && existing.WindowStart.Before(nextDay) {
+			return domain.ErrAppointmentExists
+		}
+		if existing.WindowStart.Equal(appointment.WindowStart) {
+			booked++
+		}
+	}
+	if booked >= capacity {
+		return domain.ErrWindowFull
+	}
+
+	stored := *appointment
+	stored.WaitingPackages = 0
+	mr.appointments[appointment.ID] = stored
+	return nil
+}
+
+func (mr *MemoryAppointmentRepository) GetByID(id uuid.UUID) (*domain.Appointment, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	appointment, ok := mr.appointments[id]
+	if !ok {
+		return nil, nil
+	}
+	return &appointment, nil
+}
+
+func (mr *MemoryAppointmentRepository) ListBooked(filter domain.AppointmentFilter) ([]*domain.Appointment, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	matched := []*domain.Appointment{}
+	for _, appointment := range mr.appointments {
+		if appointment.Status != domain.AppointmentBooked ||
+			appointment.WindowStart.Before(filter.From) || !appointment.WindowStart.Before(filter.To) ||
+			(filter.DriverCode != "" && appointment.DriverCode != filter.DriverCode) {
+			continue
+		}
+		out := appointment
+		matched = append(matched, &out)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if !a.WindowStart.Equal(b.WindowStart) {
+			return a.WindowStart.Before(b.WindowStart)
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	return matched, nil
+}
+
+func (mr *MemoryAppointmentRepository) Cancel(id uuid.UUID, cancelledAt time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	appointment, ok := mr.appointments[id]
+	if !ok || appointment.Status != domain.AppointmentBooked {
+		return nil
+	}
+	appointment.Status = domain.AppointmentCancelled
+	appointment.CancelledAt = &cancelledAt
+	mr.appointments[id] = appointment
+	return nil
+}
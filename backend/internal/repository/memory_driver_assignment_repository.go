@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryDriverAssignmentRepository is a thread-safe in-memory domain.DriverAssignmentRepository
+type MemoryDriverAssignmentRepository struct {
+	mu          sync.RWMutex
+	assignments []domain.DriverAssignment
+}
+
+func NewMemoryDriverAssignmentRepository() *MemoryDriverAssignmentRepository {
+	return &MemoryDriverAssignmentRepository{}
+}
+
+func (mr *MemoryDriverAssignmentRepository) Create(assignment *domain.DriverAssignment) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.assignments = append(mr.assignments, *assignment)
+	return nil
+}
+
+func (mr *MemoryDriverAssignmentRepository) ListByPackage(packageID uuid.UUID) ([]*domain.DriverAssignment, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	history := []*domain.DriverAssignment{}
+	for _, assignment := range mr.assignments {
+		if assignment.PackageID == packageID {
+			a := assignment
+			history = append(history, &a)
+		}
+	}
+	return history, nil
+}
+
+// Snapshot captures the current history; calling the returned function restores it
+func (mr *MemoryDriverAssignmentRepository) Snapshot() (restore func()) {
+	mr.mu.RLock()
+	assignments := append([]domain.DriverAssignment(nil), mr.assignments...)
+	mr.mu.RUnlock()
+
+	return func() {
+		mr.mu.Lock()
+		defer mr.mu.Unlock()
+		mr.assignments = assignments
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sync"
+	"time"
+)
+
+// MemoryIdempotencyRepository is a thread-safe in-memory domain.IdempotencyRepository
+type MemoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func NewMemoryIdempotencyRepository() *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{records: make(map[string]domain.IdempotencyRecord)}
+}
+
+func (mr *MemoryIdempotencyRepository) Reserve(record *domain.IdempotencyRecord) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, exists := mr.records[record.Key]; exists {
+		return domain.ErrIdempotencyKeyExists
+	}
+	stored := *record
+	stored.StatusCode = 0
+	stored.ResponseBody = nil
+	mr.records[record.Key] = stored
+	return nil
+}
+
+func (mr *MemoryIdempotencyRepository) Get(key string) (*domain.IdempotencyRecord, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	record, ok := mr.records[key]
+	if !ok {
+		return nil, nil
+	}
+	record.ResponseBody = append([]byte(nil), record.ResponseBody...)
+	return &record, nil
+}
+
+func (mr *MemoryIdempotencyRepository) Complete(key string, statusCode int, contentType string, body []byte, expiresAt time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	record, ok := mr.records[key]
+	if !ok {
+		return nil
+	}
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.ResponseBody = append([]byte(nil), body...)
+	record.ExpiresAt = expiresAt
+	mr.records[key] = record
+	return nil
+}
+
+func (mr *MemoryIdempotencyRepository) Delete(key string) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	delete(mr.records, key)
+	return nil
+}
+
+func (mr *MemoryIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	var deleted int64
+	for key, record := range mr.records {
+		if record.ExpiresAt.Before(now) {
+			delete(mr.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
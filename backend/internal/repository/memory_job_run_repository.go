@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryJobRunRepository is a thread-safe in-memory domain.JobRunRepository
+type MemoryJobRunRepository struct {
+	mu   sync.RWMutex
+	runs map[uuid.UUID]*domain.JobRun
+}
+
+func NewMemoryJobRunRepository() *MemoryJobRunRepository {
+	return &MemoryJobRunRepository{runs: make(map[uuid.UUID]*domain.JobRun)}
+}
+
+func (mr *MemoryJobRunRepository) Create(run *domain.JobRun) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.runs[run.ID] = cloneJobRun(run)
+	return nil
+}
+
+func (mr *MemoryJobRunRepository) Finish(run *domain.JobRun) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if stored, ok := mr.runs[run.ID]; ok {
+		stored.Status = run.Status
+		stored.FinishedAt = cloneTime(run.FinishedAt)
+		stored.Result = run.Result
+		stored.Error = run.Error
+	}
+	return nil
+}
+
+func (mr *MemoryJobRunRepository) ListByJob(job string, limit int) ([]*domain.JobRun, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	runs := []*domain.JobRun{}
+	for _, run := range mr.runs {
+		if run.Job == job {
+			runs = append(runs, cloneJobRun(run))
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].ID.String() < runs[j].ID.String()
+		}
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func cloneJobRun(run *domain.JobRun) *domain.JobRun {
+	out := *run
+	out.FinishedAt = cloneTime(run.FinishedAt)
+	return &out
+}
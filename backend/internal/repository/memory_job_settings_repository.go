@@ -0,0 +1,28 @@
+package repository
+
+import "sync"
+
+// MemoryJobSettingsRepository is a thread-safe in-memory domain.JobSettingsRepository
+type MemoryJobSettingsRepository struct {
+	mu     sync.RWMutex
+	paused map[string]bool
+}
+
+func NewMemoryJobSettingsRepository() *MemoryJobSettingsRepository {
+	return &MemoryJobSettingsRepository{paused: make(map[string]bool)}
+}
+
+func (mr *MemoryJobSettingsRepository) SetPaused(job string, paused bool) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.paused[job] = paused
+	return nil
+}
+
+func (mr *MemoryJobSettingsRepository) Paused(job string) (bool, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	return mr.paused[job], nil
+}
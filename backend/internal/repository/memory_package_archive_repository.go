@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"pickup-queue/internal/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryPackageArchiveRepository is an in-memory domain.PackageArchiveRepository
+// that moves packages out of a MemoryPackageRepository
+type MemoryPackageArchiveRepository struct {
+	mu       sync.RWMutex
+	packages *MemoryPackageRepository
+	archived map[uuid.UUID]*domain.Package
+}
+
+func NewMemoryPackageArchiveRepository(packages *MemoryPackageRepository) *MemoryPackageArchiveRepository {
+	return &MemoryPackageArchiveRepository{
+		packages: packages,
+		archived: make(map[uuid.UUID]*domain.Package),
+	}
+}
+
+func (ar *MemoryPackageArchiveRepository) ArchiveBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	ar.packages.mu.Lock()
+	defer ar.packages.mu.Unlock()
+
+	var candidates []*domain.Package
+	for _, pkg := range ar.packages.packages {
+		if pkg.DeletedAt == nil && isArchivable(pkg.Status) && pkg.UpdatedAt.Before(cutoff) {
+			candidates = append(candidates, pkg)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].UpdatedAt.Before(candidates[j].UpdatedAt)
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	now := time.Now()
+	for _, pkg := range candidates {
+		archived := clonePackage(pkg)
+		archived.ArchivedAt = &now
+		ar.archived[pkg.ID] = archived
+		delete(ar.packages.orderIndex, pkg.OrderRef)
+		delete(ar.packages.packages, pkg.ID)
+	}
+	return int64(len(candidates)), nil
+}
+
+func (ar *MemoryPackageArchiveRepository) GetByID(id uuid.UUID) (*domain.Package, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	if pkg, ok := ar.archived[id]; ok {
+		return clonePackage(pkg), nil
+	}
+	return nil, nil
+}
+
+func (ar *MemoryPackageArchiveRepository) GetByOrderRef(orderRef string) (*domain.Package, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	for _, pkg := range ar.archived {
+		if pkg.OrderRef == orderRef {
+			return clonePackage(pkg), nil
+		}
+	}
+	return nil, nil
+}
+
+func (ar *MemoryPackageArchiveRepository) Rehydrate(id uuid.UUID) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	pkg, ok := ar.archived[id]
+	if !ok {
+		return nil
+	}
+
+	live := clonePackage(pkg)
+	live.ArchivedAt = nil
+	if err := ar.packages.Create(live); err != nil {
+		return err
+	}
+	delete(ar.archived, id)
+	return nil
+}
+
+func isArchivable(status domain.PackageStatus) bool {
+	for _, archivable := range domain.ArchivableStatuses {
+		if status == archivable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,417 @@
+package repository
+
+import (
+	"context"
+	"pickup-queue/internal/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryPackageRepository is a thread-safe in-memory domain.PackageRepository
+// with the same semantics as the Postgres implementation. It backs tests and
+// the API's demo mode (-storage=memory).
+type MemoryPackageRepository struct {
+	mu         sync.RWMutex
+	packages   map[uuid.UUID]*domain.Package
+	orderIndex map[string]uuid.UUID
+}
+
+func NewMemoryPackageRepository() *MemoryPackageRepository {
+	return &MemoryPackageRepository{
+		packages:   make(map[uuid.UUID]*domain.Package),
+		orderIndex: make(map[string]uuid.UUID),
+	}
+}
+
+func (mr *MemoryPackageRepository) Create(pkg *domain.Package) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, exists := mr.orderIndex[pkg.OrderRef]; exists {
+		return domain.ErrDuplicateOrderRef
+	}
+	if _, exists := mr.packages[pkg.ID]; exists {
+		return domain.ErrDuplicateOrderRef
+	}
+
+	stored := clonePackage(pkg)
+	mr.packages[pkg.ID] = stored
+	mr.orderIndex[pkg.OrderRef] = pkg.ID
+	return nil
+}
+
+func (mr *MemoryPackageRepository) GetByID(id uuid.UUID) (*domain.Package, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	pkg := mr.live(id)
+	if pkg == nil {
+		return nil, nil
+	}
+	return clonePackage(pkg), nil
+}
+
+func (mr *MemoryPackageRepository) GetByOrderRef(orderRef string) (*domain.Package, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	pkg := mr.live(mr.orderIndex[orderRef])
+	if pkg == nil {
+		return nil, nil
+	}
+	return clonePackage(pkg), nil
+}
+
+func (mr *MemoryPackageRepository) GetAll(filter domain.PackageFilter) ([]*domain.Package, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var matched []*domain.Package
+	for _, pkg := range mr.packages {
+		if pkg.DeletedAt != nil {
+			continue
+		}
+		if filter.Status != nil && pkg.Status != *filter.Status {
+			continue
+		}
+		if filter.DriverCode != "" && pkg.DriverCode != filter.DriverCode {
+			continue
+		}
+		if filter.SizeClass != "" && pkg.SizeClass != filter.SizeClass {
+			continue
+		}
+		if filter.Fragile != nil && pkg.Fragile != *filter.Fragile {
+			continue
+		}
+		if filter.Temperature != "" && pkg.Temperature != filter.Temperature {
+			continue
+		}
+		if filter.MinDeclaredValue != nil && (pkg.DeclaredValue == nil || *pkg.DeclaredValue < *filter.MinDeclaredValue) {
+			continue
+		}
+		if filter.ShipmentID != nil && (pkg.ShipmentID == nil || *pkg.ShipmentID != *filter.ShipmentID) {
+			continue
+		}
+		if filter.Priority != "" && pkg.Priority != filter.Priority {
+			continue
+		}
+		matched = append(matched, pkg)
+	}
+	sortByPriority(matched)
+
+	return page(matched, filter.Limit, filter.Offset), nil
+}
+
+func (mr *MemoryPackageRepository) Update(pkg *domain.Package) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	existing := mr.live(pkg.ID)
+	if existing == nil {
+		return nil
+	}
+	if id, taken := mr.orderIndex[pkg.OrderRef]; taken && id != pkg.ID {
+		return domain.ErrDuplicateOrderRef
+	}
+
+	delete(mr.orderIndex, existing.OrderRef)
+	updated := clonePackage(pkg)
+	updated.CreatedAt = existing.CreatedAt
+	mr.packages[pkg.ID] = updated
+	mr.orderIndex[pkg.OrderRef] = pkg.ID
+	return nil
+}
+
+func (mr *MemoryPackageRepository) Delete(id uuid.UUID) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if pkg := mr.live(id); pkg != nil {
+		now := time.Now()
+		pkg.DeletedAt = &now
+	}
+	return nil
+}
+
+func (mr *MemoryPackageRepository) Restore(id uuid.UUID) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if pkg, ok := mr.packages[id]; ok && pkg.DeletedAt != nil {
+		pkg.DeletedAt = nil
+		pkg.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (mr *MemoryPackageRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	var purged int64
+	for id, pkg := range mr.packages {
+		if pkg.DeletedAt != nil && pkg.DeletedAt.Before(cutoff) {
+			delete(mr.orderIndex, pkg.OrderRef)
+			delete(mr.packages, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// GetExpiredPackages returns active packages created before the cutoff time
+func (mr *MemoryPackageRepository) GetExpiredPackages(ctx context.Context, cutoffTime time.Time) ([]*domain.Package, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var expired []*domain.Package
+	for _, pkg := range mr.packages {
+		if pkg.DeletedAt == nil && (pkg.Status == domain.StatusWaiting || pkg.Status == domain.StatusPicked) && pkg.CreatedAt.Before(cutoffTime) {
+			expired = append(expired, clonePackage(pkg))
+		}
+	}
+	return expired, nil
+}
+
+// GetExpiredBefore returns EXPIRED packages that expired before the cutoff time
+func (mr *MemoryPackageRepository) GetExpiredBefore(ctx context.Context, cutoffTime time.Time) ([]*domain.Package, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var expired []*domain.Package
+	for _, pkg := range mr.packages {
+		if pkg.DeletedAt == nil && pkg.Status == domain.StatusExpired && pkg.ExpiredAt != nil && pkg.ExpiredAt.Before(cutoffTime) {
+			expired = append(expired, clonePackage(pkg))
+		}
+	}
+	return expired, nil
+}
+
+func (mr *MemoryPackageRepository) UpdateStatus(id uuid.UUID, status domain.PackageStatus) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	pkg := mr.live(id)
+	if pkg == nil {
+		return nil
+	}
+
+	now := time.Now()
+	pkg.Status = status
+	pkg.UpdatedAt = now
+
+	switch status {
+	case domain.StatusPicked:
+		pkg.PickedUpAt = &now
+	case domain.StatusHandedOver:
+		pkg.HandedOverAt = &now
+	case domain.StatusExpired:
+		pkg.ExpiredAt = &now
+	case domain.StatusReturnPending:
+		pkg.ReturnPendingAt = &now
+	case domain.StatusReturned:
+		pkg.ReturnedAt = &now
+	case domain.StatusDisposed:
+		pkg.DisposedAt = &now
+	}
+	return nil
+}
+
+func (mr *MemoryPackageRepository) GetPackageStats() (*domain.PackageStats, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	stats := domain.PackageStats{BySizeClass: make(map[domain.SizeClass]int64, len(domain.SizeClasses))}
+	for _, class := range domain.SizeClasses {
+		stats.BySizeClass[class] = 0
+	}
+	for _, pkg := range mr.packages {
+		if pkg.DeletedAt != nil {
+			continue
+		}
+		stats.Total++
+		stats.BySizeClass[pkg.SizeClass]++
+		switch pkg.Status {
+		case domain.StatusWaiting:
+			stats.Waiting++
+		case domain.StatusPicked:
+			stats.Picked++
+		case domain.StatusHandedOver:
+			stats.HandedOver++
+		case domain.StatusExpired:
+			stats.Expired++
+		case domain.StatusReturnPending:
+			stats.ReturnPending++
+		case domain.StatusReturned:
+			stats.Returned++
+		case domain.StatusDisposed:
+			stats.Disposed++
+		}
+	}
+	return &stats, nil
+}
+
+func (mr *MemoryPackageRepository) WaitingPositions(ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var waiting []*domain.Package
+	for _, pkg := range mr.packages {
+		if pkg.Status == domain.StatusWaiting && pkg.DeletedAt == nil {
+			waiting = append(waiting, pkg)
+		}
+	}
+	sort.Slice(waiting, func(i, j int) bool {
+		if rank, other := waiting[i].Priority.Rank(), waiting[j].Priority.Rank(); rank != other {
+			return rank < other
+		}
+		if waiting[i].CreatedAt.Equal(waiting[j].CreatedAt) {
+			return waiting[i].ID.String() < waiting[j].ID.String()
+		}
+		return waiting[i].CreatedAt.Before(waiting[j].CreatedAt)
+	})
+
+	wanted := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	positions := make(map[uuid.UUID]int, len(ids))
+	for i, pkg := range waiting {
+		if wanted[pkg.ID] {
+			positions[pkg.ID] = i + 1
+		}
+	}
+	return positions, nil
+}
+
+func (mr *MemoryPackageRepository) RecentPickupTimes(limit int) ([]time.Time, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	times := []time.Time{}
+	for _, pkg := range mr.packages {
+		if pkg.PickedUpAt != nil && pkg.DeletedAt == nil {
+			times = append(times, *pkg.PickedUpAt)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].After(times[j]) })
+	if limit > 0 && len(times) > limit {
+		times = times[:limit]
+	}
+	return times, nil
+}
+
+// live returns the stored package unless it is missing or soft-deleted
+func (mr *MemoryPackageRepository) live(id uuid.UUID) *domain.Package {
+	if pkg, ok := mr.packages[id]; ok && pkg.DeletedAt == nil {
+		return pkg
+	}
+	return nil
+}
+
+// Snapshot captures the current contents; calling the returned function
+// restores them. InMemoryUnitOfWork uses it to roll back failed units of work.
+func (mr *MemoryPackageRepository) Snapshot() (restore func()) {
+	mr.mu.RLock()
+	packages := make(map[uuid.UUID]*domain.Package, len(mr.packages))
+	for id, pkg := range mr.packages {
+		packages[id] = clonePackage(pkg)
+	}
+	orderIndex := make(map[string]uuid.UUID, len(mr.orderIndex))
+	for ref, id := range mr.orderIndex {
+		orderIndex[ref] = id
+	}
+	mr.mu.RUnlock()
+
+	return func() {
+		mr.mu.Lock()
+		defer mr.mu.Unlock()
+		mr.packages = packages
+		mr.orderIndex = orderIndex
+	}
+}
+
+// clonePackage copies a package so callers never share memory with the store
+func clonePackage(pkg *domain.Package) *domain.Package {
+	out := *pkg
+	out.PickedUpAt = cloneTime(pkg.PickedUpAt)
+	out.HandedOverAt = cloneTime(pkg.HandedOverAt)
+	out.ExpiredAt = cloneTime(pkg.ExpiredAt)
+	out.ReturnPendingAt = cloneTime(pkg.ReturnPendingAt)
+	out.ReturnedAt = cloneTime(pkg.ReturnedAt)
+	out.DisposedAt = cloneTime(pkg.DisposedAt)
+	out.DeletedAt = cloneTime(pkg.DeletedAt)
+	out.ArchivedAt = cloneTime(pkg.ArchivedAt)
+	out.LengthCm = cloneInt(pkg.LengthCm)
+	out.WidthCm = cloneInt(pkg.WidthCm)
+	out.HeightCm = cloneInt(pkg.HeightCm)
+	out.WeightGrams = cloneInt(pkg.WeightGrams)
+	if pkg.DeclaredValue != nil {
+		value := *pkg.DeclaredValue
+		out.DeclaredValue = &value
+	}
+	if pkg.ShipmentID != nil {
+		id := *pkg.ShipmentID
+		out.ShipmentID = &id
+	}
+	return &out
+}
+
+func cloneInt(n *int) *int {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	return &out
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	out := *t
+	return &out
+}
+
+// sortByPriority matches the Postgres ORDER BY priorityRank, created_at DESC
+func sortByPriority(packages []*domain.Package) {
+	sort.SliceStable(packages, func(i, j int) bool {
+		if rank, other := packages[i].Priority.Rank(), packages[j].Priority.Rank(); rank != other {
+			return rank < other
+		}
+		if packages[i].CreatedAt.Equal(packages[j].CreatedAt) {
+			return packages[i].ID.String() < packages[j].ID.String()
+		}
+		return packages[i].CreatedAt.After(packages[j].CreatedAt)
+	})
+}
+
+// page applies LIMIT/OFFSET and returns copies of the selected packages; a
+// limit of zero or less selects everything after offset
+func page(packages []*domain.Package, limit, offset int) []*domain.Package {
+	if offset >= len(packages) {
+		return nil
+	}
+	end := len(packages)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	out := make([]*domain.Package, 0, end-offset)
+	for _, pkg := range packages[offset:end] {
+		out = append(out, clonePackage(pkg))
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryPickupReminderRepository is a thread-safe in-memory domain.PickupReminderRepository
+type MemoryPickupReminderRepository struct {
+	mu        sync.RWMutex
+	reminders map[uuid.UUID]domain.PickupReminder
+}
+
+func NewMemoryPickupReminderRepository() *MemoryPickupReminderRepository {
+	return &MemoryPickupReminderRepository{reminders: make(map[uuid.UUID]domain.PickupReminder)}
+}
+
+func (mr *MemoryPickupReminderRepository) Get(packageID uuid.UUID) (*domain.PickupReminder, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	reminder, ok := mr.reminders[packageID]
+	if !ok {
+		return nil, nil
+	}
+	return &reminder, nil
+}
+
+func (mr *MemoryPickupReminderRepository) Record(packageID uuid.UUID, sentAt time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	reminder := mr.reminders[packageID]
+	reminder.PackageID = packageID
+	reminder.Count++
+	reminder.LastSentAt = sentAt
+	mr.reminders[packageID] = reminder
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryPickupSessionRepository is a thread-safe in-memory domain.PickupSessionRepository
+type MemoryPickupSessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]*domain.PickupSession
+}
+
+func NewMemoryPickupSessionRepository() *MemoryPickupSessionRepository {
+	return &MemoryPickupSessionRepository{sessions: make(map[uuid.UUID]*domain.PickupSession)}
+}
+
+func (mr *MemoryPickupSessionRepository) Create(session *domain.PickupSession) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if session.Status == domain.SessionOpen && mr.openByDriver(session.DriverCode) != nil {
+		return domain.ErrOpenSessionExists
+	}
+	mr.sessions[session.ID] = cloneSession(session)
+	return nil
+}
+
+func (mr *MemoryPickupSessionRepository) GetByID(id uuid.UUID) (*domain.PickupSession, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	session, ok := mr.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneSession(session), nil
+}
+
+func (mr *MemoryPickupSessionRepository) GetOpenByDriver(driverCode string) (*domain.PickupSession, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	if session := mr.openByDriver(driverCode); session != nil {
+		return cloneSession(session), nil
+	}
+	return nil, nil
+}
+
+func (mr *MemoryPickupSessionRepository) ListOpen() ([]*domain.PickupSession, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	open := []*domain.PickupSession{}
+	for _, session := range mr.sessions {
+		if session.Status == domain.SessionOpen {
+			open = append(open, cloneSession(session))
+		}
+	}
+	sort.Slice(open, func(i, j int) bool {
+		if !open[i].OpenedAt.Equal(open[j].OpenedAt) {
+			return open[i].OpenedAt.Before(open[j].OpenedAt)
+		}
+		return open[i].ID.String() < open[j].ID.String()
+	})
+	return open, nil
+}
+
+func (mr *MemoryPickupSessionRepository) RecordScan(sessionID uuid.UUID, item *domain.PickupSessionItem) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	session, ok := mr.sessions[sessionID]
+	if !ok {
+		return nil
+	}
+
+	scanned := cloneSessionItem(item)
+	for i, existing := range session.Items {
+		if existing.OrderRef == item.OrderRef {
+			scanned.Expected = existing.Expected
+			session.Items[i] = scanned
+			return nil
+		}
+	}
+	scanned.Expected = false
+	session.Items = append(session.Items, scanned)
+	return nil
+}
+
+func (mr *MemoryPickupSessionRepository) Close(id uuid.UUID, closedAt time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if session, ok := mr.sessions[id]; ok {
+		session.Status = domain.SessionClosed
+		session.ClosedAt = &closedAt
+	}
+	return nil
+}
+
+func (mr *MemoryPickupSessionRepository) openByDriver(driverCode string) *domain.PickupSession {
+	for _, session := range mr.sessions {
+		if session.DriverCode == driverCode && session.Status == domain.SessionOpen {
+			return session
+		}
+	}
+	return nil
+}
+
+func cloneSession(session *domain.PickupSession) *domain.PickupSession {
+	out := *session
+	out.ClosedAt = cloneTime(session.ClosedAt)
+	out.Items = make([]*domain.PickupSessionItem, 0, len(session.Items))
+	for _, item := range session.Items {
+		out.Items = append(out.Items, cloneSessionItem(item))
+	}
+
+	// Expected items first, then by order reference, like the SQL backends
+	sort.Slice(out.Items, func(i, j int) bool {
+		if out.Items[i].Expected != out.Items[j].Expected {
+			return out.Items[i].Expected
+		}
+		return out.Items[i].OrderRef < out.Items[j].OrderRef
+	})
+	return &out
+}
+
+func cloneSessionItem(item *domain.PickupSessionItem) *domain.PickupSessionItem {
+	out := *item
+	out.ScannedAt = cloneTime(item.ScannedAt)
+	if item.PackageID != nil {
+		id := *item.PackageID
+		out.PackageID = &id
+	}
+	return &out
+}
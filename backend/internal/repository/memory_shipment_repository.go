@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryShipmentRepository is a thread-safe in-memory domain.ShipmentRepository
+type MemoryShipmentRepository struct {
+	mu        sync.RWMutex
+	shipments map[uuid.UUID]domain.Shipment
+}
+
+func NewMemoryShipmentRepository() *MemoryShipmentRepository {
+	return &MemoryShipmentRepository{shipments: make(map[uuid.UUID]domain.Shipment)}
+}
+
+func (mr *MemoryShipmentRepository) Create(shipment *domain.Shipment) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	for id, existing := range mr.shipments {
+		if id == shipment.ID || existing.OrderRef == shipment.OrderRef {
+			return domain.ErrDuplicateOrderRef
+		}
+	}
+	mr.shipments[shipment.ID] = storedShipment(shipment)
+	return nil
+}
+
+func (mr *MemoryShipmentRepository) GetByID(id uuid.UUID) (*domain.Shipment, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	shipment, ok := mr.shipments[id]
+	if !ok {
+		return nil, nil
+	}
+	return &shipment, nil
+}
+
+func (mr *MemoryShipmentRepository) GetByOrderRef(orderRef string) (*domain.Shipment, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	for _, shipment := range mr.shipments {
+		if shipment.OrderRef == orderRef {
+			out := shipment
+			return &out, nil
+		}
+	}
+	return nil, nil
+}
+
+func (mr *MemoryShipmentRepository) GetAll(filter domain.ShipmentFilter) ([]*domain.Shipment, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	matched := []*domain.Shipment{}
+	for _, shipment := range mr.shipments {
+		if filter.DriverCode != "" && shipment.DriverCode != filter.DriverCode {
+			continue
+		}
+		out := shipment
+		matched = append(matched, &out)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].ID.String() < matched[j].ID.String()
+		}
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	if filter.Offset >= len(matched) {
+		return []*domain.Shipment{}, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, nil
+}
+
+// Snapshot captures the current shipments; calling the returned function restores them
+func (mr *MemoryShipmentRepository) Snapshot() (restore func()) {
+	mr.mu.RLock()
+	shipments := make(map[uuid.UUID]domain.Shipment, len(mr.shipments))
+	for id, shipment := range mr.shipments {
+		shipments[id] = shipment
+	}
+	mr.mu.RUnlock()
+
+	return func() {
+		mr.mu.Lock()
+		defer mr.mu.Unlock()
+		mr.shipments = shipments
+	}
+}
+
+// storedShipment keeps only the stored fields; status and parcels are derived
+func storedShipment(shipment *domain.Shipment) domain.Shipment {
+	out := *shipment
+	out.Status = ""
+	out.Parcels = nil
+	return out
+}
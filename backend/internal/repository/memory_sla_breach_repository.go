@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemorySLABreachRepository is a thread-safe in-memory domain.SLABreachRepository
+type MemorySLABreachRepository struct {
+	mu       sync.RWMutex
+	breaches map[uuid.UUID]domain.SLABreach
+}
+
+func NewMemorySLABreachRepository() *MemorySLABreachRepository {
+	return &MemorySLABreachRepository{breaches: make(map[uuid.UUID]domain.SLABreach)}
+}
+
+func (mr *MemorySLABreachRepository) Record(breach *domain.SLABreach) (bool, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.breaches[breach.PackageID]; ok {
+		return false, nil
+	}
+	mr.breaches[breach.PackageID] = *breach
+	return true, nil
+}
+
+func (mr *MemorySLABreachRepository) List(filter domain.SLABreachFilter) ([]*domain.SLABreach, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	matched := []*domain.SLABreach{}
+	for _, breach := range mr.breaches {
+		if breach.DetectedAt.Before(filter.From) || !breach.DetectedAt.Before(filter.To) ||
+			(filter.Priority != "" && breach.Priority != filter.Priority) {
+			continue
+		}
+		out := breach
+		matched = append(matched, &out)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].DetectedAt.Equal(matched[j].DetectedAt) {
+			return matched[i].DetectedAt.After(matched[j].DetectedAt)
+		}
+		return matched[i].PackageID.String() < matched[j].PackageID.String()
+	})
+	return matched, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"pickup-queue/internal/domain"
+	"sync"
+)
+
+// snapshotter is implemented by in-memory repositories that can undo writes
+type snapshotter interface {
+	Snapshot() (restore func())
+}
+
+// InMemoryUnitOfWork runs units of work one at a time against plain
+// repositories. It is meant for tests and demo mode: writes are rolled back
+// only when the repository can take snapshots.
+type InMemoryUnitOfWork struct {
+	mu             sync.Mutex
+	packageRepo    domain.PackageRepository
+	assignmentRepo domain.DriverAssignmentRepository
+	shipmentRepo   domain.ShipmentRepository
+
+	Commits   int
+	Rollbacks int
+}
+
+// NewInMemoryUnitOfWork creates a unit of work over packageRepo. Driver
+// reassignment history and shipments go to private repositories unless
+// WithDriverAssignments and WithShipments supply shared ones.
+func NewInMemoryUnitOfWork(packageRepo domain.PackageRepository) *InMemoryUnitOfWork {
+	return &InMemoryUnitOfWork{
+		packageRepo:    packageRepo,
+		assignmentRepo: NewMemoryDriverAssignmentRepository(),
+		shipmentRepo:   NewMemoryShipmentRepository(),
+	}
+}
+
+// WithDriverAssignments makes units of work record reassignment history in repo
+func (u *InMemoryUnitOfWork) WithDriverAssignments(repo domain.DriverAssignmentRepository) *InMemoryUnitOfWork {
+	u.assignmentRepo = repo
+	return u
+}
+
+// WithShipments makes units of work create shipments in repo
+func (u *InMemoryUnitOfWork) WithShipments(repo domain.ShipmentRepository) *InMemoryUnitOfWork {
+	u.shipmentRepo = repo
+	return u
+}
+
+func (u *InMemoryUnitOfWork) Do(opts domain.TxOptions, fn func(tx domain.Tx) error) (err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var restores []func()
+	for _, repo := range []interface{}{u.packageRepo, u.assignmentRepo, u.shipmentRepo} {
+		if s, ok := repo.(snapshotter); ok {
+			restores = append(restores, s.Snapshot())
+		}
+	}
+	restore := func() {
+		for _, fn := range restores {
+			fn()
+		}
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			restore()
+			u.Rollbacks++
+			panic(p)
+		}
+	}()
+
+	if err := fn(memoryTx{packages: u.packageRepo, assignments: u.assignmentRepo, shipments: u.shipmentRepo}); err != nil {
+		restore()
+		u.Rollbacks++
+		return err
+	}
+
+	u.Commits++
+	return nil
+}
+
+type memoryTx struct {
+	packages    domain.PackageRepository
+	assignments domain.DriverAssignmentRepository
+	shipments   domain.ShipmentRepository
+}
+
+func (t memoryTx) Packages() domain.PackageRepository {
+	return t.packages
+}
+
+func (t memoryTx) DriverAssignments() domain.DriverAssignmentRepository {
+	return t.assignments
+}
+
+func (t memoryTx) Shipments() domain.ShipmentRepository {
+	return t.shipments
+}
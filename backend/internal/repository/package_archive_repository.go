@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// archiveColumns are the package columns copied to and from packages_archive
+const archiveColumns = packageColumns
+
+type PackageArchiveRepository struct {
+	db    dbtx
+	retry database.RetryPolicy
+}
+
+func NewPackageArchiveRepository(db *sql.DB) domain.PackageArchiveRepository {
+	return &PackageArchiveRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+// ArchiveBefore moves one batch in a single statement, so a package is never
+// in both tables or in neither. Rows locked by a concurrent writer are left
+// for the next run.
+func (ar *PackageArchiveRepository) ArchiveBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		WITH moved AS (
+			DELETE FROM packages
+			WHERE id IN (
+				SELECT id FROM packages
+				WHERE status = ANY($1) AND updated_at < $2 AND deleted_at IS NULL
+				ORDER BY updated_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + archiveColumns + `
+		)
+		INSERT INTO packages_archive (` + archiveColumns + `, archived_at)
+		SELECT ` + archiveColumns + `, $4 FROM moved`
+	args := []interface{}{pq.Array(archivableStatuses()), cutoff, limit, time.Now()}
+
+	startTime := time.Now()
+	var moved int64
+	err := ar.retry.Do(func() error {
+		result, err := ar.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		moved, err = result.RowsAffected()
+		return err
+	})
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return 0, err
+	}
+	database.LogQuery(query, args, startTime)
+	return moved, nil
+}
+
+func (ar *PackageArchiveRepository) GetByID(id uuid.UUID) (*domain.Package, error) {
+	query := `SELECT ` + archiveColumns + `, archived_at FROM packages_archive WHERE id = $1`
+	return ar.getOne(query, id)
+}
+
+func (ar *PackageArchiveRepository) GetByOrderRef(orderRef string) (*domain.Package, error) {
+	query := `SELECT ` + archiveColumns + `, archived_at FROM packages_archive WHERE order_ref = $1`
+	return ar.getOne(query, orderRef)
+}
+
+// Rehydrate returns domain.ErrDuplicateOrderRef if a live package has taken
+// the order reference in the meantime
+func (ar *PackageArchiveRepository) Rehydrate(id uuid.UUID) error {
+	query := `
+		WITH moved AS (
+			DELETE FROM packages_archive WHERE id = $1
+			RETURNING ` + archiveColumns + `
+		)
+		INSERT INTO packages (` + archiveColumns + `)
+		SELECT ` + archiveColumns + ` FROM moved`
+	args := []interface{}{id}
+
+	startTime := time.Now()
+	err := ar.retry.Do(func() error {
+		_, err := ar.db.Exec(query, args...)
+		return err
+	})
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateOrderRef
+		}
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (ar *PackageArchiveRepository) getOne(query string, args ...interface{}) (*domain.Package, error) {
+	var pkg *domain.Package
+	var archivedAt time.Time
+
+	startTime := time.Now()
+	err := ar.retry.Do(func() (err error) {
+		pkg, err = scanPackage(archivedRow{ar.db.QueryRow(query, args...), &archivedAt})
+		return err
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	pkg.ArchivedAt = &archivedAt
+	return pkg, nil
+}
+
+func archivableStatuses() []string {
+	statuses := make([]string, 0, len(domain.ArchivableStatuses))
+	for _, status := range domain.ArchivableStatuses {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}
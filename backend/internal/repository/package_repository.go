@@ -1,27 +1,45 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"pickup-queue/internal/domain"
 	"pickup-queue/pkg/database"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// packageColumns is the column list every package read selects, in scanPackage order
+const packageColumns = `id, order_ref, driver_code, status, created_at, updated_at,
+		       picked_up_at, handed_over_at, expired_at, recipient_phone, carrier,
+		       length_cm, width_cm, height_cm, weight_grams, size_class,
+		       declared_value, fragile, temperature, parcel_count, shipment_id, parcel_number,
+		       slot_location, priority, return_pending_at, returned_at, disposed_at`
+
+// priorityRank sorts packages most urgent first, as domain.Priority.Rank does
+const priorityRank = `CASE priority WHEN 'EXPRESS' THEN 0 WHEN 'ECONOMY' THEN 2 ELSE 1 END`
+
 type PackageRepository struct {
-	db *sql.DB
+	db    dbtx
+	retry database.RetryPolicy
 }
 
 func NewPackageRepository(db *sql.DB) domain.PackageRepository {
-	return &PackageRepository{db: db}
+	return &PackageRepository{db: db, retry: database.DefaultRetryPolicy}
 }
 
 func (pr *PackageRepository) Create(pkg *domain.Package) error {
 	query := `
-		INSERT INTO packages (id, order_ref, driver_code, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO packages (id, order_ref, driver_code, status, created_at, updated_at, recipient_phone, carrier,
+		                      length_cm, width_cm, height_cm, weight_grams, size_class,
+		                      declared_value, fragile, temperature, parcel_count, shipment_id, parcel_number,
+		                      slot_location, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)`
 
 	args := []interface{}{
 		pkg.ID,
@@ -30,13 +48,34 @@ func (pr *PackageRepository) Create(pkg *domain.Package) error {
 		pkg.Status,
 		pkg.CreatedAt,
 		pkg.UpdatedAt,
+		nullString(pkg.RecipientPhone),
+		nullString(pkg.Carrier),
+		nullInt(pkg.LengthCm),
+		nullInt(pkg.WidthCm),
+		nullInt(pkg.HeightCm),
+		nullInt(pkg.WeightGrams),
+		pkg.SizeClass,
+		nullInt64(pkg.DeclaredValue),
+		pkg.Fragile,
+		pkg.Temperature,
+		pkg.ParcelCount,
+		nullUUID(pkg.ShipmentID),
+		nullPositive(pkg.ParcelNumber),
+		nullString(pkg.SlotLocation),
+		pkg.Priority,
 	}
 
 	startTime := time.Now()
-	_, err := pr.db.Exec(query, args...)
+	err := pr.retry.DoWrite(func() error {
+		_, err := pr.db.Exec(query, args...)
+		return err
+	})
 
 	if err != nil {
 		database.LogQueryError(query, args, err, startTime)
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateOrderRef
+		}
 	} else {
 		database.LogQuery(query, args, startTime)
 	}
@@ -45,181 +84,85 @@ func (pr *PackageRepository) Create(pkg *domain.Package) error {
 }
 
 func (pr *PackageRepository) GetByID(id uuid.UUID) (*domain.Package, error) {
-	query := `
-		SELECT id, order_ref, driver_code, status, created_at, updated_at, 
-		       picked_up_at, handed_over_at, expired_at
-		FROM packages 
-		WHERE id = $1`
-
-	args := []interface{}{id}
-	startTime := time.Now()
-
-	var pkg domain.Package
-	var pickedUpAt, handedOverAt, expiredAt sql.NullTime
-
-	err := pr.db.QueryRow(query, id).Scan(
-		&pkg.ID,
-		&pkg.OrderRef,
-		&pkg.DriverCode,
-		&pkg.Status,
-		&pkg.CreatedAt,
-		&pkg.UpdatedAt,
-		&pickedUpAt,
-		&handedOverAt,
-		&expiredAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			database.LogQuery(query, args, startTime)
-			return nil, nil
-		}
-		database.LogQueryError(query, args, err, startTime)
-		return nil, err
-	}
-
-	database.LogQuery(query, args, startTime)
-
-	// Handle nullable time fields
-	if pickedUpAt.Valid {
-		pkg.PickedUpAt = &pickedUpAt.Time
-	}
-	if handedOverAt.Valid {
-		pkg.HandedOverAt = &handedOverAt.Time
-	}
-	if expiredAt.Valid {
-		pkg.ExpiredAt = &expiredAt.Time
-	}
-
-	return &pkg, nil
+	query := `SELECT ` + packageColumns + ` FROM packages WHERE id = $1 AND deleted_at IS NULL`
+	return pr.getOne(query, id)
 }
 
 func (pr *PackageRepository) GetByOrderRef(orderRef string) (*domain.Package, error) {
-	query := `
-		SELECT id, order_ref, driver_code, status, created_at, updated_at, 
-		       picked_up_at, handed_over_at, expired_at
-		FROM packages 
-		WHERE order_ref = $1`
-
-	args := []interface{}{orderRef}
-	startTime := time.Now()
+	query := `SELECT ` + packageColumns + ` FROM packages WHERE order_ref = $1 AND deleted_at IS NULL`
+	return pr.getOne(query, orderRef)
+}
 
-	var pkg domain.Package
-	var pickedUpAt, handedOverAt, expiredAt sql.NullTime
+func (pr *PackageRepository) GetAll(filter domain.PackageFilter) ([]*domain.Package, error) {
+	baseQuery := `SELECT ` + packageColumns + ` FROM packages`
 
-	err := pr.db.QueryRow(query, orderRef).Scan(
-		&pkg.ID,
-		&pkg.OrderRef,
-		&pkg.DriverCode,
-		&pkg.Status,
-		&pkg.CreatedAt,
-		&pkg.UpdatedAt,
-		&pickedUpAt,
-		&handedOverAt,
-		&expiredAt,
-	)
+	var args []interface{}
+	conditions := []string{"deleted_at IS NULL"}
+	argIndex := 1
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			database.LogQuery(query, args, startTime)
-			return nil, nil
-		}
-		database.LogQueryError(query, args, err, startTime)
-		return nil, err
+	if filter.Status != nil {
+		conditions = append(conditions, "status = $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, *filter.Status)
+		argIndex++
 	}
-
-	database.LogQuery(query, args, startTime)
-
-	// Handle nullable time fields
-	if pickedUpAt.Valid {
-		pkg.PickedUpAt = &pickedUpAt.Time
+	if filter.DriverCode != "" {
+		conditions = append(conditions, "driver_code = $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, filter.DriverCode)
+		argIndex++
 	}
-	if handedOverAt.Valid {
-		pkg.HandedOverAt = &handedOverAt.Time
+	if filter.SizeClass != "" {
+		conditions = append(conditions, "size_class = $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, filter.SizeClass)
+		argIndex++
 	}
-	if expiredAt.Valid {
-		pkg.ExpiredAt = &expiredAt.Time
+	if filter.Fragile != nil {
+		conditions = append(conditions, "fragile = $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, *filter.Fragile)
+		argIndex++
 	}
-
-	return &pkg, nil
-}
-
-func (pr *PackageRepository) GetAll(limit, offset int, status *domain.PackageStatus) ([]*domain.Package, error) {
-	baseQuery := `
-		SELECT id, order_ref, driver_code, status, created_at, updated_at, 
-		       picked_up_at, handed_over_at, expired_at
-		FROM packages`
-
-	var args []interface{}
-	var whereClause string
-	argIndex := 1
-
-	if status != nil {
-		whereClause = " WHERE status = $" + fmt.Sprintf("%d", argIndex)
-		args = append(args, *status)
+	if filter.Temperature != "" {
+		conditions = append(conditions, "temperature = $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, filter.Temperature)
 		argIndex++
 	}
-
-	query := baseQuery + whereClause +
-		" ORDER BY created_at DESC" +
-		" LIMIT $" + fmt.Sprintf("%d", argIndex) +
-		" OFFSET $" + fmt.Sprintf("%d", argIndex+1)
-
-	args = append(args, limit, offset)
-
-	startTime := time.Now()
-	rows, err := pr.db.Query(query, args...)
-	if err != nil {
-		database.LogQueryError(query, args, err, startTime)
-		return nil, err
+	if filter.MinDeclaredValue != nil {
+		conditions = append(conditions, "declared_value >= $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, *filter.MinDeclaredValue)
+		argIndex++
+	}
+	if filter.ShipmentID != nil {
+		conditions = append(conditions, "shipment_id = $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, *filter.ShipmentID)
+		argIndex++
+	}
+	if filter.Priority != "" {
+		conditions = append(conditions, "priority = $"+fmt.Sprintf("%d", argIndex))
+		args = append(args, filter.Priority)
+		argIndex++
 	}
-	defer rows.Close()
-
-	database.LogQuery(query, args, startTime)
-
-	var packages []*domain.Package
-	for rows.Next() {
-		var pkg domain.Package
-		var pickedUpAt, handedOverAt, expiredAt sql.NullTime
-
-		err := rows.Scan(
-			&pkg.ID,
-			&pkg.OrderRef,
-			&pkg.DriverCode,
-			&pkg.Status,
-			&pkg.CreatedAt,
-			&pkg.UpdatedAt,
-			&pickedUpAt,
-			&handedOverAt,
-			&expiredAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		// Handle nullable time fields
-		if pickedUpAt.Valid {
-			pkg.PickedUpAt = &pickedUpAt.Time
-		}
-		if handedOverAt.Valid {
-			pkg.HandedOverAt = &handedOverAt.Time
-		}
-		if expiredAt.Valid {
-			pkg.ExpiredAt = &expiredAt.Time
-		}
 
-		packages = append(packages, &pkg)
+	query := baseQuery + " WHERE " + strings.Join(conditions, " AND ") + " ORDER BY " + priorityRank + ", created_at DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT $" + fmt.Sprintf("%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
 	}
+	query += " OFFSET $" + fmt.Sprintf("%d", argIndex)
+	args = append(args, filter.Offset)
 
-	return packages, rows.Err()
+	return pr.getMany(query, args...)
 }
 
 func (pr *PackageRepository) Update(pkg *domain.Package) error {
 	query := `
 		UPDATE packages 
 		SET order_ref = $2, driver_code = $3, status = $4, updated_at = $5,
-		    picked_up_at = $6, handed_over_at = $7, expired_at = $8
-		WHERE id = $1`
+		    picked_up_at = $6, handed_over_at = $7, expired_at = $8, recipient_phone = $9,
+		    carrier = $10, length_cm = $11, width_cm = $12, height_cm = $13, weight_grams = $14,
+		    size_class = $15, declared_value = $16, fragile = $17, temperature = $18, parcel_count = $19,
+		    shipment_id = $20, parcel_number = $21, slot_location = $22, priority = $23,
+		    return_pending_at = $24, returned_at = $25, disposed_at = $26
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	args := []interface{}{
 		pkg.ID,
@@ -230,10 +173,31 @@ func (pr *PackageRepository) Update(pkg *domain.Package) error {
 		pkg.PickedUpAt,
 		pkg.HandedOverAt,
 		pkg.ExpiredAt,
+		nullString(pkg.RecipientPhone),
+		nullString(pkg.Carrier),
+		nullInt(pkg.LengthCm),
+		nullInt(pkg.WidthCm),
+		nullInt(pkg.HeightCm),
+		nullInt(pkg.WeightGrams),
+		pkg.SizeClass,
+		nullInt64(pkg.DeclaredValue),
+		pkg.Fragile,
+		pkg.Temperature,
+		pkg.ParcelCount,
+		nullUUID(pkg.ShipmentID),
+		nullPositive(pkg.ParcelNumber),
+		nullString(pkg.SlotLocation),
+		pkg.Priority,
+		pkg.ReturnPendingAt,
+		pkg.ReturnedAt,
+		pkg.DisposedAt,
 	}
 
 	startTime := time.Now()
-	_, err := pr.db.Exec(query, args...)
+	err := pr.retry.Do(func() error {
+		_, err := pr.db.Exec(query, args...)
+		return err
+	})
 
 	if err != nil {
 		database.LogQueryError(query, args, err, startTime)
@@ -245,11 +209,48 @@ func (pr *PackageRepository) Update(pkg *domain.Package) error {
 }
 
 func (pr *PackageRepository) Delete(id uuid.UUID) error {
-	query := `DELETE FROM packages WHERE id = $1`
-	args := []interface{}{id}
+	query := `UPDATE packages SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	return pr.exec(query, id, time.Now())
+}
+
+func (pr *PackageRepository) Restore(id uuid.UUID) error {
+	query := `UPDATE packages SET deleted_at = NULL, updated_at = $2 WHERE id = $1 AND deleted_at IS NOT NULL`
+	return pr.exec(query, id, time.Now())
+}
+
+// PurgeDeleted hard-deletes packages soft-deleted before the cutoff together
+// with their driver assignment history
+func (pr *PackageRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `
+		WITH purged AS (
+			DELETE FROM packages WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			RETURNING id
+		), history AS (
+			DELETE FROM driver_assignments WHERE package_id IN (SELECT id FROM purged)
+		)
+		SELECT COUNT(*) FROM purged`
+	args := []interface{}{cutoff}
+
+	startTime := time.Now()
+	var purged int64
+	err := pr.retry.Do(func() error {
+		return pr.db.QueryRowContext(ctx, query, args...).Scan(&purged)
+	})
 
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return 0, err
+	}
+	database.LogQuery(query, args, startTime)
+	return purged, nil
+}
+
+func (pr *PackageRepository) exec(query string, args ...interface{}) error {
 	startTime := time.Now()
-	_, err := pr.db.Exec(query, id)
+	err := pr.retry.Do(func() error {
+		_, err := pr.db.Exec(query, args...)
+		return err
+	})
 
 	if err != nil {
 		database.LogQueryError(query, args, err, startTime)
@@ -260,20 +261,53 @@ func (pr *PackageRepository) Delete(id uuid.UUID) error {
 	return err
 }
 
-func (pr *PackageRepository) GetExpiredPackages() ([]*domain.Package, error) {
-	// Packages that have been waiting for more than 24 hours are considered expired
-	cutoffTime := time.Now().Add(-24 * time.Hour)
+// GetExpiredPackages returns active packages created before the cutoff time
+func (pr *PackageRepository) GetExpiredPackages(ctx context.Context, cutoffTime time.Time) ([]*domain.Package, error) {
+	query := `SELECT ` + packageColumns + ` FROM packages
+		WHERE status IN ($1, $2) AND created_at < $3 AND deleted_at IS NULL`
+	return pr.getManyContext(ctx, query, domain.StatusWaiting, domain.StatusPicked, cutoffTime)
+}
 
-	query := `
-		SELECT id, order_ref, driver_code, status, created_at, updated_at, 
-		       picked_up_at, handed_over_at, expired_at
-		FROM packages 
-		WHERE status IN ($1, $2) AND created_at < $3`
+// GetExpiredBefore returns EXPIRED packages that expired before the cutoff
+// time; idx_packages_expired_at covers the query
+func (pr *PackageRepository) GetExpiredBefore(ctx context.Context, cutoffTime time.Time) ([]*domain.Package, error) {
+	query := `SELECT ` + packageColumns + ` FROM packages
+		WHERE status = $1 AND expired_at < $2 AND deleted_at IS NULL`
+	return pr.getManyContext(ctx, query, domain.StatusExpired, cutoffTime)
+}
 
-	args := []interface{}{domain.StatusWaiting, domain.StatusPicked, cutoffTime}
+func (pr *PackageRepository) getOne(query string, args ...interface{}) (*domain.Package, error) {
 	startTime := time.Now()
+	var pkg *domain.Package
+	err := pr.retry.Do(func() (err error) {
+		pkg, err = scanPackage(pr.db.QueryRow(query, args...))
+		return err
+	})
 
-	rows, err := pr.db.Query(query, domain.StatusWaiting, domain.StatusPicked, cutoffTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+
+	database.LogQuery(query, args, startTime)
+	return pkg, nil
+}
+
+func (pr *PackageRepository) getMany(query string, args ...interface{}) ([]*domain.Package, error) {
+	return pr.getManyContext(context.Background(), query, args...)
+}
+
+func (pr *PackageRepository) getManyContext(ctx context.Context, query string, args ...interface{}) ([]*domain.Package, error) {
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := pr.retry.Do(func() (err error) {
+		rows, err = pr.db.QueryContext(ctx, query, args...)
+		return err
+	})
 	if err != nil {
 		database.LogQueryError(query, args, err, startTime)
 		return nil, err
@@ -284,39 +318,139 @@ func (pr *PackageRepository) GetExpiredPackages() ([]*domain.Package, error) {
 
 	var packages []*domain.Package
 	for rows.Next() {
-		var pkg domain.Package
-		var pickedUpAt, handedOverAt, expiredAt sql.NullTime
-
-		err := rows.Scan(
-			&pkg.ID,
-			&pkg.OrderRef,
-			&pkg.DriverCode,
-			&pkg.Status,
-			&pkg.CreatedAt,
-			&pkg.UpdatedAt,
-			&pickedUpAt,
-			&handedOverAt,
-			&expiredAt,
-		)
+		pkg, err := scanPackage(rows)
 		if err != nil {
 			return nil, err
 		}
+		packages = append(packages, pkg)
+	}
 
-		// Handle nullable time fields
-		if pickedUpAt.Valid {
-			pkg.PickedUpAt = &pickedUpAt.Time
-		}
-		if handedOverAt.Valid {
-			pkg.HandedOverAt = &handedOverAt.Time
-		}
-		if expiredAt.Valid {
-			pkg.ExpiredAt = &expiredAt.Time
+	return packages, rows.Err()
+}
+
+// scanPackage reads one row selected with packageColumns
+func scanPackage(row rowScanner) (*domain.Package, error) {
+	var pkg domain.Package
+	var pickedUpAt, handedOverAt, expiredAt, returnPendingAt, returnedAt, disposedAt sql.NullTime
+	var recipientPhone, carrier, slotLocation sql.NullString
+	var lengthCm, widthCm, heightCm, weightGrams, declaredValue, parcelNumber sql.NullInt64
+	var shipmentID uuid.NullUUID
+
+	err := row.Scan(
+		&pkg.ID,
+		&pkg.OrderRef,
+		&pkg.DriverCode,
+		&pkg.Status,
+		&pkg.CreatedAt,
+		&pkg.UpdatedAt,
+		&pickedUpAt,
+		&handedOverAt,
+		&expiredAt,
+		&recipientPhone,
+		&carrier,
+		&lengthCm,
+		&widthCm,
+		&heightCm,
+		&weightGrams,
+		&pkg.SizeClass,
+		&declaredValue,
+		&pkg.Fragile,
+		&pkg.Temperature,
+		&pkg.ParcelCount,
+		&shipmentID,
+		&parcelNumber,
+		&slotLocation,
+		&pkg.Priority,
+		&returnPendingAt,
+		&returnedAt,
+		&disposedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	scanAttributes(&pkg, lengthCm, widthCm, heightCm, weightGrams, declaredValue)
+	if shipmentID.Valid {
+		pkg.ShipmentID = &shipmentID.UUID
+	}
+	pkg.ParcelNumber = int(parcelNumber.Int64)
+
+	// Handle nullable fields
+	if pickedUpAt.Valid {
+		pkg.PickedUpAt = &pickedUpAt.Time
+	}
+	if handedOverAt.Valid {
+		pkg.HandedOverAt = &handedOverAt.Time
+	}
+	if expiredAt.Valid {
+		pkg.ExpiredAt = &expiredAt.Time
+	}
+	if returnPendingAt.Valid {
+		pkg.ReturnPendingAt = &returnPendingAt.Time
+	}
+	if returnedAt.Valid {
+		pkg.ReturnedAt = &returnedAt.Time
+	}
+	if disposedAt.Valid {
+		pkg.DisposedAt = &disposedAt.Time
+	}
+	pkg.RecipientPhone = recipientPhone.String
+	pkg.Carrier = carrier.String
+	pkg.SlotLocation = slotLocation.String
+
+	return &pkg, nil
+}
+
+// scanAttributes copies the nullable physical attributes into pkg
+func scanAttributes(pkg *domain.Package, lengthCm, widthCm, heightCm, weightGrams, declaredValue sql.NullInt64) {
+	for _, field := range []struct {
+		src sql.NullInt64
+		dst **int
+	}{
+		{lengthCm, &pkg.LengthCm},
+		{widthCm, &pkg.WidthCm},
+		{heightCm, &pkg.HeightCm},
+		{weightGrams, &pkg.WeightGrams},
+	} {
+		if field.src.Valid {
+			v := int(field.src.Int64)
+			*field.dst = &v
 		}
+	}
+	if declaredValue.Valid {
+		pkg.DeclaredValue = &declaredValue.Int64
+	}
+}
+
+// nullString stores an empty optional text field as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
 
-		packages = append(packages, &pkg)
+// nullInt stores an unset optional number as NULL
+func nullInt(n *int) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
 	}
+	return sql.NullInt64{Int64: int64(*n), Valid: true}
+}
 
-	return packages, rows.Err()
+// nullPositive stores an unset (zero) counter as NULL
+func nullPositive(n int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n), Valid: n > 0}
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func nullInt64(n *int64) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *n, Valid: true}
 }
 
 func (pr *PackageRepository) UpdateStatus(id uuid.UUID, status domain.PackageStatus) error {
@@ -327,21 +461,33 @@ func (pr *PackageRepository) UpdateStatus(id uuid.UUID, status domain.PackageSta
 
 	switch status {
 	case domain.StatusPicked:
-		query = `UPDATE packages SET status = $2, updated_at = $3, picked_up_at = $3 WHERE id = $1`
+		query = `UPDATE packages SET status = $2, updated_at = $3, picked_up_at = $3 WHERE id = $1 AND deleted_at IS NULL`
 		args = []interface{}{id, status, now}
 	case domain.StatusHandedOver:
-		query = `UPDATE packages SET status = $2, updated_at = $3, handed_over_at = $3 WHERE id = $1`
+		query = `UPDATE packages SET status = $2, updated_at = $3, handed_over_at = $3 WHERE id = $1 AND deleted_at IS NULL`
 		args = []interface{}{id, status, now}
 	case domain.StatusExpired:
-		query = `UPDATE packages SET status = $2, updated_at = $3, expired_at = $3 WHERE id = $1`
+		query = `UPDATE packages SET status = $2, updated_at = $3, expired_at = $3 WHERE id = $1 AND deleted_at IS NULL`
+		args = []interface{}{id, status, now}
+	case domain.StatusReturnPending:
+		query = `UPDATE packages SET status = $2, updated_at = $3, return_pending_at = $3 WHERE id = $1 AND deleted_at IS NULL`
+		args = []interface{}{id, status, now}
+	case domain.StatusReturned:
+		query = `UPDATE packages SET status = $2, updated_at = $3, returned_at = $3 WHERE id = $1 AND deleted_at IS NULL`
+		args = []interface{}{id, status, now}
+	case domain.StatusDisposed:
+		query = `UPDATE packages SET status = $2, updated_at = $3, disposed_at = $3 WHERE id = $1 AND deleted_at IS NULL`
 		args = []interface{}{id, status, now}
 	default:
-		query = `UPDATE packages SET status = $2, updated_at = $3 WHERE id = $1`
+		query = `UPDATE packages SET status = $2, updated_at = $3 WHERE id = $1 AND deleted_at IS NULL`
 		args = []interface{}{id, status, now}
 	}
 
 	startTime := time.Now()
-	_, err := pr.db.Exec(query, args...)
+	err := pr.retry.Do(func() error {
+		_, err := pr.db.Exec(query, args...)
+		return err
+	})
 
 	if err != nil {
 		database.LogQueryError(query, args, err, startTime)
@@ -356,7 +502,7 @@ func (pr *PackageRepository) GetPackageStats() (*domain.PackageStats, error) {
 	var stats domain.PackageStats
 
 	// Get total count
-	query1 := "SELECT COUNT(*) FROM packages"
+	query1 := "SELECT COUNT(*) FROM packages WHERE deleted_at IS NULL"
 	args1 := []interface{}{}
 	startTime1 := time.Now()
 	err := pr.db.QueryRow(query1).Scan(&stats.Total)
@@ -367,7 +513,7 @@ func (pr *PackageRepository) GetPackageStats() (*domain.PackageStats, error) {
 	database.LogQuery(query1, args1, startTime1)
 
 	// Get counts by status
-	query2 := "SELECT COUNT(*) FROM packages WHERE status = $1"
+	query2 := "SELECT COUNT(*) FROM packages WHERE status = $1 AND deleted_at IS NULL"
 	args2 := []interface{}{domain.StatusWaiting}
 	startTime2 := time.Now()
 	err = pr.db.QueryRow(query2, domain.StatusWaiting).Scan(&stats.Waiting)
@@ -404,5 +550,132 @@ func (pr *PackageRepository) GetPackageStats() (*domain.PackageStats, error) {
 	}
 	database.LogQuery(query2, args5, startTime5)
 
+	for _, count := range []struct {
+		status domain.PackageStatus
+		dst    *int64
+	}{
+		{domain.StatusReturnPending, &stats.ReturnPending},
+		{domain.StatusReturned, &stats.Returned},
+		{domain.StatusDisposed, &stats.Disposed},
+	} {
+		args := []interface{}{count.status}
+		startTime := time.Now()
+		if err := pr.db.QueryRow(query2, args...).Scan(count.dst); err != nil {
+			database.LogQueryError(query2, args, err, startTime)
+			return nil, err
+		}
+		database.LogQuery(query2, args, startTime)
+	}
+
+	query3 := "SELECT size_class, COUNT(*) FROM packages WHERE deleted_at IS NULL GROUP BY size_class"
+	stats.BySizeClass, err = countBySizeClass(pr.db, query3)
+	if err != nil {
+		return nil, err
+	}
+
 	return &stats, nil
 }
+
+func (pr *PackageRepository) WaitingPositions(ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	query := `
+		SELECT id, position FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY ` + priorityRank + `, created_at, id) AS position
+			FROM packages
+			WHERE status = $1 AND deleted_at IS NULL
+		) queue
+		WHERE id = ANY($2::uuid[])`
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
+	}
+	args := []interface{}{domain.StatusWaiting, pq.Array(keys)}
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := pr.retry.Do(func() (err error) {
+		rows, err = pr.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	positions := make(map[uuid.UUID]int, len(ids))
+	for rows.Next() {
+		var id uuid.UUID
+		var position int
+		if err := rows.Scan(&id, &position); err != nil {
+			return nil, err
+		}
+		positions[id] = position
+	}
+	return positions, rows.Err()
+}
+
+func (pr *PackageRepository) RecentPickupTimes(limit int) ([]time.Time, error) {
+	query := `
+		SELECT picked_up_at FROM packages
+		WHERE picked_up_at IS NOT NULL AND deleted_at IS NULL
+		ORDER BY picked_up_at DESC
+		LIMIT $1`
+	args := []interface{}{limit}
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := pr.retry.Do(func() (err error) {
+		rows, err = pr.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	times := []time.Time{}
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	return times, rows.Err()
+}
+
+// countBySizeClass runs a size_class, COUNT(*) query, reporting every size
+// class even when no package has it
+func countBySizeClass(db dbtx, query string, args ...interface{}) (map[domain.SizeClass]int64, error) {
+	counts := make(map[domain.SizeClass]int64, len(domain.SizeClasses))
+	for _, class := range domain.SizeClasses {
+		counts[class] = 0
+	}
+
+	startTime := time.Now()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	for rows.Next() {
+		var class domain.SizeClass
+		var count int64
+		if err := rows.Scan(&class, &count); err != nil {
+			return nil, err
+		}
+		counts[class] = count
+	}
+	return counts, rows.Err()
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
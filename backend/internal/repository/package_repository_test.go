@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,7 +35,8 @@ func TestPackageRepository_Create_HappyPath(t *testing.T) {
 
 	// Mock expectations
 	mock.ExpectExec("INSERT INTO packages").
-		WithArgs(pkg.ID, pkg.OrderRef, pkg.DriverCode, pkg.Status, pkg.CreatedAt, pkg.UpdatedAt).
+		WithArgs(pkg.ID, pkg.OrderRef, pkg.DriverCode, pkg.Status, pkg.CreatedAt, pkg.UpdatedAt, nil, nil,
+			nil, nil, nil, nil, "", nil, false, "", 0, nil, nil, nil, domain.Priority("")).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Execute
@@ -63,7 +66,8 @@ func TestPackageRepository_Create_EdgeCase_DatabaseError(t *testing.T) {
 
 	// Mock expectations - simulate database error
 	mock.ExpectExec("INSERT INTO packages").
-		WithArgs(pkg.ID, pkg.OrderRef, pkg.DriverCode, pkg.Status, pkg.CreatedAt, pkg.UpdatedAt).
+		WithArgs(pkg.ID, pkg.OrderRef, pkg.DriverCode, pkg.Status, pkg.CreatedAt, pkg.UpdatedAt, nil, nil,
+			nil, nil, nil, nil, "", nil, false, "", 0, nil, nil, nil, domain.Priority("")).
 		WillReturnError(sql.ErrConnDone)
 
 	// Execute
@@ -89,10 +93,15 @@ func TestPackageRepository_GetByID_HappyPath(t *testing.T) {
 	// Mock expectations
 	rows := sqlmock.NewRows([]string{
 		"id", "order_ref", "driver_code", "status", "created_at", "updated_at",
-		"picked_up_at", "handed_over_at", "expired_at",
+		"picked_up_at", "handed_over_at", "expired_at", "recipient_phone", "carrier",
+		"length_cm", "width_cm", "height_cm", "weight_grams", "size_class",
+		"declared_value", "fragile", "temperature", "parcel_count", "shipment_id", "parcel_number", "slot_location",
+		"priority", "return_pending_at", "returned_at", "disposed_at",
 	}).AddRow(
 		expectedID, "TEST-001", "DRV-001", domain.StatusWaiting, expectedTime, expectedTime,
-		nil, nil, nil,
+		nil, nil, nil, nil, nil,
+		nil, nil, nil, nil, domain.SizeUnknown, nil, false, domain.TemperatureAmbient, 1, nil, nil, nil,
+		domain.PriorityStandard, nil, nil, nil,
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM packages WHERE id = \\$1").
@@ -196,10 +205,15 @@ func TestPackageRepository_GetExpiredPackages_HappyPath(t *testing.T) {
 	// Mock expectations
 	rows := sqlmock.NewRows([]string{
 		"id", "order_ref", "driver_code", "status", "created_at", "updated_at",
-		"picked_up_at", "handed_over_at", "expired_at",
+		"picked_up_at", "handed_over_at", "expired_at", "recipient_phone", "carrier",
+		"length_cm", "width_cm", "height_cm", "weight_grams", "size_class",
+		"declared_value", "fragile", "temperature", "parcel_count", "shipment_id", "parcel_number", "slot_location",
+		"priority", "return_pending_at", "returned_at", "disposed_at",
 	}).AddRow(
 		expiredID, "EXPIRED-001", "DRV-001", domain.StatusWaiting, expiredTime, expiredTime,
-		nil, nil, nil,
+		nil, nil, nil, nil, nil,
+		nil, nil, nil, nil, domain.SizeUnknown, nil, false, domain.TemperatureAmbient, 1, nil, nil, nil,
+		domain.PriorityStandard, nil, nil, nil,
 	)
 
 	mock.ExpectQuery("SELECT (.+) FROM packages WHERE status IN \\(\\$1, \\$2\\) AND created_at < \\$3").
@@ -207,7 +221,7 @@ func TestPackageRepository_GetExpiredPackages_HappyPath(t *testing.T) {
 		WillReturnRows(rows)
 
 	// Execute
-	packages, err := repo.GetExpiredPackages()
+	packages, err := repo.GetExpiredPackages(context.Background(), time.Now().Add(-24*time.Hour))
 
 	// Assert
 	assert.NoError(t, err)
@@ -228,7 +242,10 @@ func TestPackageRepository_GetExpiredPackages_EdgeCase_NoExpiredPackages(t *test
 	// Mock expectations - no rows returned
 	rows := sqlmock.NewRows([]string{
 		"id", "order_ref", "driver_code", "status", "created_at", "updated_at",
-		"picked_up_at", "handed_over_at", "expired_at",
+		"picked_up_at", "handed_over_at", "expired_at", "recipient_phone", "carrier",
+		"length_cm", "width_cm", "height_cm", "weight_grams", "size_class",
+		"declared_value", "fragile", "temperature", "parcel_count", "shipment_id", "parcel_number", "slot_location",
+		"priority", "return_pending_at", "returned_at", "disposed_at",
 	})
 
 	mock.ExpectQuery("SELECT (.+) FROM packages WHERE status IN \\(\\$1, \\$2\\) AND created_at < \\$3").
@@ -236,10 +253,61 @@ func TestPackageRepository_GetExpiredPackages_EdgeCase_NoExpiredPackages(t *test
 		WillReturnRows(rows)
 
 	// Execute
-	packages, err := repo.GetExpiredPackages()
+	packages, err := repo.GetExpiredPackages(context.Background(), time.Now().Add(-24*time.Hour))
 
 	// Assert
 	assert.NoError(t, err)
 	assert.Len(t, packages, 0)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPackageRepository_UpdateStatus_HappyPath_RetriesSerializationFailure(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPackageRepository(db)
+	packageID := uuid.New()
+
+	// Mock expectations - first attempt hits a serialization failure, second succeeds
+	mock.ExpectExec("UPDATE packages SET status = \\$2").
+		WithArgs(packageID, domain.StatusPicked, sqlmock.AnyArg()).
+		WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectExec("UPDATE packages SET status = \\$2").
+		WithArgs(packageID, domain.StatusPicked, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	err = repo.UpdateStatus(packageID, domain.StatusPicked)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPackageRepository_WaitingPositions_HappyPath(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPackageRepository(db)
+	first, second := uuid.New(), uuid.New()
+
+	// Mock expectations
+	rows := sqlmock.NewRows([]string{"id", "position"}).
+		AddRow(first, 4).
+		AddRow(second, 9)
+	mock.ExpectQuery("ROW_NUMBER\\(\\) OVER \\(ORDER BY CASE priority (.+) END, created_at, id\\)").
+		WithArgs(domain.StatusWaiting, pq.Array([]string{first.String(), second.String()})).
+		WillReturnRows(rows)
+
+	// Execute
+	positions, err := repo.WaitingPositions([]uuid.UUID{first, second})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{first: 4, second: 9}, positions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
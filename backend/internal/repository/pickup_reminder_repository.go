@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PickupReminderRepository struct {
+	db    *sql.DB
+	retry database.RetryPolicy
+}
+
+func NewPickupReminderRepository(db *sql.DB) domain.PickupReminderRepository {
+	return &PickupReminderRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (rr *PickupReminderRepository) Get(packageID uuid.UUID) (*domain.PickupReminder, error) {
+	query := `SELECT package_id, reminder_count, last_sent_at FROM pickup_reminders WHERE package_id = $1`
+	args := []interface{}{packageID}
+
+	startTime := time.Now()
+	var reminder domain.PickupReminder
+	err := rr.retry.Do(func() error {
+		return rr.db.QueryRow(query, args...).Scan(&reminder.PackageID, &reminder.Count, &reminder.LastSentAt)
+	})
+	if err == sql.ErrNoRows {
+		database.LogQuery(query, args, startTime)
+		return nil, nil
+	}
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+	return &reminder, nil
+}
+
+func (rr *PickupReminderRepository) Record(packageID uuid.UUID, sentAt time.Time) error {
+	query := `
+		INSERT INTO pickup_reminders (package_id, reminder_count, last_sent_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (package_id) DO UPDATE SET
+			reminder_count = pickup_reminders.reminder_count + 1,
+			last_sent_at = EXCLUDED.last_sent_at`
+	args := []interface{}{packageID, sentAt}
+
+	startTime := time.Now()
+	err := rr.retry.Do(func() error {
+		_, err := rr.db.Exec(query, args...)
+		return err
+	})
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+	return err
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PickupSessionRepository struct {
+	db    *sql.DB
+	retry database.RetryPolicy
+}
+
+func NewPickupSessionRepository(db *sql.DB) domain.PickupSessionRepository {
+	return &PickupSessionRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+// Create inserts the session and its expected items in one transaction
+func (pr *PickupSessionRepository) Create(session *domain.PickupSession) error {
+	sessionQuery := `
+		INSERT INTO pickup_sessions (id, driver_code, status, opened_at)
+		VALUES ($1, $2, $3, $4)`
+	itemQuery := `
+		INSERT INTO pickup_session_items (session_id, order_ref, package_id, expected)
+		VALUES ($1, $2, $3, TRUE)`
+	args := []interface{}{session.ID, session.DriverCode, session.Status, session.OpenedAt}
+
+	startTime := time.Now()
+	err := pr.retry.DoWrite(func() error {
+		tx, err := pr.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(sessionQuery, args...); err != nil {
+			return err
+		}
+		for _, item := range session.Items {
+			if _, err := tx.Exec(itemQuery, session.ID, item.OrderRef, item.PackageID); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+
+	if err != nil {
+		database.LogQueryError(sessionQuery, args, err, startTime)
+		if isUniqueViolation(err) {
+			return domain.ErrOpenSessionExists
+		}
+		return err
+	}
+	database.LogQuery(sessionQuery, args, startTime)
+	return nil
+}
+
+func (pr *PickupSessionRepository) GetByID(id uuid.UUID) (*domain.PickupSession, error) {
+	query := `
+		SELECT id, driver_code, status, opened_at, closed_at
+		FROM pickup_sessions
+		WHERE id = $1`
+	return pr.getOne(query, id)
+}
+
+func (pr *PickupSessionRepository) GetOpenByDriver(driverCode string) (*domain.PickupSession, error) {
+	query := `
+		SELECT id, driver_code, status, opened_at, closed_at
+		FROM pickup_sessions
+		WHERE driver_code = $1 AND status = $2`
+	return pr.getOne(query, driverCode, domain.SessionOpen)
+}
+
+func (pr *PickupSessionRepository) ListOpen() ([]*domain.PickupSession, error) {
+	query := `SELECT id FROM pickup_sessions WHERE status = $1 ORDER BY opened_at, id`
+	args := []interface{}{domain.SessionOpen}
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := pr.retry.Do(func() (err error) {
+		rows, err = pr.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	return listSessions(ids, pr.GetByID)
+}
+
+func (pr *PickupSessionRepository) RecordScan(sessionID uuid.UUID, item *domain.PickupSessionItem) error {
+	query := `
+		INSERT INTO pickup_session_items (session_id, order_ref, package_id, expected, result, reason, scanned_at)
+		VALUES ($1, $2, $3, FALSE, $4, $5, $6)
+		ON CONFLICT (session_id, order_ref) DO UPDATE
+		SET package_id = EXCLUDED.package_id, result = EXCLUDED.result,
+		    reason = EXCLUDED.reason, scanned_at = EXCLUDED.scanned_at`
+	args := []interface{}{sessionID, item.OrderRef, item.PackageID, item.Result, item.Reason, item.ScannedAt}
+
+	startTime := time.Now()
+	err := pr.retry.Do(func() error {
+		_, err := pr.db.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (pr *PickupSessionRepository) Close(id uuid.UUID, closedAt time.Time) error {
+	query := `UPDATE pickup_sessions SET status = $2, closed_at = $3 WHERE id = $1`
+	args := []interface{}{id, domain.SessionClosed, closedAt}
+
+	startTime := time.Now()
+	err := pr.retry.Do(func() error {
+		_, err := pr.db.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (pr *PickupSessionRepository) getOne(query string, args ...interface{}) (*domain.PickupSession, error) {
+	var session domain.PickupSession
+	var closedAt sql.NullTime
+
+	startTime := time.Now()
+	err := pr.retry.Do(func() error {
+		return pr.db.QueryRow(query, args...).Scan(
+			&session.ID,
+			&session.DriverCode,
+			&session.Status,
+			&session.OpenedAt,
+			&closedAt,
+		)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	if closedAt.Valid {
+		session.ClosedAt = &closedAt.Time
+	}
+
+	session.Items, err = pr.getItems(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (pr *PickupSessionRepository) getItems(sessionID uuid.UUID) ([]*domain.PickupSessionItem, error) {
+	query := `
+		SELECT order_ref, package_id, expected, COALESCE(result, ''), COALESCE(reason, ''), scanned_at
+		FROM pickup_session_items
+		WHERE session_id = $1
+		ORDER BY expected DESC, order_ref`
+	args := []interface{}{sessionID}
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := pr.retry.Do(func() (err error) {
+		rows, err = pr.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+
+	database.LogQuery(query, args, startTime)
+
+	items := []*domain.PickupSessionItem{}
+	for rows.Next() {
+		var item domain.PickupSessionItem
+		var packageID uuid.NullUUID
+		var scannedAt sql.NullTime
+
+		if err := rows.Scan(&item.OrderRef, &packageID, &item.Expected, &item.Result, &item.Reason, &scannedAt); err != nil {
+			return nil, err
+		}
+		if packageID.Valid {
+			item.PackageID = &packageID.UUID
+		}
+		if scannedAt.Valid {
+			item.ScannedAt = &scannedAt.Time
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// listSessions loads the sessions with the given ids in order, skipping any
+// that disappeared in between
+func listSessions(ids []uuid.UUID, get func(uuid.UUID) (*domain.PickupSession, error)) ([]*domain.PickupSession, error) {
+	sessions := make([]*domain.PickupSession, 0, len(ids))
+	for _, id := range ids {
+		session, err := get(id)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"math"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is how often idle buckets are dropped. Every limit
+// is per minute, so a bucket idle for a minute is full and can be forgotten.
+const rateLimitSweepInterval = time.Minute
+
+// PostgresRateLimiter keeps token buckets in the rate_limit_buckets table so
+// every API replica draws from the same buckets. Refills use the database
+// clock, so replicas with skewed clocks still agree.
+type PostgresRateLimiter struct {
+	db    *sql.DB
+	retry database.RetryPolicy
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresRateLimiter(db *sql.DB) domain.RateLimiter {
+	return &PostgresRateLimiter{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (l *PostgresRateLimiter) Allow(key string, perMinute int) (bool, time.Duration, error) {
+	l.sweep()
+
+	// refilled is the bucket's tokens after refilling since the last request
+	const refilled = `LEAST($2::double precision, rate_limit_buckets.tokens +
+		EXTRACT(EPOCH FROM (now() - rate_limit_buckets.updated_at))::double precision * $3::double precision)`
+	query := `
+		INSERT INTO rate_limit_buckets (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::double precision - 1, TRUE, now())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE WHEN ` + refilled + ` >= 1 THEN ` + refilled + ` - 1 ELSE ` + refilled + ` END,
+			allowed = ` + refilled + ` >= 1,
+			updated_at = now()
+		RETURNING allowed, tokens`
+	rate := float64(perMinute) / 60
+	args := []interface{}{key, perMinute, rate}
+
+	startTime := time.Now()
+	var allowed bool
+	var tokens float64
+	err := l.retry.Do(func() error {
+		return l.db.QueryRow(query, args...).Scan(&allowed, &tokens)
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return false, 0, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	if allowed {
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - tokens) / rate * float64(time.Second)), nil
+}
+
+// sweep deletes idle buckets, at most once per interval per replica
+func (l *PostgresRateLimiter) sweep() {
+	l.mu.Lock()
+	if time.Since(l.lastSweep) < rateLimitSweepInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.lastSweep = time.Now()
+	l.mu.Unlock()
+
+	query := `DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)`
+	args := []interface{}{rateLimitSweepInterval.Seconds()}
+	startTime := time.Now()
+	if _, err := l.db.Exec(query, args...); err != nil {
+		// Stale buckets are harmless; the next sweep retries
+		database.LogQueryError(query, args, err, startTime)
+		return
+	}
+	database.LogQuery(query, args, startTime)
+}
+
+// MemoryRateLimiter keeps token buckets in process memory, so each API
+// replica counts on its own
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// tokenBucket is a token bucket; tokens refill continuously up to the burst size
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// WithClock replaces the limiter's clock, for tests
+func (l *MemoryRateLimiter) WithClock(now func() time.Time) *MemoryRateLimiter {
+	l.now = now
+	return l
+}
+
+func (l *MemoryRateLimiter) Allow(key string, perMinute int) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	burst := float64(perMinute)
+	rate := burst / 60
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second)), nil
+}
+
+// sweep drops idle buckets, at most once per interval, so clients that went
+// away do not pile up
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= rateLimitSweepInterval {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiter_Allow_HappyPath_Refills(t *testing.T) {
+	// Setup
+	now := time.Date(2025, 8, 24, 12, 0, 0, 0, time.UTC)
+	limiter := repository.NewMemoryRateLimiter().WithClock(func() time.Time { return now })
+	for i := 0; i < 2; i++ {
+		ok, _, err := limiter.Allow("ip:203.0.113.7", 2)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	// Execute
+	denied, retryAfter, _ := limiter.Allow("ip:203.0.113.7", 2)
+	now = now.Add(30 * time.Second)
+	refilled, _, _ := limiter.Allow("ip:203.0.113.7", 2)
+
+	// Assert
+	assert.False(t, denied)
+	assert.Equal(t, 30*time.Second, retryAfter)
+	assert.True(t, refilled)
+}
+
+func TestPostgresRateLimiter_Allow_HappyPath(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	limiter := repository.NewPostgresRateLimiter(db)
+
+	// Mock expectations - idle buckets are swept first
+	mock.ExpectExec("DELETE FROM rate_limit_buckets").WithArgs(60.0).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery("INSERT INTO rate_limit_buckets").WithArgs("key:scanner-1", 60, 1.0).
+		WillReturnRows(sqlmock.NewRows([]string{"allowed", "tokens"}).AddRow(true, 59.0))
+
+	// Execute
+	ok, retryAfter, err := limiter.Allow("key:scanner-1", 60)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Zero(t, retryAfter)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRateLimiter_Allow_EdgeCase_Denied(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	limiter := repository.NewPostgresRateLimiter(db)
+
+	// Mock expectations - a failed sweep does not block the check
+	mock.ExpectExec("DELETE FROM rate_limit_buckets").WillReturnError(errors.New("deadlock detected"))
+	mock.ExpectQuery("INSERT INTO rate_limit_buckets").WithArgs("key:scanner-1", 60, 1.0).
+		WillReturnRows(sqlmock.NewRows([]string{"allowed", "tokens"}).AddRow(false, 0.25))
+
+	// Execute
+	ok, retryAfter, err := limiter.Allow("key:scanner-1", 60)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 750*time.Millisecond, retryAfter)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
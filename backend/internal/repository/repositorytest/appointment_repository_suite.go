@@ -0,0 +1,139 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AppointmentFactory returns an empty appointment repository for a single subtest
+type AppointmentFactory func(t *testing.T) domain.AppointmentRepository
+
+// RunAppointmentRepositorySuite runs the shared conformance tests against the repositories built by newRepo
+func RunAppointmentRepositorySuite(t *testing.T, newRepo AppointmentFactory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		testAppointmentCreateAndGet(t, newRepo(t))
+	})
+	t.Run("Capacity", func(t *testing.T) {
+		testAppointmentCapacity(t, newRepo(t))
+	})
+	t.Run("OnePerDriverPerDay", func(t *testing.T) {
+		testAppointmentOnePerDriverPerDay(t, newRepo(t))
+	})
+	t.Run("ListBookedAndCancel", func(t *testing.T) {
+		testAppointmentListBookedAndCancel(t, newRepo(t))
+	})
+}
+
+// NewAppointment returns a booked appointment for a 30 minute window starting at windowStart
+func NewAppointment(driverCode string, windowStart time.Time) *domain.Appointment {
+	windowStart = windowStart.UTC().Truncate(time.Microsecond)
+	return &domain.Appointment{
+		ID:          uuid.New(),
+		DriverCode:  driverCode,
+		WindowStart: windowStart,
+		WindowEnd:   windowStart.Add(30 * time.Minute),
+		Status:      domain.AppointmentBooked,
+		CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+// DayOf returns UTC midnight of t's day, the booking day of windows built by
+// NewAppointment
+func DayOf(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// create books appointment into a window holding capacity places
+func create(repo domain.AppointmentRepository, appointment *domain.Appointment, capacity int) error {
+	return repo.Create(appointment, capacity, DayOf(appointment.WindowStart))
+}
+
+func testAppointmentCreateAndGet(t *testing.T, repo domain.AppointmentRepository) {
+	appointment := NewAppointment("DRV-001", time.Now().Add(time.Hour))
+	appointment.BookedBy = "dispatch"
+	require.NoError(t, create(repo, appointment, 2))
+
+	got, err := repo.GetByID(appointment.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "DRV-001", got.DriverCode)
+	assert.Equal(t, "dispatch", got.BookedBy)
+	assert.Equal(t, domain.AppointmentBooked, got.Status)
+	assert.True(t, appointment.WindowStart.Equal(got.WindowStart))
+	assert.True(t, appointment.WindowEnd.Equal(got.WindowEnd))
+	assert.Nil(t, got.CancelledAt)
+
+	missing, err := repo.GetByID(uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func testAppointmentCapacity(t *testing.T, repo domain.AppointmentRepository) {
+	start := DayOf(time.Now()).Add(24*time.Hour + 9*time.Hour)
+	first := NewAppointment("DRV-001", start)
+	require.NoError(t, create(repo, first, 2))
+
+	assert.ErrorIs(t, create(repo, NewAppointment("DRV-001", start), 2), domain.ErrAppointmentExists)
+	require.NoError(t, create(repo, NewAppointment("DRV-002", start), 2))
+	assert.ErrorIs(t, create(repo, NewAppointment("DRV-003", start), 2), domain.ErrWindowFull)
+	require.NoError(t, create(repo, NewAppointment("DRV-003", start.Add(30*time.Minute)), 2))
+
+	// A cancelled booking frees its place and lets the driver book again
+	require.NoError(t, repo.Cancel(first.ID, time.Now()))
+	require.NoError(t, create(repo, NewAppointment("DRV-001", start), 2))
+}
+
+func testAppointmentOnePerDriverPerDay(t *testing.T, repo domain.AppointmentRepository) {
+	day := DayOf(time.Now()).Add(24 * time.Hour)
+	morning := NewAppointment("DRV-001", day.Add(9*time.Hour))
+	require.NoError(t, create(repo, morning, 5))
+
+	// Another window the same day is refused, one the next day is not
+	assert.ErrorIs(t, create(repo, NewAppointment("DRV-001", day.Add(14*time.Hour)), 5), domain.ErrAppointmentExists)
+	require.NoError(t, create(repo, NewAppointment("DRV-001", day.Add(33*time.Hour)), 5))
+
+	// The day's bounds come from the caller, e.g. the site's local midnight
+	require.NoError(t, create(repo, NewAppointment("DRV-002", day.Add(3*time.Hour)), 5))
+	assert.ErrorIs(t, repo.Create(NewAppointment("DRV-002", day.Add(23*time.Hour)), 5, day), domain.ErrAppointmentExists)
+	require.NoError(t, repo.Create(NewAppointment("DRV-002", day.Add(23*time.Hour)), 5, day.Add(22*time.Hour)))
+
+	// Cancelling frees the day
+	require.NoError(t, repo.Cancel(morning.ID, time.Now()))
+	require.NoError(t, create(repo, NewAppointment("DRV-001", day.Add(14*time.Hour)), 5))
+}
+
+func testAppointmentListBookedAndCancel(t *testing.T, repo domain.AppointmentRepository) {
+	day := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	late := NewAppointment("DRV-001", day.Add(10*time.Hour))
+	early := NewAppointment("DRV-002", day.Add(8*time.Hour))
+	cancelled := NewAppointment("DRV-003", day.Add(9*time.Hour))
+	nextDay := NewAppointment("DRV-001", day.Add(32*time.Hour))
+	for _, appointment := range []*domain.Appointment{late, early, cancelled, nextDay} {
+		require.NoError(t, create(repo, appointment, 5))
+	}
+	cancelledAt := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.Cancel(cancelled.ID, cancelledAt))
+
+	booked, err := repo.ListBooked(domain.AppointmentFilter{From: day, To: day.Add(24 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, booked, 2)
+	assert.Equal(t, early.ID, booked[0].ID)
+	assert.Equal(t, late.ID, booked[1].ID)
+
+	mine, err := repo.ListBooked(domain.AppointmentFilter{DriverCode: "DRV-001", From: day, To: day.Add(48 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+	assert.Equal(t, nextDay.ID, mine[1].ID)
+
+	got, err := repo.GetByID(cancelled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AppointmentCancelled, got.Status)
+	require.NotNil(t, got.CancelledAt)
+	assert.True(t, cancelledAt.Equal(*got.CancelledAt))
+}
@@ -0,0 +1,78 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// JobRunFactory returns an empty repository for a single subtest
+type JobRunFactory func(t *testing.T) domain.JobRunRepository
+
+// RunJobRunRepositorySuite runs the shared conformance tests against the repository built by newRepo
+func RunJobRunRepositorySuite(t *testing.T, newRepo JobRunFactory) {
+	t.Run("CreateAndFinish", func(t *testing.T) { testJobRunCreateAndFinish(t, newRepo(t)) })
+	t.Run("ListNewestFirst", func(t *testing.T) { testJobRunListNewestFirst(t, newRepo(t)) })
+}
+
+// NewJobRun returns a running scheduled run of job started at startedAt
+func NewJobRun(job string, startedAt time.Time) *domain.JobRun {
+	return &domain.JobRun{
+		ID:        uuid.New(),
+		Job:       job,
+		Trigger:   domain.TriggerSchedule,
+		Status:    domain.JobRunRunning,
+		StartedAt: startedAt.UTC().Truncate(time.Microsecond),
+	}
+}
+
+func testJobRunCreateAndFinish(t *testing.T, repo domain.JobRunRepository) {
+	run := NewJobRun("expire-packages", time.Now())
+	require.NoError(t, repo.Create(run))
+
+	runs, err := repo.ListByJob("expire-packages", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, domain.JobRunRunning, runs[0].Status)
+	assert.Nil(t, runs[0].FinishedAt)
+
+	finishedAt := time.Now().UTC().Truncate(time.Microsecond)
+	run.Status = domain.JobRunFailed
+	run.FinishedAt = &finishedAt
+	run.Error = "connection refused"
+	require.NoError(t, repo.Finish(run))
+
+	runs, err = repo.ListByJob("expire-packages", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, run.ID, runs[0].ID)
+	assert.Equal(t, domain.TriggerSchedule, runs[0].Trigger)
+	assert.Equal(t, domain.JobRunFailed, runs[0].Status)
+	assert.Equal(t, "connection refused", runs[0].Error)
+	require.NotNil(t, runs[0].FinishedAt)
+	assert.True(t, finishedAt.Equal(*runs[0].FinishedAt))
+	assert.True(t, run.StartedAt.Equal(runs[0].StartedAt))
+}
+
+func testJobRunListNewestFirst(t *testing.T, repo domain.JobRunRepository) {
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.Create(NewJobRun("archive-packages", base.Add(time.Duration(i)*time.Minute))))
+	}
+	require.NoError(t, repo.Create(NewJobRun("expire-packages", base)))
+
+	runs, err := repo.ListByJob("archive-packages", 2)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.True(t, runs[0].StartedAt.After(runs[1].StartedAt))
+	assert.True(t, runs[0].StartedAt.Equal(base.Add(2*time.Minute).UTC().Truncate(time.Microsecond)))
+
+	runs, err = repo.ListByJob("unknown", 10)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
@@ -0,0 +1,37 @@
+package repositorytest
+
+import (
+	"testing"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// JobSettingsFactory returns an empty repository for a single subtest
+type JobSettingsFactory func(t *testing.T) domain.JobSettingsRepository
+
+// RunJobSettingsRepositorySuite runs the shared conformance tests against the repository built by newRepo
+func RunJobSettingsRepositorySuite(t *testing.T, newRepo JobSettingsFactory) {
+	t.Run("PauseAndResume", func(t *testing.T) { testJobSettingsPauseAndResume(t, newRepo(t)) })
+}
+
+func testJobSettingsPauseAndResume(t *testing.T, repo domain.JobSettingsRepository) {
+	paused, err := repo.Paused("expire-packages")
+	require.NoError(t, err)
+	assert.False(t, paused)
+
+	require.NoError(t, repo.SetPaused("expire-packages", true))
+	paused, err = repo.Paused("expire-packages")
+	require.NoError(t, err)
+	assert.True(t, paused)
+	paused, err = repo.Paused("archive-packages")
+	require.NoError(t, err)
+	assert.False(t, paused)
+
+	require.NoError(t, repo.SetPaused("expire-packages", false))
+	paused, err = repo.Paused("expire-packages")
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
@@ -0,0 +1,163 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ArchiveFactory returns an empty package repository and the archive that
+// moves packages out of it, for a single subtest
+type ArchiveFactory func(t *testing.T) (domain.PackageRepository, domain.PackageArchiveRepository)
+
+// RunPackageArchiveRepositorySuite runs the shared conformance tests against the archive built by newRepos
+func RunPackageArchiveRepositorySuite(t *testing.T, newRepos ArchiveFactory) {
+	t.Run("ArchiveSelection", func(t *testing.T) { testArchiveSelection(t, newRepos) })
+	t.Run("ArchiveLimit", func(t *testing.T) { testArchiveLimit(t, newRepos) })
+	t.Run("Lookup", func(t *testing.T) { testArchiveLookup(t, newRepos) })
+	t.Run("Rehydrate", func(t *testing.T) { testArchiveRehydrate(t, newRepos) })
+	t.Run("RehydrateConflict", func(t *testing.T) { testArchiveRehydrateConflict(t, newRepos) })
+}
+
+// mustCreateTerminal stores a package in the given terminal status, last
+// updated at updatedAt
+func mustCreateTerminal(t *testing.T, repo domain.PackageRepository, orderRef string, status domain.PackageStatus, updatedAt time.Time) *domain.Package {
+	t.Helper()
+	pkg := NewPackage(orderRef, updatedAt.Add(-time.Hour))
+	pkg.RecipientPhone = "600100200"
+	pkg.Carrier = "acme"
+	mustCreate(t, repo, pkg)
+	pkg.Status = status
+	pkg.UpdatedAt = updatedAt.UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.Update(pkg))
+	return pkg
+}
+
+func testArchiveSelection(t *testing.T, newRepos ArchiveFactory) {
+	packages, archive := newRepos(t)
+	old := time.Now().Add(-100 * 24 * time.Hour)
+	mustCreateTerminal(t, packages, "OLD-HANDED", domain.StatusHandedOver, old)
+	mustCreateTerminal(t, packages, "OLD-RETURNED", domain.StatusReturned, old)
+	mustCreateTerminal(t, packages, "OLD-DISPOSED", domain.StatusDisposed, old)
+	// Expired packages stay live until they are returned or disposed of
+	mustCreateTerminal(t, packages, "OLD-EXPIRED", domain.StatusExpired, old)
+	mustCreateTerminal(t, packages, "OLD-RETURN-PENDING", domain.StatusReturnPending, old)
+	mustCreateTerminal(t, packages, "FRESH-HANDED", domain.StatusHandedOver, time.Now())
+	mustCreate(t, packages, NewPackage("OLD-WAITING", old))
+	deleted := mustCreateTerminal(t, packages, "OLD-DELETED", domain.StatusHandedOver, old)
+	require.NoError(t, packages.Delete(deleted.ID))
+
+	moved, err := archive.ArchiveBefore(context.Background(), time.Now().Add(-90*24*time.Hour), 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), moved)
+
+	live, err := packages.GetAll(domain.PackageFilter{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"FRESH-HANDED", "OLD-WAITING", "OLD-EXPIRED", "OLD-RETURN-PENDING"}, orderRefs(live))
+
+	stats, err := packages.GetPackageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stats.Total)
+	assert.Equal(t, int64(1), stats.Expired)
+	assert.Equal(t, int64(1), stats.ReturnPending)
+}
+
+func testArchiveLimit(t *testing.T, newRepos ArchiveFactory) {
+	packages, archive := newRepos(t)
+	old := time.Now().Add(-100 * 24 * time.Hour)
+	oldest := mustCreateTerminal(t, packages, "OLDEST", domain.StatusHandedOver, old.Add(-time.Hour))
+	mustCreateTerminal(t, packages, "OLDER", domain.StatusHandedOver, old)
+
+	moved, err := archive.ArchiveBefore(context.Background(), time.Now(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), moved)
+
+	// The least recently updated package goes first
+	got, err := archive.GetByID(oldest.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+
+	moved, err = archive.ArchiveBefore(context.Background(), time.Now(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), moved)
+
+	moved, err = archive.ArchiveBefore(context.Background(), time.Now(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), moved)
+}
+
+func testArchiveLookup(t *testing.T, newRepos ArchiveFactory) {
+	packages, archive := newRepos(t)
+	pkg := mustCreateTerminal(t, packages, "ABC-001", domain.StatusReturned, time.Now().Add(-time.Hour))
+
+	_, err := archive.ArchiveBefore(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+
+	live, err := packages.GetByID(pkg.ID)
+	require.NoError(t, err)
+	assert.Nil(t, live)
+
+	got, err := archive.GetByID(pkg.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "ABC-001", got.OrderRef)
+	assert.Equal(t, domain.StatusReturned, got.Status)
+	assert.Equal(t, pkg.RecipientPhone, got.RecipientPhone)
+	assert.Equal(t, pkg.Carrier, got.Carrier)
+	assert.True(t, pkg.CreatedAt.Equal(got.CreatedAt))
+	require.NotNil(t, got.ArchivedAt)
+
+	got, err = archive.GetByOrderRef("ABC-001")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, pkg.ID, got.ID)
+
+	got, err = archive.GetByID(uuid.New())
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func testArchiveRehydrate(t *testing.T, newRepos ArchiveFactory) {
+	packages, archive := newRepos(t)
+	pkg := mustCreateTerminal(t, packages, "ABC-001", domain.StatusHandedOver, time.Now().Add(-time.Hour))
+	_, err := archive.ArchiveBefore(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+
+	require.NoError(t, archive.Rehydrate(pkg.ID))
+
+	live, err := packages.GetByOrderRef("ABC-001")
+	require.NoError(t, err)
+	require.NotNil(t, live)
+	assert.Equal(t, pkg.ID, live.ID)
+	assert.Equal(t, domain.StatusHandedOver, live.Status)
+	assert.Nil(t, live.ArchivedAt)
+
+	archived, err := archive.GetByID(pkg.ID)
+	require.NoError(t, err)
+	assert.Nil(t, archived)
+
+	// Rehydrating a package that is not archived is a no-op
+	assert.NoError(t, archive.Rehydrate(pkg.ID))
+	assert.NoError(t, archive.Rehydrate(uuid.New()))
+}
+
+func testArchiveRehydrateConflict(t *testing.T, newRepos ArchiveFactory) {
+	packages, archive := newRepos(t)
+	pkg := mustCreateTerminal(t, packages, "ABC-001", domain.StatusHandedOver, time.Now().Add(-time.Hour))
+	_, err := archive.ArchiveBefore(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+	mustCreate(t, packages, NewPackage("ABC-001", time.Now()))
+
+	err = archive.Rehydrate(pkg.ID)
+
+	assert.ErrorIs(t, err, domain.ErrDuplicateOrderRef)
+	archived, err := archive.GetByID(pkg.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, archived)
+}
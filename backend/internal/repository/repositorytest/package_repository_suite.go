@@ -0,0 +1,584 @@
+// Package repositorytest holds a conformance suite that every
+// domain.PackageRepository implementation must pass.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty repository for a single subtest
+type Factory func(t *testing.T) domain.PackageRepository
+
+// RunPackageRepositorySuite runs the shared conformance tests against the repository built by newRepo
+func RunPackageRepositorySuite(t *testing.T, newRepo Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) { testCreateAndGet(t, newRepo(t)) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newRepo(t)) })
+	t.Run("UniqueOrderRef", func(t *testing.T) { testUniqueOrderRef(t, newRepo(t)) })
+	t.Run("GetAllOrderingAndPaging", func(t *testing.T) { testGetAllOrderingAndPaging(t, newRepo(t)) })
+	t.Run("GetAllStatusFilter", func(t *testing.T) { testGetAllStatusFilter(t, newRepo(t)) })
+	t.Run("GetAllDriverFilter", func(t *testing.T) { testGetAllDriverFilter(t, newRepo(t)) })
+	t.Run("PhysicalAttributes", func(t *testing.T) { testPhysicalAttributes(t, newRepo(t)) })
+	t.Run("GetAllAttributeFilters", func(t *testing.T) { testGetAllAttributeFilters(t, newRepo(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepo(t)) })
+	t.Run("UpdateStatusTimestamps", func(t *testing.T) { testUpdateStatusTimestamps(t, newRepo(t)) })
+	t.Run("ReturnStatuses", func(t *testing.T) { testReturnStatuses(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo(t)) })
+	t.Run("DeletedHiddenFromReads", func(t *testing.T) { testDeletedHiddenFromReads(t, newRepo(t)) })
+	t.Run("Restore", func(t *testing.T) { testRestore(t, newRepo(t)) })
+	t.Run("PurgeDeleted", func(t *testing.T) { testPurgeDeleted(t, newRepo(t)) })
+	t.Run("ExpirySelection", func(t *testing.T) { testExpirySelection(t, newRepo(t)) })
+	t.Run("ExpiredBefore", func(t *testing.T) { testExpiredBefore(t, newRepo(t)) })
+	t.Run("Stats", func(t *testing.T) { testStats(t, newRepo(t)) })
+	t.Run("WaitingPositions", func(t *testing.T) { testWaitingPositions(t, newRepo(t)) })
+	t.Run("PriorityOrdering", func(t *testing.T) { testPriorityOrdering(t, newRepo(t)) })
+	t.Run("RecentPickupTimes", func(t *testing.T) { testRecentPickupTimes(t, newRepo(t)) })
+	t.Run("ConcurrentStatusUpdates", func(t *testing.T) { testConcurrentStatusUpdates(t, newRepo(t)) })
+}
+
+// NewPackage builds a WAITING package created at the given time
+func NewPackage(orderRef string, createdAt time.Time) *domain.Package {
+	createdAt = createdAt.UTC().Truncate(time.Microsecond)
+	return &domain.Package{
+		ID:         uuid.New(),
+		OrderRef:   orderRef,
+		DriverCode: "DRV-001",
+		Status:     domain.StatusWaiting,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+
+		SizeClass:   domain.SizeUnknown,
+		Temperature: domain.TemperatureAmbient,
+		ParcelCount: 1,
+		Priority:    domain.PriorityStandard,
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func int64Ptr(n int64) *int64 { return &n }
+
+func mustCreate(t *testing.T, repo domain.PackageRepository, pkgs ...*domain.Package) {
+	t.Helper()
+	for _, pkg := range pkgs {
+		require.NoError(t, repo.Create(pkg))
+	}
+}
+
+func orderRefs(pkgs []*domain.Package) []string {
+	refs := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		refs = append(refs, pkg.OrderRef)
+	}
+	return refs
+}
+
+func testCreateAndGet(t *testing.T, repo domain.PackageRepository) {
+	pkg := NewPackage("ABC-001", time.Now())
+	pkg.RecipientPhone = "+48 600 100 200"
+	pkg.Carrier = "acme"
+	mustCreate(t, repo, pkg)
+
+	byID, err := repo.GetByID(pkg.ID)
+	require.NoError(t, err)
+	require.NotNil(t, byID)
+	assert.Equal(t, pkg.OrderRef, byID.OrderRef)
+	assert.Equal(t, pkg.DriverCode, byID.DriverCode)
+	assert.Equal(t, "+48 600 100 200", byID.RecipientPhone)
+	assert.Equal(t, "acme", byID.Carrier)
+	assert.Equal(t, domain.StatusWaiting, byID.Status)
+	assert.WithinDuration(t, pkg.CreatedAt, byID.CreatedAt, time.Millisecond)
+	assert.Nil(t, byID.PickedUpAt)
+
+	byRef, err := repo.GetByOrderRef("ABC-001")
+	require.NoError(t, err)
+	require.NotNil(t, byRef)
+	assert.Equal(t, pkg.ID, byRef.ID)
+}
+
+func testNotFound(t *testing.T, repo domain.PackageRepository) {
+	byID, err := repo.GetByID(uuid.New())
+	assert.NoError(t, err)
+	assert.Nil(t, byID)
+
+	byRef, err := repo.GetByOrderRef("MISSING")
+	assert.NoError(t, err)
+	assert.Nil(t, byRef)
+}
+
+func testUniqueOrderRef(t *testing.T, repo domain.PackageRepository) {
+	mustCreate(t, repo, NewPackage("ABC-001", time.Now()))
+
+	err := repo.Create(NewPackage("ABC-001", time.Now()))
+	assert.ErrorIs(t, err, domain.ErrDuplicateOrderRef)
+
+	all, err := repo.GetAll(domain.PackageFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func testGetAllOrderingAndPaging(t *testing.T, repo domain.PackageRepository) {
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		mustCreate(t, repo, NewPackage(fmt.Sprintf("ABC-%03d", i), base.Add(time.Duration(i)*time.Minute)))
+	}
+
+	all, err := repo.GetAll(domain.PackageFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-004", "ABC-003", "ABC-002", "ABC-001", "ABC-000"}, orderRefs(all))
+
+	paged, err := repo.GetAll(domain.PackageFilter{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-003", "ABC-002"}, orderRefs(paged))
+
+	beyond, err := repo.GetAll(domain.PackageFilter{Limit: 10, Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, beyond)
+}
+
+func testGetAllStatusFilter(t *testing.T, repo domain.PackageRepository) {
+	waiting := NewPackage("ABC-001", time.Now().Add(-2*time.Minute))
+	picked := NewPackage("ABC-002", time.Now().Add(-time.Minute))
+	mustCreate(t, repo, waiting, picked)
+	require.NoError(t, repo.UpdateStatus(picked.ID, domain.StatusPicked))
+
+	status := domain.StatusPicked
+	filtered, err := repo.GetAll(domain.PackageFilter{Limit: 10, Status: &status})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-002"}, orderRefs(filtered))
+}
+
+func testGetAllDriverFilter(t *testing.T, repo domain.PackageRepository) {
+	mine := NewPackage("ABC-001", time.Now().Add(-3*time.Minute))
+	minePicked := NewPackage("ABC-002", time.Now().Add(-2*time.Minute))
+	other := NewPackage("ABC-003", time.Now().Add(-time.Minute))
+	other.DriverCode = "DRV-002"
+	mustCreate(t, repo, mine, minePicked, other)
+	require.NoError(t, repo.UpdateStatus(minePicked.ID, domain.StatusPicked))
+
+	all, err := repo.GetAll(domain.PackageFilter{DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-002", "ABC-001"}, orderRefs(all))
+
+	status := domain.StatusWaiting
+	waiting, err := repo.GetAll(domain.PackageFilter{DriverCode: "DRV-001", Status: &status})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-001"}, orderRefs(waiting))
+}
+
+func testUpdate(t *testing.T, repo domain.PackageRepository) {
+	pkg := NewPackage("ABC-001", time.Now())
+	mustCreate(t, repo, pkg)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	pkg.DriverCode = "DRV-002"
+	pkg.RecipientPhone = "600100200"
+	pkg.Status = domain.StatusPicked
+	pkg.PickedUpAt = &now
+	pkg.UpdatedAt = now
+	require.NoError(t, repo.Update(pkg))
+
+	got, err := repo.GetByID(pkg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "DRV-002", got.DriverCode)
+	assert.Equal(t, "600100200", got.RecipientPhone)
+	assert.Equal(t, domain.StatusPicked, got.Status)
+	require.NotNil(t, got.PickedUpAt)
+	assert.WithinDuration(t, now, *got.PickedUpAt, time.Millisecond)
+
+	// Mutating the returned value must not change the stored package
+	got.DriverCode = "CHANGED"
+	again, err := repo.GetByID(pkg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "DRV-002", again.DriverCode)
+}
+
+func testUpdateStatusTimestamps(t *testing.T, repo domain.PackageRepository) {
+	pkg := NewPackage("ABC-001", time.Now())
+	mustCreate(t, repo, pkg)
+
+	require.NoError(t, repo.UpdateStatus(pkg.ID, domain.StatusPicked))
+	require.NoError(t, repo.UpdateStatus(pkg.ID, domain.StatusHandedOver))
+
+	got, err := repo.GetByID(pkg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusHandedOver, got.Status)
+	assert.NotNil(t, got.PickedUpAt)
+	assert.NotNil(t, got.HandedOverAt)
+	assert.Nil(t, got.ExpiredAt)
+}
+
+func testReturnStatuses(t *testing.T, repo domain.PackageRepository) {
+	returned := NewPackage("ABC-001", time.Now())
+	disposed := NewPackage("ABC-002", time.Now())
+	mustCreate(t, repo, returned, disposed)
+
+	for _, status := range []domain.PackageStatus{domain.StatusExpired, domain.StatusReturnPending, domain.StatusReturned} {
+		require.NoError(t, repo.UpdateStatus(returned.ID, status))
+	}
+	got, err := repo.GetByID(returned.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusReturned, got.Status)
+	assert.NotNil(t, got.ExpiredAt)
+	assert.NotNil(t, got.ReturnPendingAt)
+	assert.NotNil(t, got.ReturnedAt)
+	assert.Nil(t, got.DisposedAt)
+
+	// Update writes the post-expiry timestamps as given
+	disposedAt := time.Now().UTC().Truncate(time.Microsecond)
+	disposed.Status = domain.StatusDisposed
+	disposed.DisposedAt = &disposedAt
+	require.NoError(t, repo.Update(disposed))
+	got, err = repo.GetByID(disposed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDisposed, got.Status)
+	require.NotNil(t, got.DisposedAt)
+	assert.True(t, disposedAt.Equal(*got.DisposedAt))
+	assert.Nil(t, got.ReturnedAt)
+
+	stats, err := repo.GetPackageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Returned)
+	assert.Equal(t, int64(1), stats.Disposed)
+}
+
+func testDelete(t *testing.T, repo domain.PackageRepository) {
+	pkg := NewPackage("ABC-001", time.Now())
+	mustCreate(t, repo, pkg)
+
+	require.NoError(t, repo.Delete(pkg.ID))
+
+	got, err := repo.GetByID(pkg.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	// The order reference stays reserved until the package is purged
+	assert.ErrorIs(t, repo.Create(NewPackage("ABC-001", time.Now())), domain.ErrDuplicateOrderRef)
+}
+
+func testDeletedHiddenFromReads(t *testing.T, repo domain.PackageRepository) {
+	old := time.Now().Add(-48 * time.Hour)
+	kept := NewPackage("KEPT", old)
+	deleted := NewPackage("DELETED", old)
+	mustCreate(t, repo, kept, deleted)
+	require.NoError(t, repo.Delete(deleted.ID))
+
+	byRef, err := repo.GetByOrderRef("DELETED")
+	require.NoError(t, err)
+	assert.Nil(t, byRef)
+
+	all, err := repo.GetAll(domain.PackageFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"KEPT"}, orderRefs(all))
+
+	expired, err := repo.GetExpiredPackages(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"KEPT"}, orderRefs(expired))
+
+	stats, err := repo.GetPackageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+	assert.Equal(t, int64(1), stats.Waiting)
+
+	// Writes do not resurrect or touch a deleted package
+	require.NoError(t, repo.UpdateStatus(deleted.ID, domain.StatusPicked))
+	require.NoError(t, repo.Restore(deleted.ID))
+	got, err := repo.GetByID(deleted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusWaiting, got.Status)
+}
+
+func testRestore(t *testing.T, repo domain.PackageRepository) {
+	pkg := NewPackage("ABC-001", time.Now())
+	mustCreate(t, repo, pkg)
+	require.NoError(t, repo.Delete(pkg.ID))
+
+	require.NoError(t, repo.Restore(pkg.ID))
+
+	got, err := repo.GetByOrderRef("ABC-001")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, pkg.ID, got.ID)
+	assert.Nil(t, got.DeletedAt)
+
+	// Restoring a live or unknown package is a no-op
+	assert.NoError(t, repo.Restore(pkg.ID))
+	assert.NoError(t, repo.Restore(uuid.New()))
+}
+
+func testPurgeDeleted(t *testing.T, repo domain.PackageRepository) {
+	live := NewPackage("LIVE", time.Now())
+	deleted := NewPackage("DELETED", time.Now())
+	mustCreate(t, repo, live, deleted)
+	require.NoError(t, repo.Delete(deleted.ID))
+
+	// Nothing was deleted before the cutoff yet
+	purged, err := repo.PurgeDeleted(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = repo.PurgeDeleted(context.Background(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	// Purged packages cannot be restored and free their order reference
+	require.NoError(t, repo.Restore(deleted.ID))
+	got, err := repo.GetByID(deleted.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.NoError(t, repo.Create(NewPackage("DELETED", time.Now())))
+
+	got, err = repo.GetByID(live.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
+
+func testExpirySelection(t *testing.T, repo domain.PackageRepository) {
+	old := time.Now().Add(-48 * time.Hour)
+	oldWaiting := NewPackage("OLD-WAITING", old)
+	oldPicked := NewPackage("OLD-PICKED", old)
+	oldHanded := NewPackage("OLD-HANDED", old)
+	fresh := NewPackage("FRESH", time.Now())
+	mustCreate(t, repo, oldWaiting, oldPicked, oldHanded, fresh)
+	require.NoError(t, repo.UpdateStatus(oldPicked.ID, domain.StatusPicked))
+	require.NoError(t, repo.UpdateStatus(oldHanded.ID, domain.StatusPicked))
+	require.NoError(t, repo.UpdateStatus(oldHanded.ID, domain.StatusHandedOver))
+
+	expired, err := repo.GetExpiredPackages(context.Background(), time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"OLD-WAITING", "OLD-PICKED"}, orderRefs(expired))
+}
+
+func testExpiredBefore(t *testing.T, repo domain.PackageRepository) {
+	created := time.Now().Add(-30 * 24 * time.Hour)
+	longAgo := time.Now().Add(-10 * 24 * time.Hour).UTC().Truncate(time.Microsecond)
+	recently := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+	due := NewPackage("DUE", created)
+	recent := NewPackage("RECENT", created)
+	returning := NewPackage("RETURNING", created)
+	waiting := NewPackage("WAITING", created)
+	mustCreate(t, repo, due, recent, returning, waiting)
+	for pkg, expiredAt := range map[*domain.Package]time.Time{due: longAgo, recent: recently, returning: longAgo} {
+		pkg.Status = domain.StatusExpired
+		pkg.ExpiredAt = &expiredAt
+		require.NoError(t, repo.Update(pkg))
+	}
+	require.NoError(t, repo.UpdateStatus(returning.ID, domain.StatusReturnPending))
+
+	expired, err := repo.GetExpiredBefore(context.Background(), time.Now().Add(-7*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DUE"}, orderRefs(expired))
+}
+
+func testPhysicalAttributes(t *testing.T, repo domain.PackageRepository) {
+	pkg := NewPackage("ABC-001", time.Now())
+	pkg.LengthCm, pkg.WidthCm, pkg.HeightCm = intPtr(30), intPtr(20), intPtr(5)
+	pkg.WeightGrams = intPtr(1200)
+	pkg.SizeClass = domain.SizeSmall
+	pkg.DeclaredValue = int64Ptr(25000)
+	pkg.Fragile = true
+	pkg.Temperature = domain.TemperatureChilled
+	pkg.ParcelCount = 2
+	pkg.SlotLocation = "A-03-2"
+	bare := NewPackage("ABC-002", time.Now())
+	mustCreate(t, repo, pkg, bare)
+
+	got, err := repo.GetByID(pkg.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, intPtr(30), got.LengthCm)
+	assert.Equal(t, intPtr(20), got.WidthCm)
+	assert.Equal(t, intPtr(5), got.HeightCm)
+	assert.Equal(t, intPtr(1200), got.WeightGrams)
+	assert.Equal(t, domain.SizeSmall, got.SizeClass)
+	assert.Equal(t, int64Ptr(25000), got.DeclaredValue)
+	assert.True(t, got.Fragile)
+	assert.Equal(t, domain.TemperatureChilled, got.Temperature)
+	assert.Equal(t, 2, got.ParcelCount)
+	assert.Equal(t, "A-03-2", got.SlotLocation)
+
+	got, err = repo.GetByID(bare.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.LengthCm)
+	assert.Nil(t, got.WeightGrams)
+	assert.Nil(t, got.DeclaredValue)
+	assert.Equal(t, domain.SizeUnknown, got.SizeClass)
+	assert.False(t, got.Fragile)
+	assert.Equal(t, 1, got.ParcelCount)
+
+	// Update writes the attributes back
+	got.WeightGrams = intPtr(900)
+	got.Fragile = true
+	require.NoError(t, repo.Update(got))
+	got, err = repo.GetByID(bare.ID)
+	require.NoError(t, err)
+	assert.Equal(t, intPtr(900), got.WeightGrams)
+	assert.True(t, got.Fragile)
+}
+
+func testGetAllAttributeFilters(t *testing.T, repo domain.PackageRepository) {
+	small := NewPackage("SMALL", time.Now())
+	small.SizeClass = domain.SizeSmall
+	small.DeclaredValue = int64Ptr(500)
+	fragile := NewPackage("FRAGILE", time.Now())
+	fragile.SizeClass = domain.SizeLarge
+	fragile.Fragile = true
+	fragile.DeclaredValue = int64Ptr(100000)
+	frozen := NewPackage("FROZEN", time.Now())
+	frozen.Temperature = domain.TemperatureFrozen
+	mustCreate(t, repo, small, fragile, frozen)
+
+	yes := true
+	no := false
+	for name, tc := range map[string]struct {
+		filter domain.PackageFilter
+		want   []string
+	}{
+		"size class":  {domain.PackageFilter{SizeClass: domain.SizeSmall}, []string{"SMALL"}},
+		"fragile":     {domain.PackageFilter{Fragile: &yes}, []string{"FRAGILE"}},
+		"not fragile": {domain.PackageFilter{Fragile: &no}, []string{"SMALL", "FROZEN"}},
+		"temperature": {domain.PackageFilter{Temperature: domain.TemperatureFrozen}, []string{"FROZEN"}},
+		"min value":   {domain.PackageFilter{MinDeclaredValue: int64Ptr(1000)}, []string{"FRAGILE"}},
+		"combined":    {domain.PackageFilter{SizeClass: domain.SizeLarge, Fragile: &no}, []string{}},
+	} {
+		got, err := repo.GetAll(tc.filter)
+		require.NoError(t, err, name)
+		assert.ElementsMatch(t, tc.want, orderRefs(got), name)
+	}
+
+	stats, err := repo.GetPackageStats()
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.SizeClass]int64{
+		domain.SizeSmall:   1,
+		domain.SizeMedium:  0,
+		domain.SizeLarge:   1,
+		domain.SizeXLarge:  0,
+		domain.SizeUnknown: 1,
+	}, stats.BySizeClass)
+}
+
+func testStats(t *testing.T, repo domain.PackageRepository) {
+	a := NewPackage("ABC-001", time.Now())
+	b := NewPackage("ABC-002", time.Now())
+	c := NewPackage("ABC-003", time.Now())
+	mustCreate(t, repo, a, b, c)
+	require.NoError(t, repo.UpdateStatus(b.ID, domain.StatusPicked))
+	require.NoError(t, repo.UpdateStatus(c.ID, domain.StatusExpired))
+
+	stats, err := repo.GetPackageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Total)
+	assert.Equal(t, int64(1), stats.Waiting)
+	assert.Equal(t, int64(1), stats.Picked)
+	assert.Equal(t, int64(0), stats.HandedOver)
+	assert.Equal(t, int64(1), stats.Expired)
+}
+
+func testWaitingPositions(t *testing.T, repo domain.PackageRepository) {
+	base := time.Now().Add(-time.Hour)
+	first := NewPackage("ABC-001", base)
+	picked := NewPackage("ABC-002", base.Add(time.Minute))
+	deleted := NewPackage("ABC-003", base.Add(2*time.Minute))
+	second := NewPackage("ABC-004", base.Add(3*time.Minute))
+	third := NewPackage("ABC-005", base.Add(4*time.Minute))
+	mustCreate(t, repo, third, second, deleted, picked, first)
+	require.NoError(t, repo.UpdateStatus(picked.ID, domain.StatusPicked))
+	require.NoError(t, repo.Delete(deleted.ID))
+
+	positions, err := repo.WaitingPositions([]uuid.UUID{third.ID, first.ID, picked.ID, deleted.ID, second.ID, uuid.New()})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{first.ID: 1, second.ID: 2, third.ID: 3}, positions)
+
+	none, err := repo.WaitingPositions(nil)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func testPriorityOrdering(t *testing.T, repo domain.PackageRepository) {
+	base := time.Now().Add(-time.Hour)
+	standard := NewPackage("ABC-001", base)
+	economy := NewPackage("ABC-002", base.Add(time.Minute))
+	economy.Priority = domain.PriorityEconomy
+	express := NewPackage("ABC-003", base.Add(2*time.Minute))
+	express.Priority = domain.PriorityExpress
+	laterStandard := NewPackage("ABC-004", base.Add(3*time.Minute))
+	mustCreate(t, repo, standard, economy, express, laterStandard)
+
+	// Express first, economy last, newest first within a class
+	got, err := repo.GetAll(domain.PackageFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-003", "ABC-004", "ABC-001", "ABC-002"}, orderRefs(got))
+
+	got, err = repo.GetAll(domain.PackageFilter{Priority: domain.PriorityEconomy, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-002"}, orderRefs(got))
+
+	// Express packages jump the queue, oldest first within a class
+	positions, err := repo.WaitingPositions([]uuid.UUID{standard.ID, economy.ID, express.ID, laterStandard.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{express.ID: 1, standard.ID: 2, laterStandard.ID: 3, economy.ID: 4}, positions)
+}
+
+func testRecentPickupTimes(t *testing.T, repo domain.PackageRepository) {
+	base := time.Now().UTC().Truncate(time.Microsecond).Add(-time.Hour)
+	var pickups []time.Time
+	for i := 0; i < 4; i++ {
+		pkg := NewPackage(fmt.Sprintf("ABC-%03d", i), base)
+		mustCreate(t, repo, pkg)
+		pickedUpAt := base.Add(time.Duration(i) * time.Minute)
+		pkg.Status = domain.StatusPicked
+		pkg.PickedUpAt = &pickedUpAt
+		require.NoError(t, repo.Update(pkg))
+		pickups = append(pickups, pickedUpAt)
+	}
+	deleted := NewPackage("DELETED", base)
+	mustCreate(t, repo, deleted, NewPackage("WAITING", base))
+	require.NoError(t, repo.UpdateStatus(deleted.ID, domain.StatusPicked))
+	require.NoError(t, repo.Delete(deleted.ID))
+
+	times, err := repo.RecentPickupTimes(3)
+	require.NoError(t, err)
+	require.Len(t, times, 3)
+	for i, want := range []time.Time{pickups[3], pickups[2], pickups[1]} {
+		assert.True(t, want.Equal(times[i]), "pickup %d: want %s, got %s", i, want, times[i])
+	}
+}
+
+func testConcurrentStatusUpdates(t *testing.T, repo domain.PackageRepository) {
+	pkgs := make([]*domain.Package, 10)
+	for i := range pkgs {
+		pkgs[i] = NewPackage(fmt.Sprintf("ABC-%03d", i), time.Now())
+		mustCreate(t, repo, pkgs[i])
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(pkgs)*2)
+	for _, pkg := range pkgs {
+		for _, status := range []domain.PackageStatus{domain.StatusPicked, domain.StatusPicked} {
+			wg.Add(1)
+			go func(id uuid.UUID, status domain.PackageStatus) {
+				defer wg.Done()
+				errs <- repo.UpdateStatus(id, status)
+			}(pkg.ID, status)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	stats, err := repo.GetPackageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(pkgs)), stats.Picked)
+}
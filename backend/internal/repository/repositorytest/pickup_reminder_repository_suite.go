@@ -0,0 +1,42 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PickupReminderFactory returns an empty pickup reminder repository for a single subtest
+type PickupReminderFactory func(t *testing.T) domain.PickupReminderRepository
+
+// RunPickupReminderRepositorySuite runs the shared conformance tests against the repositories built by newRepo
+func RunPickupReminderRepositorySuite(t *testing.T, newRepo PickupReminderFactory) {
+	t.Run("RecordCounts", func(t *testing.T) {
+		testPickupReminderRecordCounts(t, newRepo(t))
+	})
+}
+
+func testPickupReminderRecordCounts(t *testing.T, repo domain.PickupReminderRepository) {
+	packageID := uuid.New()
+	first := time.Now().UTC().Truncate(time.Microsecond).Add(-4 * time.Hour)
+
+	got, err := repo.Get(packageID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, repo.Record(packageID, first))
+	require.NoError(t, repo.Record(packageID, first.Add(2*time.Hour)))
+	require.NoError(t, repo.Record(uuid.New(), first))
+
+	got, err = repo.Get(packageID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, packageID, got.PackageID)
+	assert.Equal(t, 2, got.Count)
+	assert.True(t, first.Add(2*time.Hour).Equal(got.LastSentAt))
+}
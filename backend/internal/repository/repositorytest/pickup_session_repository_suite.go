@@ -0,0 +1,153 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PickupSessionFactory returns an empty repository for a single subtest
+type PickupSessionFactory func(t *testing.T) domain.PickupSessionRepository
+
+// RunPickupSessionRepositorySuite runs the shared conformance tests against the repository built by newRepo
+func RunPickupSessionRepositorySuite(t *testing.T, newRepo PickupSessionFactory) {
+	t.Run("CreateAndGet", func(t *testing.T) { testSessionCreateAndGet(t, newRepo(t)) })
+	t.Run("OneOpenSessionPerDriver", func(t *testing.T) { testSessionOnePerDriver(t, newRepo(t)) })
+	t.Run("RecordScan", func(t *testing.T) { testSessionRecordScan(t, newRepo(t)) })
+	t.Run("Close", func(t *testing.T) { testSessionClose(t, newRepo(t)) })
+	t.Run("ListOpen", func(t *testing.T) { testSessionListOpen(t, newRepo(t)) })
+}
+
+// NewPickupSession returns an open session expecting the given order references
+func NewPickupSession(driverCode string, orderRefs ...string) *domain.PickupSession {
+	session := &domain.PickupSession{
+		ID:         uuid.New(),
+		DriverCode: driverCode,
+		Status:     domain.SessionOpen,
+		OpenedAt:   time.Now().UTC().Truncate(time.Microsecond),
+	}
+	for _, orderRef := range orderRefs {
+		id := uuid.New()
+		session.Items = append(session.Items, &domain.PickupSessionItem{OrderRef: orderRef, PackageID: &id, Expected: true})
+	}
+	return session
+}
+
+func testSessionCreateAndGet(t *testing.T, repo domain.PickupSessionRepository) {
+	session := NewPickupSession("DRV-001", "ABC-002", "ABC-001")
+	require.NoError(t, repo.Create(session))
+
+	got, err := repo.GetByID(session.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "DRV-001", got.DriverCode)
+	assert.Equal(t, domain.SessionOpen, got.Status)
+	assert.True(t, session.OpenedAt.Equal(got.OpenedAt))
+	assert.Nil(t, got.ClosedAt)
+	require.Len(t, got.Items, 2)
+	assert.Equal(t, "ABC-001", got.Items[0].OrderRef)
+	assert.Equal(t, session.Items[1].PackageID, got.Items[0].PackageID)
+	assert.True(t, got.Items[0].Expected)
+	assert.Empty(t, got.Items[0].Result)
+
+	missing, err := repo.GetByID(uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func testSessionOnePerDriver(t *testing.T, repo domain.PickupSessionRepository) {
+	first := NewPickupSession("DRV-001")
+	require.NoError(t, repo.Create(first))
+
+	err := repo.Create(NewPickupSession("DRV-001"))
+	assert.ErrorIs(t, err, domain.ErrOpenSessionExists)
+	require.NoError(t, repo.Create(NewPickupSession("DRV-002")))
+
+	open, err := repo.GetOpenByDriver("DRV-001")
+	require.NoError(t, err)
+	require.NotNil(t, open)
+	assert.Equal(t, first.ID, open.ID)
+
+	// Once closed, the driver can check in again
+	require.NoError(t, repo.Close(first.ID, time.Now()))
+	open, err = repo.GetOpenByDriver("DRV-001")
+	require.NoError(t, err)
+	assert.Nil(t, open)
+	require.NoError(t, repo.Create(NewPickupSession("DRV-001")))
+}
+
+func testSessionRecordScan(t *testing.T, repo domain.PickupSessionRepository) {
+	session := NewPickupSession("DRV-001", "ABC-001")
+	require.NoError(t, repo.Create(session))
+
+	scannedAt := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.RecordScan(session.ID, &domain.PickupSessionItem{
+		OrderRef:  "ABC-001",
+		PackageID: session.Items[0].PackageID,
+		Result:    domain.ScanPicked,
+		ScannedAt: &scannedAt,
+	}))
+	require.NoError(t, repo.RecordScan(session.ID, &domain.PickupSessionItem{
+		OrderRef:  "XYZ-999",
+		Result:    domain.ScanExtra,
+		Reason:    "unknown order reference",
+		ScannedAt: &scannedAt,
+	}))
+
+	got, err := repo.GetByID(session.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Items, 2)
+
+	picked := got.Items[0]
+	assert.Equal(t, "ABC-001", picked.OrderRef)
+	assert.True(t, picked.Expected)
+	assert.Equal(t, domain.ScanPicked, picked.Result)
+	require.NotNil(t, picked.ScannedAt)
+	assert.True(t, scannedAt.Equal(*picked.ScannedAt))
+
+	extra := got.Items[1]
+	assert.Equal(t, "XYZ-999", extra.OrderRef)
+	assert.False(t, extra.Expected)
+	assert.Equal(t, domain.ScanExtra, extra.Result)
+	assert.Equal(t, "unknown order reference", extra.Reason)
+	assert.Nil(t, extra.PackageID)
+}
+
+func testSessionClose(t *testing.T, repo domain.PickupSessionRepository) {
+	session := NewPickupSession("DRV-001")
+	require.NoError(t, repo.Create(session))
+
+	closedAt := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.Close(session.ID, closedAt))
+
+	got, err := repo.GetByID(session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SessionClosed, got.Status)
+	require.NotNil(t, got.ClosedAt)
+	assert.True(t, closedAt.Equal(*got.ClosedAt))
+}
+
+func testSessionListOpen(t *testing.T, repo domain.PickupSessionRepository) {
+	later := NewPickupSession("DRV-002", "ABC-002")
+	earlier := NewPickupSession("DRV-001", "ABC-001")
+	earlier.OpenedAt = later.OpenedAt.Add(-time.Minute)
+	closed := NewPickupSession("DRV-003")
+	closed.OpenedAt = later.OpenedAt.Add(-time.Hour)
+	require.NoError(t, repo.Create(later))
+	require.NoError(t, repo.Create(earlier))
+	require.NoError(t, repo.Create(closed))
+	require.NoError(t, repo.Close(closed.ID, time.Now()))
+
+	open, err := repo.ListOpen()
+	require.NoError(t, err)
+	require.Len(t, open, 2)
+	assert.Equal(t, earlier.ID, open[0].ID)
+	assert.Equal(t, later.ID, open[1].ID)
+	require.Len(t, open[0].Items, 1)
+	assert.Equal(t, "ABC-001", open[0].Items[0].OrderRef)
+}
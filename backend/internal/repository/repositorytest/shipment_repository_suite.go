@@ -0,0 +1,123 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ShipmentFactory returns empty shipment and package repositories sharing one
+// store for a single subtest
+type ShipmentFactory func(t *testing.T) (domain.ShipmentRepository, domain.PackageRepository)
+
+// RunShipmentRepositorySuite runs the shared conformance tests against the repositories built by newRepos
+func RunShipmentRepositorySuite(t *testing.T, newRepos ShipmentFactory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		shipments, packages := newRepos(t)
+		testShipmentCreateAndGet(t, shipments, packages)
+	})
+	t.Run("DuplicateOrderRef", func(t *testing.T) {
+		shipments, _ := newRepos(t)
+		testShipmentDuplicateOrderRef(t, shipments)
+	})
+	t.Run("GetAllNewestFirst", func(t *testing.T) {
+		shipments, _ := newRepos(t)
+		testShipmentGetAllNewestFirst(t, shipments)
+	})
+}
+
+// NewShipment returns a shipment of parcelCount parcels created at createdAt
+func NewShipment(orderRef string, parcelCount int, createdAt time.Time) *domain.Shipment {
+	createdAt = createdAt.UTC().Truncate(time.Microsecond)
+	return &domain.Shipment{
+		ID:          uuid.New(),
+		OrderRef:    orderRef,
+		DriverCode:  "DRV-001",
+		ParcelCount: parcelCount,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+}
+
+func testShipmentCreateAndGet(t *testing.T, shipments domain.ShipmentRepository, packages domain.PackageRepository) {
+	shipment := NewShipment("ORD-100", 2, time.Now())
+	shipment.Carrier = "DHL"
+	require.NoError(t, shipments.Create(shipment))
+
+	first := NewPackage("ORD-100/1", time.Now())
+	first.ShipmentID, first.ParcelNumber = &shipment.ID, 1
+	second := NewPackage("ORD-100/2", time.Now())
+	second.ShipmentID, second.ParcelNumber = &shipment.ID, 2
+	mustCreate(t, packages, first, second, NewPackage("ORD-200", time.Now()))
+
+	byID, err := shipments.GetByID(shipment.ID)
+	require.NoError(t, err)
+	require.NotNil(t, byID)
+	assert.Equal(t, "ORD-100", byID.OrderRef)
+	assert.Equal(t, "DHL", byID.Carrier)
+	assert.Equal(t, 2, byID.ParcelCount)
+	assert.True(t, shipment.CreatedAt.Equal(byID.CreatedAt))
+
+	byRef, err := shipments.GetByOrderRef("ORD-100")
+	require.NoError(t, err)
+	require.NotNil(t, byRef)
+	assert.Equal(t, shipment.ID, byRef.ID)
+
+	missing, err := shipments.GetByID(uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	parcels, err := packages.GetAll(domain.PackageFilter{ShipmentID: &shipment.ID})
+	require.NoError(t, err)
+	require.Len(t, parcels, 2)
+	numbers := map[string]int{}
+	for _, parcel := range parcels {
+		require.NotNil(t, parcel.ShipmentID)
+		assert.Equal(t, shipment.ID, *parcel.ShipmentID)
+		numbers[parcel.OrderRef] = parcel.ParcelNumber
+	}
+	assert.Equal(t, map[string]int{"ORD-100/1": 1, "ORD-100/2": 2}, numbers)
+
+	loose, err := packages.GetByOrderRef("ORD-200")
+	require.NoError(t, err)
+	assert.Nil(t, loose.ShipmentID)
+	assert.Zero(t, loose.ParcelNumber)
+}
+
+func testShipmentDuplicateOrderRef(t *testing.T, shipments domain.ShipmentRepository) {
+	require.NoError(t, shipments.Create(NewShipment("ORD-100", 1, time.Now())))
+
+	err := shipments.Create(NewShipment("ORD-100", 3, time.Now()))
+
+	assert.ErrorIs(t, err, domain.ErrDuplicateOrderRef)
+}
+
+func testShipmentGetAllNewestFirst(t *testing.T, shipments domain.ShipmentRepository) {
+	base := time.Now().Add(-time.Hour)
+	for i, ref := range []string{"ORD-1", "ORD-2", "ORD-3"} {
+		require.NoError(t, shipments.Create(NewShipment(ref, 1, base.Add(time.Duration(i)*time.Minute))))
+	}
+	other := NewShipment("ORD-4", 1, base)
+	other.DriverCode = "DRV-002"
+	require.NoError(t, shipments.Create(other))
+
+	page, err := shipments.GetAll(domain.ShipmentFilter{DriverCode: "DRV-001", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "ORD-3", page[0].OrderRef)
+	assert.Equal(t, "ORD-2", page[1].OrderRef)
+
+	rest, err := shipments.GetAll(domain.ShipmentFilter{DriverCode: "DRV-001", Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, "ORD-1", rest[0].OrderRef)
+
+	all, err := shipments.GetAll(domain.ShipmentFilter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+}
@@ -0,0 +1,92 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SLABreachFactory returns an empty SLA breach repository for a single subtest
+type SLABreachFactory func(t *testing.T) domain.SLABreachRepository
+
+// RunSLABreachRepositorySuite runs the shared conformance tests against the repositories built by newRepo
+func RunSLABreachRepositorySuite(t *testing.T, newRepo SLABreachFactory) {
+	t.Run("RecordOnce", func(t *testing.T) {
+		testSLABreachRecordOnce(t, newRepo(t))
+	})
+	t.Run("List", func(t *testing.T) {
+		testSLABreachList(t, newRepo(t))
+	})
+}
+
+// NewSLABreach returns a breach of priority detected at detectedAt, due an hour earlier
+func NewSLABreach(priority domain.Priority, detectedAt time.Time) *domain.SLABreach {
+	detectedAt = detectedAt.UTC().Truncate(time.Microsecond)
+	return &domain.SLABreach{
+		PackageID:  uuid.New(),
+		OrderRef:   "ORD-" + uuid.NewString()[:8],
+		DriverCode: "DRV-001",
+		Priority:   priority,
+		DueAt:      detectedAt.Add(-time.Hour),
+		DetectedAt: detectedAt,
+	}
+}
+
+func testSLABreachRecordOnce(t *testing.T, repo domain.SLABreachRepository) {
+	now := time.Now()
+	breach := NewSLABreach(domain.PriorityExpress, now)
+
+	recorded, err := repo.Record(breach)
+	require.NoError(t, err)
+	assert.True(t, recorded)
+
+	// A second detection of the same package keeps the first record
+	again := *breach
+	again.DetectedAt = breach.DetectedAt.Add(time.Minute)
+	recorded, err = repo.Record(&again)
+	require.NoError(t, err)
+	assert.False(t, recorded)
+
+	got, err := repo.List(domain.SLABreachFilter{From: now.Add(-time.Hour), To: now.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, breach.PackageID, got[0].PackageID)
+	assert.Equal(t, breach.OrderRef, got[0].OrderRef)
+	assert.Equal(t, domain.PriorityExpress, got[0].Priority)
+	assert.True(t, breach.DueAt.Equal(got[0].DueAt))
+	assert.True(t, breach.DetectedAt.Equal(got[0].DetectedAt))
+}
+
+func testSLABreachList(t *testing.T, repo domain.SLABreachRepository) {
+	now := time.Now()
+	old := NewSLABreach(domain.PriorityStandard, now.Add(-48*time.Hour))
+	earlier := NewSLABreach(domain.PriorityExpress, now.Add(-2*time.Hour))
+	latest := NewSLABreach(domain.PriorityStandard, now.Add(-time.Hour))
+	for _, breach := range []*domain.SLABreach{old, earlier, latest} {
+		_, err := repo.Record(breach)
+		require.NoError(t, err)
+	}
+
+	got, err := repo.List(domain.SLABreachFilter{From: now.Add(-24 * time.Hour), To: now})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, latest.PackageID, got[0].PackageID)
+	assert.Equal(t, earlier.PackageID, got[1].PackageID)
+
+	got, err = repo.List(domain.SLABreachFilter{From: now.Add(-72 * time.Hour), To: now, Priority: domain.PriorityStandard})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, latest.PackageID, got[0].PackageID)
+	assert.Equal(t, old.PackageID, got[1].PackageID)
+
+	// To is exclusive
+	got, err = repo.List(domain.SLABreachFilter{From: now.Add(-24 * time.Hour), To: latest.DetectedAt})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, earlier.PackageID, got[0].PackageID)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const shipmentColumns = `id, order_ref, driver_code, carrier, parcel_count, created_at, updated_at`
+
+type ShipmentRepository struct {
+	db    dbtx
+	retry database.RetryPolicy
+}
+
+func NewShipmentRepository(db *sql.DB) domain.ShipmentRepository {
+	return &ShipmentRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (sr *ShipmentRepository) Create(shipment *domain.Shipment) error {
+	query := `
+		INSERT INTO shipments (id, order_ref, driver_code, carrier, parcel_count, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	args := []interface{}{
+		shipment.ID,
+		shipment.OrderRef,
+		shipment.DriverCode,
+		nullString(shipment.Carrier),
+		shipment.ParcelCount,
+		shipment.CreatedAt,
+		shipment.UpdatedAt,
+	}
+
+	startTime := time.Now()
+	err := sr.retry.DoWrite(func() error {
+		_, err := sr.db.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateOrderRef
+		}
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (sr *ShipmentRepository) GetByID(id uuid.UUID) (*domain.Shipment, error) {
+	return sr.getOne(`SELECT `+shipmentColumns+` FROM shipments WHERE id = $1`, id)
+}
+
+func (sr *ShipmentRepository) GetByOrderRef(orderRef string) (*domain.Shipment, error) {
+	return sr.getOne(`SELECT `+shipmentColumns+` FROM shipments WHERE order_ref = $1`, orderRef)
+}
+
+func (sr *ShipmentRepository) GetAll(filter domain.ShipmentFilter) ([]*domain.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments`
+	var args []interface{}
+	if filter.DriverCode != "" {
+		args = append(args, filter.DriverCode)
+		query += " WHERE driver_code = $1"
+	}
+	query += " ORDER BY created_at DESC, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	args = append(args, filter.Offset)
+	query += fmt.Sprintf(" OFFSET $%d", len(args))
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := sr.retry.Do(func() (err error) {
+		rows, err = sr.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	shipments := []*domain.Shipment{}
+	for rows.Next() {
+		shipment, err := scanShipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, shipment)
+	}
+	return shipments, rows.Err()
+}
+
+func (sr *ShipmentRepository) getOne(query string, args ...interface{}) (*domain.Shipment, error) {
+	startTime := time.Now()
+	var shipment *domain.Shipment
+	err := sr.retry.Do(func() (err error) {
+		shipment, err = scanShipment(sr.db.QueryRow(query, args...))
+		return err
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+	return shipment, nil
+}
+
+// scanShipment reads one row selected with shipmentColumns
+func scanShipment(row rowScanner) (*domain.Shipment, error) {
+	var shipment domain.Shipment
+	var carrier sql.NullString
+	if err := row.Scan(&shipment.ID, &shipment.OrderRef, &shipment.DriverCode, &carrier, &shipment.ParcelCount, &shipment.CreatedAt, &shipment.UpdatedAt); err != nil {
+		return nil, err
+	}
+	shipment.Carrier = carrier.String
+	return &shipment, nil
+}
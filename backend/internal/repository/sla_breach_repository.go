@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+)
+
+const slaBreachColumns = `package_id, order_ref, driver_code, priority, due_at, detected_at`
+
+type SLABreachRepository struct {
+	db    *sql.DB
+	retry database.RetryPolicy
+}
+
+func NewSLABreachRepository(db *sql.DB) domain.SLABreachRepository {
+	return &SLABreachRepository{db: db, retry: database.DefaultRetryPolicy}
+}
+
+func (br *SLABreachRepository) Record(breach *domain.SLABreach) (bool, error) {
+	query := `
+		INSERT INTO sla_breaches (` + slaBreachColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (package_id) DO NOTHING`
+	args := []interface{}{
+		breach.PackageID,
+		breach.OrderRef,
+		breach.DriverCode,
+		breach.Priority,
+		breach.DueAt,
+		breach.DetectedAt,
+	}
+
+	startTime := time.Now()
+	var recorded int64
+	err := br.retry.DoWrite(func() error {
+		result, err := br.db.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		recorded, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return false, err
+	}
+	database.LogQuery(query, args, startTime)
+	return recorded > 0, nil
+}
+
+func (br *SLABreachRepository) List(filter domain.SLABreachFilter) ([]*domain.SLABreach, error) {
+	query := `SELECT ` + slaBreachColumns + ` FROM sla_breaches
+		WHERE detected_at >= $1 AND detected_at < $2`
+	args := []interface{}{filter.From, filter.To}
+	if filter.Priority != "" {
+		args = append(args, filter.Priority)
+		query += fmt.Sprintf(" AND priority = $%d", len(args))
+	}
+	query += " ORDER BY detected_at DESC, package_id"
+
+	startTime := time.Now()
+	var rows *sql.Rows
+	err := br.retry.Do(func() (err error) {
+		rows, err = br.db.Query(query, args...)
+		return err
+	})
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	breaches := []*domain.SLABreach{}
+	for rows.Next() {
+		var breach domain.SLABreach
+		err := rows.Scan(&breach.PackageID, &breach.OrderRef, &breach.DriverCode, &breach.Priority, &breach.DueAt, &breach.DetectedAt)
+		if err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, &breach)
+	}
+	return breaches, rows.Err()
+}
@@ -0,0 +1,37 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/repository/repositorytest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLABreachRepository_Record_EdgeCase_AlreadyRecorded(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewSLABreachRepository(db)
+	breach := repositorytest.NewSLABreach(domain.PriorityExpress, time.Date(2025, 8, 25, 12, 0, 0, 0, time.UTC))
+
+	// Mock expectations - the conflicting insert affects no rows
+	mock.ExpectExec("INSERT INTO sla_breaches (.+) ON CONFLICT \\(package_id\\) DO NOTHING").
+		WithArgs(breach.PackageID, breach.OrderRef, breach.DriverCode, breach.Priority, breach.DueAt, breach.DetectedAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	recorded, err := repo.Record(breach)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, recorded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLiteAppointmentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAppointmentRepository(db *sql.DB) domain.AppointmentRepository {
+	return &SQLiteAppointmentRepository{db: db}
+}
+
+// Create counts the driver's bookings that day and the window's bookings,
+// then inserts the appointment in one transaction; SQLite runs one writer at
+// a time, so no other booking can slip in between
+func (ar *SQLiteAppointmentRepository) Create(appointment *domain.Appointment, capacity int, day time.Time) error {
+	query := `
+		INSERT INTO pickup_appointments (id, driver_code, window_start, window_end, status, booked_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{
+		appointment.ID.String(),
+		appointment.DriverCode,
+		formatSQLiteTime(appointment.WindowStart),
+		formatSQLiteTime(appointment.WindowEnd),
+		appointment.Status,
+		nullString(appointment.BookedBy),
+		formatSQLiteTime(appointment.CreatedAt),
+	}
+
+	startTime := time.Now()
+	tx, err := ar.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var sameDay int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM pickup_appointments WHERE driver_code = ? AND status = ? AND window_start >= ? AND window_start < ?`,
+		appointment.DriverCode, domain.AppointmentBooked, formatSQLiteTime(day), formatSQLiteTime(day.AddDate(0, 0, 1))).Scan(&sameDay)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	if sameDay > 0 {
+		database.LogQuery(query, args, startTime)
+		return domain.ErrAppointmentExists
+	}
+
+	var booked int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM pickup_appointments WHERE window_start = ? AND status = ?`,
+		formatSQLiteTime(appointment.WindowStart), domain.AppointmentBooked).Scan(&booked)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	if booked >= capacity {
+		database.LogQuery(query, args, startTime)
+		return domain.ErrWindowFull
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		if isSQLiteUniqueViolation(err) {
+			return domain.ErrAppointmentExists
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (ar *SQLiteAppointmentRepository) GetByID(id uuid.UUID) (*domain.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM pickup_appointments WHERE id = ?`
+	args := []interface{}{id.String()}
+
+	startTime := time.Now()
+	appointment, err := scanSQLiteAppointment(ar.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+	return appointment, nil
+}
+
+func (ar *SQLiteAppointmentRepository) ListBooked(filter domain.AppointmentFilter) ([]*domain.Appointment, error) {
+	query := `SELECT ` + appointmentColumns + ` FROM pickup_appointments
+		WHERE status = ? AND window_start >= ? AND window_start < ?`
+	args := []interface{}{domain.AppointmentBooked, formatSQLiteTime(filter.From), formatSQLiteTime(filter.To)}
+	if filter.DriverCode != "" {
+		query += " AND driver_code = ?"
+		args = append(args, filter.DriverCode)
+	}
+	query += " ORDER BY window_start, created_at, id"
+
+	startTime := time.Now()
+	rows, err := ar.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	appointments := []*domain.Appointment{}
+	for rows.Next() {
+		appointment, err := scanSQLiteAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, appointment)
+	}
+	return appointments, rows.Err()
+}
+
+func (ar *SQLiteAppointmentRepository) Cancel(id uuid.UUID, cancelledAt time.Time) error {
+	query := `UPDATE pickup_appointments SET status = ?, cancelled_at = ? WHERE id = ? AND status = ?`
+	args := []interface{}{domain.AppointmentCancelled, formatSQLiteTime(cancelledAt), id.String(), domain.AppointmentBooked}
+
+	startTime := time.Now()
+	if _, err := ar.db.Exec(query, args...); err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func scanSQLiteAppointment(row rowScanner) (*domain.Appointment, error) {
+	var appointment domain.Appointment
+	var id, windowStart, windowEnd, createdAt string
+	var bookedBy, cancelledAt sql.NullString
+	err := row.Scan(&id, &appointment.DriverCode, &windowStart, &windowEnd, &appointment.Status, &bookedBy, &createdAt, &cancelledAt)
+	if err != nil {
+		return nil, err
+	}
+	appointment.BookedBy = bookedBy.String
+
+	if appointment.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if appointment.WindowStart, err = time.Parse(sqliteTimeFormat, windowStart); err != nil {
+		return nil, err
+	}
+	if appointment.WindowEnd, err = time.Parse(sqliteTimeFormat, windowEnd); err != nil {
+		return nil, err
+	}
+	if appointment.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAt); err != nil {
+		return nil, err
+	}
+	if appointment.CancelledAt, err = parseSQLiteTimePtr(cancelledAt); err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
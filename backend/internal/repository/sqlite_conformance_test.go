@@ -0,0 +1,210 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/repository/repositorytest"
+	sqlitemigrations "pickup-queue/migrations/sqlite"
+	"pickup-queue/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLitePackageRepository_Conformance(t *testing.T) {
+	repositorytest.RunPackageRepositorySuite(t, func(t *testing.T) domain.PackageRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLitePackageRepository(db)
+	})
+}
+
+func TestSQLitePickupSessionRepository_Conformance(t *testing.T) {
+	repositorytest.RunPickupSessionRepositorySuite(t, func(t *testing.T) domain.PickupSessionRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLitePickupSessionRepository(db)
+	})
+}
+
+func TestSQLitePackageArchiveRepository_Conformance(t *testing.T) {
+	repositorytest.RunPackageArchiveRepositorySuite(t, func(t *testing.T) (domain.PackageRepository, domain.PackageArchiveRepository) {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLitePackageRepository(db), repository.NewSQLitePackageArchiveRepository(db)
+	})
+}
+
+func TestSQLiteJobRunRepository_Conformance(t *testing.T) {
+	repositorytest.RunJobRunRepositorySuite(t, func(t *testing.T) domain.JobRunRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLiteJobRunRepository(db)
+	})
+}
+
+func TestSQLiteJobSettingsRepository_Conformance(t *testing.T) {
+	repositorytest.RunJobSettingsRepositorySuite(t, func(t *testing.T) domain.JobSettingsRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLiteJobSettingsRepository(db)
+	})
+}
+
+func TestSQLiteShipmentRepository_Conformance(t *testing.T) {
+	repositorytest.RunShipmentRepositorySuite(t, func(t *testing.T) (domain.ShipmentRepository, domain.PackageRepository) {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLiteShipmentRepository(db), repository.NewSQLitePackageRepository(db)
+	})
+}
+
+func TestSQLiteAppointmentRepository_Conformance(t *testing.T) {
+	repositorytest.RunAppointmentRepositorySuite(t, func(t *testing.T) domain.AppointmentRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLiteAppointmentRepository(db)
+	})
+}
+
+func TestSQLiteSLABreachRepository_Conformance(t *testing.T) {
+	repositorytest.RunSLABreachRepositorySuite(t, func(t *testing.T) domain.SLABreachRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLiteSLABreachRepository(db)
+	})
+}
+
+func TestSQLitePickupReminderRepository_Conformance(t *testing.T) {
+	repositorytest.RunPickupReminderRepositorySuite(t, func(t *testing.T) domain.PickupReminderRepository {
+		db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return repository.NewSQLitePickupReminderRepository(db)
+	})
+}
+
+func TestSQLiteUnitOfWork_Do_EdgeCase_RollsBack(t *testing.T) {
+	// Setup
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewSQLitePackageRepository(db)
+	uow := repository.NewSQLiteUnitOfWork(db)
+	pkg := repositorytest.NewPackage("ABC-001", time.Now())
+
+	// Execute
+	err = uow.Do(domain.TxOptions{Isolation: domain.IsolationSerializable}, func(tx domain.Tx) error {
+		if err := tx.Packages().Create(pkg); err != nil {
+			return err
+		}
+		return tx.Packages().Create(repositorytest.NewPackage("ABC-001", time.Now()))
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrDuplicateOrderRef)
+	got, err := repo.GetByID(pkg.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSQLiteMigrate_IsIdempotent(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "pickup.db")
+	db, err := database.NewSQLiteConnection(path, sqlitemigrations.FS)
+	require.NoError(t, err)
+	db.Close()
+
+	// Execute - reopening must not re-run applied migrations
+	db, err = database.NewSQLiteConnection(path, sqlitemigrations.FS)
+
+	// Assert
+	require.NoError(t, err)
+	db.Close()
+}
+
+func TestSQLiteUnitOfWork_Do_HappyPath_DriverAssignments(t *testing.T) {
+	// Setup
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+	require.NoError(t, err)
+	defer db.Close()
+
+	uow := repository.NewSQLiteUnitOfWork(db)
+	history := repository.NewSQLiteDriverAssignmentRepository(db)
+	pkg := repositorytest.NewPackage("ABC-001", time.Now())
+	require.NoError(t, repository.NewSQLitePackageRepository(db).Create(pkg))
+
+	// Execute
+	err = uow.Do(domain.TxOptions{}, func(tx domain.Tx) error {
+		pkg.DriverCode = "DRV-002"
+		if err := tx.Packages().Update(pkg); err != nil {
+			return err
+		}
+		return tx.DriverAssignments().Create(&domain.DriverAssignment{
+			ID:         uuid.New(),
+			PackageID:  pkg.ID,
+			FromDriver: "DRV-001",
+			ToDriver:   "DRV-002",
+			Reason:     "route change",
+			ChangedBy:  "alice",
+			ChangedAt:  time.Now(),
+		})
+	})
+
+	// Assert
+	require.NoError(t, err)
+	entries, err := history.ListByPackage(pkg.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "DRV-002", entries[0].ToDriver)
+	assert.Equal(t, "alice", entries[0].ChangedBy)
+}
+
+func TestSQLitePackageArchiveRepository_ArchiveBefore_HappyPath_KeepsDriverHistory(t *testing.T) {
+	// Setup
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+	require.NoError(t, err)
+	defer db.Close()
+
+	packages := repository.NewSQLitePackageRepository(db)
+	history := repository.NewSQLiteDriverAssignmentRepository(db)
+	pkg := repositorytest.NewPackage("ABC-001", time.Now().Add(-2*time.Hour))
+	require.NoError(t, packages.Create(pkg))
+	require.NoError(t, history.Create(&domain.DriverAssignment{
+		ID:         uuid.New(),
+		PackageID:  pkg.ID,
+		FromDriver: "DRV-001",
+		ToDriver:   "DRV-002",
+		Reason:     "route change",
+		ChangedBy:  "alice",
+		ChangedAt:  time.Now(),
+	}))
+	pkg.Status = domain.StatusDisposed
+	pkg.UpdatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, packages.Update(pkg))
+
+	// Execute
+	moved, err := repository.NewSQLitePackageArchiveRepository(db).ArchiveBefore(context.Background(), time.Now(), 100)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), moved)
+	entries, err := history.ListByPackage(pkg.ID)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
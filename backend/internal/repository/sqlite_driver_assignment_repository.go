@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLiteDriverAssignmentRepository struct {
+	db dbtx
+}
+
+func NewSQLiteDriverAssignmentRepository(db *sql.DB) domain.DriverAssignmentRepository {
+	return &SQLiteDriverAssignmentRepository{db: db}
+}
+
+func (sr *SQLiteDriverAssignmentRepository) Create(assignment *domain.DriverAssignment) error {
+	query := `
+		INSERT INTO driver_assignments (id, package_id, from_driver, to_driver, reason, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{
+		assignment.ID.String(),
+		assignment.PackageID.String(),
+		assignment.FromDriver,
+		assignment.ToDriver,
+		assignment.Reason,
+		assignment.ChangedBy,
+		formatSQLiteTime(assignment.ChangedAt),
+	}
+
+	startTime := time.Now()
+	_, err := sr.db.Exec(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (sr *SQLiteDriverAssignmentRepository) ListByPackage(packageID uuid.UUID) ([]*domain.DriverAssignment, error) {
+	query := `
+		SELECT id, package_id, from_driver, to_driver, reason, changed_by, changed_at
+		FROM driver_assignments
+		WHERE package_id = ?
+		ORDER BY changed_at, id`
+	args := []interface{}{packageID.String()}
+
+	startTime := time.Now()
+	rows, err := sr.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+
+	database.LogQuery(query, args, startTime)
+
+	history := []*domain.DriverAssignment{}
+	for rows.Next() {
+		var a domain.DriverAssignment
+		var id, pkgID, changedAt string
+		if err := rows.Scan(&id, &pkgID, &a.FromDriver, &a.ToDriver, &a.Reason, &a.ChangedBy, &changedAt); err != nil {
+			return nil, err
+		}
+		if a.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if a.PackageID, err = uuid.Parse(pkgID); err != nil {
+			return nil, err
+		}
+		if a.ChangedAt, err = time.Parse(sqliteTimeFormat, changedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, &a)
+	}
+
+	return history, rows.Err()
+}
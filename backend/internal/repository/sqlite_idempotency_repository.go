@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+)
+
+type SQLiteIdempotencyRepository struct {
+	db dbtx
+}
+
+func NewSQLiteIdempotencyRepository(db *sql.DB) domain.IdempotencyRepository {
+	return &SQLiteIdempotencyRepository{db: db}
+}
+
+func (sr *SQLiteIdempotencyRepository) Reserve(record *domain.IdempotencyRecord) error {
+	query := `
+		INSERT INTO idempotency_keys (key, request_hash, status_code, created_at, expires_at)
+		VALUES (?, ?, 0, ?, ?)
+		ON CONFLICT (key) DO NOTHING`
+	args := []interface{}{record.Key, record.RequestHash, formatSQLiteTime(record.CreatedAt), formatSQLiteTime(record.ExpiresAt)}
+
+	startTime := time.Now()
+	result, err := sr.db.Exec(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func (sr *SQLiteIdempotencyRepository) Get(key string) (*domain.IdempotencyRecord, error) {
+	query := `
+		SELECT key, request_hash, status_code, content_type, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = ?`
+	args := []interface{}{key}
+
+	var record domain.IdempotencyRecord
+	var createdAt, expiresAt string
+	startTime := time.Now()
+	err := sr.db.QueryRow(query, args...).Scan(
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.ResponseBody,
+		&createdAt,
+		&expiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	if record.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAt); err != nil {
+		return nil, err
+	}
+	if record.ExpiresAt, err = time.Parse(sqliteTimeFormat, expiresAt); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (sr *SQLiteIdempotencyRepository) Complete(key string, statusCode int, contentType string, body []byte, expiresAt time.Time) error {
+	query := `UPDATE idempotency_keys SET status_code = ?, content_type = ?, response_body = ?, expires_at = ? WHERE key = ?`
+	return sr.exec(query, statusCode, contentType, body, formatSQLiteTime(expiresAt), key)
+}
+
+func (sr *SQLiteIdempotencyRepository) Delete(key string) error {
+	return sr.exec(`DELETE FROM idempotency_keys WHERE key = ?`, key)
+}
+
+func (sr *SQLiteIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < ?`
+	args := []interface{}{formatSQLiteTime(now)}
+
+	startTime := time.Now()
+	result, err := sr.db.Exec(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return 0, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	return result.RowsAffected()
+}
+
+func (sr *SQLiteIdempotencyRepository) exec(query string, args ...interface{}) error {
+	startTime := time.Now()
+	_, err := sr.db.Exec(query, args...)
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+
+	return err
+}
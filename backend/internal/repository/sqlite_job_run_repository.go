@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLiteJobRunRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteJobRunRepository(db *sql.DB) domain.JobRunRepository {
+	return &SQLiteJobRunRepository{db: db}
+}
+
+func (jr *SQLiteJobRunRepository) Create(run *domain.JobRun) error {
+	query := `
+		INSERT INTO job_runs (id, job, trigger, status, started_at)
+		VALUES (?, ?, ?, ?, ?)`
+	return jr.exec(query, run.ID.String(), run.Job, run.Trigger, run.Status, formatSQLiteTime(run.StartedAt))
+}
+
+func (jr *SQLiteJobRunRepository) Finish(run *domain.JobRun) error {
+	query := `UPDATE job_runs SET status = ?, finished_at = ?, result = ?, error = ? WHERE id = ?`
+	return jr.exec(query, run.Status, formatSQLiteTimePtr(run.FinishedAt), run.Result, run.Error, run.ID.String())
+}
+
+func (jr *SQLiteJobRunRepository) ListByJob(job string, limit int) ([]*domain.JobRun, error) {
+	query := `
+		SELECT id, job, trigger, status, started_at, finished_at, result, error
+		FROM job_runs
+		WHERE job = ?
+		ORDER BY started_at DESC, id
+		LIMIT ?`
+	args := []interface{}{job, limit}
+
+	startTime := time.Now()
+	rows, err := jr.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+
+	database.LogQuery(query, args, startTime)
+
+	runs := []*domain.JobRun{}
+	for rows.Next() {
+		var run domain.JobRun
+		var id, startedAt string
+		var finishedAt sql.NullString
+		if err := rows.Scan(&id, &run.Job, &run.Trigger, &run.Status, &startedAt, &finishedAt, &run.Result, &run.Error); err != nil {
+			return nil, err
+		}
+		if run.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if run.StartedAt, err = time.Parse(sqliteTimeFormat, startedAt); err != nil {
+			return nil, err
+		}
+		if run.FinishedAt, err = parseSQLiteTimePtr(finishedAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (jr *SQLiteJobRunRepository) exec(query string, args ...interface{}) error {
+	startTime := time.Now()
+	_, err := jr.db.Exec(query, args...)
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+
+	return err
+}
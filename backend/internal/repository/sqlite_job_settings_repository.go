@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+)
+
+type SQLiteJobSettingsRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteJobSettingsRepository(db *sql.DB) domain.JobSettingsRepository {
+	return &SQLiteJobSettingsRepository{db: db}
+}
+
+func (jr *SQLiteJobSettingsRepository) SetPaused(job string, paused bool) error {
+	query := `
+		INSERT INTO job_settings (job, paused, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (job) DO UPDATE SET paused = excluded.paused, updated_at = excluded.updated_at`
+	args := []interface{}{job, paused, formatSQLiteTime(time.Now())}
+
+	startTime := time.Now()
+	_, err := jr.db.Exec(query, args...)
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+	return err
+}
+
+func (jr *SQLiteJobSettingsRepository) Paused(job string) (bool, error) {
+	query := `SELECT paused FROM job_settings WHERE job = ?`
+	args := []interface{}{job}
+
+	startTime := time.Now()
+	var paused bool
+	err := jr.db.QueryRow(query, args...).Scan(&paused)
+
+	if err != nil && err != sql.ErrNoRows {
+		database.LogQueryError(query, args, err, startTime)
+		return false, err
+	}
+	database.LogQuery(query, args, startTime)
+	return paused, nil
+}
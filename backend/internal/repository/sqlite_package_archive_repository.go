@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLitePackageArchiveRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePackageArchiveRepository(db *sql.DB) domain.PackageArchiveRepository {
+	return &SQLitePackageArchiveRepository{db: db}
+}
+
+// ArchiveBefore copies one batch into the archive and deletes it from
+// packages in the same transaction
+func (ar *SQLitePackageArchiveRepository) ArchiveBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	archivedAt := formatSQLiteTime(time.Now())
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(domain.ArchivableStatuses)), ", ")
+	copyQuery := `
+		INSERT INTO packages_archive (` + archiveColumns + `, archived_at)
+		SELECT ` + archiveColumns + `, ? FROM packages
+		WHERE status IN (` + placeholders + `) AND updated_at < ? AND deleted_at IS NULL
+		ORDER BY updated_at
+		LIMIT ?`
+	deleteQuery := `DELETE FROM packages WHERE id IN (SELECT id FROM packages_archive WHERE archived_at = ?)`
+
+	args := []interface{}{archivedAt}
+	for _, status := range domain.ArchivableStatuses {
+		args = append(args, status)
+	}
+	args = append(args, formatSQLiteTime(cutoff), limit)
+
+	startTime := time.Now()
+	var moved int64
+	err := func() error {
+		tx, err := ar.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		result, err := tx.ExecContext(ctx, copyQuery, args...)
+		if err != nil {
+			return err
+		}
+		if moved, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, deleteQuery, archivedAt); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}()
+
+	if err != nil {
+		database.LogQueryError(copyQuery, args, err, startTime)
+		return 0, err
+	}
+	database.LogQuery(copyQuery, args, startTime)
+	return moved, nil
+}
+
+func (ar *SQLitePackageArchiveRepository) GetByID(id uuid.UUID) (*domain.Package, error) {
+	query := `SELECT ` + archiveColumns + `, archived_at FROM packages_archive WHERE id = ?`
+	return ar.getOne(query, id.String())
+}
+
+func (ar *SQLitePackageArchiveRepository) GetByOrderRef(orderRef string) (*domain.Package, error) {
+	query := `SELECT ` + archiveColumns + `, archived_at FROM packages_archive WHERE order_ref = ?`
+	return ar.getOne(query, orderRef)
+}
+
+// Rehydrate returns domain.ErrDuplicateOrderRef if a live package has taken
+// the order reference in the meantime
+func (ar *SQLitePackageArchiveRepository) Rehydrate(id uuid.UUID) error {
+	copyQuery := `
+		INSERT INTO packages (` + archiveColumns + `)
+		SELECT ` + archiveColumns + ` FROM packages_archive WHERE id = ?`
+	deleteQuery := `DELETE FROM packages_archive WHERE id = ?`
+	args := []interface{}{id.String()}
+
+	startTime := time.Now()
+	err := func() error {
+		tx, err := ar.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(copyQuery, args...); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(deleteQuery, args...); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}()
+
+	if err != nil {
+		database.LogQueryError(copyQuery, args, err, startTime)
+		if isSQLiteUniqueViolation(err) {
+			return domain.ErrDuplicateOrderRef
+		}
+		return err
+	}
+	database.LogQuery(copyQuery, args, startTime)
+	return nil
+}
+
+func (ar *SQLitePackageArchiveRepository) getOne(query string, args ...interface{}) (*domain.Package, error) {
+	var archivedAt string
+
+	startTime := time.Now()
+	pkg, err := scanSQLitePackage(archivedRow{ar.db.QueryRow(query, args...), &archivedAt})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	t, err := time.Parse(sqliteTimeFormat, archivedAt)
+	if err != nil {
+		return nil, err
+	}
+	pkg.ArchivedAt = &t
+	return pkg, nil
+}
+
+// archivedRow lets scanPackage and scanSQLitePackage read an archive row by
+// scanning the trailing archived_at column into extra
+type archivedRow struct {
+	row   rowScanner
+	extra interface{}
+}
+
+func (r archivedRow) Scan(dest ...interface{}) error {
+	return r.row.Scan(append(dest, r.extra)...)
+}
@@ -0,0 +1,524 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTimeFormat is fixed width so that text comparison orders chronologically
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// sqlitePackageColumns matches packageColumns; the schemas share column names
+const sqlitePackageColumns = packageColumns
+
+// SQLitePackageRepository implements domain.PackageRepository on SQLite for
+// single-counter deployments
+type SQLitePackageRepository struct {
+	db dbtx
+}
+
+func NewSQLitePackageRepository(db *sql.DB) domain.PackageRepository {
+	return &SQLitePackageRepository{db: db}
+}
+
+func (sr *SQLitePackageRepository) Create(pkg *domain.Package) error {
+	query := `
+		INSERT INTO packages (id, order_ref, driver_code, status, created_at, updated_at, recipient_phone, carrier,
+		                      length_cm, width_cm, height_cm, weight_grams, size_class,
+		                      declared_value, fragile, temperature, parcel_count, shipment_id, parcel_number,
+		                      slot_location, priority)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	args := []interface{}{
+		pkg.ID.String(),
+		pkg.OrderRef,
+		pkg.DriverCode,
+		pkg.Status,
+		formatSQLiteTime(pkg.CreatedAt),
+		formatSQLiteTime(pkg.UpdatedAt),
+		nullString(pkg.RecipientPhone),
+		nullString(pkg.Carrier),
+		nullInt(pkg.LengthCm),
+		nullInt(pkg.WidthCm),
+		nullInt(pkg.HeightCm),
+		nullInt(pkg.WeightGrams),
+		pkg.SizeClass,
+		nullInt64(pkg.DeclaredValue),
+		pkg.Fragile,
+		pkg.Temperature,
+		pkg.ParcelCount,
+		nullUUID(pkg.ShipmentID),
+		nullPositive(pkg.ParcelNumber),
+		nullString(pkg.SlotLocation),
+		pkg.Priority,
+	}
+
+	startTime := time.Now()
+	_, err := sr.db.Exec(query, args...)
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		if isSQLiteUniqueViolation(err) {
+			return domain.ErrDuplicateOrderRef
+		}
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+
+	return err
+}
+
+func (sr *SQLitePackageRepository) GetByID(id uuid.UUID) (*domain.Package, error) {
+	query := `SELECT ` + sqlitePackageColumns + ` FROM packages WHERE id = ? AND deleted_at IS NULL`
+	return sr.getOne(query, id.String())
+}
+
+func (sr *SQLitePackageRepository) GetByOrderRef(orderRef string) (*domain.Package, error) {
+	query := `SELECT ` + sqlitePackageColumns + ` FROM packages WHERE order_ref = ? AND deleted_at IS NULL`
+	return sr.getOne(query, orderRef)
+}
+
+func (sr *SQLitePackageRepository) GetAll(filter domain.PackageFilter) ([]*domain.Package, error) {
+	query := `SELECT ` + sqlitePackageColumns + ` FROM packages`
+
+	var args []interface{}
+	conditions := []string{"deleted_at IS NULL"}
+	if filter.Status != nil {
+		conditions = append(conditions, "status = ?")
+		args = append(args, *filter.Status)
+	}
+	if filter.DriverCode != "" {
+		conditions = append(conditions, "driver_code = ?")
+		args = append(args, filter.DriverCode)
+	}
+	if filter.SizeClass != "" {
+		conditions = append(conditions, "size_class = ?")
+		args = append(args, filter.SizeClass)
+	}
+	if filter.Fragile != nil {
+		conditions = append(conditions, "fragile = ?")
+		args = append(args, *filter.Fragile)
+	}
+	if filter.Temperature != "" {
+		conditions = append(conditions, "temperature = ?")
+		args = append(args, filter.Temperature)
+	}
+	if filter.MinDeclaredValue != nil {
+		conditions = append(conditions, "declared_value >= ?")
+		args = append(args, *filter.MinDeclaredValue)
+	}
+	if filter.ShipmentID != nil {
+		conditions = append(conditions, "shipment_id = ?")
+		args = append(args, filter.ShipmentID.String())
+	}
+	if filter.Priority != "" {
+		conditions = append(conditions, "priority = ?")
+		args = append(args, filter.Priority)
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ")
+
+	// SQLite needs a LIMIT before OFFSET; -1 means no limit
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	query += " ORDER BY " + priorityRank + ", created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, filter.Offset)
+
+	return sr.getMany(query, args...)
+}
+
+func (sr *SQLitePackageRepository) Update(pkg *domain.Package) error {
+	query := `
+		UPDATE packages
+		SET order_ref = ?, driver_code = ?, status = ?, updated_at = ?,
+		    picked_up_at = ?, handed_over_at = ?, expired_at = ?, recipient_phone = ?,
+		    carrier = ?, length_cm = ?, width_cm = ?, height_cm = ?, weight_grams = ?,
+		    size_class = ?, declared_value = ?, fragile = ?, temperature = ?, parcel_count = ?,
+		    shipment_id = ?, parcel_number = ?, slot_location = ?, priority = ?,
+		    return_pending_at = ?, returned_at = ?, disposed_at = ?
+		WHERE id = ? AND deleted_at IS NULL`
+
+	args := []interface{}{
+		pkg.OrderRef,
+		pkg.DriverCode,
+		pkg.Status,
+		formatSQLiteTime(pkg.UpdatedAt),
+		formatSQLiteTimePtr(pkg.PickedUpAt),
+		formatSQLiteTimePtr(pkg.HandedOverAt),
+		formatSQLiteTimePtr(pkg.ExpiredAt),
+		nullString(pkg.RecipientPhone),
+		nullString(pkg.Carrier),
+		nullInt(pkg.LengthCm),
+		nullInt(pkg.WidthCm),
+		nullInt(pkg.HeightCm),
+		nullInt(pkg.WeightGrams),
+		pkg.SizeClass,
+		nullInt64(pkg.DeclaredValue),
+		pkg.Fragile,
+		pkg.Temperature,
+		pkg.ParcelCount,
+		nullUUID(pkg.ShipmentID),
+		nullPositive(pkg.ParcelNumber),
+		nullString(pkg.SlotLocation),
+		pkg.Priority,
+		formatSQLiteTimePtr(pkg.ReturnPendingAt),
+		formatSQLiteTimePtr(pkg.ReturnedAt),
+		formatSQLiteTimePtr(pkg.DisposedAt),
+		pkg.ID.String(),
+	}
+
+	err := sr.exec(query, args...)
+	if isSQLiteUniqueViolation(err) {
+		return domain.ErrDuplicateOrderRef
+	}
+	return err
+}
+
+func (sr *SQLitePackageRepository) Delete(id uuid.UUID) error {
+	return sr.exec(`UPDATE packages SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, formatSQLiteTime(time.Now()), id.String())
+}
+
+func (sr *SQLitePackageRepository) Restore(id uuid.UUID) error {
+	return sr.exec(`UPDATE packages SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL`, formatSQLiteTime(time.Now()), id.String())
+}
+
+// PurgeDeleted hard-deletes packages soft-deleted before the cutoff together
+// with their driver assignment history
+func (sr *SQLitePackageRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	historyQuery := `
+		DELETE FROM driver_assignments
+		WHERE package_id IN (SELECT id FROM packages WHERE deleted_at IS NOT NULL AND deleted_at < ?)`
+	query := `DELETE FROM packages WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+	args := []interface{}{formatSQLiteTime(cutoff)}
+
+	if err := sr.execContext(ctx, historyQuery, args...); err != nil {
+		return 0, err
+	}
+
+	startTime := time.Now()
+	result, err := sr.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return 0, err
+	}
+	database.LogQuery(query, args, startTime)
+	return result.RowsAffected()
+}
+
+// GetExpiredPackages returns active packages created before the cutoff time
+func (sr *SQLitePackageRepository) GetExpiredPackages(ctx context.Context, cutoffTime time.Time) ([]*domain.Package, error) {
+	query := `SELECT ` + sqlitePackageColumns + ` FROM packages WHERE status IN (?, ?) AND created_at < ? AND deleted_at IS NULL`
+	return sr.getManyContext(ctx, query, domain.StatusWaiting, domain.StatusPicked, formatSQLiteTime(cutoffTime))
+}
+
+// GetExpiredBefore returns EXPIRED packages that expired before the cutoff
+// time; idx_packages_expired_at covers the query
+func (sr *SQLitePackageRepository) GetExpiredBefore(ctx context.Context, cutoffTime time.Time) ([]*domain.Package, error) {
+	query := `SELECT ` + sqlitePackageColumns + ` FROM packages WHERE status = ? AND expired_at < ? AND deleted_at IS NULL`
+	return sr.getManyContext(ctx, query, domain.StatusExpired, formatSQLiteTime(cutoffTime))
+}
+
+func (sr *SQLitePackageRepository) UpdateStatus(id uuid.UUID, status domain.PackageStatus) error {
+	now := formatSQLiteTime(time.Now())
+
+	switch status {
+	case domain.StatusPicked:
+		return sr.exec(`UPDATE packages SET status = ?, updated_at = ?, picked_up_at = ? WHERE id = ? AND deleted_at IS NULL`, status, now, now, id.String())
+	case domain.StatusHandedOver:
+		return sr.exec(`UPDATE packages SET status = ?, updated_at = ?, handed_over_at = ? WHERE id = ? AND deleted_at IS NULL`, status, now, now, id.String())
+	case domain.StatusExpired:
+		return sr.exec(`UPDATE packages SET status = ?, updated_at = ?, expired_at = ? WHERE id = ? AND deleted_at IS NULL`, status, now, now, id.String())
+	case domain.StatusReturnPending:
+		return sr.exec(`UPDATE packages SET status = ?, updated_at = ?, return_pending_at = ? WHERE id = ? AND deleted_at IS NULL`, status, now, now, id.String())
+	case domain.StatusReturned:
+		return sr.exec(`UPDATE packages SET status = ?, updated_at = ?, returned_at = ? WHERE id = ? AND deleted_at IS NULL`, status, now, now, id.String())
+	case domain.StatusDisposed:
+		return sr.exec(`UPDATE packages SET status = ?, updated_at = ?, disposed_at = ? WHERE id = ? AND deleted_at IS NULL`, status, now, now, id.String())
+	default:
+		return sr.exec(`UPDATE packages SET status = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL`, status, now, id.String())
+	}
+}
+
+func (sr *SQLitePackageRepository) GetPackageStats() (*domain.PackageStats, error) {
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0)
+		FROM packages
+		WHERE deleted_at IS NULL`
+	args := []interface{}{
+		domain.StatusWaiting, domain.StatusPicked, domain.StatusHandedOver, domain.StatusExpired,
+		domain.StatusReturnPending, domain.StatusReturned, domain.StatusDisposed,
+	}
+
+	var stats domain.PackageStats
+	startTime := time.Now()
+	err := sr.db.QueryRow(query, args...).Scan(&stats.Total, &stats.Waiting, &stats.Picked, &stats.HandedOver, &stats.Expired,
+		&stats.ReturnPending, &stats.Returned, &stats.Disposed)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	stats.BySizeClass, err = countBySizeClass(sr.db, `SELECT size_class, COUNT(*) FROM packages WHERE deleted_at IS NULL GROUP BY size_class`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func (sr *SQLitePackageRepository) WaitingPositions(ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	positions := make(map[uuid.UUID]int, len(ids))
+	if len(ids) == 0 {
+		return positions, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	query := `
+		SELECT id, position FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY ` + priorityRank + `, created_at, id) AS position
+			FROM packages
+			WHERE status = ? AND deleted_at IS NULL
+		) queue
+		WHERE id IN (` + placeholders + `)`
+	args := []interface{}{domain.StatusWaiting}
+	for _, id := range ids {
+		args = append(args, id.String())
+	}
+
+	startTime := time.Now()
+	rows, err := sr.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	for rows.Next() {
+		var id string
+		var position int
+		if err := rows.Scan(&id, &position); err != nil {
+			return nil, err
+		}
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		positions[parsed] = position
+	}
+	return positions, rows.Err()
+}
+
+func (sr *SQLitePackageRepository) RecentPickupTimes(limit int) ([]time.Time, error) {
+	query := `
+		SELECT picked_up_at FROM packages
+		WHERE picked_up_at IS NOT NULL AND deleted_at IS NULL
+		ORDER BY picked_up_at DESC
+		LIMIT ?`
+	args := []interface{}{limit}
+
+	startTime := time.Now()
+	rows, err := sr.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	times := []time.Time{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(sqliteTimeFormat, value)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	return times, rows.Err()
+}
+
+func (sr *SQLitePackageRepository) exec(query string, args ...interface{}) error {
+	return sr.execContext(context.Background(), query, args...)
+}
+
+func (sr *SQLitePackageRepository) execContext(ctx context.Context, query string, args ...interface{}) error {
+	startTime := time.Now()
+	_, err := sr.db.ExecContext(ctx, query, args...)
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+
+	return err
+}
+
+func (sr *SQLitePackageRepository) getOne(query string, args ...interface{}) (*domain.Package, error) {
+	startTime := time.Now()
+	pkg, err := scanSQLitePackage(sr.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+
+	database.LogQuery(query, args, startTime)
+	return pkg, nil
+}
+
+func (sr *SQLitePackageRepository) getMany(query string, args ...interface{}) ([]*domain.Package, error) {
+	return sr.getManyContext(context.Background(), query, args...)
+}
+
+func (sr *SQLitePackageRepository) getManyContext(ctx context.Context, query string, args ...interface{}) ([]*domain.Package, error) {
+	startTime := time.Now()
+	rows, err := sr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+
+	database.LogQuery(query, args, startTime)
+
+	var packages []*domain.Package
+	for rows.Next() {
+		pkg, err := scanSQLitePackage(rows)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSQLitePackage(row rowScanner) (*domain.Package, error) {
+	var pkg domain.Package
+	var id, createdAt, updatedAt string
+	var pickedUpAt, handedOverAt, expiredAt, returnPendingAt, returnedAt, disposedAt sql.NullString
+	var recipientPhone, carrier, slotLocation sql.NullString
+	var lengthCm, widthCm, heightCm, weightGrams, declaredValue, parcelNumber sql.NullInt64
+	var shipmentID uuid.NullUUID
+
+	err := row.Scan(
+		&id,
+		&pkg.OrderRef,
+		&pkg.DriverCode,
+		&pkg.Status,
+		&createdAt,
+		&updatedAt,
+		&pickedUpAt,
+		&handedOverAt,
+		&expiredAt,
+		&recipientPhone,
+		&carrier,
+		&lengthCm,
+		&widthCm,
+		&heightCm,
+		&weightGrams,
+		&pkg.SizeClass,
+		&declaredValue,
+		&pkg.Fragile,
+		&pkg.Temperature,
+		&pkg.ParcelCount,
+		&shipmentID,
+		&parcelNumber,
+		&slotLocation,
+		&pkg.Priority,
+		&returnPendingAt,
+		&returnedAt,
+		&disposedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	pkg.RecipientPhone = recipientPhone.String
+	pkg.Carrier = carrier.String
+	pkg.SlotLocation = slotLocation.String
+	scanAttributes(&pkg, lengthCm, widthCm, heightCm, weightGrams, declaredValue)
+	if shipmentID.Valid {
+		pkg.ShipmentID = &shipmentID.UUID
+	}
+	pkg.ParcelNumber = int(parcelNumber.Int64)
+
+	if pkg.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if pkg.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAt); err != nil {
+		return nil, err
+	}
+	if pkg.UpdatedAt, err = time.Parse(sqliteTimeFormat, updatedAt); err != nil {
+		return nil, err
+	}
+
+	// Handle nullable time fields
+	for _, field := range []struct {
+		src sql.NullString
+		dst **time.Time
+	}{
+		{pickedUpAt, &pkg.PickedUpAt},
+		{handedOverAt, &pkg.HandedOverAt},
+		{expiredAt, &pkg.ExpiredAt},
+		{returnPendingAt, &pkg.ReturnPendingAt},
+		{returnedAt, &pkg.ReturnedAt},
+		{disposedAt, &pkg.DisposedAt},
+	} {
+		if !field.src.Valid {
+			continue
+		}
+		t, err := time.Parse(sqliteTimeFormat, field.src.String)
+		if err != nil {
+			return nil, err
+		}
+		*field.dst = &t
+	}
+
+	return &pkg, nil
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+func formatSQLiteTimePtr(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatSQLiteTime(*t)
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
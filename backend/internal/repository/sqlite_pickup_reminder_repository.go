@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLitePickupReminderRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePickupReminderRepository(db *sql.DB) domain.PickupReminderRepository {
+	return &SQLitePickupReminderRepository{db: db}
+}
+
+func (rr *SQLitePickupReminderRepository) Get(packageID uuid.UUID) (*domain.PickupReminder, error) {
+	query := `SELECT reminder_count, last_sent_at FROM pickup_reminders WHERE package_id = ?`
+	args := []interface{}{packageID.String()}
+
+	startTime := time.Now()
+	reminder := domain.PickupReminder{PackageID: packageID}
+	var lastSentAt string
+	err := rr.db.QueryRow(query, args...).Scan(&reminder.Count, &lastSentAt)
+	if err == sql.ErrNoRows {
+		database.LogQuery(query, args, startTime)
+		return nil, nil
+	}
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+	if reminder.LastSentAt, err = time.Parse(sqliteTimeFormat, lastSentAt); err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (rr *SQLitePickupReminderRepository) Record(packageID uuid.UUID, sentAt time.Time) error {
+	query := `
+		INSERT INTO pickup_reminders (package_id, reminder_count, last_sent_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (package_id) DO UPDATE SET
+			reminder_count = pickup_reminders.reminder_count + 1,
+			last_sent_at = excluded.last_sent_at`
+	args := []interface{}{packageID.String(), formatSQLiteTime(sentAt)}
+
+	startTime := time.Now()
+	_, err := rr.db.Exec(query, args...)
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+	return err
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLitePickupSessionRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePickupSessionRepository(db *sql.DB) domain.PickupSessionRepository {
+	return &SQLitePickupSessionRepository{db: db}
+}
+
+// Create inserts the session and its expected items in one transaction
+func (sr *SQLitePickupSessionRepository) Create(session *domain.PickupSession) error {
+	sessionQuery := `
+		INSERT INTO pickup_sessions (id, driver_code, status, opened_at)
+		VALUES (?, ?, ?, ?)`
+	itemQuery := `
+		INSERT INTO pickup_session_items (session_id, order_ref, package_id, expected)
+		VALUES (?, ?, ?, 1)`
+	args := []interface{}{session.ID.String(), session.DriverCode, session.Status, formatSQLiteTime(session.OpenedAt)}
+
+	startTime := time.Now()
+	err := func() error {
+		tx, err := sr.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.Exec(sessionQuery, args...); err != nil {
+			return err
+		}
+		for _, item := range session.Items {
+			if _, err := tx.Exec(itemQuery, session.ID.String(), item.OrderRef, formatSQLiteUUIDPtr(item.PackageID)); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	}()
+
+	if err != nil {
+		database.LogQueryError(sessionQuery, args, err, startTime)
+		if isSQLiteUniqueViolation(err) {
+			return domain.ErrOpenSessionExists
+		}
+		return err
+	}
+	database.LogQuery(sessionQuery, args, startTime)
+	return nil
+}
+
+func (sr *SQLitePickupSessionRepository) GetByID(id uuid.UUID) (*domain.PickupSession, error) {
+	query := `SELECT id, driver_code, status, opened_at, closed_at FROM pickup_sessions WHERE id = ?`
+	return sr.getOne(query, id.String())
+}
+
+func (sr *SQLitePickupSessionRepository) GetOpenByDriver(driverCode string) (*domain.PickupSession, error) {
+	query := `SELECT id, driver_code, status, opened_at, closed_at FROM pickup_sessions WHERE driver_code = ? AND status = ?`
+	return sr.getOne(query, driverCode, domain.SessionOpen)
+}
+
+func (sr *SQLitePickupSessionRepository) ListOpen() ([]*domain.PickupSession, error) {
+	query := `SELECT id FROM pickup_sessions WHERE status = ? ORDER BY opened_at, id`
+	args := []interface{}{domain.SessionOpen}
+
+	startTime := time.Now()
+	rows, err := sr.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	// Read every id before loading items: sqlite holds a single connection
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, parsed)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	return listSessions(ids, sr.GetByID)
+}
+
+func (sr *SQLitePickupSessionRepository) RecordScan(sessionID uuid.UUID, item *domain.PickupSessionItem) error {
+	query := `
+		INSERT INTO pickup_session_items (session_id, order_ref, package_id, expected, result, reason, scanned_at)
+		VALUES (?, ?, ?, 0, ?, ?, ?)
+		ON CONFLICT (session_id, order_ref) DO UPDATE
+		SET package_id = excluded.package_id, result = excluded.result,
+		    reason = excluded.reason, scanned_at = excluded.scanned_at`
+	args := []interface{}{
+		sessionID.String(),
+		item.OrderRef,
+		formatSQLiteUUIDPtr(item.PackageID),
+		item.Result,
+		item.Reason,
+		formatSQLiteTimePtr(item.ScannedAt),
+	}
+	return sr.exec(query, args...)
+}
+
+func (sr *SQLitePickupSessionRepository) Close(id uuid.UUID, closedAt time.Time) error {
+	query := `UPDATE pickup_sessions SET status = ?, closed_at = ? WHERE id = ?`
+	return sr.exec(query, domain.SessionClosed, formatSQLiteTime(closedAt), id.String())
+}
+
+func (sr *SQLitePickupSessionRepository) exec(query string, args ...interface{}) error {
+	startTime := time.Now()
+	_, err := sr.db.Exec(query, args...)
+
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+	} else {
+		database.LogQuery(query, args, startTime)
+	}
+
+	return err
+}
+
+func (sr *SQLitePickupSessionRepository) getOne(query string, args ...interface{}) (*domain.PickupSession, error) {
+	var session domain.PickupSession
+	var id, openedAt string
+	var closedAt sql.NullString
+
+	startTime := time.Now()
+	err := sr.db.QueryRow(query, args...).Scan(&id, &session.DriverCode, &session.Status, &openedAt, &closedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+
+	if session.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if session.OpenedAt, err = time.Parse(sqliteTimeFormat, openedAt); err != nil {
+		return nil, err
+	}
+	if session.ClosedAt, err = parseSQLiteTimePtr(closedAt); err != nil {
+		return nil, err
+	}
+
+	session.Items, err = sr.getItems(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (sr *SQLitePickupSessionRepository) getItems(sessionID uuid.UUID) ([]*domain.PickupSessionItem, error) {
+	query := `
+		SELECT order_ref, package_id, expected, COALESCE(result, ''), COALESCE(reason, ''), scanned_at
+		FROM pickup_session_items
+		WHERE session_id = ?
+		ORDER BY expected DESC, order_ref`
+	args := []interface{}{sessionID.String()}
+
+	startTime := time.Now()
+	rows, err := sr.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+
+	database.LogQuery(query, args, startTime)
+
+	items := []*domain.PickupSessionItem{}
+	for rows.Next() {
+		var item domain.PickupSessionItem
+		var packageID, scannedAt sql.NullString
+
+		if err := rows.Scan(&item.OrderRef, &packageID, &item.Expected, &item.Result, &item.Reason, &scannedAt); err != nil {
+			return nil, err
+		}
+		if packageID.Valid {
+			id, err := uuid.Parse(packageID.String)
+			if err != nil {
+				return nil, err
+			}
+			item.PackageID = &id
+		}
+		if item.ScannedAt, err = parseSQLiteTimePtr(scannedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+func formatSQLiteUUIDPtr(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
+
+func parseSQLiteTimePtr(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(sqliteTimeFormat, value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
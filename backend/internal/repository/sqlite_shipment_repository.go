@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLiteShipmentRepository struct {
+	db dbtx
+}
+
+func NewSQLiteShipmentRepository(db *sql.DB) domain.ShipmentRepository {
+	return &SQLiteShipmentRepository{db: db}
+}
+
+func (sr *SQLiteShipmentRepository) Create(shipment *domain.Shipment) error {
+	query := `
+		INSERT INTO shipments (id, order_ref, driver_code, carrier, parcel_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{
+		shipment.ID.String(),
+		shipment.OrderRef,
+		shipment.DriverCode,
+		nullString(shipment.Carrier),
+		shipment.ParcelCount,
+		formatSQLiteTime(shipment.CreatedAt),
+		formatSQLiteTime(shipment.UpdatedAt),
+	}
+
+	startTime := time.Now()
+	_, err := sr.db.Exec(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		if isSQLiteUniqueViolation(err) {
+			return domain.ErrDuplicateOrderRef
+		}
+		return err
+	}
+	database.LogQuery(query, args, startTime)
+	return nil
+}
+
+func (sr *SQLiteShipmentRepository) GetByID(id uuid.UUID) (*domain.Shipment, error) {
+	return sr.getOne(`SELECT `+shipmentColumns+` FROM shipments WHERE id = ?`, id.String())
+}
+
+func (sr *SQLiteShipmentRepository) GetByOrderRef(orderRef string) (*domain.Shipment, error) {
+	return sr.getOne(`SELECT `+shipmentColumns+` FROM shipments WHERE order_ref = ?`, orderRef)
+}
+
+func (sr *SQLiteShipmentRepository) GetAll(filter domain.ShipmentFilter) ([]*domain.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments`
+	var args []interface{}
+	if filter.DriverCode != "" {
+		query += " WHERE driver_code = ?"
+		args = append(args, filter.DriverCode)
+	}
+
+	// SQLite needs a LIMIT before OFFSET; -1 means no limit
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	query += " ORDER BY created_at DESC, id LIMIT ? OFFSET ?"
+	args = append(args, limit, filter.Offset)
+
+	startTime := time.Now()
+	rows, err := sr.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	shipments := []*domain.Shipment{}
+	for rows.Next() {
+		shipment, err := scanSQLiteShipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, shipment)
+	}
+	return shipments, rows.Err()
+}
+
+func (sr *SQLiteShipmentRepository) getOne(query string, args ...interface{}) (*domain.Shipment, error) {
+	startTime := time.Now()
+	shipment, err := scanSQLiteShipment(sr.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			database.LogQuery(query, args, startTime)
+			return nil, nil
+		}
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	database.LogQuery(query, args, startTime)
+	return shipment, nil
+}
+
+func scanSQLiteShipment(row rowScanner) (*domain.Shipment, error) {
+	var shipment domain.Shipment
+	var id, createdAt, updatedAt string
+	var carrier sql.NullString
+	err := row.Scan(&id, &shipment.OrderRef, &shipment.DriverCode, &carrier, &shipment.ParcelCount, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	shipment.Carrier = carrier.String
+
+	if shipment.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if shipment.CreatedAt, err = time.Parse(sqliteTimeFormat, createdAt); err != nil {
+		return nil, err
+	}
+	if shipment.UpdatedAt, err = time.Parse(sqliteTimeFormat, updatedAt); err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"database/sql"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLiteSLABreachRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSLABreachRepository(db *sql.DB) domain.SLABreachRepository {
+	return &SQLiteSLABreachRepository{db: db}
+}
+
+func (br *SQLiteSLABreachRepository) Record(breach *domain.SLABreach) (bool, error) {
+	query := `
+		INSERT INTO sla_breaches (` + slaBreachColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (package_id) DO NOTHING`
+	args := []interface{}{
+		breach.PackageID.String(),
+		breach.OrderRef,
+		breach.DriverCode,
+		breach.Priority,
+		formatSQLiteTime(breach.DueAt),
+		formatSQLiteTime(breach.DetectedAt),
+	}
+
+	startTime := time.Now()
+	result, err := br.db.Exec(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return false, err
+	}
+	database.LogQuery(query, args, startTime)
+	recorded, err := result.RowsAffected()
+	return recorded > 0, err
+}
+
+func (br *SQLiteSLABreachRepository) List(filter domain.SLABreachFilter) ([]*domain.SLABreach, error) {
+	query := `SELECT ` + slaBreachColumns + ` FROM sla_breaches
+		WHERE detected_at >= ? AND detected_at < ?`
+	args := []interface{}{formatSQLiteTime(filter.From), formatSQLiteTime(filter.To)}
+	if filter.Priority != "" {
+		query += " AND priority = ?"
+		args = append(args, filter.Priority)
+	}
+	query += " ORDER BY detected_at DESC, package_id"
+
+	startTime := time.Now()
+	rows, err := br.db.Query(query, args...)
+	if err != nil {
+		database.LogQueryError(query, args, err, startTime)
+		return nil, err
+	}
+	defer rows.Close()
+	database.LogQuery(query, args, startTime)
+
+	breaches := []*domain.SLABreach{}
+	for rows.Next() {
+		var breach domain.SLABreach
+		var packageID, dueAt, detectedAt string
+		if err := rows.Scan(&packageID, &breach.OrderRef, &breach.DriverCode, &breach.Priority, &dueAt, &detectedAt); err != nil {
+			return nil, err
+		}
+		if breach.PackageID, err = uuid.Parse(packageID); err != nil {
+			return nil, err
+		}
+		if breach.DueAt, err = time.Parse(sqliteTimeFormat, dueAt); err != nil {
+			return nil, err
+		}
+		if breach.DetectedAt, err = time.Parse(sqliteTimeFormat, detectedAt); err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, &breach)
+	}
+	return breaches, rows.Err()
+}
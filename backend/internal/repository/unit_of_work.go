@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/database"
+)
+
+// dbtx is the subset of *sql.DB and *sql.Tx used by the repositories
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// noRetry is used inside transactions: a failed statement aborts the whole
+// transaction, so retrying is left to the unit of work
+var noRetry = database.RetryPolicy{MaxAttempts: 1}
+
+type UnitOfWork struct {
+	db    *sql.DB
+	retry database.RetryPolicy
+
+	// newTx binds the repositories to an open transaction
+	newTx func(tx *sql.Tx) domain.Tx
+	// isolation maps the requested level to one the driver supports
+	isolation func(level domain.IsolationLevel) sql.IsolationLevel
+}
+
+func NewUnitOfWork(db *sql.DB) domain.UnitOfWork {
+	return &UnitOfWork{
+		db:        db,
+		retry:     database.DefaultRetryPolicy,
+		newTx:     func(tx *sql.Tx) domain.Tx { return &sqlTx{tx: tx} },
+		isolation: sqlIsolation,
+	}
+}
+
+// NewSQLiteUnitOfWork creates a unit of work for SQLite, where every
+// transaction is already serializable and the connection is exclusive
+func NewSQLiteUnitOfWork(db *sql.DB) domain.UnitOfWork {
+	return &UnitOfWork{
+		db:        db,
+		retry:     noRetry,
+		newTx:     func(tx *sql.Tx) domain.Tx { return &sqliteTx{tx: tx} },
+		isolation: func(domain.IsolationLevel) sql.IsolationLevel { return sql.LevelDefault },
+	}
+}
+
+// Do runs fn in a database transaction, retrying the whole transaction on
+// serialization failures and deadlocks
+func (u *UnitOfWork) Do(opts domain.TxOptions, fn func(tx domain.Tx) error) error {
+	return u.retry.DoWrite(func() error {
+		return u.run(opts, fn)
+	})
+}
+
+func (u *UnitOfWork) run(opts domain.TxOptions, fn func(tx domain.Tx) error) (err error) {
+	tx, err := u.db.BeginTx(context.Background(), &sql.TxOptions{
+		Isolation: u.isolation(opts.Isolation),
+		ReadOnly:  opts.ReadOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(u.newTx(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func sqlIsolation(level domain.IsolationLevel) sql.IsolationLevel {
+	switch level {
+	case domain.IsolationReadCommitted:
+		return sql.LevelReadCommitted
+	case domain.IsolationRepeatableRead:
+		return sql.LevelRepeatableRead
+	case domain.IsolationSerializable:
+		return sql.LevelSerializable
+	default:
+		return sql.LevelDefault
+	}
+}
+
+type sqlTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqlTx) Packages() domain.PackageRepository {
+	return &PackageRepository{db: t.tx, retry: noRetry}
+}
+
+func (t *sqlTx) DriverAssignments() domain.DriverAssignmentRepository {
+	return &DriverAssignmentRepository{db: t.tx, retry: noRetry}
+}
+
+func (t *sqlTx) Shipments() domain.ShipmentRepository {
+	return &ShipmentRepository{db: t.tx, retry: noRetry}
+}
+
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) Packages() domain.PackageRepository {
+	return &SQLitePackageRepository{db: t.tx}
+}
+
+func (t *sqliteTx) DriverAssignments() domain.DriverAssignmentRepository {
+	return &SQLiteDriverAssignmentRepository{db: t.tx}
+}
+
+func (t *sqliteTx) Shipments() domain.ShipmentRepository {
+	return &SQLiteShipmentRepository{db: t.tx}
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitOfWork_Do_HappyPath_Commits(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	uow := repository.NewUnitOfWork(db)
+	packageID := uuid.New()
+
+	// Mock expectations
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE packages SET status = \\$2").
+		WithArgs(packageID, domain.StatusPicked, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	err = uow.Do(domain.TxOptions{}, func(tx domain.Tx) error {
+		return tx.Packages().UpdateStatus(packageID, domain.StatusPicked)
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnitOfWork_Do_EdgeCase_RollsBackOnError(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	uow := repository.NewUnitOfWork(db)
+	fnErr := errors.New("validation failed")
+
+	// Mock expectations
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	// Execute
+	err = uow.Do(domain.TxOptions{}, func(tx domain.Tx) error {
+		return fnErr
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, fnErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnitOfWork_Do_EdgeCase_RetriesSerializationFailure(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	uow := repository.NewUnitOfWork(db)
+	packageID := uuid.New()
+
+	// Mock expectations - first transaction fails to serialize, second commits
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE packages SET deleted_at").WithArgs(packageID, sqlmock.AnyArg()).WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE packages SET deleted_at").WithArgs(packageID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	attempts := 0
+	err = uow.Do(domain.TxOptions{Isolation: domain.IsolationSerializable}, func(tx domain.Tx) error {
+		attempts++
+		return tx.Packages().Delete(packageID)
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPackageRepository_Create_EdgeCase_UniqueViolation(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPackageRepository(db)
+	pkg := &domain.Package{ID: uuid.New(), OrderRef: "DUP-001", DriverCode: "DRV-001", Status: domain.StatusWaiting, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	// Mock expectations
+	mock.ExpectExec("INSERT INTO packages").WillReturnError(&pq.Error{Code: "23505"})
+
+	// Execute
+	err = repo.Create(pkg)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrDuplicateOrderRef)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,92 @@
+package scheduler_test
+
+import (
+	"pickup-queue/internal/scheduler"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_HappyPath_Next(t *testing.T) {
+	// Setup
+	from := time.Date(2024, time.March, 15, 10, 17, 30, 0, time.UTC) // a Friday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 15, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, time.March, 16, 3, 0, 0, 0, time.UTC)},
+		{"30 9-17 * * 1-5", time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,20 * *", time.Date(2024, time.March, 20, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.spec, func(t *testing.T) {
+			// Execute
+			schedule, err := scheduler.Parse(tc.spec)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestParse_EdgeCase_DayOfMonthOrDayOfWeek(t *testing.T) {
+	// Setup: restricting both day fields matches either one, as in cron
+	schedule, err := scheduler.Parse("0 0 1 * 1")
+	require.NoError(t, err)
+	from := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	// Execute
+	next := schedule.Next(from)
+
+	// Assert: Monday 18 March comes before 1 April
+	assert.Equal(t, time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC), next)
+}
+
+func TestParse_EdgeCase_NeverMatches(t *testing.T) {
+	// Setup
+	schedule, err := scheduler.Parse("0 0 30 2 *")
+	require.NoError(t, err)
+
+	// Execute
+	next := schedule.Next(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	// Assert
+	assert.True(t, next.IsZero())
+}
+
+func TestParse_EdgeCase_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every soon",
+		"@every 10ms",
+		"@fortnightly",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			// Execute
+			_, err := scheduler.Parse(spec)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,512 @@
+// Package scheduler runs the worker's background jobs on cron schedules.
+// Scheduled runs only happen on the leading replica when a leader elector is
+// set. Each job runs at most once at a time per process, only on the replica
+// that wins the job's lock, and every run is recorded in the job run history.
+// Pausing a job is shared with the other replicas through the job settings.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/logger"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownJob   = domain.NewError(domain.KindNotFound, "JOB_NOT_FOUND", "job not found")
+	ErrDuplicateJob = errors.New("job already registered")
+	ErrJobRunning   = domain.NewError(domain.KindConflict, "JOB_RUNNING", "job is already running")
+	ErrNotLeader    = domain.NewError(domain.KindConflict, "JOB_RUNNING_ELSEWHERE", "job is running on another worker replica")
+)
+
+// DefaultTimeout bounds a run when neither the job nor the scheduler sets one
+const DefaultTimeout = 10 * time.Minute
+
+// Job is one unit of background work
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Timeout bounds one run; zero uses the scheduler default
+	Timeout time.Duration
+	// Run does the work and returns a short summary such as "expired 3 packages".
+	// Work that ignores ctx keeps running after a timeout; the run is recorded
+	// as failed and the job cannot start again until it returns.
+	Run func(ctx context.Context) (string, error)
+}
+
+// JobStatus describes a registered job for the admin API
+type JobStatus struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Timeout  string `json:"timeout"`
+	Paused   bool   `json:"paused"`
+	Running  bool   `json:"running"`
+	// NextRun is unset while the job is paused or its schedule never fires
+	NextRun *time.Time     `json:"next_run,omitempty"`
+	LastRun *domain.JobRun `json:"last_run,omitempty"`
+	// LastDuration is how long the last finished run took, such as "1.2s"
+	LastDuration string `json:"last_duration,omitempty"`
+}
+
+// entry is a registered job and its run state
+type entry struct {
+	job     Job
+	running atomic.Bool
+	paused  atomic.Bool
+	wake    chan struct{}
+	// next is the upcoming activation, guarded by Scheduler.mu
+	next time.Time
+}
+
+type Scheduler struct {
+	locker   domain.JobLocker
+	runs     domain.JobRunRepository
+	settings domain.JobSettingsRepository
+	leader   domain.LeaderElector
+	logger   *logger.Logger
+	timeout  atomic.Int64
+
+	mu   sync.RWMutex
+	jobs map[string]*entry
+	// triggered tracks runs started by Trigger so Run can wait for them
+	triggered sync.WaitGroup
+}
+
+func New(locker domain.JobLocker, runs domain.JobRunRepository, log *logger.Logger) *Scheduler {
+	s := &Scheduler{
+		locker: locker,
+		runs:   runs,
+		logger: log,
+		jobs:   make(map[string]*entry),
+	}
+	s.timeout.Store(int64(DefaultTimeout))
+	return s
+}
+
+// WithSettings stores paused jobs in settings, so pausing a job on one
+// replica pauses it on every replica sharing them. Without settings pausing
+// only applies to this process.
+func (s *Scheduler) WithSettings(settings domain.JobSettingsRepository) *Scheduler {
+	s.settings = settings
+	return s
+}
+
+// WithLeader makes scheduled runs happen only while this replica leads, so
+// replicas whose clocks disagree cannot run the same activation twice.
+// Manual runs are not affected. Without a leader every replica runs
+// scheduled jobs, one at a time per job through the job lock.
+func (s *Scheduler) WithLeader(leader domain.LeaderElector) *Scheduler {
+	s.leader = leader
+	return s
+}
+
+// leads reports whether this replica may start scheduled runs
+func (s *Scheduler) leads(ctx context.Context) bool {
+	if s.leader == nil {
+		return true
+	}
+	leader, err := s.leader.IsLeader(ctx)
+	if err != nil {
+		s.logger.Error("Could not check worker leadership:", err)
+		return false
+	}
+	return leader
+}
+
+// SetDefaultTimeout changes the timeout of jobs without their own; it is safe to call at runtime
+func (s *Scheduler) SetDefaultTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.timeout.Store(int64(timeout))
+	}
+}
+
+// Register adds a job. Jobs must be registered before Run.
+func (s *Scheduler) Register(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+	}
+	s.jobs[job.Name] = &entry{job: job, wake: make(chan struct{}, 1)}
+	return nil
+}
+
+// Reschedule changes a job's schedule and timeout; the next activation is
+// recomputed immediately
+func (s *Scheduler) Reschedule(name string, schedule Schedule, timeout time.Duration) error {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	if ok {
+		e.job.Schedule = schedule
+		e.job.Timeout = timeout
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	e.signal()
+	return nil
+}
+
+// Pause stops scheduled runs of a job on every replica sharing the
+// scheduler's settings; manual runs still work
+func (s *Scheduler) Pause(name string) error {
+	e, err := s.entry(name)
+	if err != nil {
+		return err
+	}
+	if s.settings != nil {
+		if err := s.settings.SetPaused(name, true); err != nil {
+			return err
+		}
+	}
+	e.paused.Store(true)
+	return nil
+}
+
+// Resume restarts scheduled runs of a paused job from the next activation
+func (s *Scheduler) Resume(name string) error {
+	e, err := s.entry(name)
+	if err != nil {
+		return err
+	}
+	if s.settings != nil {
+		if err := s.settings.SetPaused(name, false); err != nil {
+			return err
+		}
+	}
+	if e.paused.CompareAndSwap(true, false) {
+		e.signal()
+	}
+	return nil
+}
+
+// isPaused reads the shared pause flag, falling back to the last one seen
+// when the settings cannot be read
+func (s *Scheduler) isPaused(e *entry) bool {
+	if s.settings == nil {
+		return e.paused.Load()
+	}
+	paused, err := s.settings.Paused(e.job.Name)
+	if err != nil {
+		s.logger.Error("Could not read job settings", e.job.Name+":", err)
+		return e.paused.Load()
+	}
+	e.paused.Store(paused)
+	return paused
+}
+
+// Status describes one job, including its latest recorded run
+func (s *Scheduler) Status(name string) (*JobStatus, error) {
+	e, err := s.entry(name)
+	if err != nil {
+		return nil, err
+	}
+
+	paused := s.isPaused(e)
+	s.mu.RLock()
+	status := &JobStatus{
+		Name:     e.job.Name,
+		Schedule: fmt.Sprint(e.job.Schedule),
+		Timeout:  s.timeoutOf(e.job).String(),
+		Paused:   paused,
+		Running:  e.running.Load(),
+	}
+	if !status.Paused && !e.next.IsZero() {
+		next := e.next
+		status.NextRun = &next
+	}
+	s.mu.RUnlock()
+
+	runs, err := s.runs.ListByJob(name, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		status.LastRun = runs[0]
+		if runs[0].FinishedAt != nil {
+			status.LastDuration = runs[0].FinishedAt.Sub(runs[0].StartedAt).Round(time.Millisecond).String()
+		}
+	}
+	return status, nil
+}
+
+// Statuses describes every registered job, ordered by name
+func (s *Scheduler) Statuses() ([]*JobStatus, error) {
+	names := s.Names()
+	statuses := make([]*JobStatus, 0, len(names))
+	for _, name := range names {
+		status, err := s.Status(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Runs returns the latest recorded runs of a job, newest first
+func (s *Scheduler) Runs(name string, limit int) ([]*domain.JobRun, error) {
+	if _, err := s.entry(name); err != nil {
+		return nil, err
+	}
+	return s.runs.ListByJob(name, limit)
+}
+
+// Names returns the registered job names in order
+func (s *Scheduler) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run starts every job on its schedule and blocks until ctx is cancelled and
+// the runs in progress, including triggered ones, have returned or timed
+// out. It then gives up the lead, if this replica held it.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.RLock()
+	entries := make([]*entry, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		entries = append(entries, e)
+	}
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+	s.triggered.Wait()
+	if s.leader != nil {
+		s.leader.Resign()
+	}
+}
+
+// RunNow runs a job immediately, outside its schedule, and waits for it. It
+// returns ErrJobRunning or ErrNotLeader when the run was skipped, including
+// a TriggerSchedule run on a replica that does not lead; a run that fails is
+// returned with status FAILED and a nil error.
+func (s *Scheduler) RunNow(ctx context.Context, name string, trigger domain.JobTrigger) (*domain.JobRun, error) {
+	e, err := s.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, e, trigger)
+}
+
+// Trigger starts a job immediately without waiting for it and returns the
+// RUNNING run record. ctx bounds the run, not the call.
+func (s *Scheduler) Trigger(ctx context.Context, name string, trigger domain.JobTrigger) (*domain.JobRun, error) {
+	e, err := s.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	started, err := s.start(ctx, e, trigger)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := *started.run
+	s.triggered.Add(1)
+	go func() {
+		defer s.triggered.Done()
+		started.wait()
+	}()
+	return &snapshot, nil
+}
+
+func (s *Scheduler) entry(name string) (*entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return e, nil
+}
+
+func (s *Scheduler) timeoutOf(job Job) time.Duration {
+	if job.Timeout > 0 {
+		return job.Timeout
+	}
+	return time.Duration(s.timeout.Load())
+}
+
+// signal makes the job's loop recompute its next activation
+func (e *entry) signal() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop waits for each activation and runs the job synchronously, so
+// scheduled runs of one job never overlap
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		s.mu.Lock()
+		next := e.job.Schedule.Next(time.Now())
+		e.next = next
+		s.mu.Unlock()
+
+		var fire <-chan time.Time
+		var timer *time.Timer
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			fire = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return
+		case <-e.wake:
+			stopTimer(timer)
+		case <-fire:
+			if s.isPaused(e) {
+				s.logger.Debug("Skipped paused job", e.job.Name)
+				continue
+			}
+			_, err := s.run(ctx, e, domain.TriggerSchedule)
+			switch {
+			case errors.Is(err, ErrNotLeader), errors.Is(err, ErrJobRunning):
+				s.logger.Debug("Skipped job", e.job.Name+":", err)
+			case err != nil:
+				s.logger.Error("Could not start job", e.job.Name+":", err)
+			}
+		}
+	}
+}
+
+// outcome is what a job's Run returned
+type outcome struct {
+	result string
+	err    error
+}
+
+func (s *Scheduler) run(ctx context.Context, e *entry, trigger domain.JobTrigger) (*domain.JobRun, error) {
+	started, err := s.start(ctx, e, trigger)
+	if err != nil {
+		return nil, err
+	}
+	started.wait()
+	return started.run, nil
+}
+
+// startedRun is a run that holds its job's lock and running flag
+type startedRun struct {
+	s       *Scheduler
+	run     *domain.JobRun
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+	done    chan outcome
+}
+
+// start claims the job, records the run as RUNNING and launches the work
+func (s *Scheduler) start(ctx context.Context, e *entry, trigger domain.JobTrigger) (*startedRun, error) {
+	if trigger == domain.TriggerSchedule && !s.leads(ctx) {
+		return nil, ErrNotLeader
+	}
+	if !e.running.CompareAndSwap(false, true) {
+		return nil, ErrJobRunning
+	}
+	unlock, ok, err := s.locker.TryLock(ctx, e.job.Name)
+	if err != nil || !ok {
+		e.running.Store(false)
+		if err != nil {
+			return nil, fmt.Errorf("acquire job lock: %w", err)
+		}
+		return nil, ErrNotLeader
+	}
+
+	s.mu.RLock()
+	job := e.job
+	s.mu.RUnlock()
+
+	run := &domain.JobRun{
+		ID:        uuid.New(),
+		Job:       job.Name,
+		Trigger:   trigger,
+		Status:    domain.JobRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.runs.Create(run); err != nil {
+		s.logger.Error("Could not record job run", job.Name+":", err)
+	}
+
+	timeout := s.timeoutOf(job)
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	done := make(chan outcome, 1)
+	go func() {
+		// The lock and the running flag are held until the work really returns
+		defer e.running.Store(false)
+		defer unlock()
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		result, err := job.Run(runCtx)
+		done <- outcome{result: result, err: err}
+	}()
+
+	return &startedRun{s: s, run: run, ctx: runCtx, cancel: cancel, timeout: timeout, done: done}, nil
+}
+
+// wait blocks until the work returns or times out, then records the outcome
+func (r *startedRun) wait() {
+	defer r.cancel()
+
+	var out outcome
+	select {
+	case out = <-r.done:
+	case <-r.ctx.Done():
+		if errors.Is(r.ctx.Err(), context.DeadlineExceeded) {
+			out.err = fmt.Errorf("timed out after %s", r.timeout)
+		} else {
+			out.err = fmt.Errorf("cancelled: %w", r.ctx.Err())
+		}
+	}
+
+	run := r.run
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Result = out.result
+	run.Status = domain.JobRunSucceeded
+	if out.err != nil {
+		run.Status = domain.JobRunFailed
+		run.Error = out.err.Error()
+		r.s.logger.Error("Job", run.Job, "failed after", finishedAt.Sub(run.StartedAt).String()+":", out.err)
+	} else {
+		r.s.logger.Info("Job", run.Job, "succeeded in", finishedAt.Sub(run.StartedAt).String()+":", out.result)
+	}
+	if err := r.s.runs.Finish(run); err != nil {
+		r.s.logger.Error("Could not record job run", run.Job+":", err)
+	}
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
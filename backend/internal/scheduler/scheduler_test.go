@@ -0,0 +1,467 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/scheduler"
+	"pickup-queue/pkg/logger"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// soon fires a few milliseconds after every call so loop tests run quickly
+type soon struct{}
+
+func (soon) Next(t time.Time) time.Time { return t.Add(5 * time.Millisecond) }
+
+func newScheduler(locker domain.JobLocker) (*scheduler.Scheduler, *repository.MemoryJobRunRepository) {
+	runs := repository.NewMemoryJobRunRepository()
+	return scheduler.New(locker, runs, logger.New()), runs
+}
+
+func TestScheduler_RunNow_HappyPath_RecordsRun(t *testing.T) {
+	// Setup
+	s, runs := newScheduler(repository.NewLocalJobLocker())
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "expire-packages",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			return "expired 2 packages", nil
+		},
+	}))
+
+	// Execute
+	run, err := s.RunNow(context.Background(), "expire-packages", domain.TriggerManual)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobRunSucceeded, run.Status)
+	assert.Equal(t, domain.TriggerManual, run.Trigger)
+	assert.Equal(t, "expired 2 packages", run.Result)
+	require.NotNil(t, run.FinishedAt)
+
+	history, err := runs.ListByJob("expire-packages", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, run.ID, history[0].ID)
+	assert.Equal(t, domain.JobRunSucceeded, history[0].Status)
+}
+
+func TestScheduler_RunNow_EdgeCase_FailureAndPanic(t *testing.T) {
+	// Setup
+	s, _ := newScheduler(repository.NewLocalJobLocker())
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "fails",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			return "", errors.New("database unavailable")
+		},
+	}))
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "panics",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			panic("boom")
+		},
+	}))
+
+	// Execute
+	failed, err := s.RunNow(context.Background(), "fails", domain.TriggerManual)
+	require.NoError(t, err)
+	panicked, err := s.RunNow(context.Background(), "panics", domain.TriggerManual)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, domain.JobRunFailed, failed.Status)
+	assert.Equal(t, "database unavailable", failed.Error)
+	assert.Equal(t, domain.JobRunFailed, panicked.Status)
+	assert.Equal(t, "panic: boom", panicked.Error)
+}
+
+func TestScheduler_RunNow_EdgeCase_Timeout(t *testing.T) {
+	// Setup: the job ignores its context and outlives the timeout
+	s, runs := newScheduler(repository.NewLocalJobLocker())
+	release := make(chan struct{})
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "slow",
+		Schedule: scheduler.Every(time.Hour),
+		Timeout:  20 * time.Millisecond,
+		Run: func(ctx context.Context) (string, error) {
+			<-release
+			return "done", nil
+		},
+	}))
+
+	// Execute
+	run, err := s.RunNow(context.Background(), "slow", domain.TriggerManual)
+	require.NoError(t, err)
+
+	// Assert: the run is failed, but the job stays busy until the work returns
+	assert.Equal(t, domain.JobRunFailed, run.Status)
+	assert.Contains(t, run.Error, "timed out after 20ms")
+	history, err := runs.ListByJob("slow", 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobRunFailed, history[0].Status)
+
+	_, err = s.RunNow(context.Background(), "slow", domain.TriggerManual)
+	assert.ErrorIs(t, err, scheduler.ErrJobRunning)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		_, err := s.RunNow(context.Background(), "slow", domain.TriggerManual)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestScheduler_RunNow_EdgeCase_Overlap(t *testing.T) {
+	// Setup
+	s, runs := newScheduler(repository.NewLocalJobLocker())
+	started := make(chan struct{})
+	release := make(chan struct{})
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "long",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "", nil
+		},
+	}))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = s.RunNow(context.Background(), "long", domain.TriggerSchedule)
+	}()
+	<-started
+
+	// Execute
+	run, err := s.RunNow(context.Background(), "long", domain.TriggerManual)
+
+	// Assert: the second run is skipped and not recorded
+	assert.Nil(t, run)
+	assert.ErrorIs(t, err, scheduler.ErrJobRunning)
+	close(release)
+	<-done
+	history, err := runs.ListByJob("long", 10)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestScheduler_RunNow_EdgeCase_LockedElsewhere(t *testing.T) {
+	// Setup: another replica holds the job lock
+	locker := repository.NewLocalJobLocker()
+	unlock, ok, err := locker.TryLock(context.Background(), "archive-packages")
+	require.NoError(t, err)
+	require.True(t, ok)
+	defer unlock()
+
+	s, runs := newScheduler(locker)
+	var calls atomic.Int32
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "archive-packages",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			return "", nil
+		},
+	}))
+
+	// Execute
+	_, err = s.RunNow(context.Background(), "archive-packages", domain.TriggerSchedule)
+
+	// Assert
+	assert.ErrorIs(t, err, scheduler.ErrNotLeader)
+	assert.Zero(t, calls.Load())
+	history, err := runs.ListByJob("archive-packages", 10)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+// fakeLeader leads while leading is set and records resignations
+type fakeLeader struct {
+	leading  atomic.Bool
+	resigned atomic.Bool
+}
+
+func (l *fakeLeader) IsLeader(ctx context.Context) (bool, error) { return l.leading.Load(), nil }
+
+func (l *fakeLeader) Resign() { l.resigned.Store(true) }
+
+func TestScheduler_Run_EdgeCase_OnlyLeaderRunsScheduledJobs(t *testing.T) {
+	// Setup
+	leader := &fakeLeader{}
+	s, runs := newScheduler(repository.NewLocalJobLocker())
+	s.WithLeader(leader)
+	var calls atomic.Int32
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "tick",
+		Schedule: soon{},
+		Run: func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			return "", nil
+		},
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	// Execute - follow for a while, then take the lead
+	_, scheduledErr := s.RunNow(context.Background(), "tick", domain.TriggerSchedule)
+	manual, manualErr := s.RunNow(context.Background(), "tick", domain.TriggerManual)
+	go func() {
+		s.Run(ctx)
+		close(stopped)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	followerCalls := calls.Load()
+	leader.leading.Store(true)
+
+	// Assert
+	assert.ErrorIs(t, scheduledErr, scheduler.ErrNotLeader)
+	require.NoError(t, manualErr)
+	assert.Equal(t, domain.JobRunSucceeded, manual.Status)
+	assert.Equal(t, int32(1), followerCalls)
+	assert.Eventually(t, func() bool { return calls.Load() > 1 }, time.Second, 5*time.Millisecond)
+	cancel()
+	<-stopped
+	assert.True(t, leader.resigned.Load())
+	history, err := runs.ListByJob("tick", 100)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TriggerManual, history[len(history)-1].Trigger)
+}
+
+func TestScheduler_RunNow_EdgeCase_UnknownJob(t *testing.T) {
+	// Setup
+	s, _ := newScheduler(repository.NewLocalJobLocker())
+
+	// Execute
+	_, err := s.RunNow(context.Background(), "missing", domain.TriggerManual)
+
+	// Assert
+	assert.ErrorIs(t, err, scheduler.ErrUnknownJob)
+}
+
+func TestScheduler_Register_EdgeCase_Duplicate(t *testing.T) {
+	// Setup
+	s, _ := newScheduler(repository.NewLocalJobLocker())
+	job := scheduler.Job{Name: "purge", Schedule: scheduler.Every(time.Hour)}
+	require.NoError(t, s.Register(job))
+
+	// Execute
+	err := s.Register(job)
+
+	// Assert
+	assert.ErrorIs(t, err, scheduler.ErrDuplicateJob)
+}
+
+func TestScheduler_Run_HappyPath_FiresOnScheduleAndReschedules(t *testing.T) {
+	// Setup
+	s, runs := newScheduler(repository.NewLocalJobLocker())
+	var calls atomic.Int32
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "tick",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			return "", nil
+		},
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.Run(ctx)
+	}()
+
+	// Execute: nothing is due for an hour until the job is rescheduled
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, calls.Load())
+	require.NoError(t, s.Reschedule("tick", soon{}, 0))
+
+	// Assert
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	cancel()
+	<-stopped
+
+	history, err := runs.ListByJob("tick", 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	assert.Equal(t, domain.TriggerSchedule, history[0].Trigger)
+}
+
+func TestScheduler_Reschedule_EdgeCase_UnknownJob(t *testing.T) {
+	// Setup
+	s, _ := newScheduler(repository.NewLocalJobLocker())
+
+	// Execute
+	err := s.Reschedule("missing", scheduler.Every(time.Minute), 0)
+
+	// Assert
+	assert.ErrorIs(t, err, scheduler.ErrUnknownJob)
+}
+
+func TestScheduler_Pause_HappyPath_SkipsScheduledRunsUntilResumed(t *testing.T) {
+	// Setup
+	s, _ := newScheduler(repository.NewLocalJobLocker())
+	var calls atomic.Int32
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "tick",
+		Schedule: soon{},
+		Run: func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			return "", nil
+		},
+	}))
+	require.NoError(t, s.Pause("tick"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	// Execute
+	time.Sleep(30 * time.Millisecond)
+	pausedCalls := calls.Load()
+	status, err := s.Status("tick")
+	require.NoError(t, err)
+	require.NoError(t, s.Resume("tick"))
+
+	// Assert
+	assert.Zero(t, pausedCalls)
+	assert.True(t, status.Paused)
+	assert.Nil(t, status.NextRun)
+	assert.Eventually(t, func() bool { return calls.Load() > 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestScheduler_Pause_HappyPath_SharedBetweenReplicas(t *testing.T) {
+	// Setup
+	settings := repository.NewMemoryJobSettingsRepository()
+	replicas := make([]*scheduler.Scheduler, 2)
+	var calls atomic.Int32
+	for i := range replicas {
+		s, _ := newScheduler(repository.NewLocalJobLocker())
+		replicas[i] = s.WithSettings(settings)
+		require.NoError(t, replicas[i].Register(scheduler.Job{
+			Name:     "tick",
+			Schedule: soon{},
+			Run: func(ctx context.Context) (string, error) {
+				calls.Add(1)
+				return "", nil
+			},
+		}))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Execute - pause through the first replica, run only the second
+	require.NoError(t, replicas[0].Pause("tick"))
+	go replicas[1].Run(ctx)
+	time.Sleep(30 * time.Millisecond)
+	pausedCalls := calls.Load()
+	status, err := replicas[1].Status("tick")
+	require.NoError(t, err)
+	require.NoError(t, replicas[0].Resume("tick"))
+
+	// Assert
+	assert.Zero(t, pausedCalls)
+	assert.True(t, status.Paused)
+	assert.Eventually(t, func() bool { return calls.Load() > 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestScheduler_Pause_EdgeCase_ManualRunStillAllowed(t *testing.T) {
+	// Setup
+	s, _ := newScheduler(repository.NewLocalJobLocker())
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "expire-packages",
+		Schedule: scheduler.Every(time.Hour),
+		Run:      func(ctx context.Context) (string, error) { return "ok", nil },
+	}))
+	require.NoError(t, s.Pause("expire-packages"))
+
+	// Execute
+	run, err := s.RunNow(context.Background(), "expire-packages", domain.TriggerManual)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobRunSucceeded, run.Status)
+	assert.ErrorIs(t, s.Pause("missing"), scheduler.ErrUnknownJob)
+	assert.ErrorIs(t, s.Resume("missing"), scheduler.ErrUnknownJob)
+}
+
+func TestScheduler_Trigger_HappyPath_ReturnsRunningRun(t *testing.T) {
+	// Setup
+	s, runs := newScheduler(repository.NewLocalJobLocker())
+	release := make(chan struct{})
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "archive-packages",
+		Schedule: scheduler.Every(time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			<-release
+			return "archived 4 terminal packages", nil
+		},
+	}))
+
+	// Execute
+	run, err := s.Trigger(context.Background(), "archive-packages", domain.TriggerManual)
+	require.NoError(t, err)
+	status, err := s.Status("archive-packages")
+	require.NoError(t, err)
+	close(release)
+
+	// Assert
+	assert.Equal(t, domain.JobRunRunning, run.Status)
+	assert.Nil(t, run.FinishedAt)
+	assert.True(t, status.Running)
+	assert.Eventually(t, func() bool {
+		history, err := runs.ListByJob("archive-packages", 1)
+		return err == nil && len(history) == 1 && history[0].Status == domain.JobRunSucceeded
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestScheduler_Statuses_HappyPath_LastAndNextRun(t *testing.T) {
+	// Setup
+	s, _ := newScheduler(repository.NewLocalJobLocker())
+	schedule, err := scheduler.Parse("0 3 * * *")
+	require.NoError(t, err)
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "purge-deleted-packages",
+		Schedule: schedule,
+		Timeout:  time.Minute,
+		Run:      func(ctx context.Context) (string, error) { return "purged 1 deleted packages", nil },
+	}))
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:     "archive-packages",
+		Schedule: scheduler.Every(time.Hour),
+		Run:      func(ctx context.Context) (string, error) { return "", nil },
+	}))
+	_, err = s.RunNow(context.Background(), "purge-deleted-packages", domain.TriggerManual)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	// Execute
+	var statuses []*scheduler.JobStatus
+	require.Eventually(t, func() bool {
+		statuses, err = s.Statuses()
+		return err == nil && statuses[1].NextRun != nil
+	}, time.Second, 5*time.Millisecond)
+
+	// Assert
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "archive-packages", statuses[0].Name)
+	assert.Equal(t, "@every 1h0m0s", statuses[0].Schedule)
+	assert.Equal(t, "10m0s", statuses[0].Timeout)
+	assert.Nil(t, statuses[0].LastRun)
+
+	purge := statuses[1]
+	assert.Equal(t, "0 3 * * *", purge.Schedule)
+	assert.Equal(t, "1m0s", purge.Timeout)
+	assert.Equal(t, 3, purge.NextRun.Hour())
+	require.NotNil(t, purge.LastRun)
+	assert.Equal(t, "purged 1 deleted packages", purge.LastRun.Result)
+	assert.NotEmpty(t, purge.LastDuration)
+}
@@ -0,0 +1,141 @@
+// Package storage wires the repositories for the configured storage backend
+// so cmd/api and cmd/worker build them the same way.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	sqlitemigrations "pickup-queue/migrations/sqlite"
+	"pickup-queue/pkg/config"
+	"pickup-queue/pkg/database"
+)
+
+// Storage holds the repositories for one backend
+type Storage struct {
+	Backend        string
+	Packages       domain.PackageRepository
+	UnitOfWork     domain.UnitOfWork
+	Idempotency    domain.IdempotencyRepository
+	PickupSessions domain.PickupSessionRepository
+	// Archive holds terminal packages moved out of Packages
+	Archive domain.PackageArchiveRepository
+	// DriverAssignments reads the reassignment history; writes go through UnitOfWork
+	DriverAssignments domain.DriverAssignmentRepository
+	// Shipments reads shipments; they are created through UnitOfWork
+	Shipments domain.ShipmentRepository
+	// Appointments holds drivers' bookings of pickup windows
+	Appointments domain.AppointmentRepository
+	// SLABreaches records packages that missed their priority's pickup target
+	SLABreaches domain.SLABreachRepository
+	// PickupReminders counts the reminders sent for waiting packages
+	PickupReminders domain.PickupReminderRepository
+	// JobRuns records worker job history; JobLocker keeps a job on one replica
+	JobRuns   domain.JobRunRepository
+	JobLocker domain.JobLocker
+	// JobLeader elects the replica that runs scheduled jobs; nil for the
+	// single-host backends
+	JobLeader domain.LeaderElector
+	// JobSettings shares paused jobs between worker replicas
+	JobSettings domain.JobSettingsRepository
+
+	// DB is the underlying pool; nil for in-memory storage
+	DB *sql.DB
+}
+
+// Open connects to the backend selected in cfg.Storage
+func Open(cfg config.DatabaseConfig) (*Storage, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		packages := repository.NewMemoryPackageRepository()
+		assignments := repository.NewMemoryDriverAssignmentRepository()
+		shipments := repository.NewMemoryShipmentRepository()
+		return &Storage{
+			Backend:           cfg.Storage,
+			Packages:          packages,
+			UnitOfWork:        repository.NewInMemoryUnitOfWork(packages).WithDriverAssignments(assignments).WithShipments(shipments),
+			DriverAssignments: assignments,
+			Shipments:         shipments,
+			Appointments:      repository.NewMemoryAppointmentRepository(),
+			SLABreaches:       repository.NewMemorySLABreachRepository(),
+			PickupReminders:   repository.NewMemoryPickupReminderRepository(),
+			Idempotency:       repository.NewMemoryIdempotencyRepository(),
+			PickupSessions:    repository.NewMemoryPickupSessionRepository(),
+			Archive:           repository.NewMemoryPackageArchiveRepository(packages),
+			JobRuns:           repository.NewMemoryJobRunRepository(),
+			JobSettings:       repository.NewMemoryJobSettingsRepository(),
+			JobLocker:         repository.NewLocalJobLocker(),
+		}, nil
+
+	case config.StorageSQLite:
+		db, err := database.NewSQLiteConnection(cfg.SQLitePath, sqlitemigrations.FS)
+		if err != nil {
+			return nil, err
+		}
+		return &Storage{
+			Backend:           cfg.Storage,
+			Packages:          repository.NewSQLitePackageRepository(db),
+			UnitOfWork:        repository.NewSQLiteUnitOfWork(db),
+			Idempotency:       repository.NewSQLiteIdempotencyRepository(db),
+			PickupSessions:    repository.NewSQLitePickupSessionRepository(db),
+			Archive:           repository.NewSQLitePackageArchiveRepository(db),
+			DriverAssignments: repository.NewSQLiteDriverAssignmentRepository(db),
+			Shipments:         repository.NewSQLiteShipmentRepository(db),
+			Appointments:      repository.NewSQLiteAppointmentRepository(db),
+			SLABreaches:       repository.NewSQLiteSLABreachRepository(db),
+			PickupReminders:   repository.NewSQLitePickupReminderRepository(db),
+			JobRuns:           repository.NewSQLiteJobRunRepository(db),
+			JobSettings:       repository.NewSQLiteJobSettingsRepository(db),
+			// SQLite is a single-host backend, so an in-process lock is enough
+			JobLocker: repository.NewLocalJobLocker(),
+			DB:        db,
+		}, nil
+
+	case config.StoragePostgres:
+		// Note: Database migrations should be handled separately with migration files
+		db, err := database.NewConnection(cfg.Connection())
+		if err != nil {
+			return nil, err
+		}
+		return &Storage{
+			Backend:           cfg.Storage,
+			Packages:          repository.NewPackageRepository(db),
+			UnitOfWork:        repository.NewUnitOfWork(db),
+			Idempotency:       repository.NewIdempotencyRepository(db),
+			PickupSessions:    repository.NewPickupSessionRepository(db),
+			Archive:           repository.NewPackageArchiveRepository(db),
+			DriverAssignments: repository.NewDriverAssignmentRepository(db),
+			Shipments:         repository.NewShipmentRepository(db),
+			Appointments:      repository.NewAppointmentRepository(db),
+			SLABreaches:       repository.NewSLABreachRepository(db),
+			PickupReminders:   repository.NewPickupReminderRepository(db),
+			JobRuns:           repository.NewJobRunRepository(db),
+			JobSettings:       repository.NewJobSettingsRepository(db),
+			JobLocker:         repository.NewAdvisoryJobLocker(db),
+			JobLeader:         repository.NewAdvisoryLeaderElector(db),
+			DB:                db,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Storage)
+	}
+}
+
+// RateLimiter returns the limiter for rate_limit.backend. The postgres
+// backend shares buckets between API replicas; config validation ensures it
+// is only chosen with postgres storage.
+func (s *Storage) RateLimiter(backend string) domain.RateLimiter {
+	if backend == config.RateLimitPostgres && s.Backend == config.StoragePostgres {
+		return repository.NewPostgresRateLimiter(s.DB)
+	}
+	return repository.NewMemoryRateLimiter()
+}
+
+// Close releases the database pool, if any
+func (s *Storage) Close() error {
+	if s.DB == nil {
+		return nil
+	}
+	return s.DB.Close()
+}
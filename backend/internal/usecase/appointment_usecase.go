@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"fmt"
+	"pickup-queue/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAppointmentNotFound = domain.NewError(domain.KindNotFound, "APPOINTMENT_NOT_FOUND", "appointment not found")
+	ErrNoWaitingPackages   = domain.NewError(domain.KindUnprocessable, "NO_WAITING_PACKAGES", "driver has no packages waiting to be picked up")
+	// ErrOutsideAppointment refuses a check-in when appointments are enforced;
+	// the returned copy says which window the driver booked, if any
+	ErrOutsideAppointment = domain.NewError(domain.KindConflict, "OUTSIDE_APPOINTMENT", "driver has no appointment for the current pickup window")
+)
+
+// MaxBookingDays is how many days ahead, today included, windows can be booked
+const MaxBookingDays = 14
+
+// AppointmentEnforcement is what happens when a driver checks in without an
+// appointment covering the time
+type AppointmentEnforcement string
+
+const (
+	EnforcementOff     AppointmentEnforcement = "off"
+	EnforcementWarn    AppointmentEnforcement = "warn"
+	EnforcementEnforce AppointmentEnforcement = "enforce"
+)
+
+// PickupWindowRule is a daily pickup window; Start and End are offsets from
+// local midnight
+type PickupWindowRule struct {
+	Start    time.Duration
+	End      time.Duration
+	Capacity int
+}
+
+// AppointmentRules hold the site's daily pickup windows and how strictly
+// check-ins are held to them. Windows are in Location; Grace is how early or
+// late a driver may arrive around their window.
+type AppointmentRules struct {
+	Windows     []PickupWindowRule
+	Location    *time.Location
+	Grace       time.Duration
+	Enforcement AppointmentEnforcement
+}
+
+// AppointmentUsecase books drivers into the site's pickup windows so they do
+// not all arrive at once, and checks them in against their bookings
+type AppointmentUsecase struct {
+	appointments domain.AppointmentRepository
+	packages     *PackageUsecase
+	rules        AppointmentRules
+	now          func() time.Time
+}
+
+func NewAppointmentUsecase(appointments domain.AppointmentRepository, packages *PackageUsecase, rules AppointmentRules) *AppointmentUsecase {
+	if rules.Location == nil {
+		rules.Location = time.Local
+	}
+	return &AppointmentUsecase{
+		appointments: appointments,
+		packages:     packages,
+		rules:        rules,
+		now:          time.Now,
+	}
+}
+
+// WithClock replaces the usecase's clock, for tests
+func (au *AppointmentUsecase) WithClock(now func() time.Time) *AppointmentUsecase {
+	au.now = now
+	return au
+}
+
+// Windows lists the pickup windows of a day, YYYY-MM-DD in the site's time
+// zone and today when empty, with how many places are left in each
+func (au *AppointmentUsecase) Windows(date string) ([]*domain.PickupWindow, error) {
+	schedule, err := au.Schedule(date)
+	if err != nil {
+		return nil, err
+	}
+	windows := make([]*domain.PickupWindow, len(schedule.Windows))
+	for i, w := range schedule.Windows {
+		window := w.PickupWindow
+		windows[i] = &window
+	}
+	return windows, nil
+}
+
+// Schedule lists the pickup windows of a day with the appointments booked in
+// each and how many packages every booked driver has waiting
+func (au *AppointmentUsecase) Schedule(date string) (*domain.PickupSchedule, error) {
+	day, err := au.parseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	booked, err := au.appointments.ListBooked(domain.AppointmentFilter{From: day, To: day.AddDate(0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+	if err := au.countWaiting(booked); err != nil {
+		return nil, err
+	}
+
+	schedule := &domain.PickupSchedule{Date: day.Format(time.DateOnly), Windows: []*domain.ScheduledWindow{}}
+	for _, window := range au.windowsOn(day) {
+		scheduled := &domain.ScheduledWindow{PickupWindow: *window, Appointments: []*domain.Appointment{}}
+		for _, appointment := range booked {
+			if appointment.WindowStart.Equal(window.Start) {
+				scheduled.Appointments = append(scheduled.Appointments, appointment)
+			}
+		}
+		scheduled.Booked = len(scheduled.Appointments)
+		scheduled.Available = max(scheduled.Capacity-scheduled.Booked, 0)
+		schedule.Windows = append(schedule.Windows, scheduled)
+	}
+	return schedule, nil
+}
+
+// BookAppointment books the window starting at req.WindowStart for a driver
+// with packages waiting. A driver holds at most one appointment per day;
+// bookedBy is the caller, recorded for the schedule.
+func (au *AppointmentUsecase) BookAppointment(req *domain.BookAppointmentRequest, bookedBy string) (*domain.Appointment, error) {
+	driverCode, err := au.packages.normalizeDriverCode("", "driver_code", req.DriverCode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := au.now()
+	start := req.WindowStart.In(au.rules.Location)
+	day := startOfDay(start)
+	var window *domain.PickupWindow
+	for _, w := range au.windowsOn(day) {
+		if w.Start.Equal(start) {
+			window = w
+		}
+	}
+	switch {
+	case window == nil:
+		return nil, domain.NewValidationError(domain.FieldError{Field: "window_start", Code: "unknown", Message: "no pickup window starts at this time"})
+	case !window.End.After(now):
+		return nil, domain.NewValidationError(domain.FieldError{Field: "window_start", Code: "past", Message: "pickup window is over"})
+	case !day.Before(startOfDay(now.In(au.rules.Location)).AddDate(0, 0, MaxBookingDays)):
+		return nil, domain.NewValidationError(domain.FieldError{Field: "window_start", Code: "too_far", Message: fmt.Sprintf("pickup windows can be booked up to %d days ahead", MaxBookingDays)})
+	}
+
+	waiting, err := au.waitingPackages(driverCode)
+	if err != nil {
+		return nil, err
+	}
+	if waiting == 0 {
+		return nil, ErrNoWaitingPackages
+	}
+
+	appointment := &domain.Appointment{
+		ID:              uuid.New(),
+		DriverCode:      driverCode,
+		WindowStart:     window.Start,
+		WindowEnd:       window.End,
+		Status:          domain.AppointmentBooked,
+		BookedBy:        bookedBy,
+		CreatedAt:       now,
+		WaitingPackages: waiting,
+	}
+	if err := au.appointments.Create(appointment, window.Capacity, day); err != nil {
+		return nil, err
+	}
+	return appointment, nil
+}
+
+func (au *AppointmentUsecase) GetAppointment(id uuid.UUID) (*domain.Appointment, error) {
+	appointment, err := au.appointments.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if appointment == nil {
+		return nil, ErrAppointmentNotFound
+	}
+	if err := au.countWaiting([]*domain.Appointment{appointment}); err != nil {
+		return nil, err
+	}
+	return appointment, nil
+}
+
+// ListDriverAppointments lists a driver's booked appointments from today on
+func (au *AppointmentUsecase) ListDriverAppointments(driverCode string) ([]*domain.Appointment, error) {
+	driverCode = au.packages.rules.DriverCode.normalize(driverCode)
+	if driverCode == "" {
+		return nil, ErrDriverCodeRequired
+	}
+	from := startOfDay(au.now().In(au.rules.Location))
+	appointments, err := au.appointments.ListBooked(domain.AppointmentFilter{DriverCode: driverCode, From: from, To: from.AddDate(0, 0, MaxBookingDays)})
+	if err != nil {
+		return nil, err
+	}
+	if err := au.countWaiting(appointments); err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+// CancelAppointment frees the appointment's place. Cancelling twice is
+// harmless.
+func (au *AppointmentUsecase) CancelAppointment(id uuid.UUID) (*domain.Appointment, error) {
+	if _, err := au.GetAppointment(id); err != nil {
+		return nil, err
+	}
+	if err := au.appointments.Cancel(id, au.now()); err != nil {
+		return nil, err
+	}
+	return au.GetAppointment(id)
+}
+
+// CheckIn matches a driver arriving at the counter against their bookings.
+// It returns the appointment covering the current time, give or take the
+// grace period. Without one the check-in carries a warning or, when
+// appointments are enforced, fails with ErrOutsideAppointment. Sites without
+// pickup windows accept every check-in. Only check-ins are held to
+// appointments; marking packages PICKED through status updates is not.
+func (au *AppointmentUsecase) CheckIn(driverCode string) (*domain.Appointment, string, error) {
+	if au.rules.Enforcement == EnforcementOff || len(au.rules.Windows) == 0 {
+		return nil, "", nil
+	}
+	driverCode = au.packages.rules.DriverCode.normalize(driverCode)
+
+	now := au.now()
+	day := startOfDay(now.In(au.rules.Location))
+	booked, err := au.appointments.ListBooked(domain.AppointmentFilter{DriverCode: driverCode, From: day, To: day.AddDate(0, 0, 1)})
+	if err != nil {
+		return nil, "", err
+	}
+
+	warning := "driver has no pickup appointment today"
+	for _, appointment := range booked {
+		if !now.Before(appointment.WindowStart.Add(-au.rules.Grace)) && !now.After(appointment.WindowEnd.Add(au.rules.Grace)) {
+			return appointment, "", nil
+		}
+		warning = fmt.Sprintf("driver booked the %s-%s pickup window",
+			appointment.WindowStart.In(au.rules.Location).Format("15:04"), appointment.WindowEnd.In(au.rules.Location).Format("15:04"))
+	}
+
+	if au.rules.Enforcement == EnforcementEnforce {
+		return nil, "", domain.NewError(ErrOutsideAppointment.Kind, ErrOutsideAppointment.Code, warning)
+	}
+	return nil, warning, nil
+}
+
+// windowsOn returns the configured windows of day, which must be local
+// midnight, without bookings filled in
+func (au *AppointmentUsecase) windowsOn(day time.Time) []*domain.PickupWindow {
+	windows := make([]*domain.PickupWindow, 0, len(au.rules.Windows))
+	for _, rule := range au.rules.Windows {
+		windows = append(windows, &domain.PickupWindow{
+			Start:     atOffset(day, rule.Start),
+			End:       atOffset(day, rule.End),
+			Capacity:  rule.Capacity,
+			Available: rule.Capacity,
+		})
+	}
+	return windows
+}
+
+// parseDate reads a YYYY-MM-DD date in the site's time zone, today when empty
+func (au *AppointmentUsecase) parseDate(date string) (time.Time, error) {
+	if strings.TrimSpace(date) == "" {
+		return startOfDay(au.now().In(au.rules.Location)), nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(date), au.rules.Location)
+	if err != nil {
+		return time.Time{}, domain.NewValidationError(domain.FieldError{Field: "date", Code: "format", Message: "date must look like 2006-01-02"})
+	}
+	return day, nil
+}
+
+// countWaiting fills in how many packages each appointment's driver has waiting
+func (au *AppointmentUsecase) countWaiting(appointments []*domain.Appointment) error {
+	counts := make(map[string]int)
+	for _, appointment := range appointments {
+		n, ok := counts[appointment.DriverCode]
+		if !ok {
+			var err error
+			if n, err = au.waitingPackages(appointment.DriverCode); err != nil {
+				return err
+			}
+			counts[appointment.DriverCode] = n
+		}
+		appointment.WaitingPackages = n
+	}
+	return nil
+}
+
+func (au *AppointmentUsecase) waitingPackages(driverCode string) (int, error) {
+	waitingStatus := domain.StatusWaiting
+	waiting, err := au.packages.ListPackages(domain.PackageFilter{Status: &waitingStatus, DriverCode: driverCode})
+	if err != nil {
+		return 0, err
+	}
+	return len(waiting), nil
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// atOffset returns the wall-clock time offset after midnight of day, so
+// windows keep their local times across daylight saving changes
+func atOffset(day time.Time, offset time.Duration) time.Time {
+	year, month, d := day.Date()
+	return time.Date(year, month, d, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, day.Location())
+}
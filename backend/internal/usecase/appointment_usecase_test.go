@@ -0,0 +1,180 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appointmentDay is a Monday; the tests run at 08:00 on it
+var appointmentDay = time.Date(2025, 8, 25, 0, 0, 0, 0, time.UTC)
+
+func setupAppointments(t *testing.T, enforcement usecase.AppointmentEnforcement) (*usecase.AppointmentUsecase, *usecase.PackageUsecase, *time.Time) {
+	packages := usecase.NewPackageUsecase(repository.NewMemoryPackageRepository())
+	now := appointmentDay.Add(8 * time.Hour)
+	uc := usecase.NewAppointmentUsecase(repository.NewMemoryAppointmentRepository(), packages, usecase.AppointmentRules{
+		Windows: []usecase.PickupWindowRule{
+			{Start: 9 * time.Hour, End: 10 * time.Hour, Capacity: 1},
+			{Start: 10 * time.Hour, End: 11 * time.Hour, Capacity: 2},
+		},
+		Location:    time.UTC,
+		Grace:       15 * time.Minute,
+		Enforcement: enforcement,
+	}).WithClock(func() time.Time { return now })
+	for _, driver := range []string{"DRV-001", "DRV-002"} {
+		_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-" + driver, DriverCode: driver})
+		require.NoError(t, err)
+	}
+	return uc, packages, &now
+}
+
+func book(uc *usecase.AppointmentUsecase, driverCode string, hour int) (*domain.Appointment, error) {
+	return uc.BookAppointment(&domain.BookAppointmentRequest{
+		DriverCode:  driverCode,
+		WindowStart: appointmentDay.Add(time.Duration(hour) * time.Hour),
+	}, "dispatch")
+}
+
+func TestAppointmentUsecase_BookAppointment_HappyPath(t *testing.T) {
+	// Setup
+	uc, _, _ := setupAppointments(t, usecase.EnforcementWarn)
+
+	// Execute
+	appointment, err := book(uc, " DRV-001 ", 9)
+	require.NoError(t, err)
+	schedule, scheduleErr := uc.Schedule("2025-08-25")
+	windows, windowsErr := uc.Windows("2025-08-25")
+
+	// Assert
+	assert.Equal(t, "DRV-001", appointment.DriverCode)
+	assert.Equal(t, domain.AppointmentBooked, appointment.Status)
+	assert.True(t, appointment.WindowEnd.Equal(appointmentDay.Add(10*time.Hour)))
+	assert.Equal(t, 1, appointment.WaitingPackages)
+
+	require.NoError(t, scheduleErr)
+	assert.Equal(t, "2025-08-25", schedule.Date)
+	require.Len(t, schedule.Windows, 2)
+	require.Len(t, schedule.Windows[0].Appointments, 1)
+	assert.Equal(t, "dispatch", schedule.Windows[0].Appointments[0].BookedBy)
+	assert.Equal(t, 1, schedule.Windows[0].Appointments[0].WaitingPackages)
+	assert.Empty(t, schedule.Windows[1].Appointments)
+
+	require.NoError(t, windowsErr)
+	assert.Equal(t, 0, windows[0].Available)
+	assert.Equal(t, 2, windows[1].Available)
+}
+
+func TestAppointmentUsecase_BookAppointment_EdgeCase_Rejected(t *testing.T) {
+	// Setup
+	uc, _, now := setupAppointments(t, usecase.EnforcementWarn)
+	_, err := book(uc, "DRV-001", 9)
+	require.NoError(t, err)
+
+	// Execute
+	_, full := book(uc, "DRV-002", 9)
+	_, sameDay := book(uc, "DRV-001", 10)
+	_, noPackages := book(uc, "DRV-003", 10)
+	_, unknown := uc.BookAppointment(&domain.BookAppointmentRequest{DriverCode: "DRV-002", WindowStart: appointmentDay.Add(9*time.Hour + 30*time.Minute)}, "")
+	_, tooFar := uc.BookAppointment(&domain.BookAppointmentRequest{DriverCode: "DRV-002", WindowStart: appointmentDay.AddDate(0, 0, usecase.MaxBookingDays).Add(9 * time.Hour)}, "")
+	*now = appointmentDay.Add(11 * time.Hour)
+	_, past := book(uc, "DRV-002", 10)
+
+	// Assert
+	assert.ErrorIs(t, full, domain.ErrWindowFull)
+	assert.ErrorIs(t, sameDay, domain.ErrAppointmentExists)
+	assert.ErrorIs(t, noPackages, usecase.ErrNoWaitingPackages)
+	assert.ErrorIs(t, unknown, domain.ErrValidation)
+	assert.ErrorIs(t, tooFar, domain.ErrValidation)
+	assert.ErrorIs(t, past, domain.ErrValidation)
+}
+
+func TestAppointmentUsecase_CancelAppointment_HappyPath_FreesPlace(t *testing.T) {
+	// Setup
+	uc, _, _ := setupAppointments(t, usecase.EnforcementWarn)
+	appointment, err := book(uc, "DRV-001", 9)
+	require.NoError(t, err)
+
+	// Execute
+	cancelled, err := uc.CancelAppointment(appointment.ID)
+	require.NoError(t, err)
+	again, againErr := uc.CancelAppointment(appointment.ID)
+	_, rebookErr := book(uc, "DRV-002", 9)
+
+	// Assert
+	assert.Equal(t, domain.AppointmentCancelled, cancelled.Status)
+	require.NotNil(t, cancelled.CancelledAt)
+	assert.NoError(t, againErr)
+	assert.Equal(t, domain.AppointmentCancelled, again.Status)
+	assert.NoError(t, rebookErr)
+}
+
+func TestAppointmentUsecase_CheckIn_HappyPath_WithinGrace(t *testing.T) {
+	// Setup
+	uc, packages, now := setupAppointments(t, usecase.EnforcementEnforce)
+	sessions := usecase.NewPickupSessionUsecase(repository.NewMemoryPickupSessionRepository(), packages).WithAppointments(uc)
+	appointment, err := book(uc, "DRV-001", 9)
+	require.NoError(t, err)
+	*now = appointmentDay.Add(8*time.Hour + 50*time.Minute)
+
+	// Execute
+	session, waiting, err := sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, waiting, 1)
+	require.NotNil(t, session.Appointment)
+	assert.Equal(t, appointment.ID, session.Appointment.ID)
+	assert.Empty(t, session.Warning)
+}
+
+func TestAppointmentUsecase_CheckIn_EdgeCase_OutsideWindow(t *testing.T) {
+	// Setup
+	warnUC, warnPackages, warnNow := setupAppointments(t, usecase.EnforcementWarn)
+	warnSessions := usecase.NewPickupSessionUsecase(repository.NewMemoryPickupSessionRepository(), warnPackages).WithAppointments(warnUC)
+	enforceUC, enforcePackages, enforceNow := setupAppointments(t, usecase.EnforcementEnforce)
+	enforceSessions := usecase.NewPickupSessionUsecase(repository.NewMemoryPickupSessionRepository(), enforcePackages).WithAppointments(enforceUC)
+	_, err := book(warnUC, "DRV-001", 10)
+	require.NoError(t, err)
+	_, err = book(enforceUC, "DRV-001", 10)
+	require.NoError(t, err)
+	*warnNow = appointmentDay.Add(9 * time.Hour)
+	*enforceNow = appointmentDay.Add(9 * time.Hour)
+
+	// Execute
+	warned, _, warnErr := warnSessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+	walkIn, _, walkInErr := warnSessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-002"})
+	_, _, enforceErr := enforceSessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+
+	// Assert
+	require.NoError(t, warnErr)
+	assert.Nil(t, warned.Appointment)
+	assert.Equal(t, "driver booked the 10:00-11:00 pickup window", warned.Warning)
+	require.NoError(t, walkInErr)
+	assert.Equal(t, "driver has no pickup appointment today", walkIn.Warning)
+	assert.ErrorIs(t, enforceErr, usecase.ErrOutsideAppointment)
+	assert.EqualError(t, enforceErr, "driver booked the 10:00-11:00 pickup window")
+}
+
+func TestAppointmentUsecase_CheckIn_EdgeCase_StatusUpdateNotEnforced(t *testing.T) {
+	// Setup - appointments are only checked when a pickup session opens
+	uc, packages, now := setupAppointments(t, usecase.EnforcementEnforce)
+	sessions := usecase.NewPickupSessionUsecase(repository.NewMemoryPickupSessionRepository(), packages).WithAppointments(uc)
+	*now = appointmentDay.Add(9 * time.Hour)
+	pkg, err := packages.GetPackageByOrderRef("ORD-DRV-001")
+	require.NoError(t, err)
+
+	// Execute
+	_, _, checkInErr := sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+	picked, pickErr := packages.UpdatePackageStatus(pkg.ID, domain.StatusPicked)
+
+	// Assert
+	assert.ErrorIs(t, checkInErr, usecase.ErrOutsideAppointment)
+	require.NoError(t, pickErr)
+	assert.Equal(t, domain.StatusPicked, picked.Status)
+}
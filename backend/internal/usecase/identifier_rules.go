@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"pickup-queue/internal/domain"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Checksums a FieldRule can require
+const (
+	// ChecksumLuhn checks the last digit of the identifier's digits
+	ChecksumLuhn = "luhn"
+	// ChecksumS10 checks UPU S10 parcel numbers such as "RR123456785PL"
+	ChecksumS10 = "s10"
+)
+
+// MaxIdentifierLength is the size of the order_ref and driver_code columns
+const MaxIdentifierLength = 255
+
+// FieldRule normalizes and checks one identifier. Values are always trimmed;
+// UpperCase also upper-cases them. The zero FieldRule only requires a value
+// of at most MaxIdentifierLength characters.
+type FieldRule struct {
+	// Pattern, if set, must match the normalized value
+	Pattern   *regexp.Regexp
+	MinLength int
+	// MaxLength of 0 means MaxIdentifierLength
+	MaxLength int
+	Checksum  string
+	UpperCase bool
+}
+
+// IdentifierRules are the rules for both identifiers of a package
+type IdentifierRules struct {
+	OrderRef   FieldRule
+	DriverCode FieldRule
+}
+
+var s10Format = regexp.MustCompile(`^[A-Z]{2}[0-9]{9}[A-Z]{2}$`)
+
+var s10Weights = [8]int{8, 6, 4, 2, 3, 5, 9, 7}
+
+// parcelSuffix is the "/<n>" a default parcel reference adds to its
+// shipment's order reference
+var parcelSuffix = regexp.MustCompile(`/[0-9]{1,3}$`)
+
+func (f FieldRule) normalize(value string) string {
+	value = strings.TrimSpace(value)
+	if f.UpperCase {
+		value = strings.ToUpper(value)
+	}
+	return value
+}
+
+// check reports what is wrong with the normalized value, if anything. label
+// names the field in messages.
+func (f FieldRule) check(field, label, value string) *domain.FieldError {
+	maxLength := f.MaxLength
+	if maxLength <= 0 || maxLength > MaxIdentifierLength {
+		maxLength = MaxIdentifierLength
+	}
+	length := len([]rune(value))
+
+	switch {
+	case value == "":
+		return &domain.FieldError{Field: field, Code: "required", Message: label + " is required"}
+	case length < f.MinLength:
+		return &domain.FieldError{Field: field, Code: "too_short", Message: label + " must be at least " + strconv.Itoa(f.MinLength) + " characters"}
+	case length > maxLength:
+		return &domain.FieldError{Field: field, Code: "too_long", Message: label + " must be at most " + strconv.Itoa(maxLength) + " characters"}
+	case f.Pattern != nil && !f.Pattern.MatchString(value):
+		return &domain.FieldError{Field: field, Code: "format", Message: label + " does not match the required format"}
+	case f.Checksum != "" && !validChecksum(f.Checksum, value):
+		return &domain.FieldError{Field: field, Code: "checksum", Message: label + " has an invalid check digit"}
+	}
+	return nil
+}
+
+// checkParcelRef checks a parcel reference, which is either valid under the
+// rule itself or an order reference valid under it followed by a parcel
+// suffix
+func (f FieldRule) checkParcelRef(field, value string) *domain.FieldError {
+	fieldErr := f.check(field, "parcel reference", value)
+	if fieldErr == nil || len([]rune(value)) > MaxIdentifierLength {
+		return fieldErr
+	}
+	if loc := parcelSuffix.FindStringIndex(value); loc != nil && f.check(field, "parcel reference", value[:loc[0]]) == nil {
+		return nil
+	}
+	return fieldErr
+}
+
+func validChecksum(algorithm, value string) bool {
+	switch algorithm {
+	case ChecksumLuhn:
+		return luhnValid(phoneDigits(value))
+	case ChecksumS10:
+		return s10Valid(strings.ToUpper(value))
+	}
+	return false
+}
+
+func luhnValid(digits string) bool {
+	if len(digits) < 2 {
+		return false
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 0 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// s10Valid checks the mod 11 check digit of a UPU S10 number: two letters,
+// eight serial digits, the check digit and a two letter country code
+func s10Valid(value string) bool {
+	if !s10Format.MatchString(value) {
+		return false
+	}
+	sum := 0
+	for i, w := range s10Weights {
+		sum += int(value[2+i]-'0') * w
+	}
+	check := 11 - sum%11
+	switch check {
+	case 10:
+		check = 0
+	case 11:
+		check = 5
+	}
+	return int(value[10]-'0') == check
+}
+
+// WithIdentifierRules sets the rules order references and driver codes are
+// checked against: carriers' rules for packages created for that carrier,
+// def for all others. Lookups by order reference normalize the reference
+// with the first rule set it is valid under, default rules first.
+func (pu *PackageUsecase) WithIdentifierRules(def IdentifierRules, carriers map[string]IdentifierRules) *PackageUsecase {
+	pu.rules = def
+	pu.carrierRules = carriers
+	pu.carriers = make([]string, 0, len(carriers))
+	for carrier := range carriers {
+		pu.carriers = append(pu.carriers, carrier)
+	}
+	sort.Strings(pu.carriers)
+	return pu
+}
+
+// rulesFor returns the rules of carrier, or the default rules without one
+func (pu *PackageUsecase) rulesFor(carrier string) (IdentifierRules, bool) {
+	if carrier == "" {
+		return pu.rules, true
+	}
+	rules, ok := pu.carrierRules[carrier]
+	return rules, ok
+}
+
+// normalizeOrderRef prepares an order reference for a lookup; parcel
+// references of shipments are accepted too. A reference that is valid under
+// no rule set cannot belong to any package.
+func (pu *PackageUsecase) normalizeOrderRef(orderRef string) (string, *domain.FieldError) {
+	normalized := pu.rules.OrderRef.normalize(orderRef)
+	fieldErr := pu.rules.OrderRef.check("order_reference", "order reference", normalized)
+	if fieldErr == nil || pu.rules.OrderRef.checkParcelRef("order_reference", normalized) == nil {
+		return normalized, nil
+	}
+	for _, carrier := range pu.carriers {
+		rule := pu.carrierRules[carrier].OrderRef
+		if candidate := rule.normalize(orderRef); rule.checkParcelRef("order_reference", candidate) == nil {
+			return candidate, nil
+		}
+	}
+	return "", fieldErr
+}
+
+// normalizeDriverCode checks a driver code given in field against the rules
+// of carrier. Packages of a carrier no longer configured fall back to the
+// zero rules.
+func (pu *PackageUsecase) normalizeDriverCode(carrier, field, driverCode string) (string, error) {
+	rules, _ := pu.rulesFor(carrier)
+	driverCode = rules.DriverCode.normalize(driverCode)
+	if fieldErr := rules.DriverCode.check(field, "driver code", driverCode); fieldErr != nil {
+		return "", domain.NewValidationError(*fieldErr)
+	}
+	return driverCode, nil
+}
@@ -0,0 +1,122 @@
+package usecase_test
+
+import (
+	"regexp"
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdentifierRules(t *testing.T) *usecase.PackageUsecase {
+	repo := repository.NewMemoryPackageRepository()
+	return usecase.NewPackageUsecase(repo).WithIdentifierRules(
+		usecase.IdentifierRules{
+			OrderRef:   usecase.FieldRule{Pattern: regexp.MustCompile(`^[A-Z]{3}-[0-9]{3}$`), UpperCase: true},
+			DriverCode: usecase.FieldRule{MinLength: 3, MaxLength: 10, UpperCase: true},
+		},
+		map[string]usecase.IdentifierRules{
+			"upu": {OrderRef: usecase.FieldRule{Checksum: usecase.ChecksumS10, UpperCase: true}},
+		},
+	)
+}
+
+// fieldCodes returns the field errors of err as field: code pairs
+func fieldCodes(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	codes := make(map[string]string, len(domainErr.Fields))
+	for _, f := range domainErr.Fields {
+		codes[f.Field] = f.Code
+	}
+	return codes
+}
+
+func TestPackageUsecase_IdentifierRules_HappyPath_NormalizesOnCreateAndLookup(t *testing.T) {
+	// Setup
+	uc := setupIdentifierRules(t)
+
+	// Execute
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: " abc-001 ", DriverCode: "drv-1"})
+	require.NoError(t, err)
+	found, lookupErr := uc.GetPackageByOrderRef("abc-001")
+
+	// Assert
+	assert.Equal(t, "ABC-001", pkg.OrderRef)
+	assert.Equal(t, "DRV-1", pkg.DriverCode)
+	require.NoError(t, lookupErr)
+	assert.Equal(t, pkg.ID, found.ID)
+}
+
+func TestPackageUsecase_IdentifierRules_HappyPath_CarrierChecksum(t *testing.T) {
+	// Setup
+	uc := setupIdentifierRules(t)
+
+	// Execute
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "rr123456785pl", DriverCode: "DRV-1", Carrier: "upu"})
+	require.NoError(t, err)
+	found, lookupErr := uc.GetPackageByOrderRef("rr123456785pl")
+	_, badErr := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "RR123456784PL", DriverCode: "DRV-1", Carrier: "upu"})
+
+	// Assert
+	assert.Equal(t, "RR123456785PL", pkg.OrderRef)
+	assert.Equal(t, "upu", pkg.Carrier)
+	require.NoError(t, lookupErr)
+	assert.Equal(t, pkg.ID, found.ID)
+	assert.Equal(t, map[string]string{"order_reference": "checksum"}, fieldCodes(t, badErr))
+}
+
+func TestPackageUsecase_IdentifierRules_EdgeCase_FieldErrors(t *testing.T) {
+	// Setup
+	uc := setupIdentifierRules(t)
+
+	// Execute
+	_, formatErr := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-1", DriverCode: "D1"})
+	_, carrierErr := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-001", DriverCode: "DRV-1", Carrier: "nope"})
+	_, lengthErr := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-001", DriverCode: "DRIVER-0001"})
+
+	// Assert
+	assert.ErrorIs(t, formatErr, domain.ErrValidation)
+	assert.Equal(t, map[string]string{"order_reference": "format", "driver_code": "too_short"}, fieldCodes(t, formatErr))
+	assert.Equal(t, "unknown", fieldCodes(t, carrierErr)["carrier"])
+	assert.Equal(t, map[string]string{"driver_code": "too_long"}, fieldCodes(t, lengthErr))
+}
+
+func TestPackageUsecase_IdentifierRules_EdgeCase_InvalidLookup(t *testing.T) {
+	// Setup
+	uc := setupIdentifierRules(t)
+
+	// Execute
+	pkg, err := uc.GetPackageByOrderRef("not a reference")
+
+	// Assert
+	assert.Nil(t, pkg)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Equal(t, map[string]string{"order_reference": "format"}, fieldCodes(t, err))
+}
+
+func TestPackageUsecase_IdentifierRules_EdgeCase_BatchReportsInvalidReference(t *testing.T) {
+	// Setup
+	uc := setupIdentifierRules(t)
+	_, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-001", DriverCode: "DRV-1"})
+	require.NoError(t, err)
+
+	// Execute
+	results, err := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{
+		OrderRefs: []string{"abc-001", "bogus"},
+		Status:    domain.StatusPicked,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "ABC-001", results[0].OrderRef)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "order reference does not match the required format", results[1].Error)
+}
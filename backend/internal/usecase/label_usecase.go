@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"pickup-queue/pkg/barcode"
+	"pickup-queue/pkg/label"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrLabelNotPrintable is returned when a package's order reference cannot
+// be encoded in the label's barcode, because it is too long or holds
+// characters Code 128 has no symbol for
+var ErrLabelNotPrintable = domain.NewError(domain.KindUnprocessable, "LABEL_NOT_PRINTABLE", "the order reference cannot be encoded in the label barcode")
+
+type LabelUsecase struct {
+	packages *PackageUsecase
+	site     string
+	options  label.Options
+}
+
+// NewLabelUsecase creates a usecase printing labels headed with site at the
+// given printer resolution; a dpi of 0 uses label.DefaultDPI
+func NewLabelUsecase(packages *PackageUsecase, site string, dpi int) *LabelUsecase {
+	return &LabelUsecase{packages: packages, site: site, options: label.Options{DPI: dpi}}
+}
+
+// PackageLabel renders the label of a live or archived package. format is
+// pdf, png or zpl and defaults to pdf.
+func (lu *LabelUsecase) PackageLabel(id uuid.UUID, format string) ([]byte, label.Format, error) {
+	f, err := parseLabelFormat(format, false)
+	if err != nil {
+		return nil, "", err
+	}
+	pkg, err := lu.packages.GetPackage(id)
+	if err != nil {
+		return nil, "", err
+	}
+	return lu.render(f, []*domain.Package{pkg})
+}
+
+// BatchLabels renders the labels of up to MaxBatchSize packages as one
+// multi-page PDF or one ZPL stream. Unknown packages fail the whole batch
+// and are listed in the error.
+func (lu *LabelUsecase) BatchLabels(req *domain.BatchLabelRequest) ([]byte, label.Format, error) {
+	f, err := parseLabelFormat(req.Format, true)
+	if err != nil {
+		return nil, "", err
+	}
+	total := len(req.IDs) + len(req.OrderRefs)
+	if total == 0 {
+		return nil, "", ErrEmptyBatch
+	}
+	if total > MaxBatchSize {
+		return nil, "", ErrBatchTooLarge
+	}
+
+	packages := make([]*domain.Package, 0, total)
+	var missing []domain.FieldError
+	add := func(field string, pkg *domain.Package, err error) error {
+		if errors.Is(err, ErrPackageNotFound) || (err == nil && pkg == nil) {
+			missing = append(missing, domain.FieldError{Field: field, Code: "not_found", Message: "package not found"})
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		packages = append(packages, pkg)
+		return nil
+	}
+	for i, id := range req.IDs {
+		pkg, err := lu.packages.GetPackage(id)
+		if err := add(fmt.Sprintf("ids[%d]", i), pkg, err); err != nil {
+			return nil, "", err
+		}
+	}
+	for i, orderRef := range req.OrderRefs {
+		pkg, err := lu.packages.GetPackageByOrderRef(orderRef)
+		if errors.Is(err, domain.ErrValidation) {
+			err = ErrPackageNotFound
+		}
+		if err := add(fmt.Sprintf("order_references[%d]", i), pkg, err); err != nil {
+			return nil, "", err
+		}
+	}
+	if len(missing) > 0 {
+		return nil, "", ErrPackageNotFound.WithFields(missing...)
+	}
+	return lu.render(f, packages)
+}
+
+func (lu *LabelUsecase) render(f label.Format, packages []*domain.Package) ([]byte, label.Format, error) {
+	labels := make([]label.Label, len(packages))
+	for i, pkg := range packages {
+		labels[i] = lu.labelFor(pkg)
+	}
+	out, err := label.Render(f, labels, lu.options)
+	if errors.Is(err, label.ErrBarcodeTooWide) || errors.Is(err, barcode.ErrUnencodable) {
+		return nil, "", ErrLabelNotPrintable
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return out, f, nil
+}
+
+// labelFor collects what gets printed for pkg
+func (lu *LabelUsecase) labelFor(pkg *domain.Package) label.Label {
+	l := label.Label{
+		Site:       lu.site,
+		OrderRef:   pkg.OrderRef,
+		PackageID:  pkg.ID.String(),
+		DriverCode: pkg.DriverCode,
+		Slot:       pkg.SlotLocation,
+		Carrier:    pkg.Carrier,
+		CreatedAt:  pkg.CreatedAt,
+	}
+	if pkg.ParcelNumber > 0 {
+		l.Parcel = strconv.Itoa(pkg.ParcelNumber)
+		if pkg.ShipmentID != nil && lu.packages.shipments != nil {
+			if shipment, _ := lu.packages.shipments.GetByID(*pkg.ShipmentID); shipment != nil {
+				l.Parcel += "/" + strconv.Itoa(shipment.ParcelCount)
+			}
+		}
+	} else if pkg.ParcelCount > 1 {
+		l.Parcel = strconv.Itoa(pkg.ParcelCount) + " PARCELS"
+	}
+	if pkg.Fragile {
+		l.Marks = append(l.Marks, "FRAGILE")
+	}
+	if pkg.Temperature != "" && pkg.Temperature != domain.TemperatureAmbient {
+		l.Marks = append(l.Marks, string(pkg.Temperature))
+	}
+	return l
+}
+
+// parseLabelFormat resolves the requested format, pdf when none is given
+func parseLabelFormat(name string, batch bool) (label.Format, error) {
+	if strings.TrimSpace(name) == "" {
+		return label.FormatPDF, nil
+	}
+	f, ok := label.ParseFormat(name)
+	switch {
+	case !ok:
+		return "", domain.NewValidationError(domain.FieldError{Field: "format", Code: "oneof", Message: "format must be one of pdf png zpl"})
+	case batch && !f.Batchable():
+		return "", domain.NewValidationError(domain.FieldError{Field: "format", Code: "oneof", Message: "format must be one of pdf zpl for batches"})
+	}
+	return f, nil
+}
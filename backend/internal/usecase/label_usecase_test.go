@@ -0,0 +1,133 @@
+package usecase_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/usecase"
+	"pickup-queue/pkg/label"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelUsecase_PackageLabel_HappyPath(t *testing.T) {
+	// Setup
+	shipments, packages := setupShipments(t)
+	uc := usecase.NewLabelUsecase(packages, "Pickup Point Central", 0)
+	shipment, err := shipments.CreateShipment(&domain.CreateShipmentRequest{
+		OrderRef:   "ORD-100",
+		DriverCode: "DRV-001",
+		Parcels: []domain.CreateParcelRequest{
+			{PackageAttributes: domain.PackageAttributes{SlotLocation: " A-03-2 ", Fragile: true}},
+			{},
+		},
+	})
+	require.NoError(t, err)
+	parcel := shipment.Parcels[0]
+
+	// Execute
+	zpl, zplFormat, zplErr := uc.PackageLabel(parcel.ID, "zpl")
+	pdf, pdfFormat, pdfErr := uc.PackageLabel(parcel.ID, "")
+	img, _, pngErr := uc.PackageLabel(parcel.ID, "png")
+
+	// Assert
+	require.NoError(t, zplErr)
+	assert.Equal(t, label.FormatZPL, zplFormat)
+	assert.Contains(t, string(zpl), "^FDORD-100/1^FS")
+	assert.Contains(t, string(zpl), "^FDA-03-2^FS")
+	assert.Contains(t, string(zpl), "^FDDRV-001^FS")
+	assert.Contains(t, string(zpl), "1/2")
+	assert.Contains(t, string(zpl), "FRAGILE")
+	assert.Contains(t, string(zpl), "^FDMA,"+parcel.ID.String()+"^FS")
+
+	require.NoError(t, pdfErr)
+	assert.Equal(t, label.FormatPDF, pdfFormat)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+
+	require.NoError(t, pngErr)
+	_, err = png.Decode(bytes.NewReader(img))
+	assert.NoError(t, err)
+}
+
+func TestLabelUsecase_PackageLabel_EdgeCase_Rejected(t *testing.T) {
+	// Setup
+	_, packages := setupShipments(t)
+	uc := usecase.NewLabelUsecase(packages, "Pickup Point Central", 0)
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: strings.Repeat("AB", 40), DriverCode: "DRV-001"})
+	require.NoError(t, err)
+
+	// Execute
+	_, _, missingErr := uc.PackageLabel(uuid.New(), "pdf")
+	_, _, formatErr := uc.PackageLabel(pkg.ID, "svg")
+	_, _, wideErr := uc.PackageLabel(pkg.ID, "pdf")
+
+	// Assert
+	assert.ErrorIs(t, missingErr, usecase.ErrPackageNotFound)
+	assert.ErrorIs(t, formatErr, domain.ErrValidation)
+	assert.ErrorIs(t, wideErr, usecase.ErrLabelNotPrintable)
+}
+
+func TestLabelUsecase_PackageLabel_EdgeCase_NonASCIIOrderRef(t *testing.T) {
+	// Setup
+	_, packages := setupShipments(t)
+	uc := usecase.NewLabelUsecase(packages, "Pickup Point Central", 0)
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ZAMÓWIENIE-1", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+
+	// Execute
+	_, _, err = uc.PackageLabel(pkg.ID, "pdf")
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrLabelNotPrintable)
+}
+
+func TestLabelUsecase_BatchLabels_HappyPath(t *testing.T) {
+	// Setup
+	_, packages := setupShipments(t)
+	uc := usecase.NewLabelUsecase(packages, "Pickup Point Central", 0)
+	first, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-1", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	_, err = packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-2", DriverCode: "DRV-002"})
+	require.NoError(t, err)
+
+	// Execute
+	out, format, err := uc.BatchLabels(&domain.BatchLabelRequest{
+		IDs:       []uuid.UUID{first.ID},
+		OrderRefs: []string{"ORD-2"},
+		Format:    "zpl",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, label.FormatZPL, format)
+	assert.Equal(t, 2, strings.Count(string(out), "^XA"))
+	assert.Contains(t, string(out), "^FDDRV-002^FS")
+}
+
+func TestLabelUsecase_BatchLabels_EdgeCase_Rejected(t *testing.T) {
+	// Setup
+	_, packages := setupShipments(t)
+	uc := usecase.NewLabelUsecase(packages, "Pickup Point Central", 0)
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-1", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+
+	// Execute
+	_, _, missingErr := uc.BatchLabels(&domain.BatchLabelRequest{IDs: []uuid.UUID{pkg.ID}, OrderRefs: []string{"ORD-404"}})
+	_, _, pngErr := uc.BatchLabels(&domain.BatchLabelRequest{IDs: []uuid.UUID{pkg.ID}, Format: "png"})
+	_, _, emptyErr := uc.BatchLabels(&domain.BatchLabelRequest{})
+
+	// Assert
+	assert.ErrorIs(t, missingErr, usecase.ErrPackageNotFound)
+	var domainErr *domain.Error
+	require.True(t, errors.As(missingErr, &domainErr))
+	require.Len(t, domainErr.Fields, 1)
+	assert.Equal(t, "order_references[0]", domainErr.Fields[0].Field)
+	assert.ErrorIs(t, pngErr, domain.ErrValidation)
+	assert.ErrorIs(t, emptyErr, usecase.ErrEmptyBatch)
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"pickup-queue/internal/domain"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrHandoverVerificationRequired is returned when a high-value package is
+// handed over without the recipient's verification token
+var ErrHandoverVerificationRequired = domain.NewError(domain.KindUnprocessable, "HANDOVER_VERIFICATION_REQUIRED",
+	"high-value packages can only be handed over with the recipient's verification token")
+
+// MaxSlotLocationLength is the longest shelf slot a package may be stored in
+const MaxSlotLocationLength = 32
+
+// WithHighValueThreshold makes packages declared at or above threshold high
+// value: they need a recipient phone on create and the last 4 digits of it
+// to be handed over. A threshold of 0 disables the checks.
+func (pu *PackageUsecase) WithHighValueThreshold(threshold int64) *PackageUsecase {
+	pu.highValueThreshold = threshold
+	return pu
+}
+
+// IsHighValue reports whether pkg needs verification to be handed over
+func (pu *PackageUsecase) IsHighValue(pkg *domain.Package) bool {
+	return pu.highValueThreshold > 0 && pkg.DeclaredValue != nil && *pkg.DeclaredValue >= pu.highValueThreshold
+}
+
+// applyAttributes validates the physical attributes and copies them to pkg,
+// deriving its size class. It returns what is wrong with them.
+func applyAttributes(pkg *domain.Package, attrs domain.PackageAttributes) []domain.FieldError {
+	var fields []domain.FieldError
+	positive := func(field string, value *int) {
+		if value != nil && *value <= 0 {
+			fields = append(fields, domain.FieldError{Field: field, Code: "min", Message: field + " must be at least 1"})
+		}
+	}
+
+	positive("length_cm", attrs.LengthCm)
+	positive("width_cm", attrs.WidthCm)
+	positive("height_cm", attrs.HeightCm)
+	positive("weight_grams", attrs.WeightGrams)
+
+	dimensions := 0
+	for _, d := range []*int{attrs.LengthCm, attrs.WidthCm, attrs.HeightCm} {
+		if d != nil {
+			dimensions++
+		}
+	}
+	if dimensions != 0 && dimensions != 3 {
+		fields = append(fields, domain.FieldError{Field: "dimensions", Code: "incomplete", Message: "length_cm, width_cm and height_cm must be given together"})
+	}
+	if attrs.DeclaredValue != nil && *attrs.DeclaredValue < 0 {
+		fields = append(fields, domain.FieldError{Field: "declared_value", Code: "min", Message: "declared_value must not be negative"})
+	}
+
+	slot := strings.TrimSpace(attrs.SlotLocation)
+	if len([]rune(slot)) > MaxSlotLocationLength {
+		fields = append(fields, domain.FieldError{Field: "slot_location", Code: "too_long", Message: "slot_location must be at most " + strconv.Itoa(MaxSlotLocationLength) + " characters"})
+	}
+
+	temperature := domain.Temperature(strings.ToUpper(strings.TrimSpace(string(attrs.Temperature))))
+	if temperature == "" {
+		temperature = domain.TemperatureAmbient
+	}
+	if !temperature.Valid() {
+		fields = append(fields, domain.FieldError{Field: "temperature", Code: "oneof", Message: "temperature must be one of AMBIENT CHILLED FROZEN"})
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	pkg.LengthCm, pkg.WidthCm, pkg.HeightCm = attrs.LengthCm, attrs.WidthCm, attrs.HeightCm
+	pkg.WeightGrams = attrs.WeightGrams
+	pkg.DeclaredValue = attrs.DeclaredValue
+	pkg.Fragile = attrs.Fragile
+	pkg.Temperature = temperature
+	pkg.SlotLocation = slot
+	pkg.SizeClass = domain.SizeUnknown
+	if dimensions == 3 {
+		pkg.SizeClass = domain.ClassifySize(*attrs.LengthCm, *attrs.WidthCm, *attrs.HeightCm)
+	}
+	return nil
+}
+
+// HandOverPackage hands a package over to its recipient. High-value packages
+// need token, the last 4 digits of the recipient's phone; for others it is
+// ignored.
+func (pu *PackageUsecase) HandOverPackage(id uuid.UUID, token string) (*domain.Package, error) {
+	return pu.updatePackageStatus(id, domain.StatusHandedOver, token)
+}
+
+// verifyHandover checks the verification token when pkg is about to be handed
+// over and is high value
+func (pu *PackageUsecase) verifyHandover(pkg *domain.Package, newStatus domain.PackageStatus, token string) error {
+	if newStatus != domain.StatusHandedOver || pkg.Status == newStatus || !pu.IsHighValue(pkg) {
+		return nil
+	}
+	if !tokenMatches(pkg.RecipientPhone, token) {
+		return ErrHandoverVerificationRequired.WithFields(domain.FieldError{
+			Field:   "verification_token",
+			Code:    "mismatch",
+			Message: "verification_token must be the last 4 digits of the recipient's phone",
+		})
+	}
+	return nil
+}
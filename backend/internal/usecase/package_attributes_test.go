@@ -0,0 +1,176 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(n int) *int { return &n }
+
+func int64Ptr(n int64) *int64 { return &n }
+
+func setupHighValue(t *testing.T) *usecase.PackageUsecase {
+	return usecase.NewPackageUsecase(repository.NewMemoryPackageRepository()).WithHighValueThreshold(50000)
+}
+
+func TestPackageUsecase_Attributes_HappyPath_StoredWithSizeClass(t *testing.T) {
+	// Setup
+	uc := setupHighValue(t)
+
+	// Execute
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{
+		OrderRef:   "ABC-001",
+		DriverCode: "DRV-1",
+		PackageAttributes: domain.PackageAttributes{
+			LengthCm:    intPtr(10),
+			WidthCm:     intPtr(40),
+			HeightCm:    intPtr(30),
+			WeightGrams: intPtr(2500),
+			Fragile:     true,
+			Temperature: "chilled",
+		},
+	})
+	require.NoError(t, err)
+	bare, bareErr := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-002", DriverCode: "DRV-1"})
+
+	// Assert
+	assert.Equal(t, domain.SizeMedium, pkg.SizeClass)
+	assert.Equal(t, intPtr(2500), pkg.WeightGrams)
+	assert.True(t, pkg.Fragile)
+	assert.Equal(t, domain.TemperatureChilled, pkg.Temperature)
+	assert.Equal(t, 1, pkg.ParcelCount)
+	require.NoError(t, bareErr)
+	assert.Equal(t, domain.SizeUnknown, bare.SizeClass)
+	assert.Equal(t, domain.TemperatureAmbient, bare.Temperature)
+}
+
+func TestPackageUsecase_Attributes_HappyPath_ClassifySize(t *testing.T) {
+	// Setup
+	cases := map[domain.SizeClass][3]int{
+		domain.SizeSmall:  {35, 25, 10},
+		domain.SizeMedium: {20, 45, 35},
+		domain.SizeLarge:  {40, 65, 45},
+		domain.SizeXLarge: {66, 10, 10},
+	}
+
+	for want, d := range cases {
+		// Execute
+		got := domain.ClassifySize(d[0], d[1], d[2])
+
+		// Assert
+		assert.Equal(t, want, got, "%v", d)
+	}
+}
+
+func TestPackageUsecase_Attributes_EdgeCase_FieldErrors(t *testing.T) {
+	// Setup
+	uc := setupHighValue(t)
+
+	// Execute
+	_, err := uc.CreatePackage(&domain.CreatePackageRequest{
+		OrderRef:   "ABC-001",
+		DriverCode: "DRV-1",
+		PackageAttributes: domain.PackageAttributes{
+			LengthCm:      intPtr(10),
+			WeightGrams:   intPtr(0),
+			DeclaredValue: int64Ptr(-1),
+			Temperature:   "WARM",
+		},
+		ParcelCount: -2,
+	})
+	_, phoneErr := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-002", DriverCode: "DRV-1", PackageAttributes: domain.PackageAttributes{DeclaredValue: int64Ptr(50000)}})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Equal(t, map[string]string{
+		"dimensions":     "incomplete",
+		"weight_grams":   "min",
+		"declared_value": "min",
+		"temperature":    "oneof",
+		"parcel_count":   "min",
+	}, fieldCodes(t, err))
+	assert.Equal(t, map[string]string{"recipient_phone": "required"}, fieldCodes(t, phoneErr))
+}
+
+func TestPackageUsecase_HighValue_HappyPath_HandOverWithToken(t *testing.T) {
+	// Setup
+	uc := setupHighValue(t)
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{
+		OrderRef:          "ABC-001",
+		DriverCode:        "DRV-1",
+		RecipientPhone:    "+48 600 100 200",
+		PackageAttributes: domain.PackageAttributes{DeclaredValue: int64Ptr(75000)},
+	})
+	require.NoError(t, err)
+	_, err = uc.UpdatePackageStatus(pkg.ID, domain.StatusPicked)
+	require.NoError(t, err)
+
+	// Execute
+	handed, err := uc.HandOverPackage(pkg.ID, "0200")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, uc.IsHighValue(handed))
+	assert.Equal(t, domain.StatusHandedOver, handed.Status)
+}
+
+func TestPackageUsecase_HighValue_EdgeCase_HandOverRejected(t *testing.T) {
+	// Setup
+	uc := setupHighValue(t)
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{
+		OrderRef:          "ABC-001",
+		DriverCode:        "DRV-1",
+		RecipientPhone:    "+48 600 100 200",
+		PackageAttributes: domain.PackageAttributes{DeclaredValue: int64Ptr(75000)},
+	})
+	require.NoError(t, err)
+	_, err = uc.UpdatePackageStatus(pkg.ID, domain.StatusPicked)
+	require.NoError(t, err)
+
+	// Execute
+	_, plainErr := uc.UpdatePackageStatus(pkg.ID, domain.StatusHandedOver)
+	_, wrongErr := uc.HandOverPackage(pkg.ID, "1234")
+	results, batchErr := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{
+		OrderRefs:    []string{"ABC-001"},
+		Status:       domain.StatusHandedOver,
+		AllOrNothing: true,
+	})
+	current, getErr := uc.GetPackage(pkg.ID)
+
+	// Assert
+	assert.ErrorIs(t, plainErr, usecase.ErrHandoverVerificationRequired)
+	assert.ErrorIs(t, wrongErr, usecase.ErrHandoverVerificationRequired)
+	assert.Equal(t, map[string]string{"verification_token": "mismatch"}, fieldCodes(t, wrongErr))
+	assert.ErrorIs(t, batchErr, usecase.ErrBatchRejected)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	require.NoError(t, getErr)
+	assert.Equal(t, domain.StatusPicked, current.Status)
+}
+
+func TestPackageUsecase_HighValue_EdgeCase_ListFilter(t *testing.T) {
+	// Setup
+	uc := setupHighValue(t)
+	_, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "CHEAP", DriverCode: "DRV-1", PackageAttributes: domain.PackageAttributes{DeclaredValue: int64Ptr(100)}})
+	require.NoError(t, err)
+	_, err = uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "PRICEY", DriverCode: "DRV-1", PackageAttributes: domain.PackageAttributes{DeclaredValue: int64Ptr(50000)}, RecipientPhone: "600100200"})
+	require.NoError(t, err)
+	disabled := usecase.NewPackageUsecase(repository.NewMemoryPackageRepository())
+
+	// Execute
+	highValue, err := uc.ListPackages(domain.PackageFilter{HighValue: true})
+	none, disabledErr := disabled.ListPackages(domain.PackageFilter{HighValue: true})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, highValue, 1)
+	assert.Equal(t, "PRICEY", highValue[0].OrderRef)
+	require.NoError(t, disabledErr)
+	assert.Empty(t, none)
+}
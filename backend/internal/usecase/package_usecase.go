@@ -1,54 +1,171 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
 	"pickup-queue/internal/domain"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrPackageNotFound         = errors.New("package not found")
-	ErrDuplicateOrderRef       = errors.New("order reference already exists")
-	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	ErrPackageNotFound         = domain.NewError(domain.KindNotFound, "PACKAGE_NOT_FOUND", "package not found")
+	ErrDuplicateOrderRef       = domain.ErrDuplicateOrderRef
+	ErrInvalidStatusTransition = domain.NewError(domain.KindValidation, "INVALID_STATUS_TRANSITION", "invalid status transition")
+	ErrEmptyBatch              = domain.NewError(domain.KindValidation, "BATCH_EMPTY", "batch must reference at least one package")
+	ErrBatchTooLarge           = domain.NewError(domain.KindValidation, "BATCH_TOO_LARGE", "batch exceeds the maximum size")
+	ErrBatchRejected           = domain.NewError(domain.KindUnprocessable, "BATCH_REJECTED", "batch rejected, no packages were updated")
 )
 
+// DefaultExpiryWindow is how long a package may stay active before it expires
+const DefaultExpiryWindow = 24 * time.Hour
+
+// MaxBatchSize caps how many packages one batch status update may touch
+const MaxBatchSize = 100
+
+// ArchiveBatchSize is how many packages the archive job moves per statement,
+// keeping each transaction short
+const ArchiveBatchSize = 500
+
 type PackageUsecase struct {
-	packageRepo domain.PackageRepository
+	packageRepo  domain.PackageRepository
+	uow          domain.UnitOfWork
+	archive      domain.PackageArchiveRepository
+	expiryWindow atomic.Int64
+
+	rules        IdentifierRules
+	carrierRules map[string]IdentifierRules
+	// carriers lists the carrierRules keys in order, for lookups
+	carriers []string
+
+	highValueThreshold int64
+	// shipments, when set, keeps package and shipment order references apart
+	shipments domain.ShipmentRepository
+	// queue tunes the wait estimates on WAITING packages
+	queue QueueSettings
+	// serviceLevels holds a map[domain.Priority]ServiceLevel, swapped on reload
+	serviceLevels atomic.Pointer[map[domain.Priority]ServiceLevel]
 }
 
 func NewPackageUsecase(packageRepo domain.PackageRepository) *PackageUsecase {
-	return &PackageUsecase{
+	return NewPackageUsecaseWithUnitOfWork(packageRepo, nil)
+}
+
+// NewPackageUsecaseWithUnitOfWork creates a usecase whose multi-step operations
+// run inside one transaction. Without a unit of work each call runs on its own.
+func NewPackageUsecaseWithUnitOfWork(packageRepo domain.PackageRepository, uow domain.UnitOfWork) *PackageUsecase {
+	pu := &PackageUsecase{
 		packageRepo: packageRepo,
+		uow:         uow,
+		queue:       DefaultQueueSettings,
 	}
+	pu.expiryWindow.Store(int64(DefaultExpiryWindow))
+	return pu
+}
+
+// WithArchive makes lookups by ID and order reference fall back to the cold
+// archive and enables ArchiveTerminalPackages and RehydratePackage
+func (pu *PackageUsecase) WithArchive(archive domain.PackageArchiveRepository) *PackageUsecase {
+	pu.archive = archive
+	return pu
+}
+
+// WithShipments reserves shipments' order references, so no package can be
+// created with one
+func (pu *PackageUsecase) WithShipments(shipments domain.ShipmentRepository) *PackageUsecase {
+	pu.shipments = shipments
+	return pu
+}
+
+// inTx runs fn with a package repository bound to a single transaction
+func (pu *PackageUsecase) inTx(opts domain.TxOptions, fn func(repo domain.PackageRepository) error) error {
+	if pu.uow == nil {
+		return fn(pu.packageRepo)
+	}
+	return pu.uow.Do(opts, func(tx domain.Tx) error {
+		return fn(tx.Packages())
+	})
+}
+
+// SetExpiryWindow changes how long packages may stay active; it is safe to call at runtime
+func (pu *PackageUsecase) SetExpiryWindow(window time.Duration) {
+	if window > 0 {
+		pu.expiryWindow.Store(int64(window))
+	}
+}
+
+// ExpiryWindow returns the current expiry window
+func (pu *PackageUsecase) ExpiryWindow() time.Duration {
+	return time.Duration(pu.expiryWindow.Load())
 }
 
 func (pu *PackageUsecase) CreatePackage(req *domain.CreatePackageRequest) (*domain.Package, error) {
 	// Validate input
-	if req.OrderRef == "" {
-		return nil, errors.New("order reference is required")
+	rules, pkg, fields := pu.newPackage(req.Carrier, req.DriverCode, req.RecipientPhone, req.Priority)
+	orderRef := rules.OrderRef.normalize(req.OrderRef)
+	if fieldErr := rules.OrderRef.check("order_reference", "order reference", orderRef); fieldErr != nil {
+		fields = append(fields, *fieldErr)
+	}
+	pkg.OrderRef = orderRef
+	fields = append(fields, applyAttributes(pkg, req.PackageAttributes)...)
+	pkg.ParcelCount = req.ParcelCount
+	if pkg.ParcelCount == 0 {
+		pkg.ParcelCount = 1
+	}
+	if pkg.ParcelCount < 0 {
+		fields = append(fields, domain.FieldError{Field: "parcel_count", Code: "min", Message: "parcel_count must be at least 1"})
 	}
-	if req.DriverCode == "" {
-		return nil, errors.New("driver code is required")
+	// High-value packages are only handed over against the recipient's phone
+	if pkg.RecipientPhone == "" && pu.IsHighValue(pkg) {
+		fields = append(fields, domain.FieldError{Field: "recipient_phone", Code: "required", Message: "recipient phone is required for high-value packages"})
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError(fields...)
 	}
 
-	// Check if order reference already exists
-	existing, _ := pu.packageRepo.GetByOrderRef(req.OrderRef)
-	if existing != nil {
-		return nil, ErrDuplicateOrderRef
+	// Shipments share the order reference namespace with packages
+	if pu.shipments != nil {
+		shipment, err := pu.shipments.GetByOrderRef(orderRef)
+		if err != nil {
+			return nil, err
+		}
+		if shipment != nil {
+			return nil, ErrDuplicateOrderRef
+		}
 	}
 
-	pkg := &domain.Package{
-		ID:         uuid.New(),
-		OrderRef:   req.OrderRef,
-		DriverCode: req.DriverCode,
-		Status:     domain.StatusWaiting,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+	// Archived packages keep their order reference reserved. The archive is
+	// checked outside the transaction: SQLite has a single connection, which
+	// the transaction holds. A package archived between this check and the
+	// insert leaves its reference both live and archived; packages.order_ref
+	// is unique, so rehydrating the archived one then fails with
+	// ErrDuplicateOrderRef instead of duplicating the live package.
+	if pu.archive != nil {
+		archived, err := pu.archive.GetByOrderRef(orderRef)
+		if err != nil {
+			return nil, err
+		}
+		if archived != nil {
+			return nil, ErrDuplicateOrderRef
+		}
 	}
 
-	err := pu.packageRepo.Create(pkg)
+	err := pu.inTx(domain.TxOptions{}, func(repo domain.PackageRepository) error {
+		// Check if order reference already exists
+		existing, err := repo.GetByOrderRef(orderRef)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrDuplicateOrderRef
+		}
+		return repo.Create(pkg)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -56,8 +173,52 @@ func (pu *PackageUsecase) CreatePackage(req *domain.CreatePackageRequest) (*doma
 	return pkg, nil
 }
 
+// newPackage validates the fields a package shares with the other parcels of
+// its shipment and returns a WAITING package carrying them, together with the
+// carrier's identifier rules
+func (pu *PackageUsecase) newPackage(carrier, driverCode, phone string, priority domain.Priority) (IdentifierRules, *domain.Package, []domain.FieldError) {
+	var fields []domain.FieldError
+	carrier = strings.TrimSpace(carrier)
+	rules, ok := pu.rulesFor(carrier)
+	if !ok {
+		fields = append(fields, domain.FieldError{Field: "carrier", Code: "unknown", Message: "carrier has no validation rules at this site"})
+	}
+	driverCode = rules.DriverCode.normalize(driverCode)
+	if fieldErr := rules.DriverCode.check("driver_code", "driver code", driverCode); fieldErr != nil {
+		fields = append(fields, *fieldErr)
+	}
+	phone = strings.TrimSpace(phone)
+	if phone != "" && (len(phone) > 32 || len(phoneDigits(phone)) < 4) {
+		fields = append(fields, domain.FieldError{Field: "recipient_phone", Code: "invalid", Message: "recipient phone must have at least 4 digits and at most 32 characters"})
+	}
+	priority = domain.Priority(strings.ToUpper(strings.TrimSpace(string(priority))))
+	if priority == "" {
+		priority = domain.PriorityStandard
+	}
+	if !priority.Valid() {
+		fields = append(fields, domain.FieldError{Field: "priority", Code: "oneof", Message: "priority must be one of EXPRESS STANDARD ECONOMY"})
+	}
+
+	now := time.Now()
+	return rules, &domain.Package{
+		ID:             uuid.New(),
+		DriverCode:     driverCode,
+		Status:         domain.StatusWaiting,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		RecipientPhone: phone,
+		Carrier:        carrier,
+		Priority:       priority,
+	}, fields
+}
+
+// GetPackage looks a package up by ID, falling back to the archive; archived
+// packages carry ArchivedAt
 func (pu *PackageUsecase) GetPackage(id uuid.UUID) (*domain.Package, error) {
 	pkg, err := pu.packageRepo.GetByID(id)
+	if err == nil && pkg == nil && pu.archive != nil {
+		pkg, err = pu.archive.GetByID(id)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -67,8 +228,20 @@ func (pu *PackageUsecase) GetPackage(id uuid.UUID) (*domain.Package, error) {
 	return pkg, nil
 }
 
+// GetPackageByOrderRef looks a package up by order reference, falling back to
+// the archive; archived packages carry ArchivedAt. The reference is
+// normalized like on create, and one that no rule set accepts is reported as
+// a validation error.
 func (pu *PackageUsecase) GetPackageByOrderRef(orderRef string) (*domain.Package, error) {
+	orderRef, fieldErr := pu.normalizeOrderRef(orderRef)
+	if fieldErr != nil {
+		return nil, domain.NewValidationError(*fieldErr)
+	}
+
 	pkg, err := pu.packageRepo.GetByOrderRef(orderRef)
+	if err == nil && pkg == nil && pu.archive != nil {
+		pkg, err = pu.archive.GetByOrderRef(orderRef)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -78,25 +251,75 @@ func (pu *PackageUsecase) GetPackageByOrderRef(orderRef string) (*domain.Package
 	return pkg, nil
 }
 
-func (pu *PackageUsecase) ListPackages(limit, offset int, status *domain.PackageStatus) ([]*domain.Package, error) {
-	return pu.packageRepo.GetAll(limit, offset, status)
+// ListPackages lists packages matching filter. With HighValue set only
+// packages at or above the high-value threshold match, none if it is disabled.
+func (pu *PackageUsecase) ListPackages(filter domain.PackageFilter) ([]*domain.Package, error) {
+	filter.DriverCode = pu.rules.DriverCode.normalize(filter.DriverCode)
+	if filter.HighValue {
+		if pu.highValueThreshold <= 0 {
+			return nil, nil
+		}
+		if filter.MinDeclaredValue == nil || *filter.MinDeclaredValue < pu.highValueThreshold {
+			threshold := pu.highValueThreshold
+			filter.MinDeclaredValue = &threshold
+		}
+	}
+	return pu.packageRepo.GetAll(filter)
 }
 
+// UpdatePackageStatus moves a package to newStatus. High-value packages
+// cannot be handed over this way; use HandOverPackage.
 func (pu *PackageUsecase) UpdatePackageStatus(id uuid.UUID, newStatus domain.PackageStatus) (*domain.Package, error) {
-	pkg, err := pu.packageRepo.GetByID(id)
+	return pu.updatePackageStatus(id, newStatus, "")
+}
+
+func (pu *PackageUsecase) updatePackageStatus(id uuid.UUID, newStatus domain.PackageStatus, token string) (*domain.Package, error) {
+	var pkg *domain.Package
+
+	// Serializable so two concurrent transitions of the same package cannot both pass validation
+	err := pu.inTx(domain.TxOptions{Isolation: domain.IsolationSerializable}, func(repo domain.PackageRepository) error {
+		var err error
+		pkg, err = repo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if pkg == nil {
+			return ErrPackageNotFound
+		}
+
+		if err := pu.verifyHandover(pkg, newStatus, token); err != nil {
+			return err
+		}
+		if err := pu.checkShipmentComplete(repo, pkg, newStatus); err != nil {
+			return err
+		}
+		changed, err := pu.applyStatusTransition(pkg, newStatus, time.Now())
+		if err != nil || !changed {
+			return err
+		}
+		return repo.Update(pkg)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if pkg == nil {
-		return nil, ErrPackageNotFound
+
+	return pkg, nil
+}
+
+// applyStatusTransition validates newStatus against the state machine and
+// applies it to pkg in memory, stamping the matching timestamp. It reports
+// false without error when pkg already has newStatus: repeating a transition
+// is a no-op, so scanner retries succeed instead of failing validation.
+func (pu *PackageUsecase) applyStatusTransition(pkg *domain.Package, newStatus domain.PackageStatus, now time.Time) (bool, error) {
+	if pkg.Status == newStatus {
+		return false, nil
 	}
 
 	// Validate status transition
 	if !pu.isValidStatusTransition(pkg.Status, newStatus) {
-		return nil, ErrInvalidStatusTransition
+		return false, ErrInvalidStatusTransition
 	}
 
-	now := time.Now()
 	pkg.Status = newStatus
 	pkg.UpdatedAt = now
 
@@ -107,16 +330,183 @@ func (pu *PackageUsecase) UpdatePackageStatus(id uuid.UUID, newStatus domain.Pac
 		pkg.HandedOverAt = &now
 	case domain.StatusExpired:
 		pkg.ExpiredAt = &now
+	case domain.StatusReturnPending:
+		pkg.ReturnPendingAt = &now
+	case domain.StatusReturned:
+		pkg.ReturnedAt = &now
+	case domain.StatusDisposed:
+		pkg.DisposedAt = &now
 	}
 
-	err = pu.packageRepo.Update(pkg)
-	if err != nil {
-		return nil, err
+	return true, nil
+}
+
+// BatchUpdatePackageStatus moves every package in req to req.Status and
+// returns one result per item, IDs first and then order references, in
+// request order. Results carry order references as normalized for lookup. Best-effort batches update each package on its own; with
+// AllOrNothing set the whole batch runs in one transaction and nothing is
+// written unless every item is valid, in which case ErrBatchRejected is
+// returned together with the per-item results. Batches carry no verification
+// token, so high-value packages cannot be handed over in one.
+func (pu *PackageUsecase) BatchUpdatePackageStatus(req *domain.BatchUpdateStatusRequest) ([]*domain.BatchStatusResult, error) {
+	results := make([]*domain.BatchStatusResult, 0, len(req.IDs)+len(req.OrderRefs))
+	for i := range req.IDs {
+		results = append(results, &domain.BatchStatusResult{ID: &req.IDs[i]})
+	}
+	for _, orderRef := range req.OrderRefs {
+		results = append(results, &domain.BatchStatusResult{OrderRef: orderRef})
 	}
 
-	return pkg, nil
+	if len(results) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(results) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	// Order references are normalized like on lookup; one that no rule set
+	// accepts fails without touching the store
+	invalid := make(map[*domain.BatchStatusResult]string)
+	for _, result := range results {
+		if result.ID != nil {
+			continue
+		}
+		orderRef, fieldErr := pu.normalizeOrderRef(result.OrderRef)
+		if fieldErr != nil {
+			invalid[result] = fieldErr.Message
+			continue
+		}
+		result.OrderRef = orderRef
+	}
+
+	if req.AllOrNothing {
+		return results, pu.batchUpdateAtomic(results, invalid, req.Status)
+	}
+
+	for _, result := range results {
+		if message, ok := invalid[result]; ok {
+			result.Error = message
+			continue
+		}
+		pkg, err := lookupBatchItem(pu.packageRepo, result)
+		if err == nil && pkg == nil {
+			err = ErrPackageNotFound
+		}
+		if err == nil {
+			pkg, err = pu.UpdatePackageStatus(pkg.ID, req.Status)
+		}
+		if err != nil {
+			result.Error = batchItemError(err)
+			continue
+		}
+		result.Success = true
+		result.Package = pkg
+	}
+
+	return results, nil
 }
 
+func (pu *PackageUsecase) batchUpdateAtomic(results []*domain.BatchStatusResult, invalid map[*domain.BatchStatusResult]string, newStatus domain.PackageStatus) error {
+	err := pu.inTx(domain.TxOptions{Isolation: domain.IsolationSerializable}, func(repo domain.PackageRepository) error {
+		now := time.Now()
+		seen := make(map[uuid.UUID]*domain.Package, len(results))
+		var changed []*domain.Package
+		rejected := false
+
+		// Validate every item before writing anything
+		for _, result := range results {
+			result.Error, result.Package = "", nil
+			if message, ok := invalid[result]; ok {
+				result.Error = message
+				rejected = true
+				continue
+			}
+
+			pkg, err := lookupBatchItem(repo, result)
+			if err != nil {
+				return err
+			}
+			if pkg == nil {
+				result.Error = ErrPackageNotFound.Error()
+				rejected = true
+				continue
+			}
+
+			// The same package listed twice is only transitioned once
+			if prev, ok := seen[pkg.ID]; ok {
+				result.Package = prev
+				continue
+			}
+			seen[pkg.ID] = pkg
+
+			ok := false
+			err = pu.verifyHandover(pkg, newStatus, "")
+			if err == nil {
+				err = pu.checkShipmentComplete(repo, pkg, newStatus)
+			}
+			if err == nil {
+				ok, err = pu.applyStatusTransition(pkg, newStatus, now)
+			}
+			if err != nil {
+				result.Error = batchItemError(err)
+				rejected = true
+				continue
+			}
+			result.Package = pkg
+			if ok {
+				changed = append(changed, pkg)
+			}
+		}
+		if rejected {
+			return ErrBatchRejected
+		}
+
+		for _, pkg := range changed {
+			if err := repo.Update(pkg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		for _, result := range results {
+			result.Success = true
+		}
+	case errors.Is(err, ErrBatchRejected):
+		for _, result := range results {
+			result.Package = nil
+			if result.Error == "" {
+				result.Error = "not applied: batch rejected"
+			}
+		}
+	}
+	return err
+}
+
+// batchItemError is what a batch result shows for err. Only domain errors are
+// shown as is; anything else is logged and reported generically so storage
+// details never reach the client.
+func batchItemError(err error) string {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Error()
+	}
+	log.Printf("Batch status update failed: %v", err)
+	return "internal error"
+}
+
+// lookupBatchItem resolves a batch item by ID, or by order reference when no ID was given
+func lookupBatchItem(repo domain.PackageRepository, result *domain.BatchStatusResult) (*domain.Package, error) {
+	if result.ID != nil {
+		return repo.GetByID(*result.ID)
+	}
+	return repo.GetByOrderRef(result.OrderRef)
+}
+
+// DeletePackage soft-deletes a package. It disappears from every listing and
+// lookup but can be brought back with RestorePackage until it is purged.
 func (pu *PackageUsecase) DeletePackage(id uuid.UUID) error {
 	pkg, err := pu.packageRepo.GetByID(id)
 	if err != nil {
@@ -129,25 +519,127 @@ func (pu *PackageUsecase) DeletePackage(id uuid.UUID) error {
 	return pu.packageRepo.Delete(id)
 }
 
+// RestorePackage undoes DeletePackage. Restoring a package that was never
+// deleted returns it unchanged; one that was purged is not found.
+func (pu *PackageUsecase) RestorePackage(id uuid.UUID) (*domain.Package, error) {
+	pkg, err := pu.packageRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if pkg != nil {
+		return pkg, nil
+	}
+
+	if err := pu.packageRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	return pu.getLivePackage(id)
+}
+
+// PurgeDeletedPackages permanently removes packages deleted longer ago than
+// the retention period and returns how many were removed
+func (pu *PackageUsecase) PurgeDeletedPackages(ctx context.Context, retention time.Duration) (int64, error) {
+	return pu.packageRepo.PurgeDeleted(ctx, time.Now().Add(-retention))
+}
+
+// ArchiveTerminalPackages moves HANDED_OVER, RETURNED and DISPOSED packages
+// last updated longer ago than olderThan into the archive, in batches, and
+// returns how many were moved. Without an archive it does nothing. Batches
+// stop when ctx is done.
+func (pu *PackageUsecase) ArchiveTerminalPackages(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if pu.archive == nil {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var total int64
+	for {
+		moved, err := pu.archive.ArchiveBefore(ctx, cutoff, ArchiveBatchSize)
+		total += moved
+		if err != nil || moved < ArchiveBatchSize {
+			return total, err
+		}
+	}
+}
+
+// RehydratePackage moves an archived package back into the packages table.
+// Rehydrating a live package returns it unchanged.
+func (pu *PackageUsecase) RehydratePackage(id uuid.UUID) (*domain.Package, error) {
+	pkg, err := pu.packageRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if pkg != nil {
+		return pkg, nil
+	}
+	if pu.archive == nil {
+		return nil, ErrPackageNotFound
+	}
+
+	if err := pu.archive.Rehydrate(id); err != nil {
+		return nil, err
+	}
+	return pu.getLivePackage(id)
+}
+
+// getLivePackage reads a package from the packages table only
+func (pu *PackageUsecase) getLivePackage(id uuid.UUID) (*domain.Package, error) {
+	pkg, err := pu.packageRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, ErrPackageNotFound
+	}
+	return pkg, nil
+}
+
 func (pu *PackageUsecase) GetPackageStats() (*domain.PackageStats, error) {
 	return pu.packageRepo.GetPackageStats()
 }
 
-func (pu *PackageUsecase) MarkExpiredPackages() error {
-	expiredPackages, err := pu.packageRepo.GetExpiredPackages()
+// PreviewExpiredPackages returns the packages MarkExpiredPackages would expire
+// now and the creation cutoff it would use for STANDARD packages, without
+// changing anything. Other priorities expire by their own service level.
+func (pu *PackageUsecase) PreviewExpiredPackages(ctx context.Context) ([]*domain.Package, time.Time, error) {
+	now := time.Now()
+	cutoff := now.Add(-pu.ExpiryWindow())
+	candidates, err := pu.packageRepo.GetExpiredPackages(ctx, now.Add(-pu.shortestExpiryWindow()))
+	if err != nil {
+		return nil, cutoff, err
+	}
+
+	expiredPackages := make([]*domain.Package, 0, len(candidates))
+	for _, pkg := range candidates {
+		if pkg.CreatedAt.Before(now.Add(-pu.ExpiryWindowFor(pkg.Priority))) {
+			expiredPackages = append(expiredPackages, pkg)
+		}
+	}
+	return expiredPackages, cutoff, nil
+}
+
+// MarkExpiredPackages expires every overdue package, stopping between
+// packages once ctx is done. A package that cannot be expired is logged and
+// skipped, so the next run retries it; the failures are joined into the
+// returned error.
+func (pu *PackageUsecase) MarkExpiredPackages(ctx context.Context) error {
+	expiredPackages, _, err := pu.PreviewExpiredPackages(ctx)
 	if err != nil {
 		return err
 	}
 
+	var failures []error
 	for _, pkg := range expiredPackages {
-		_, err := pu.UpdatePackageStatus(pkg.ID, domain.StatusExpired)
-		if err != nil {
-			// Log error but continue processing other packages
-			continue
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(failures, err)...)
+		}
+		if _, err := pu.UpdatePackageStatus(pkg.ID, domain.StatusExpired); err != nil {
+			log.Printf("Failed to expire package %s: %v", pkg.ID, err)
+			failures = append(failures, fmt.Errorf("package %s: %w", pkg.ID, err))
 		}
 	}
 
-	return nil
+	return errors.Join(failures...)
 }
 
 func (pu *PackageUsecase) isValidStatusTransition(currentStatus, newStatus domain.PackageStatus) bool {
@@ -156,7 +648,12 @@ func (pu *PackageUsecase) isValidStatusTransition(currentStatus, newStatus domai
 		return newStatus == domain.StatusPicked || newStatus == domain.StatusExpired
 	case domain.StatusPicked:
 		return newStatus == domain.StatusHandedOver || newStatus == domain.StatusExpired
-	case domain.StatusHandedOver, domain.StatusExpired:
+	case domain.StatusExpired:
+		// Expired packages are returned to their carrier or disposed of
+		return newStatus == domain.StatusReturnPending || newStatus == domain.StatusDisposed
+	case domain.StatusReturnPending:
+		return newStatus == domain.StatusReturned || newStatus == domain.StatusDisposed
+	case domain.StatusHandedOver, domain.StatusReturned, domain.StatusDisposed:
 		return false // Terminal states
 	default:
 		return false
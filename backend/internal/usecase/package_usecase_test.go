@@ -1,16 +1,22 @@
 package usecase_test
 
 import (
+	"context"
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
 	"pickup-queue/internal/usecase"
+	sqlitemigrations "pickup-queue/migrations/sqlite"
+	"pickup-queue/pkg/database"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock Repository
@@ -39,8 +45,8 @@ func (m *MockPackageRepository) GetByOrderRef(orderRef string) (*domain.Package,
 	return args.Get(0).(*domain.Package), args.Error(1)
 }
 
-func (m *MockPackageRepository) GetAll(limit, offset int, status *domain.PackageStatus) ([]*domain.Package, error) {
-	args := m.Called(limit, offset, status)
+func (m *MockPackageRepository) GetAll(filter domain.PackageFilter) ([]*domain.Package, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]*domain.Package), args.Error(1)
 }
 
@@ -54,13 +60,28 @@ func (m *MockPackageRepository) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockPackageRepository) Restore(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPackageRepository) PurgeDeleted(_ context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockPackageRepository) UpdateStatus(id uuid.UUID, status domain.PackageStatus) error {
 	args := m.Called(id, status)
 	return args.Error(0)
 }
 
-func (m *MockPackageRepository) GetExpiredPackages() ([]*domain.Package, error) {
-	args := m.Called()
+func (m *MockPackageRepository) GetExpiredPackages(_ context.Context, cutoff time.Time) ([]*domain.Package, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).([]*domain.Package), args.Error(1)
+}
+
+func (m *MockPackageRepository) GetExpiredBefore(_ context.Context, cutoff time.Time) ([]*domain.Package, error) {
+	args := m.Called(cutoff)
 	return args.Get(0).([]*domain.Package), args.Error(1)
 }
 
@@ -69,6 +90,16 @@ func (m *MockPackageRepository) GetPackageStats() (*domain.PackageStats, error)
 	return args.Get(0).(*domain.PackageStats), args.Error(1)
 }
 
+// WaitingPositions and RecentPickupTimes only feed queue estimates, which
+// these tests do not cover, so they answer without expectations
+func (m *MockPackageRepository) WaitingPositions(ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	return map[uuid.UUID]int{}, nil
+}
+
+func (m *MockPackageRepository) RecentPickupTimes(limit int) ([]time.Time, error) {
+	return []time.Time{}, nil
+}
+
 func TestPackageUsecase_CreatePackage_HappyPath(t *testing.T) {
 	// Setup
 	mockRepo := new(MockPackageRepository)
@@ -275,7 +306,7 @@ func TestPackageUsecase_MarkExpiredPackages_HappyPath(t *testing.T) {
 	}
 
 	// Mock expectations
-	mockRepo.On("GetExpiredPackages").Return(expiredPackages, nil)
+	mockRepo.On("GetExpiredPackages", mock.AnythingOfType("time.Time")).Return(expiredPackages, nil)
 	for _, pkg := range expiredPackages {
 		mockRepo.On("GetByID", pkg.ID).Return(pkg, nil)
 		mockRepo.On("Update", mock.MatchedBy(func(p *domain.Package) bool {
@@ -284,7 +315,7 @@ func TestPackageUsecase_MarkExpiredPackages_HappyPath(t *testing.T) {
 	}
 
 	// Execute
-	err := uc.MarkExpiredPackages()
+	err := uc.MarkExpiredPackages(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -297,10 +328,10 @@ func TestPackageUsecase_MarkExpiredPackages_EdgeCase_NoExpiredPackages(t *testin
 	uc := usecase.NewPackageUsecase(mockRepo)
 
 	// Mock expectations
-	mockRepo.On("GetExpiredPackages").Return([]*domain.Package{}, nil)
+	mockRepo.On("GetExpiredPackages", mock.AnythingOfType("time.Time")).Return([]*domain.Package{}, nil)
 
 	// Execute
-	err := uc.MarkExpiredPackages()
+	err := uc.MarkExpiredPackages(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -314,13 +345,518 @@ func TestPackageUsecase_MarkExpiredPackages_EdgeCase_RepositoryError(t *testing.
 	uc := usecase.NewPackageUsecase(mockRepo)
 
 	// Mock expectations
-	mockRepo.On("GetExpiredPackages").Return([]*domain.Package{}, errors.New("database connection failed"))
+	mockRepo.On("GetExpiredPackages", mock.AnythingOfType("time.Time")).Return([]*domain.Package{}, errors.New("database connection failed"))
 
 	// Execute
-	err := uc.MarkExpiredPackages()
+	err := uc.MarkExpiredPackages(context.Background())
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "database connection failed")
 	mockRepo.AssertExpectations(t)
 }
+
+func TestPackageUsecase_MarkExpiredPackages_EdgeCase_ReportsFailures(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewPackageUsecase(mockRepo)
+	failing := &domain.Package{ID: uuid.New(), OrderRef: "EXPIRED-001", Status: domain.StatusWaiting, CreatedAt: time.Now().Add(-25 * time.Hour)}
+	expiring := &domain.Package{ID: uuid.New(), OrderRef: "EXPIRED-002", Status: domain.StatusWaiting, CreatedAt: time.Now().Add(-25 * time.Hour)}
+	storageErr := errors.New("connection reset")
+
+	// Mock expectations
+	mockRepo.On("GetExpiredPackages", mock.AnythingOfType("time.Time")).Return([]*domain.Package{failing, expiring}, nil)
+	mockRepo.On("GetByID", failing.ID).Return(failing, nil)
+	mockRepo.On("GetByID", expiring.ID).Return(expiring, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(p *domain.Package) bool { return p.ID == failing.ID })).Return(storageErr)
+	mockRepo.On("Update", mock.MatchedBy(func(p *domain.Package) bool { return p.ID == expiring.ID })).Return(nil)
+
+	// Execute
+	err := uc.MarkExpiredPackages(context.Background())
+
+	// Assert
+	assert.ErrorIs(t, err, storageErr)
+	assert.ErrorContains(t, err, failing.ID.String())
+	assert.NotContains(t, err.Error(), expiring.ID.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageUsecase_MarkExpiredPackages_EdgeCase_Cancelled(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo)
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "EXPIRED-001", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	uc.SetExpiryWindow(time.Nanosecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Execute
+	err = uc.MarkExpiredPackages(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	got, err := repo.GetByID(pkg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusWaiting, got.Status)
+}
+
+func TestPackageUsecase_MarkExpiredPackages_HappyPath_CustomExpiryWindow(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewPackageUsecase(mockRepo)
+	uc.SetExpiryWindow(2 * time.Hour)
+
+	// Mock expectations - cutoff must follow the configured window
+	mockRepo.On("GetExpiredPackages", mock.MatchedBy(func(cutoff time.Time) bool {
+		expected := time.Now().Add(-2 * time.Hour)
+		return cutoff.Sub(expected).Abs() < time.Minute
+	})).Return([]*domain.Package{}, nil)
+
+	// Execute
+	err := uc.MarkExpiredPackages(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, uc.ExpiryWindow())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageUsecase_PreviewExpiredPackages_HappyPath_DoesNotUpdate(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewPackageUsecase(mockRepo)
+	due := []*domain.Package{
+		{ID: uuid.New(), OrderRef: "EXPIRED-001", Status: domain.StatusWaiting, CreatedAt: time.Now().Add(-25 * time.Hour)},
+	}
+
+	// Mock expectations
+	mockRepo.On("GetExpiredPackages", mock.AnythingOfType("time.Time")).Return(due, nil)
+
+	// Execute
+	packages, cutoff, err := uc.PreviewExpiredPackages(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, due, packages)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), cutoff, time.Minute)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update")
+	mockRepo.AssertNotCalled(t, "UpdateStatus")
+}
+
+func TestPackageUsecase_CreatePackage_EdgeCase_RollsBackUnitOfWork(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uow := repository.NewInMemoryUnitOfWork(mockRepo)
+	uc := usecase.NewPackageUsecaseWithUnitOfWork(mockRepo, uow)
+
+	req := &domain.CreatePackageRequest{
+		OrderRef:   "TEST-001",
+		DriverCode: "DRV-001",
+	}
+
+	// Mock expectations
+	mockRepo.On("GetByOrderRef", req.OrderRef).Return(nil, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*domain.Package")).Return(errors.New("insert failed"))
+
+	// Execute
+	pkg, err := uc.CreatePackage(req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, pkg)
+	assert.Equal(t, 0, uow.Commits)
+	assert.Equal(t, 1, uow.Rollbacks)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageUsecase_UpdatePackageStatus_HappyPath_CommitsUnitOfWork(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uow := repository.NewInMemoryUnitOfWork(mockRepo)
+	uc := usecase.NewPackageUsecaseWithUnitOfWork(mockRepo, uow)
+
+	packageID := uuid.New()
+	existingPkg := &domain.Package{ID: packageID, OrderRef: "TEST-001", Status: domain.StatusWaiting}
+
+	// Mock expectations
+	mockRepo.On("GetByID", packageID).Return(existingPkg, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*domain.Package")).Return(nil)
+
+	// Execute
+	pkg, err := uc.UpdatePackageStatus(packageID, domain.StatusPicked)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusPicked, pkg.Status)
+	assert.Equal(t, 1, uow.Commits)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageUsecase_CreatePackage_EdgeCase_InMemoryRollback(t *testing.T) {
+	// Setup - a unit of work over the in-memory repository really rolls back
+	repo := repository.NewMemoryPackageRepository()
+	uow := repository.NewInMemoryUnitOfWork(repo)
+	uc := usecase.NewPackageUsecaseWithUnitOfWork(repo, uow)
+
+	_, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "TEST-001", DriverCode: "DRV-001"})
+	assert.NoError(t, err)
+
+	// Execute
+	err = uow.Do(domain.TxOptions{}, func(tx domain.Tx) error {
+		pkg, _ := tx.Packages().GetByOrderRef("TEST-001")
+		if err := tx.Packages().Delete(pkg.ID); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+
+	// Assert
+	assert.Error(t, err)
+	pkg, err := uc.GetPackageByOrderRef("TEST-001")
+	assert.NoError(t, err)
+	assert.NotNil(t, pkg)
+}
+
+func TestPackageUsecase_RestorePackage_HappyPath(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo)
+
+	created, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "TEST-001", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	require.NoError(t, uc.DeletePackage(created.ID))
+	_, err = uc.GetPackage(created.ID)
+	require.ErrorIs(t, err, usecase.ErrPackageNotFound)
+
+	// Execute
+	restored, err := uc.RestorePackage(created.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, restored.ID)
+	stats, err := uc.GetPackageStats()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+}
+
+func TestPackageUsecase_RestorePackage_EdgeCase_NotFound(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewPackageUsecase(mockRepo)
+
+	packageID := uuid.New()
+
+	// Mock expectations - nothing to restore, e.g. already purged
+	mockRepo.On("GetByID", packageID).Return(nil, nil).Twice()
+	mockRepo.On("Restore", packageID).Return(nil)
+
+	// Execute
+	pkg, err := uc.RestorePackage(packageID)
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrPackageNotFound)
+	assert.Nil(t, pkg)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageUsecase_PurgeDeletedPackages_HappyPath(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewPackageUsecase(mockRepo)
+
+	// Mock expectations - cutoff must follow the retention period
+	mockRepo.On("PurgeDeleted", mock.MatchedBy(func(cutoff time.Time) bool {
+		expected := time.Now().Add(-30 * 24 * time.Hour)
+		return cutoff.Sub(expected).Abs() < time.Minute
+	})).Return(int64(3), nil)
+
+	// Execute
+	purged, err := uc.PurgeDeletedPackages(context.Background(), 30*24*time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPackageUsecase_ArchiveTerminalPackages_HappyPath_LookupFallsBack(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo).WithArchive(repository.NewMemoryPackageArchiveRepository(repo))
+
+	handed, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "TEST-001", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	_, err = uc.UpdatePackageStatus(handed.ID, domain.StatusPicked)
+	require.NoError(t, err)
+	_, err = uc.UpdatePackageStatus(handed.ID, domain.StatusHandedOver)
+	require.NoError(t, err)
+	_, err = uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "TEST-002", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+
+	// Execute
+	moved, err := uc.ArchiveTerminalPackages(context.Background(), 0)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), moved)
+
+	live, err := uc.ListPackages(domain.PackageFilter{})
+	require.NoError(t, err)
+	assert.Len(t, live, 1)
+
+	archived, err := uc.GetPackageByOrderRef("TEST-001")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusHandedOver, archived.Status)
+	assert.NotNil(t, archived.ArchivedAt)
+
+	_, err = uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "TEST-001", DriverCode: "DRV-002"})
+	assert.ErrorIs(t, err, usecase.ErrDuplicateOrderRef)
+}
+
+func TestPackageUsecase_RehydratePackage_HappyPath(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo).WithArchive(repository.NewMemoryPackageArchiveRepository(repo))
+
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "TEST-001", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	_, err = uc.UpdatePackageStatus(pkg.ID, domain.StatusExpired)
+	require.NoError(t, err)
+	_, err = uc.ArchiveTerminalPackages(context.Background(), 0)
+	require.NoError(t, err)
+
+	// Execute
+	rehydrated, err := uc.RehydratePackage(pkg.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, pkg.ID, rehydrated.ID)
+	assert.Nil(t, rehydrated.ArchivedAt)
+	stats, err := uc.GetPackageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Expired)
+}
+
+func TestPackageUsecase_RehydratePackage_EdgeCase_NotArchived(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo).WithArchive(repository.NewMemoryPackageArchiveRepository(repo))
+
+	// Execute
+	pkg, err := uc.RehydratePackage(uuid.New())
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrPackageNotFound)
+	assert.Nil(t, pkg)
+}
+
+// failingArchive is an archive whose order reference lookup always fails
+type failingArchive struct {
+	domain.PackageArchiveRepository
+	err error
+}
+
+func (a failingArchive) GetByOrderRef(orderRef string) (*domain.Package, error) {
+	return nil, a.err
+}
+
+func TestPackageUsecase_CreatePackage_EdgeCase_ArchiveLookupFails(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	lookupErr := errors.New("archive unavailable")
+	uc := usecase.NewPackageUsecase(repo).WithArchive(failingArchive{err: lookupErr})
+
+	// Execute
+	pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ARCH-ERR-001", DriverCode: "DRV-001"})
+
+	// Assert
+	assert.ErrorIs(t, err, lookupErr)
+	assert.Nil(t, pkg)
+	stored, err := repo.GetByOrderRef("ARCH-ERR-001")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestPackageUsecase_UpdatePackageStatus_EdgeCase_RepeatTransitionIsNoOp(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewPackageUsecase(mockRepo)
+
+	packageID := uuid.New()
+	handedOverAt := time.Now().Add(-time.Minute)
+	existingPkg := &domain.Package{
+		ID:           packageID,
+		OrderRef:     "TEST-001",
+		Status:       domain.StatusHandedOver,
+		HandedOverAt: &handedOverAt,
+	}
+
+	// Mock expectations
+	mockRepo.On("GetByID", packageID).Return(existingPkg, nil)
+
+	// Execute
+	pkg, err := uc.UpdatePackageStatus(packageID, domain.StatusHandedOver)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusHandedOver, pkg.Status)
+	assert.Equal(t, &handedOverAt, pkg.HandedOverAt)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func seedBatchPackages(t *testing.T, uc *usecase.PackageUsecase) (*domain.Package, *domain.Package) {
+	waiting, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "BATCH-001", DriverCode: "DRV-001"})
+	assert.NoError(t, err)
+	handedOver, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "BATCH-002", DriverCode: "DRV-001"})
+	assert.NoError(t, err)
+	_, err = uc.UpdatePackageStatus(handedOver.ID, domain.StatusPicked)
+	assert.NoError(t, err)
+	_, err = uc.UpdatePackageStatus(handedOver.ID, domain.StatusHandedOver)
+	assert.NoError(t, err)
+	return waiting, handedOver
+}
+
+func TestPackageUsecase_BatchUpdatePackageStatus_HappyPath_BestEffort(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecaseWithUnitOfWork(repo, repository.NewInMemoryUnitOfWork(repo))
+	waiting, handedOver := seedBatchPackages(t, uc)
+
+	// Execute
+	results, err := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{
+		IDs:       []uuid.UUID{waiting.ID},
+		OrderRefs: []string{handedOver.OrderRef, "MISSING"},
+		Status:    domain.StatusPicked,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, domain.StatusPicked, results[0].Package.Status)
+	assert.NotNil(t, results[0].Package.PickedUpAt)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, usecase.ErrInvalidStatusTransition.Error(), results[1].Error)
+	assert.False(t, results[2].Success)
+	assert.Equal(t, usecase.ErrPackageNotFound.Error(), results[2].Error)
+
+	stored, _ := repo.GetByID(waiting.ID)
+	assert.Equal(t, domain.StatusPicked, stored.Status)
+}
+
+func TestPackageUsecase_BatchUpdatePackageStatus_EdgeCase_HidesStorageErrors(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewPackageUsecase(mockRepo)
+	pkg := &domain.Package{ID: uuid.New(), OrderRef: "BATCH-001", Status: domain.StatusWaiting}
+	mockRepo.On("GetByID", pkg.ID).Return(pkg, nil)
+	mockRepo.On("Update", mock.Anything).Return(errors.New("pq: connection reset by peer"))
+
+	// Execute
+	results, err := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{
+		IDs:    []uuid.UUID{pkg.ID},
+		Status: domain.StatusPicked,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.Equal(t, "internal error", results[0].Error)
+}
+
+func TestPackageUsecase_BatchUpdatePackageStatus_EdgeCase_AllOrNothingRejected(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecaseWithUnitOfWork(repo, repository.NewInMemoryUnitOfWork(repo))
+	waiting, handedOver := seedBatchPackages(t, uc)
+
+	// Execute
+	results, err := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{
+		IDs:          []uuid.UUID{waiting.ID, handedOver.ID},
+		Status:       domain.StatusPicked,
+		AllOrNothing: true,
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrBatchRejected)
+	assert.Len(t, results, 2)
+	assert.False(t, results[0].Success)
+	assert.Nil(t, results[0].Package)
+	assert.Equal(t, usecase.ErrInvalidStatusTransition.Error(), results[1].Error)
+
+	stored, _ := repo.GetByID(waiting.ID)
+	assert.Equal(t, domain.StatusWaiting, stored.Status)
+}
+
+func TestPackageUsecase_BatchUpdatePackageStatus_HappyPath_AllOrNothingCommits(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecaseWithUnitOfWork(repo, repository.NewInMemoryUnitOfWork(repo))
+	waiting, _ := seedBatchPackages(t, uc)
+
+	// Execute - the same package twice is transitioned once
+	results, err := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{
+		IDs:          []uuid.UUID{waiting.ID},
+		OrderRefs:    []string{waiting.OrderRef},
+		Status:       domain.StatusPicked,
+		AllOrNothing: true,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, results[0].Success)
+	assert.True(t, results[1].Success)
+	stored, _ := repo.GetByID(waiting.ID)
+	assert.Equal(t, domain.StatusPicked, stored.Status)
+	assert.NotNil(t, stored.PickedUpAt)
+}
+
+func TestPackageUsecase_BatchUpdatePackageStatus_EdgeCase_EmptyAndOversized(t *testing.T) {
+	// Setup
+	uc := usecase.NewPackageUsecase(new(MockPackageRepository))
+	oversized := make([]string, usecase.MaxBatchSize+1)
+
+	// Execute
+	_, emptyErr := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{Status: domain.StatusPicked})
+	_, largeErr := uc.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{OrderRefs: oversized, Status: domain.StatusPicked})
+
+	// Assert
+	assert.ErrorIs(t, emptyErr, usecase.ErrEmptyBatch)
+	assert.ErrorIs(t, largeErr, usecase.ErrBatchTooLarge)
+}
+
+func TestPackageUsecase_CreatePackage_HappyPath_SQLiteWithArchive(t *testing.T) {
+	// Setup - SQLite has a single connection, so an archive lookup inside
+	// the create transaction would wait on it forever
+	db, err := database.NewSQLiteConnection(filepath.Join(t.TempDir(), "pickup.db"), sqlitemigrations.FS)
+	require.NoError(t, err)
+	defer db.Close()
+	uc := usecase.NewPackageUsecaseWithUnitOfWork(repository.NewSQLitePackageRepository(db), repository.NewSQLiteUnitOfWork(db)).
+		WithArchive(repository.NewSQLitePackageArchiveRepository(db))
+
+	// Execute
+	type result struct {
+		pkg *domain.Package
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		pkg, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-001", DriverCode: "DRV-001"})
+		done <- result{pkg, err}
+	}()
+
+	// Assert
+	select {
+	case got := <-done:
+		require.NoError(t, got.err)
+		assert.Equal(t, "ABC-001", got.pkg.OrderRef)
+	case <-time.After(5 * time.Second):
+		t.Fatal("CreatePackage did not return: the archive lookup is waiting on the transaction's connection")
+	}
+	_, err = uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-001", DriverCode: "DRV-001"})
+	assert.ErrorIs(t, err, usecase.ErrDuplicateOrderRef)
+}
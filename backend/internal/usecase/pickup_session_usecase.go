@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"pickup-queue/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionNotFound    = domain.NewError(domain.KindNotFound, "SESSION_NOT_FOUND", "pickup session not found")
+	ErrSessionClosed      = domain.NewError(domain.KindConflict, "SESSION_CLOSED", "pickup session is closed")
+	ErrDriverCodeRequired = domain.NewError(domain.KindValidation, "DRIVER_CODE_REQUIRED", "driver code or badge is required").
+				WithFields(domain.FieldError{Field: "driver_code", Code: "required", Message: "driver code or badge is required"})
+)
+
+// PickupSessionUsecase runs the scan-to-collect workflow: a driver checks in,
+// the clerk scans each parcel handed over, and closing the session reports
+// what was missing or scanned in error.
+type PickupSessionUsecase struct {
+	sessions domain.PickupSessionRepository
+	packages *PackageUsecase
+	// appointments, when set, checks drivers in against their bookings
+	appointments *AppointmentUsecase
+}
+
+func NewPickupSessionUsecase(sessions domain.PickupSessionRepository, packages *PackageUsecase) *PickupSessionUsecase {
+	return &PickupSessionUsecase{
+		sessions: sessions,
+		packages: packages,
+	}
+}
+
+// WithAppointments checks drivers in against their pickup appointments
+func (su *PickupSessionUsecase) WithAppointments(appointments *AppointmentUsecase) *PickupSessionUsecase {
+	su.appointments = appointments
+	return su
+}
+
+// OpenSession checks a driver in and returns the session together with the
+// packages waiting for them. Checking in again while a session is open
+// returns that session instead of starting a new one. With appointments, a
+// new session carries the driver's booking, or a warning when none covers
+// the check-in; enforced appointments refuse such check-ins instead.
+func (su *PickupSessionUsecase) OpenSession(req *domain.OpenPickupSessionRequest) (*domain.PickupSession, []*domain.Package, error) {
+	driverCode := strings.TrimSpace(req.DriverCode)
+	if driverCode == "" {
+		driverCode = strings.TrimSpace(req.Badge)
+	}
+	if driverCode == "" {
+		return nil, nil, ErrDriverCodeRequired
+	}
+
+	waitingStatus := domain.StatusWaiting
+	waiting, err := su.packages.ListPackages(domain.PackageFilter{Status: &waitingStatus, DriverCode: driverCode})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := su.sessions.GetOpenByDriver(driverCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil {
+		return existing, waiting, nil
+	}
+
+	var appointment *domain.Appointment
+	var warning string
+	if su.appointments != nil {
+		if appointment, warning, err = su.appointments.CheckIn(driverCode); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	session := &domain.PickupSession{
+		ID:         uuid.New(),
+		DriverCode: driverCode,
+		Status:     domain.SessionOpen,
+		OpenedAt:   time.Now(),
+		Items:      make([]*domain.PickupSessionItem, 0, len(waiting)),
+	}
+	for _, pkg := range waiting {
+		id := pkg.ID
+		session.Items = append(session.Items, &domain.PickupSessionItem{
+			OrderRef:  pkg.OrderRef,
+			PackageID: &id,
+			Expected:  true,
+		})
+	}
+
+	err = su.sessions.Create(session)
+	if errors.Is(err, domain.ErrOpenSessionExists) {
+		// Another terminal checked the same driver in first
+		existing, err = su.sessions.GetOpenByDriver(driverCode)
+		if err == nil && existing != nil {
+			return existing, waiting, nil
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session.Appointment, session.Warning = appointment, warning
+	return session, waiting, nil
+}
+
+func (su *PickupSessionUsecase) GetSession(id uuid.UUID) (*domain.PickupSession, error) {
+	session, err := su.sessions.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// ScanPackage records one scanned order reference. A package waiting for the
+// session's driver is marked PICKED through the usual status transition;
+// anything else is recorded as an extra or rejected scan and left untouched.
+// Scanning the same parcel twice is harmless.
+func (su *PickupSessionUsecase) ScanPackage(sessionID uuid.UUID, orderRef string) (*domain.PickupSessionItem, error) {
+	session, err := su.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.SessionOpen {
+		return nil, ErrSessionClosed
+	}
+
+	now := time.Now()
+	item := &domain.PickupSessionItem{
+		OrderRef:  strings.TrimSpace(orderRef),
+		ScannedAt: &now,
+	}
+
+	pkg, err := su.packages.GetPackageByOrderRef(item.OrderRef)
+	if pkg != nil {
+		// Match the check-in list, which holds normalized references
+		item.OrderRef = pkg.OrderRef
+	}
+	switch {
+	case errors.Is(err, ErrPackageNotFound):
+		item.Result = domain.ScanExtra
+		item.Reason = "unknown order reference"
+	case errors.Is(err, domain.ErrValidation):
+		item.Result = domain.ScanExtra
+		item.Reason = "invalid order reference"
+	case err != nil:
+		return nil, err
+	case pkg.DriverCode != session.DriverCode:
+		item.PackageID = &pkg.ID
+		item.Result = domain.ScanExtra
+		item.Reason = "package is assigned to another driver"
+	case pkg.ArchivedAt != nil:
+		item.PackageID = &pkg.ID
+		item.Result = domain.ScanRejected
+		item.Reason = fmt.Sprintf("package is %s and archived", pkg.Status)
+	default:
+		item.PackageID = &pkg.ID
+		_, err := su.packages.UpdatePackageStatus(pkg.ID, domain.StatusPicked)
+		switch {
+		case errors.Is(err, ErrInvalidStatusTransition):
+			item.Result = domain.ScanRejected
+			item.Reason = fmt.Sprintf("package is %s", pkg.Status)
+		case err != nil:
+			return nil, err
+		default:
+			item.Result = domain.ScanPicked
+		}
+	}
+
+	// The package is already PICKED if recording fails; rescanning records it
+	// again as a no-op transition
+	if err := su.sessions.RecordScan(session.ID, item); err != nil {
+		return nil, err
+	}
+	for _, existing := range session.Items {
+		if existing.OrderRef == item.OrderRef {
+			item.Expected = existing.Expected
+		}
+	}
+
+	return item, nil
+}
+
+// CloseSession closes the session and reports it against the check-in list.
+// Closing an already closed session returns the same report.
+func (su *PickupSessionUsecase) CloseSession(id uuid.UUID) (*domain.PickupSessionReport, error) {
+	session, err := su.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status == domain.SessionOpen {
+		if err := su.sessions.Close(id, time.Now()); err != nil {
+			return nil, err
+		}
+		if session, err = su.GetSession(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return buildSessionReport(session), nil
+}
+
+// buildSessionReport lists picked, missing (expected but not picked), extra
+// and rejected order references
+func buildSessionReport(session *domain.PickupSession) *domain.PickupSessionReport {
+	report := &domain.PickupSessionReport{
+		Session:  session,
+		Picked:   []string{},
+		Missing:  []string{},
+		Extra:    []string{},
+		Rejected: []string{},
+	}
+
+	for _, item := range session.Items {
+		if item.Expected {
+			report.Expected++
+		}
+		switch item.Result {
+		case domain.ScanPicked:
+			report.Picked = append(report.Picked, item.OrderRef)
+		case domain.ScanExtra:
+			report.Extra = append(report.Extra, item.OrderRef)
+		case domain.ScanRejected:
+			report.Rejected = append(report.Rejected, item.OrderRef)
+		}
+		if item.Expected && item.Result != domain.ScanPicked {
+			report.Missing = append(report.Missing, item.OrderRef)
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPickupSessions(t *testing.T) (*usecase.PickupSessionUsecase, *usecase.PackageUsecase) {
+	repo := repository.NewMemoryPackageRepository()
+	packages := usecase.NewPackageUsecaseWithUnitOfWork(repo, repository.NewInMemoryUnitOfWork(repo))
+	sessions := usecase.NewPickupSessionUsecase(repository.NewMemoryPickupSessionRepository(), packages)
+
+	for _, req := range []domain.CreatePackageRequest{
+		{OrderRef: "ORD-001", DriverCode: "DRV-001"},
+		{OrderRef: "ORD-002", DriverCode: "DRV-001"},
+		{OrderRef: "ORD-003", DriverCode: "DRV-001"},
+		{OrderRef: "ORD-100", DriverCode: "DRV-002"},
+	} {
+		_, err := packages.CreatePackage(&req)
+		require.NoError(t, err)
+	}
+	return sessions, packages
+}
+
+func TestPickupSessionUsecase_OpenSession_HappyPath(t *testing.T) {
+	// Setup
+	sessions, _ := setupPickupSessions(t)
+
+	// Execute
+	session, waiting, err := sessions.OpenSession(&domain.OpenPickupSessionRequest{Badge: "DRV-001"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "DRV-001", session.DriverCode)
+	assert.Equal(t, domain.SessionOpen, session.Status)
+	assert.Len(t, waiting, 3)
+	assert.Len(t, session.Items, 3)
+
+	// Checking in again returns the same session
+	again, _, err := sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, again.ID)
+}
+
+func TestPickupSessionUsecase_OpenSession_EdgeCase_NoDriver(t *testing.T) {
+	// Setup
+	sessions, _ := setupPickupSessions(t)
+
+	// Execute
+	_, _, err := sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "  "})
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrDriverCodeRequired)
+}
+
+func TestPickupSessionUsecase_ScanAndClose_HappyPath(t *testing.T) {
+	// Setup
+	sessions, packages := setupPickupSessions(t)
+	session, _, err := sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+	require.NoError(t, err)
+
+	// ORD-003 expires between check-in and scanning
+	expiring, err := packages.GetPackageByOrderRef("ORD-003")
+	require.NoError(t, err)
+	_, err = packages.UpdatePackageStatus(expiring.ID, domain.StatusExpired)
+	require.NoError(t, err)
+
+	// Execute
+	picked, err := sessions.ScanPackage(session.ID, "ORD-001")
+	require.NoError(t, err)
+	rescanned, err := sessions.ScanPackage(session.ID, "ORD-001")
+	require.NoError(t, err)
+	otherDriver, err := sessions.ScanPackage(session.ID, "ORD-100")
+	require.NoError(t, err)
+	unknown, err := sessions.ScanPackage(session.ID, "ORD-404")
+	require.NoError(t, err)
+	rejected, err := sessions.ScanPackage(session.ID, "ORD-003")
+	require.NoError(t, err)
+
+	report, err := sessions.CloseSession(session.ID)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, domain.ScanPicked, picked.Result)
+	assert.True(t, picked.Expected)
+	assert.Equal(t, domain.ScanPicked, rescanned.Result)
+	assert.Equal(t, domain.ScanExtra, otherDriver.Result)
+	assert.Equal(t, domain.ScanExtra, unknown.Result)
+	assert.Equal(t, domain.ScanRejected, rejected.Result)
+
+	pkg, err := packages.GetPackageByOrderRef("ORD-001")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPicked, pkg.Status)
+	pkg, err = packages.GetPackageByOrderRef("ORD-100")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusWaiting, pkg.Status)
+
+	assert.Equal(t, domain.SessionClosed, report.Session.Status)
+	assert.Equal(t, 3, report.Expected)
+	assert.Equal(t, []string{"ORD-001"}, report.Picked)
+	assert.Equal(t, []string{"ORD-002", "ORD-003"}, report.Missing)
+	assert.Equal(t, []string{"ORD-100", "ORD-404"}, report.Extra)
+	assert.Equal(t, []string{"ORD-003"}, report.Rejected)
+}
+
+func TestPickupSessionUsecase_ScanPackage_EdgeCase_ClosedSession(t *testing.T) {
+	// Setup
+	sessions, _ := setupPickupSessions(t)
+	session, _, err := sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	_, err = sessions.CloseSession(session.ID)
+	require.NoError(t, err)
+
+	// Execute
+	_, closedErr := sessions.ScanPackage(session.ID, "ORD-001")
+	_, missingErr := sessions.ScanPackage(uuid.New(), "ORD-001")
+
+	// Assert
+	assert.ErrorIs(t, closedErr, usecase.ErrSessionClosed)
+	assert.ErrorIs(t, missingErr, usecase.ErrSessionNotFound)
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"pickup-queue/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MinPaceSamples is how many intervals between pickups are needed before the
+// measured pace replaces the default
+const MinPaceSamples = 3
+
+// QueueSettings tune how wait times are estimated. The pace is the average
+// interval between the latest SampleSize pickups, leaving out intervals
+// longer than IdleGap when the counter stood idle; DefaultPace stands in
+// until enough pickups have been recorded.
+type QueueSettings struct {
+	SampleSize  int
+	DefaultPace time.Duration
+	IdleGap     time.Duration
+}
+
+// DefaultQueueSettings are used until WithQueueSettings is called
+var DefaultQueueSettings = QueueSettings{
+	SampleSize:  50,
+	DefaultPace: 2 * time.Minute,
+	IdleGap:     30 * time.Minute,
+}
+
+// WithQueueSettings replaces how wait times are estimated
+func (pu *PackageUsecase) WithQueueSettings(settings QueueSettings) *PackageUsecase {
+	pu.queue = settings
+	return pu
+}
+
+// PickupPace estimates how long the counter takes per package from the
+// latest pickups
+func (pu *PackageUsecase) PickupPace() (time.Duration, error) {
+	times, err := pu.packageRepo.RecentPickupTimes(pu.queue.SampleSize)
+	if err != nil {
+		return 0, err
+	}
+	return pickupPace(times, pu.queue), nil
+}
+
+// EstimateQueue fills in the queue position and estimated pickup time of the
+// WAITING packages among pkgs. Packages are served by priority, then oldest
+// first, so a package waits for every WAITING package ahead of it at the
+// current pace.
+func (pu *PackageUsecase) EstimateQueue(pkgs ...*domain.Package) error {
+	var ids []uuid.UUID
+	for _, pkg := range pkgs {
+		if pkg.Status == domain.StatusWaiting && pkg.DeletedAt == nil && pkg.ArchivedAt == nil {
+			ids = append(ids, pkg.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	positions, err := pu.packageRepo.WaitingPositions(ids)
+	if err != nil {
+		return err
+	}
+	pace, err := pu.PickupPace()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, pkg := range pkgs {
+		position, ok := positions[pkg.ID]
+		if !ok {
+			continue
+		}
+		eta := now.Add(time.Duration(position-1) * pace)
+		pkg.QueuePosition = position
+		pkg.EstimatedPickupAt = &eta
+	}
+	return nil
+}
+
+// pickupPace averages the intervals between consecutive pickups, newest
+// first in times, that are no longer than the idle gap
+func pickupPace(times []time.Time, settings QueueSettings) time.Duration {
+	var total time.Duration
+	samples := 0
+	for i := 1; i < len(times); i++ {
+		interval := times[i-1].Sub(times[i])
+		if interval < 0 || interval > settings.IdleGap {
+			continue
+		}
+		total += interval
+		samples++
+	}
+	if samples < MinPaceSamples {
+		return settings.DefaultPace
+	}
+	return total / time.Duration(samples)
+}
+
+// QueueUsecase builds the lobby board of drivers waiting at the counter
+type QueueUsecase struct {
+	sessions domain.PickupSessionRepository
+	packages *PackageUsecase
+	now      func() time.Time
+}
+
+func NewQueueUsecase(sessions domain.PickupSessionRepository, packages *PackageUsecase) *QueueUsecase {
+	return &QueueUsecase{
+		sessions: sessions,
+		packages: packages,
+		now:      time.Now,
+	}
+}
+
+// WithClock replaces the usecase's clock, for tests
+func (qu *QueueUsecase) WithClock(now func() time.Time) *QueueUsecase {
+	qu.now = now
+	return qu
+}
+
+// Board lists the checked-in drivers in check-in order. The first driver is
+// at the counter now; each later driver starts once the packages left for
+// everyone ahead have been handed over at the current pace.
+func (qu *QueueUsecase) Board() (*domain.QueueBoard, error) {
+	sessions, err := qu.sessions.ListOpen()
+	if err != nil {
+		return nil, err
+	}
+	pace, err := qu.packages.PickupPace()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := qu.packages.GetPackageStats()
+	if err != nil {
+		return nil, err
+	}
+
+	now := qu.now()
+	board := &domain.QueueBoard{
+		GeneratedAt:     now,
+		PaceSeconds:     pace.Seconds(),
+		WaitingPackages: stats.Waiting,
+		Drivers:         make([]*domain.QueueEntry, 0, len(sessions)),
+	}
+	ahead := 0
+	for i, session := range sessions {
+		left := 0
+		for _, item := range session.Items {
+			if item.Expected && item.Result == "" {
+				left++
+			}
+		}
+		wait := time.Duration(ahead) * pace
+		board.Drivers = append(board.Drivers, &domain.QueueEntry{
+			Position:             i + 1,
+			DriverCode:           session.DriverCode,
+			CheckedInAt:          session.OpenedAt,
+			PackagesLeft:         left,
+			EstimatedStartAt:     now.Add(wait),
+			EstimatedWaitSeconds: int64(wait.Seconds()),
+		})
+		ahead += left
+	}
+	return board, nil
+}
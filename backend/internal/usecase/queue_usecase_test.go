@@ -0,0 +1,122 @@
+package usecase_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageUsecase_EstimateQueue_HappyPath(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	packages := usecase.NewPackageUsecase(repo)
+	now := time.Now()
+	for i, at := range []time.Time{now.Add(-3 * time.Minute), now.Add(-2 * time.Minute), now.Add(-time.Minute), now} {
+		seedPackage(t, repo, fmt.Sprintf("PICKED-%03d", i), forDriver("DRV-900"), pickedUpAt(at))
+	}
+
+	var waiting []*domain.Package
+	for i := 0; i < 3; i++ {
+		pkg := &domain.Package{
+			ID:         uuid.New(),
+			OrderRef:   fmt.Sprintf("ORD-%03d", i),
+			DriverCode: "DRV-001",
+			Status:     domain.StatusWaiting,
+			CreatedAt:  now.Add(time.Duration(i-10) * time.Minute),
+		}
+		require.NoError(t, repo.Create(pkg))
+		waiting = append(waiting, pkg)
+	}
+	picked, err := repo.GetByOrderRef("PICKED-000")
+	require.NoError(t, err)
+
+	// Execute
+	err = packages.EstimateQueue(waiting[2], picked, waiting[0])
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, waiting[0].QueuePosition)
+	require.NotNil(t, waiting[0].EstimatedPickupAt)
+	assert.WithinDuration(t, time.Now(), *waiting[0].EstimatedPickupAt, 5*time.Second)
+	assert.Equal(t, 3, waiting[2].QueuePosition)
+	require.NotNil(t, waiting[2].EstimatedPickupAt)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), *waiting[2].EstimatedPickupAt, 5*time.Second)
+	assert.Zero(t, picked.QueuePosition)
+	assert.Nil(t, picked.EstimatedPickupAt)
+}
+
+func TestPackageUsecase_PickupPace_EdgeCase_IdleGapsAndFewSamples(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	packages := usecase.NewPackageUsecase(repo).WithQueueSettings(usecase.QueueSettings{
+		SampleSize:  10,
+		DefaultPace: 5 * time.Minute,
+		IdleGap:     30 * time.Minute,
+	})
+	start := time.Now().Add(-24 * time.Hour)
+
+	// Execute: two intervals are too few to replace the default
+	for i, at := range []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)} {
+		seedPackage(t, repo, fmt.Sprintf("EARLY-%03d", i), forDriver("DRV-900"), pickedUpAt(at))
+	}
+	few, err := packages.PickupPace()
+	require.NoError(t, err)
+
+	// Execute: the overnight gap is idle time, not service time
+	for i, at := range []time.Time{start.Add(3 * time.Minute), start.Add(12 * time.Hour)} {
+		seedPackage(t, repo, fmt.Sprintf("LATE-%03d", i), forDriver("DRV-900"), pickedUpAt(at))
+	}
+	paced, err := packages.PickupPace()
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 5*time.Minute, few)
+	assert.Equal(t, time.Minute, paced)
+}
+
+func TestQueueUsecase_Board_HappyPath(t *testing.T) {
+	// Setup
+	_, packages := setupPickupSessions(t)
+	sessionRepo := repository.NewMemoryPickupSessionRepository()
+	sessions := usecase.NewPickupSessionUsecase(sessionRepo, packages)
+	first, _, err := sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	_, _, err = sessions.OpenSession(&domain.OpenPickupSessionRequest{DriverCode: "DRV-002"})
+	require.NoError(t, err)
+	_, err = sessions.ScanPackage(first.ID, "ORD-001")
+	require.NoError(t, err)
+
+	now := time.Date(2025, 8, 25, 9, 0, 0, 0, time.UTC)
+	queue := usecase.NewQueueUsecase(sessionRepo, packages).WithClock(func() time.Time { return now })
+
+	// Execute
+	board, err := queue.Board()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, now, board.GeneratedAt)
+	assert.Equal(t, usecase.DefaultQueueSettings.DefaultPace.Seconds(), board.PaceSeconds)
+	assert.Equal(t, int64(3), board.WaitingPackages)
+	require.Len(t, board.Drivers, 2)
+
+	assert.Equal(t, 1, board.Drivers[0].Position)
+	assert.Equal(t, "DRV-001", board.Drivers[0].DriverCode)
+	assert.Equal(t, 2, board.Drivers[0].PackagesLeft)
+	assert.Equal(t, now, board.Drivers[0].EstimatedStartAt)
+	assert.Zero(t, board.Drivers[0].EstimatedWaitSeconds)
+
+	// DRV-002 waits for the two packages DRV-001 still has to collect
+	assert.Equal(t, 2, board.Drivers[1].Position)
+	assert.Equal(t, "DRV-002", board.Drivers[1].DriverCode)
+	assert.Equal(t, 1, board.Drivers[1].PackagesLeft)
+	assert.Equal(t, int64(240), board.Drivers[1].EstimatedWaitSeconds)
+	assert.Equal(t, now.Add(4*time.Minute), board.Drivers[1].EstimatedStartAt)
+}
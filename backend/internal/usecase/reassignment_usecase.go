@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"pickup-queue/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotReassignable = domain.NewError(domain.KindConflict, "NOT_REASSIGNABLE", "only WAITING packages can be reassigned")
+	ErrSameDriver      = domain.NewError(domain.KindValidation, "SAME_DRIVER", "source and target driver are the same")
+	ErrReasonRequired  = domain.NewError(domain.KindValidation, "REASON_REQUIRED", "reason is required").
+				WithFields(domain.FieldError{Field: "reason", Code: "required", Message: "reason is required"})
+)
+
+// ReassignmentUsecase moves packages between drivers and keeps a history of
+// every change. Package updates and history entries are written in the same
+// unit of work.
+type ReassignmentUsecase struct {
+	uow         domain.UnitOfWork
+	assignments domain.DriverAssignmentRepository
+	packages    *PackageUsecase
+}
+
+func NewReassignmentUsecase(uow domain.UnitOfWork, assignments domain.DriverAssignmentRepository, packages *PackageUsecase) *ReassignmentUsecase {
+	return &ReassignmentUsecase{
+		uow:         uow,
+		assignments: assignments,
+		packages:    packages,
+	}
+}
+
+// ReassignDriver moves one WAITING package to req.DriverCode on behalf of
+// changedBy. Assigning a package to the driver it already has is a no-op.
+func (ru *ReassignmentUsecase) ReassignDriver(id uuid.UUID, req *domain.ReassignDriverRequest, changedBy string) (*domain.Package, error) {
+	driverCode, reason, err := normalizeReassignment(req.DriverCode, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg *domain.Package
+	err = ru.uow.Do(domain.TxOptions{Isolation: domain.IsolationSerializable}, func(tx domain.Tx) error {
+		var err error
+		pkg, err = tx.Packages().GetByID(id)
+		if err != nil {
+			return err
+		}
+		if pkg == nil {
+			return ErrPackageNotFound
+		}
+		if driverCode, err = ru.packages.normalizeDriverCode(pkg.Carrier, "driver_code", driverCode); err != nil {
+			return err
+		}
+		if pkg.DriverCode == driverCode {
+			return nil
+		}
+		if pkg.Status != domain.StatusWaiting {
+			return ErrNotReassignable
+		}
+
+		return reassign(tx, pkg, driverCode, reason, changedBy, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+// ReassignAll moves every WAITING package of fromDriver to req.ToDriverCode,
+// e.g. when a driver calls in sick, and returns the moved packages
+func (ru *ReassignmentUsecase) ReassignAll(fromDriver string, req *domain.BulkReassignDriverRequest, changedBy string) ([]*domain.Package, error) {
+	toDriver, reason, err := normalizeReassignment(req.ToDriverCode, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	fromDriver = ru.packages.rules.DriverCode.normalize(fromDriver)
+	if fromDriver == toDriver {
+		return nil, ErrSameDriver
+	}
+
+	var moved []*domain.Package
+	err = ru.uow.Do(domain.TxOptions{Isolation: domain.IsolationSerializable}, func(tx domain.Tx) error {
+		waitingStatus := domain.StatusWaiting
+		var err error
+		moved, err = tx.Packages().GetAll(domain.PackageFilter{Status: &waitingStatus, DriverCode: fromDriver})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, pkg := range moved {
+			driverCode, err := ru.packages.normalizeDriverCode(pkg.Carrier, "to_driver_code", toDriver)
+			if err != nil {
+				return err
+			}
+			if err := reassign(tx, pkg, driverCode, reason, changedBy, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if moved == nil {
+		moved = []*domain.Package{}
+	}
+	return moved, nil
+}
+
+// GetDriverHistory returns the reassignments of a package, oldest first
+func (ru *ReassignmentUsecase) GetDriverHistory(id uuid.UUID) ([]*domain.DriverAssignment, error) {
+	if _, err := ru.packages.GetPackage(id); err != nil {
+		return nil, err
+	}
+	return ru.assignments.ListByPackage(id)
+}
+
+func reassign(tx domain.Tx, pkg *domain.Package, driverCode, reason, changedBy string, now time.Time) error {
+	assignment := &domain.DriverAssignment{
+		ID:         uuid.New(),
+		PackageID:  pkg.ID,
+		FromDriver: pkg.DriverCode,
+		ToDriver:   driverCode,
+		Reason:     reason,
+		ChangedBy:  changedBy,
+		ChangedAt:  now,
+	}
+
+	pkg.DriverCode = driverCode
+	pkg.UpdatedAt = now
+	if err := tx.Packages().Update(pkg); err != nil {
+		return err
+	}
+	return tx.DriverAssignments().Create(assignment)
+}
+
+func normalizeReassignment(driverCode, reason string) (string, string, error) {
+	driverCode = strings.TrimSpace(driverCode)
+	reason = strings.TrimSpace(reason)
+	if driverCode == "" {
+		return "", "", ErrDriverCodeRequired
+	}
+	if reason == "" {
+		return "", "", ErrReasonRequired
+	}
+	return driverCode, reason, nil
+}
@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupReassignment(t *testing.T) (*usecase.ReassignmentUsecase, *usecase.PackageUsecase) {
+	repo := repository.NewMemoryPackageRepository()
+	assignments := repository.NewMemoryDriverAssignmentRepository()
+	uow := repository.NewInMemoryUnitOfWork(repo).WithDriverAssignments(assignments)
+	packages := usecase.NewPackageUsecaseWithUnitOfWork(repo, uow)
+	return usecase.NewReassignmentUsecase(uow, assignments, packages), packages
+}
+
+func TestReassignmentUsecase_ReassignDriver_HappyPath(t *testing.T) {
+	// Setup
+	reassignments, packages := setupReassignment(t)
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+
+	// Execute
+	updated, err := reassignments.ReassignDriver(pkg.ID, &domain.ReassignDriverRequest{DriverCode: "DRV-002", Reason: "route change"}, "alice")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "DRV-002", updated.DriverCode)
+
+	history, err := reassignments.GetDriverHistory(pkg.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "DRV-001", history[0].FromDriver)
+	assert.Equal(t, "DRV-002", history[0].ToDriver)
+	assert.Equal(t, "route change", history[0].Reason)
+	assert.Equal(t, "alice", history[0].ChangedBy)
+}
+
+func TestReassignmentUsecase_ReassignDriver_EdgeCase_NotWaiting(t *testing.T) {
+	// Setup
+	reassignments, packages := setupReassignment(t)
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	_, err = packages.UpdatePackageStatus(pkg.ID, domain.StatusPicked)
+	require.NoError(t, err)
+
+	// Execute
+	_, err = reassignments.ReassignDriver(pkg.ID, &domain.ReassignDriverRequest{DriverCode: "DRV-002", Reason: "route change"}, "alice")
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrNotReassignable)
+	history, err := reassignments.GetDriverHistory(pkg.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestReassignmentUsecase_ReassignAll_HappyPath(t *testing.T) {
+	// Setup
+	reassignments, packages := setupReassignment(t)
+	for _, ref := range []string{"ORD-001", "ORD-002", "ORD-003"} {
+		_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: ref, DriverCode: "DRV-001"})
+		require.NoError(t, err)
+	}
+	picked, err := packages.GetPackageByOrderRef("ORD-003")
+	require.NoError(t, err)
+	_, err = packages.UpdatePackageStatus(picked.ID, domain.StatusPicked)
+	require.NoError(t, err)
+
+	// Execute
+	moved, err := reassignments.ReassignAll("DRV-001", &domain.BulkReassignDriverRequest{ToDriverCode: "DRV-009", Reason: "sick"}, "alice")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, moved, 2)
+	remaining, err := packages.ListPackages(domain.PackageFilter{DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "ORD-003", remaining[0].OrderRef)
+
+	_, err = reassignments.ReassignAll("DRV-009", &domain.BulkReassignDriverRequest{ToDriverCode: "DRV-009", Reason: "sick"}, "alice")
+	assert.ErrorIs(t, err, usecase.ErrSameDriver)
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pickup-queue/internal/domain"
+	"time"
+)
+
+// ReminderUsecase reminds the recipients of WAITING packages at the cadence
+// of each package's priority
+type ReminderUsecase struct {
+	reminders domain.PickupReminderRepository
+	packages  *PackageUsecase
+	notifier  domain.PickupNotifier
+	now       func() time.Time
+}
+
+func NewReminderUsecase(reminders domain.PickupReminderRepository, packages *PackageUsecase) *ReminderUsecase {
+	return &ReminderUsecase{
+		reminders: reminders,
+		packages:  packages,
+		notifier:  logNotifier{},
+		now:       time.Now,
+	}
+}
+
+// WithNotifier replaces the default notifier, which only logs the reminders
+func (ru *ReminderUsecase) WithNotifier(notifier domain.PickupNotifier) *ReminderUsecase {
+	ru.notifier = notifier
+	return ru
+}
+
+// WithClock replaces the usecase's clock, for tests
+func (ru *ReminderUsecase) WithClock(now func() time.Time) *ReminderUsecase {
+	ru.now = now
+	return ru
+}
+
+// SendReminders reminds the recipient of every WAITING package whose next
+// reminder is due and returns how many were sent. The first reminder is due
+// one reminder interval after the package arrived, each later one an
+// interval after the previous. A package that cannot be reminded is logged
+// and skipped, so the next run retries it; the failures are joined into the
+// returned error.
+func (ru *ReminderUsecase) SendReminders(ctx context.Context) (int, error) {
+	now := ru.now()
+	var shortest time.Duration
+	for _, priority := range domain.Priorities {
+		if interval := ru.packages.ReminderIntervalFor(priority); interval > 0 && (shortest == 0 || interval < shortest) {
+			shortest = interval
+		}
+	}
+	if shortest == 0 {
+		return 0, nil
+	}
+
+	// Every package due a reminder arrived before the shortest interval's cutoff
+	candidates, err := ru.packages.packageRepo.GetExpiredPackages(ctx, now.Add(-shortest))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var failures []error
+	for _, pkg := range candidates {
+		if err := ctx.Err(); err != nil {
+			return sent, errors.Join(append(failures, err)...)
+		}
+		interval := ru.packages.ReminderIntervalFor(pkg.Priority)
+		if pkg.Status != domain.StatusWaiting || interval <= 0 {
+			continue
+		}
+		reminded, err := ru.remind(ctx, pkg, interval, now)
+		if err != nil {
+			log.Printf("Failed to remind the recipient of package %s: %v", pkg.ID, err)
+			failures = append(failures, fmt.Errorf("package %s: %w", pkg.ID, err))
+			continue
+		}
+		if reminded {
+			sent++
+		}
+	}
+	return sent, errors.Join(failures...)
+}
+
+// remind sends and records the package's next reminder if it is due by now
+// and reports whether it did
+func (ru *ReminderUsecase) remind(ctx context.Context, pkg *domain.Package, interval time.Duration, now time.Time) (bool, error) {
+	previous, err := ru.reminders.Get(pkg.ID)
+	if err != nil {
+		return false, err
+	}
+	dueAt, count := pkg.CreatedAt.Add(interval), 1
+	if previous != nil {
+		dueAt, count = previous.LastSentAt.Add(interval), previous.Count+1
+	}
+	if dueAt.After(now) {
+		return false, nil
+	}
+	if err := ru.notifier.RemindPickup(ctx, pkg, count); err != nil {
+		return false, err
+	}
+	return true, ru.reminders.Record(pkg.ID, now)
+}
+
+// logNotifier logs reminders instead of delivering them
+type logNotifier struct{}
+
+func (logNotifier) RemindPickup(ctx context.Context, pkg *domain.Package, reminder int) error {
+	log.Printf("Pickup reminder %d for package %s (%s, %s priority)", reminder, pkg.ID, pkg.OrderRef, pkg.Priority)
+	return nil
+}
@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier remembers the reminders it was asked to send and fails
+// for the order references in fail
+type recordingNotifier struct {
+	sent map[string][]int
+	fail map[string]bool
+}
+
+func (n *recordingNotifier) RemindPickup(ctx context.Context, pkg *domain.Package, reminder int) error {
+	if n.fail[pkg.OrderRef] {
+		return errors.New("notifier unavailable")
+	}
+	n.sent[pkg.OrderRef] = append(n.sent[pkg.OrderRef], reminder)
+	return nil
+}
+
+func setupReminders(t *testing.T, now *time.Time) (*usecase.ReminderUsecase, domain.PackageRepository, *recordingNotifier) {
+	repo := repository.NewMemoryPackageRepository()
+	packages := usecase.NewPackageUsecase(repo)
+	packages.SetServiceLevels(testServiceLevels)
+	notifier := &recordingNotifier{sent: map[string][]int{}, fail: map[string]bool{}}
+	uc := usecase.NewReminderUsecase(repository.NewMemoryPickupReminderRepository(), packages).
+		WithNotifier(notifier).
+		WithClock(func() time.Time { return *now })
+	return uc, repo, notifier
+}
+
+func TestReminderUsecase_SendReminders_HappyPath_CadencePerPriority(t *testing.T) {
+	// Setup
+	now := time.Now()
+	uc, repo, notifier := setupReminders(t, &now)
+
+	seedPackage(t, repo, "EXP-001", withPriority(domain.PriorityExpress), createdAgo(5*time.Hour))
+	seedPackage(t, repo, "EXP-002", withPriority(domain.PriorityExpress), createdAgo(time.Hour))
+	seedPackage(t, repo, "STD-001", withPriority(domain.PriorityStandard), createdAgo(5*time.Hour))
+	seedPackage(t, repo, "ECO-001", withPriority(domain.PriorityEconomy), createdAgo(50*time.Hour))
+	picked := seedPackage(t, repo, "EXP-003", withPriority(domain.PriorityExpress), createdAgo(5*time.Hour))
+	require.NoError(t, repo.UpdateStatus(picked.ID, domain.StatusPicked))
+
+	// Execute
+	first, err := uc.SendReminders(context.Background())
+	require.NoError(t, err)
+	now = now.Add(2 * time.Hour)
+	second, err := uc.SendReminders(context.Background())
+	require.NoError(t, err)
+	now = now.Add(2 * time.Hour)
+	third, err := uc.SendReminders(context.Background())
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 0, second)
+	// EXP-001 is due again four hours after its first reminder; EXP-002
+	// reached its first interval in the meantime
+	assert.Equal(t, 2, third)
+	assert.Equal(t, map[string][]int{"EXP-001": {1, 2}, "EXP-002": {1}}, notifier.sent)
+}
+
+func TestReminderUsecase_SendReminders_EdgeCase_ReportsFailures(t *testing.T) {
+	// Setup
+	now := time.Now()
+	uc, repo, notifier := setupReminders(t, &now)
+
+	failed := seedPackage(t, repo, "EXP-001", withPriority(domain.PriorityExpress), createdAgo(5*time.Hour))
+	seedPackage(t, repo, "EXP-002", withPriority(domain.PriorityExpress), createdAgo(5*time.Hour))
+	notifier.fail["EXP-001"] = true
+
+	// Execute
+	sent, err := uc.SendReminders(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), failed.ID.String())
+	assert.Equal(t, 1, sent)
+
+	// The failed package is retried on the next run
+	delete(notifier.fail, "EXP-001")
+	sent, err = uc.SendReminders(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, map[string][]int{"EXP-001": {1}, "EXP-002": {1}}, notifier.sent)
+}
+
+func TestReminderUsecase_SendReminders_EdgeCase_PriorityWithoutReminders(t *testing.T) {
+	// Setup
+	now := time.Now()
+	uc, repo, notifier := setupReminders(t, &now)
+	seedPackage(t, repo, "ECO-001", withPriority(domain.PriorityEconomy), createdAgo(100*time.Hour))
+
+	// Execute
+	sent, err := uc.SendReminders(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Empty(t, notifier.sent)
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pickup-queue/internal/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReturnUsecase moves expired packages into the return-to-sender flow and
+// builds the manifests drivers take them back with
+type ReturnUsecase struct {
+	packages *PackageUsecase
+	now      func() time.Time
+}
+
+func NewReturnUsecase(packages *PackageUsecase) *ReturnUsecase {
+	return &ReturnUsecase{
+		packages: packages,
+		now:      time.Now,
+	}
+}
+
+// WithClock replaces the usecase's clock, for tests
+func (ru *ReturnUsecase) WithClock(now func() time.Time) *ReturnUsecase {
+	ru.now = now
+	return ru
+}
+
+// StartReturns marks RETURN_PENDING every EXPIRED package that expired more
+// than hold ago and returns how many were moved. A package that cannot be
+// moved is logged and skipped, so the next run retries it; the failures are
+// joined into the returned error.
+func (ru *ReturnUsecase) StartReturns(ctx context.Context, hold time.Duration) (int, error) {
+	expired, err := ru.packages.packageRepo.GetExpiredBefore(ctx, ru.now().Add(-hold))
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	var failures []error
+	for _, pkg := range expired {
+		if err := ctx.Err(); err != nil {
+			return moved, errors.Join(append(failures, err)...)
+		}
+		if _, err := ru.packages.UpdatePackageStatus(pkg.ID, domain.StatusReturnPending); err != nil {
+			log.Printf("Failed to start the return of package %s: %v", pkg.ID, err)
+			failures = append(failures, fmt.Errorf("package %s: %w", pkg.ID, err))
+			continue
+		}
+		moved++
+	}
+	return moved, errors.Join(failures...)
+}
+
+// Manifest groups the RETURN_PENDING packages by carrier and then driver,
+// optionally only those of one carrier or driver
+func (ru *ReturnUsecase) Manifest(carrier, driverCode string) (*domain.ReturnsManifest, error) {
+	status := domain.StatusReturnPending
+	filter := domain.PackageFilter{Status: &status, DriverCode: ru.packages.rules.DriverCode.normalize(driverCode)}
+	pending, err := ru.packages.packageRepo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	carrier = strings.TrimSpace(carrier)
+	manifest := &domain.ReturnsManifest{GeneratedAt: ru.now(), Groups: []*domain.ReturnGroup{}}
+	groups := make(map[[2]string]*domain.ReturnGroup)
+	for _, pkg := range pending {
+		if carrier != "" && !strings.EqualFold(pkg.Carrier, carrier) {
+			continue
+		}
+		key := [2]string{pkg.Carrier, pkg.DriverCode}
+		group, ok := groups[key]
+		if !ok {
+			group = &domain.ReturnGroup{Carrier: pkg.Carrier, DriverCode: pkg.DriverCode}
+			groups[key] = group
+			manifest.Groups = append(manifest.Groups, group)
+		}
+		group.Packages = append(group.Packages, pkg)
+		group.Count++
+		manifest.Total++
+	}
+
+	sort.Slice(manifest.Groups, func(i, j int) bool {
+		a, b := manifest.Groups[i], manifest.Groups[j]
+		if a.Carrier != b.Carrier {
+			return a.Carrier < b.Carrier
+		}
+		return a.DriverCode < b.DriverCode
+	})
+	for _, group := range manifest.Groups {
+		sort.Slice(group.Packages, func(i, j int) bool {
+			return group.Packages[i].OrderRef < group.Packages[j].OrderRef
+		})
+	}
+	return manifest, nil
+}
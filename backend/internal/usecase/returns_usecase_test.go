@@ -0,0 +1,154 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageUsecase_UpdatePackageStatus_HappyPath_ReturnFlow(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo)
+	pkg := seedPackage(t, repo, "RET-001", expiredAgo(time.Hour))
+
+	// Execute
+	pending, err := uc.UpdatePackageStatus(pkg.ID, domain.StatusReturnPending)
+	require.NoError(t, err)
+	returned, err := uc.UpdatePackageStatus(pkg.ID, domain.StatusReturned)
+	require.NoError(t, err)
+
+	// Assert
+	assert.NotNil(t, pending.ReturnPendingAt)
+	assert.Equal(t, domain.StatusReturned, returned.Status)
+	assert.NotNil(t, returned.ReturnedAt)
+	_, err = uc.UpdatePackageStatus(pkg.ID, domain.StatusDisposed)
+	assert.ErrorIs(t, err, usecase.ErrInvalidStatusTransition)
+}
+
+func TestPackageUsecase_UpdatePackageStatus_EdgeCase_ReturnOnlyAfterExpiry(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo)
+	waiting, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "RET-001", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	expired := seedPackage(t, repo, "RET-002", expiredAgo(time.Hour))
+
+	// Execute
+	_, returnErr := uc.UpdatePackageStatus(waiting.ID, domain.StatusReturnPending)
+	disposed, disposeErr := uc.UpdatePackageStatus(expired.ID, domain.StatusDisposed)
+
+	// Assert
+	assert.ErrorIs(t, returnErr, usecase.ErrInvalidStatusTransition)
+	require.NoError(t, disposeErr)
+	assert.NotNil(t, disposed.DisposedAt)
+}
+
+func TestReturnUsecase_StartReturns_HappyPath_AfterHoldPeriod(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewReturnUsecase(usecase.NewPackageUsecase(repo))
+	held := seedPackage(t, repo, "RET-001", expiredAgo(8*24*time.Hour))
+	recent := seedPackage(t, repo, "RET-002", expiredAgo(2*24*time.Hour))
+
+	// Execute
+	moved, err := uc.StartReturns(context.Background(), 7*24*time.Hour)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	got, err := repo.GetByID(held.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusReturnPending, got.Status)
+	got, err = repo.GetByID(recent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusExpired, got.Status)
+}
+
+func TestReturnUsecase_StartReturns_EdgeCase_ReportsFailures(t *testing.T) {
+	// Setup
+	mockRepo := new(MockPackageRepository)
+	uc := usecase.NewReturnUsecase(usecase.NewPackageUsecase(mockRepo))
+	expiredAt := time.Now().Add(-8 * 24 * time.Hour)
+	failing := &domain.Package{ID: uuid.New(), OrderRef: "RET-001", Status: domain.StatusExpired, ExpiredAt: &expiredAt}
+	moving := &domain.Package{ID: uuid.New(), OrderRef: "RET-002", Status: domain.StatusExpired, ExpiredAt: &expiredAt}
+	storageErr := errors.New("connection reset")
+
+	// Mock expectations
+	mockRepo.On("GetExpiredBefore", mock.AnythingOfType("time.Time")).Return([]*domain.Package{failing, moving}, nil)
+	mockRepo.On("GetByID", failing.ID).Return(failing, nil)
+	mockRepo.On("GetByID", moving.ID).Return(moving, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(p *domain.Package) bool { return p.ID == failing.ID })).Return(storageErr)
+	mockRepo.On("Update", mock.MatchedBy(func(p *domain.Package) bool { return p.ID == moving.ID })).Return(nil)
+
+	// Execute
+	moved, err := uc.StartReturns(context.Background(), 7*24*time.Hour)
+
+	// Assert
+	assert.Equal(t, 1, moved)
+	assert.ErrorIs(t, err, storageErr)
+	assert.ErrorContains(t, err, failing.ID.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReturnUsecase_Manifest_HappyPath_GroupsByCarrierAndDriver(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewReturnUsecase(usecase.NewPackageUsecase(repo))
+	seedPackage(t, repo, "RET-003", forDriver("DRV-002"), withCarrier("ACME"), expiredAgo(10*24*time.Hour))
+	seedPackage(t, repo, "RET-001", withCarrier("ACME"), expiredAgo(10*24*time.Hour))
+	seedPackage(t, repo, "RET-002", withCarrier("ACME"), expiredAgo(10*24*time.Hour))
+	seedPackage(t, repo, "RET-004", expiredAgo(10*24*time.Hour))
+	seedPackage(t, repo, "RET-005", withCarrier("ACME"), expiredAgo(time.Hour))
+	_, err := uc.StartReturns(context.Background(), 7*24*time.Hour)
+	require.NoError(t, err)
+
+	// Execute
+	manifest, err := uc.Manifest("", "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 4, manifest.Total)
+	require.Len(t, manifest.Groups, 3)
+	assert.Equal(t, "", manifest.Groups[0].Carrier)
+	assert.Equal(t, "ACME", manifest.Groups[1].Carrier)
+	assert.Equal(t, "DRV-001", manifest.Groups[1].DriverCode)
+	assert.Equal(t, 2, manifest.Groups[1].Count)
+	assert.Equal(t, "RET-001", manifest.Groups[1].Packages[0].OrderRef)
+	assert.Equal(t, "DRV-002", manifest.Groups[2].DriverCode)
+}
+
+func TestReturnUsecase_Manifest_EdgeCase_Filters(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewReturnUsecase(usecase.NewPackageUsecase(repo))
+	seedPackage(t, repo, "RET-001", withCarrier("ACME"), expiredAgo(10*24*time.Hour))
+	seedPackage(t, repo, "RET-002", forDriver("DRV-002"), withCarrier("ACME"), expiredAgo(10*24*time.Hour))
+	seedPackage(t, repo, "RET-003", withCarrier("OTHER"), expiredAgo(10*24*time.Hour))
+	_, err := uc.StartReturns(context.Background(), 7*24*time.Hour)
+	require.NoError(t, err)
+
+	// Execute
+	byCarrier, err := uc.Manifest("acme", "")
+	require.NoError(t, err)
+	byDriver, err := uc.Manifest("", " DRV-001 ")
+	require.NoError(t, err)
+	none, err := uc.Manifest("NOBODY", "")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 2, byCarrier.Total)
+	assert.Equal(t, 2, byDriver.Total)
+	assert.Equal(t, 0, none.Total)
+	assert.NotNil(t, none.Groups)
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// seedOption adjusts a package before seedPackage stores it
+type seedOption func(pkg *domain.Package)
+
+// seedPackage stores a WAITING package for DRV-001, created now and adjusted
+// by opts. It writes straight to repo, so tests can seed states and
+// timestamps the usecase would not produce itself.
+func seedPackage(t *testing.T, repo domain.PackageRepository, orderRef string, opts ...seedOption) *domain.Package {
+	t.Helper()
+	now := time.Now()
+	pkg := &domain.Package{
+		ID:         uuid.New(),
+		OrderRef:   orderRef,
+		DriverCode: "DRV-001",
+		Status:     domain.StatusWaiting,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	for _, opt := range opts {
+		opt(pkg)
+	}
+	require.NoError(t, repo.Create(pkg))
+	return pkg
+}
+
+// createdAgo backdates the package by age
+func createdAgo(age time.Duration) seedOption {
+	return func(pkg *domain.Package) {
+		pkg.CreatedAt = time.Now().Add(-age)
+		pkg.UpdatedAt = pkg.CreatedAt
+	}
+}
+
+func forDriver(driverCode string) seedOption {
+	return func(pkg *domain.Package) { pkg.DriverCode = driverCode }
+}
+
+func withCarrier(carrier string) seedOption {
+	return func(pkg *domain.Package) { pkg.Carrier = carrier }
+}
+
+func withPriority(priority domain.Priority) seedOption {
+	return func(pkg *domain.Package) { pkg.Priority = priority }
+}
+
+// expiredAgo makes the package EXPIRED age ago, two days after it arrived
+func expiredAgo(age time.Duration) seedOption {
+	return func(pkg *domain.Package) {
+		expiredAt := time.Now().Add(-age)
+		pkg.Status = domain.StatusExpired
+		pkg.ExpiredAt = &expiredAt
+		pkg.CreatedAt = expiredAt.Add(-48 * time.Hour)
+		pkg.UpdatedAt = expiredAt
+	}
+}
+
+// pickedUpAt makes the package PICKED at the given time, an hour after it
+// arrived
+func pickedUpAt(at time.Time) seedOption {
+	return func(pkg *domain.Package) {
+		pkg.Status = domain.StatusPicked
+		pkg.PickedUpAt = &at
+		pkg.CreatedAt = at.Add(-time.Hour)
+		pkg.UpdatedAt = at
+	}
+}
@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"fmt"
+	"pickup-queue/internal/domain"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrShipmentNotFound   = domain.NewError(domain.KindNotFound, "SHIPMENT_NOT_FOUND", "shipment not found")
+	ErrShipmentIncomplete = domain.NewError(domain.KindConflict, "SHIPMENT_INCOMPLETE", "every parcel of the shipment must be picked before any is handed over")
+)
+
+// MaxShipmentParcels caps how many parcels one shipment may have
+const MaxShipmentParcels = 50
+
+type ShipmentUsecase struct {
+	shipments domain.ShipmentRepository
+	packages  *PackageUsecase
+}
+
+// NewShipmentUsecase creates a usecase for multi-parcel shipments. Parcels
+// are packages and follow the rules of packages: identifier rules, physical
+// attributes, the status state machine and high-value handover checks.
+func NewShipmentUsecase(shipments domain.ShipmentRepository, packages *PackageUsecase) *ShipmentUsecase {
+	return &ShipmentUsecase{shipments: shipments, packages: packages}
+}
+
+// inTx runs fn with shipment and package repositories bound to a single
+// transaction
+func (su *ShipmentUsecase) inTx(opts domain.TxOptions, fn func(shipments domain.ShipmentRepository, packages domain.PackageRepository) error) error {
+	if su.packages.uow == nil {
+		return fn(su.shipments, su.packages.packageRepo)
+	}
+	return su.packages.uow.Do(opts, func(tx domain.Tx) error {
+		return fn(tx.Shipments(), tx.Packages())
+	})
+}
+
+// CreateShipment creates a shipment and one WAITING package per parcel. The
+// shipment's order reference, driver, carrier, recipient phone and priority
+// apply to every parcel.
+func (su *ShipmentUsecase) CreateShipment(req *domain.CreateShipmentRequest) (*domain.Shipment, error) {
+	pu := su.packages
+	rules, template, fields := pu.newPackage(req.Carrier, req.DriverCode, req.RecipientPhone, req.Priority)
+	orderRef := rules.OrderRef.normalize(req.OrderRef)
+	if fieldErr := rules.OrderRef.check("order_reference", "order reference", orderRef); fieldErr != nil {
+		fields = append(fields, *fieldErr)
+	}
+	switch {
+	case len(req.Parcels) == 0:
+		fields = append(fields, domain.FieldError{Field: "parcels", Code: "required", Message: "parcels is required"})
+	case len(req.Parcels) > MaxShipmentParcels:
+		fields = append(fields, domain.FieldError{Field: "parcels", Code: "max", Message: "parcels must be at most " + strconv.Itoa(MaxShipmentParcels)})
+	}
+
+	shipment := &domain.Shipment{
+		ID:          uuid.New(),
+		OrderRef:    orderRef,
+		DriverCode:  template.DriverCode,
+		Carrier:     template.Carrier,
+		ParcelCount: len(req.Parcels),
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+
+	parcels := make([]*domain.Package, 0, len(req.Parcels))
+	refs := make(map[string]bool, len(req.Parcels))
+	highValue := false
+	for i, p := range req.Parcels {
+		prefix := fmt.Sprintf("parcels[%d].", i)
+		parcel := *template
+		parcel.ID = uuid.New()
+		parcel.ShipmentID = &shipment.ID
+		parcel.ParcelNumber = i + 1
+		parcel.ParcelCount = 1
+
+		parcel.OrderRef = rules.OrderRef.normalize(p.ParcelRef)
+		if parcel.OrderRef == "" {
+			parcel.OrderRef = orderRef + "/" + strconv.Itoa(i+1)
+		}
+		if fieldErr := rules.OrderRef.checkParcelRef(prefix+"parcel_reference", parcel.OrderRef); fieldErr != nil {
+			fields = append(fields, *fieldErr)
+		} else if refs[parcel.OrderRef] || parcel.OrderRef == orderRef {
+			fields = append(fields, domain.FieldError{Field: prefix + "parcel_reference", Code: "duplicate", Message: "parcel reference is used twice"})
+		}
+		refs[parcel.OrderRef] = true
+
+		for _, fieldErr := range applyAttributes(&parcel, p.PackageAttributes) {
+			fieldErr.Field = prefix + fieldErr.Field
+			fields = append(fields, fieldErr)
+		}
+		highValue = highValue || pu.IsHighValue(&parcel)
+		parcels = append(parcels, &parcel)
+	}
+	if highValue && template.RecipientPhone == "" {
+		fields = append(fields, domain.FieldError{Field: "recipient_phone", Code: "required", Message: "recipient phone is required for high-value packages"})
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError(fields...)
+	}
+
+	// Archived packages keep their references reserved; see CreatePackage
+	if pu.archive != nil {
+		for _, ref := range append([]string{orderRef}, sortedKeys(refs)...) {
+			archived, err := pu.archive.GetByOrderRef(ref)
+			if err != nil {
+				return nil, err
+			}
+			if archived != nil {
+				return nil, ErrDuplicateOrderRef
+			}
+		}
+	}
+
+	err := su.inTx(domain.TxOptions{}, func(shipments domain.ShipmentRepository, packages domain.PackageRepository) error {
+		existing, err := packages.GetByOrderRef(orderRef)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrDuplicateOrderRef
+		}
+		if err := shipments.Create(shipment); err != nil {
+			return err
+		}
+		for _, parcel := range parcels {
+			existing, err := packages.GetByOrderRef(parcel.OrderRef)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return ErrDuplicateOrderRef
+			}
+			if err := packages.Create(parcel); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shipment.Parcels = parcels
+	shipment.Status = domain.DeriveShipmentStatus(parcels)
+	return shipment, nil
+}
+
+// GetShipment returns a shipment with its live parcels and derived status
+func (su *ShipmentUsecase) GetShipment(id uuid.UUID) (*domain.Shipment, error) {
+	shipment, err := su.shipments.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return su.withParcels(su.packages.packageRepo, shipment)
+}
+
+// GetShipmentByOrderRef looks a shipment up by its order reference,
+// normalized like package lookups
+func (su *ShipmentUsecase) GetShipmentByOrderRef(orderRef string) (*domain.Shipment, error) {
+	orderRef, fieldErr := su.packages.normalizeOrderRef(orderRef)
+	if fieldErr != nil {
+		return nil, domain.NewValidationError(*fieldErr)
+	}
+	shipment, err := su.shipments.GetByOrderRef(orderRef)
+	if err != nil {
+		return nil, err
+	}
+	return su.withParcels(su.packages.packageRepo, shipment)
+}
+
+// ListShipments lists shipments newest first, each with its parcels
+func (su *ShipmentUsecase) ListShipments(filter domain.ShipmentFilter) ([]*domain.Shipment, error) {
+	filter.DriverCode = su.packages.rules.DriverCode.normalize(filter.DriverCode)
+	shipments, err := su.shipments.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, shipment := range shipments {
+		if _, err := su.withParcels(su.packages.packageRepo, shipment); err != nil {
+			return nil, err
+		}
+	}
+	return shipments, nil
+}
+
+// UpdateShipmentStatus moves every parcel of a shipment to newStatus in one
+// transaction; parcels already there are left alone. If any parcel cannot
+// make the transition, none does. Handing over needs every parcel picked and,
+// for high-value parcels, token.
+func (su *ShipmentUsecase) UpdateShipmentStatus(id uuid.UUID, newStatus domain.PackageStatus, token string) (*domain.Shipment, error) {
+	var result *domain.Shipment
+	err := su.inTx(domain.TxOptions{Isolation: domain.IsolationSerializable}, func(shipments domain.ShipmentRepository, packages domain.PackageRepository) error {
+		shipment, err := shipments.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if result, err = su.withParcels(packages, shipment); err != nil {
+			return err
+		}
+		if len(result.Parcels) == 0 {
+			return ErrInvalidStatusTransition
+		}
+		if newStatus == domain.StatusHandedOver && !shipmentComplete(result.Parcels, nil) {
+			return ErrShipmentIncomplete
+		}
+
+		now := time.Now()
+		var changed []*domain.Package
+		for _, parcel := range result.Parcels {
+			if err := su.packages.verifyHandover(parcel, newStatus, token); err != nil {
+				return err
+			}
+			ok, err := su.packages.applyStatusTransition(parcel, newStatus, now)
+			if err != nil {
+				return err
+			}
+			if ok {
+				changed = append(changed, parcel)
+			}
+		}
+		for _, parcel := range changed {
+			if err := packages.Update(parcel); err != nil {
+				return err
+			}
+		}
+		result.Status = domain.DeriveShipmentStatus(result.Parcels)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// withParcels fills in the live parcels and status of shipment, reporting a
+// missing shipment as not found
+func (su *ShipmentUsecase) withParcels(packages domain.PackageRepository, shipment *domain.Shipment) (*domain.Shipment, error) {
+	if shipment == nil {
+		return nil, ErrShipmentNotFound
+	}
+	parcels, err := packages.GetAll(domain.PackageFilter{ShipmentID: &shipment.ID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(parcels, func(i, j int) bool { return parcels[i].ParcelNumber < parcels[j].ParcelNumber })
+	shipment.Parcels = parcels
+	shipment.Status = domain.DeriveShipmentStatus(parcels)
+	return shipment, nil
+}
+
+// checkShipmentComplete stops a parcel from being handed over while another
+// parcel of its shipment is not at the counter
+func (pu *PackageUsecase) checkShipmentComplete(repo domain.PackageRepository, pkg *domain.Package, newStatus domain.PackageStatus) error {
+	if newStatus != domain.StatusHandedOver || pkg.Status == newStatus || pkg.ShipmentID == nil {
+		return nil
+	}
+	parcels, err := repo.GetAll(domain.PackageFilter{ShipmentID: pkg.ShipmentID})
+	if err != nil {
+		return err
+	}
+	if !shipmentComplete(parcels, pkg) {
+		return ErrShipmentIncomplete
+	}
+	return nil
+}
+
+// shipmentComplete reports whether every parcel except skip is picked or
+// already handed over
+func shipmentComplete(parcels []*domain.Package, skip *domain.Package) bool {
+	for _, parcel := range parcels {
+		if skip != nil && parcel.ID == skip.ID {
+			continue
+		}
+		if parcel.Status != domain.StatusPicked && parcel.Status != domain.StatusHandedOver {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,196 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupShipments(t *testing.T) (*usecase.ShipmentUsecase, *usecase.PackageUsecase) {
+	repo := repository.NewMemoryPackageRepository()
+	shipments := repository.NewMemoryShipmentRepository()
+	uow := repository.NewInMemoryUnitOfWork(repo).WithShipments(shipments)
+	packages := usecase.NewPackageUsecaseWithUnitOfWork(repo, uow).WithShipments(shipments)
+	return usecase.NewShipmentUsecase(shipments, packages), packages
+}
+
+func createShipment(t *testing.T, uc *usecase.ShipmentUsecase, parcels int) *domain.Shipment {
+	t.Helper()
+	shipment, err := uc.CreateShipment(&domain.CreateShipmentRequest{
+		OrderRef:   "ORD-100",
+		DriverCode: "DRV-001",
+		Parcels:    make([]domain.CreateParcelRequest, parcels),
+	})
+	require.NoError(t, err)
+	return shipment
+}
+
+func TestShipmentUsecase_CreateShipment_HappyPath(t *testing.T) {
+	// Setup
+	uc, packages := setupShipments(t)
+
+	// Execute
+	shipment, err := uc.CreateShipment(&domain.CreateShipmentRequest{
+		OrderRef:   " ORD-100 ",
+		DriverCode: "DRV-001",
+		Parcels: []domain.CreateParcelRequest{
+			{PackageAttributes: domain.PackageAttributes{LengthCm: intPtr(30), WidthCm: intPtr(20), HeightCm: intPtr(5)}},
+			{ParcelRef: "ORD-100-B", PackageAttributes: domain.PackageAttributes{Fragile: true}},
+		},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-100", shipment.OrderRef)
+	assert.Equal(t, 2, shipment.ParcelCount)
+	assert.Equal(t, domain.ShipmentWaiting, shipment.Status)
+	require.Len(t, shipment.Parcels, 2)
+	assert.Equal(t, "ORD-100/1", shipment.Parcels[0].OrderRef)
+	assert.Equal(t, domain.SizeSmall, shipment.Parcels[0].SizeClass)
+	assert.Equal(t, "ORD-100-B", shipment.Parcels[1].OrderRef)
+	assert.True(t, shipment.Parcels[1].Fragile)
+
+	parcel, err := packages.GetPackageByOrderRef("ORD-100-B")
+	require.NoError(t, err)
+	assert.Equal(t, &shipment.ID, parcel.ShipmentID)
+	assert.Equal(t, 2, parcel.ParcelNumber)
+	byRef, err := uc.GetShipmentByOrderRef("ORD-100")
+	require.NoError(t, err)
+	assert.Len(t, byRef.Parcels, 2)
+}
+
+func TestShipmentUsecase_CreateShipment_EdgeCase_DuplicateReferences(t *testing.T) {
+	// Setup
+	uc, packages := setupShipments(t)
+	_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-200", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+	createShipment(t, uc, 1)
+
+	// Execute
+	_, packageTaken := uc.CreateShipment(&domain.CreateShipmentRequest{OrderRef: "ORD-200", DriverCode: "DRV-001", Parcels: make([]domain.CreateParcelRequest, 1)})
+	_, shipmentTaken := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-100", DriverCode: "DRV-001"})
+	_, twice := uc.CreateShipment(&domain.CreateShipmentRequest{
+		OrderRef:   "ORD-300",
+		DriverCode: "DRV-001",
+		Parcels:    []domain.CreateParcelRequest{{ParcelRef: "ORD-301"}, {ParcelRef: "ORD-301"}},
+	})
+
+	// Assert
+	assert.ErrorIs(t, packageTaken, usecase.ErrDuplicateOrderRef)
+	assert.ErrorIs(t, shipmentTaken, usecase.ErrDuplicateOrderRef)
+	assert.Equal(t, map[string]string{"parcels[1].parcel_reference": "duplicate"}, fieldCodes(t, twice))
+	_, err = uc.GetShipmentByOrderRef("ORD-300")
+	assert.ErrorIs(t, err, usecase.ErrShipmentNotFound)
+}
+
+// failingShipments is a shipment repository whose order reference lookup
+// always fails
+type failingShipments struct {
+	domain.ShipmentRepository
+	err error
+}
+
+func (s failingShipments) GetByOrderRef(orderRef string) (*domain.Shipment, error) {
+	return nil, s.err
+}
+
+func TestShipmentUsecase_CreateShipment_EdgeCase_LookupErrors(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	lookupErr := errors.New("lookup unavailable")
+	packages := usecase.NewPackageUsecase(repo).WithShipments(failingShipments{err: lookupErr})
+	archived := usecase.NewPackageUsecase(repo).WithArchive(failingArchive{err: lookupErr})
+	uc := usecase.NewShipmentUsecase(repository.NewMemoryShipmentRepository(), archived)
+
+	// Execute
+	pkg, packageErr := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-400", DriverCode: "DRV-001"})
+	shipment, shipmentErr := uc.CreateShipment(&domain.CreateShipmentRequest{OrderRef: "ORD-500", DriverCode: "DRV-001", Parcels: make([]domain.CreateParcelRequest, 1)})
+
+	// Assert
+	assert.ErrorIs(t, packageErr, lookupErr)
+	assert.Nil(t, pkg)
+	assert.ErrorIs(t, shipmentErr, lookupErr)
+	assert.Nil(t, shipment)
+	all, err := repo.GetAll(domain.PackageFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestShipmentUsecase_UpdateShipmentStatus_HappyPath_DerivedStatus(t *testing.T) {
+	// Setup
+	uc, packages := setupShipments(t)
+	shipment := createShipment(t, uc, 3)
+
+	// Execute
+	_, err := packages.UpdatePackageStatus(shipment.Parcels[0].ID, domain.StatusPicked)
+	require.NoError(t, err)
+	partial, err := uc.GetShipment(shipment.ID)
+	require.NoError(t, err)
+	picked, pickErr := uc.UpdateShipmentStatus(shipment.ID, domain.StatusPicked, "")
+	handed, handErr := uc.UpdateShipmentStatus(shipment.ID, domain.StatusHandedOver, "")
+
+	// Assert
+	assert.Equal(t, domain.ShipmentPartiallyPicked, partial.Status)
+	require.NoError(t, pickErr)
+	assert.Equal(t, domain.ShipmentPicked, picked.Status)
+	require.NoError(t, handErr)
+	assert.Equal(t, domain.ShipmentHandedOver, handed.Status)
+	for _, parcel := range handed.Parcels {
+		assert.Equal(t, domain.StatusHandedOver, parcel.Status)
+		assert.NotNil(t, parcel.HandedOverAt)
+	}
+}
+
+func TestShipmentUsecase_UpdateShipmentStatus_EdgeCase_HandoverNeedsAllParcels(t *testing.T) {
+	// Setup
+	uc, packages := setupShipments(t)
+	shipment := createShipment(t, uc, 2)
+	first := shipment.Parcels[0]
+	_, err := packages.UpdatePackageStatus(first.ID, domain.StatusPicked)
+	require.NoError(t, err)
+
+	// Execute
+	_, shipmentErr := uc.UpdateShipmentStatus(shipment.ID, domain.StatusHandedOver, "")
+	_, parcelErr := packages.UpdatePackageStatus(first.ID, domain.StatusHandedOver)
+	results, batchErr := packages.BatchUpdatePackageStatus(&domain.BatchUpdateStatusRequest{
+		OrderRefs:    []string{first.OrderRef},
+		Status:       domain.StatusHandedOver,
+		AllOrNothing: true,
+	})
+	_, notFound := uc.GetShipment(first.ID)
+
+	// Assert
+	assert.ErrorIs(t, shipmentErr, usecase.ErrShipmentIncomplete)
+	assert.ErrorIs(t, parcelErr, usecase.ErrShipmentIncomplete)
+	assert.ErrorIs(t, batchErr, usecase.ErrBatchRejected)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.ErrorIs(t, notFound, usecase.ErrShipmentNotFound)
+	current, err := uc.GetShipment(shipment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ShipmentPartiallyPicked, current.Status)
+}
+
+func TestShipmentUsecase_DeriveShipmentStatus_EdgeCase_Mixed(t *testing.T) {
+	// Setup
+	parcels := func(statuses ...domain.PackageStatus) []*domain.Package {
+		out := make([]*domain.Package, len(statuses))
+		for i, status := range statuses {
+			out[i] = &domain.Package{Status: status}
+		}
+		return out
+	}
+
+	// Execute & Assert
+	assert.Equal(t, domain.ShipmentArchived, domain.DeriveShipmentStatus(nil))
+	assert.Equal(t, domain.ShipmentPartiallyHandedOver, domain.DeriveShipmentStatus(parcels(domain.StatusHandedOver, domain.StatusExpired)))
+	assert.Equal(t, domain.ShipmentPartiallyExpired, domain.DeriveShipmentStatus(parcels(domain.StatusExpired, domain.StatusPicked)))
+	assert.Equal(t, domain.ShipmentExpired, domain.DeriveShipmentStatus(parcels(domain.StatusExpired, domain.StatusExpired)))
+	assert.Equal(t, domain.ShipmentWaiting, domain.DeriveShipmentStatus(parcels(domain.StatusWaiting)))
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"pickup-queue/internal/domain"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPriority is returned for a priority outside domain.Priorities
+var ErrInvalidPriority = domain.NewError(domain.KindValidation, "INVALID_PRIORITY", "priority must be one of EXPRESS STANDARD ECONOMY")
+
+// DefaultBreachReportPeriod is how far back a breach report looks when no
+// start is given
+const DefaultBreachReportPeriod = 7 * 24 * time.Hour
+
+// ServiceLevel is what a priority class promises. A zero ExpiryWindow falls
+// back to the package usecase's expiry window; a zero PickupTarget disables
+// breach detection and a zero ReminderInterval pickup reminders for the
+// class.
+type ServiceLevel struct {
+	ExpiryWindow     time.Duration
+	PickupTarget     time.Duration
+	ReminderInterval time.Duration
+}
+
+// SetServiceLevels replaces the per-priority service levels; it is safe to
+// call at runtime
+func (pu *PackageUsecase) SetServiceLevels(levels map[domain.Priority]ServiceLevel) {
+	copied := make(map[domain.Priority]ServiceLevel, len(levels))
+	for priority, level := range levels {
+		copied[priority] = level
+	}
+	pu.serviceLevels.Store(&copied)
+}
+
+// serviceLevel returns the service level of priority; unset priorities
+// count as STANDARD
+func (pu *PackageUsecase) serviceLevel(priority domain.Priority) ServiceLevel {
+	levels := pu.serviceLevels.Load()
+	if levels == nil {
+		return ServiceLevel{}
+	}
+	if !priority.Valid() {
+		priority = domain.PriorityStandard
+	}
+	return (*levels)[priority]
+}
+
+// ExpiryWindowFor returns how long a package of priority may stay active
+func (pu *PackageUsecase) ExpiryWindowFor(priority domain.Priority) time.Duration {
+	if window := pu.serviceLevel(priority).ExpiryWindow; window > 0 {
+		return window
+	}
+	return pu.ExpiryWindow()
+}
+
+// PickupTargetFor returns how soon a package of priority should be picked
+// up, or 0 when the class has no target
+func (pu *PackageUsecase) PickupTargetFor(priority domain.Priority) time.Duration {
+	return pu.serviceLevel(priority).PickupTarget
+}
+
+// ReminderIntervalFor returns how often the recipient of a waiting package
+// of priority is reminded, or 0 when the class sends no reminders
+func (pu *PackageUsecase) ReminderIntervalFor(priority domain.Priority) time.Duration {
+	return pu.serviceLevel(priority).ReminderInterval
+}
+
+// shortestExpiryWindow is the smallest expiry window of any priority
+func (pu *PackageUsecase) shortestExpiryWindow() time.Duration {
+	shortest := pu.ExpiryWindow()
+	for _, priority := range domain.Priorities {
+		if window := pu.ExpiryWindowFor(priority); window < shortest {
+			shortest = window
+		}
+	}
+	return shortest
+}
+
+// SLAUsecase detects packages that missed their priority's pickup target
+// and reports on them
+type SLAUsecase struct {
+	breaches domain.SLABreachRepository
+	packages *PackageUsecase
+	now      func() time.Time
+}
+
+func NewSLAUsecase(breaches domain.SLABreachRepository, packages *PackageUsecase) *SLAUsecase {
+	return &SLAUsecase{
+		breaches: breaches,
+		packages: packages,
+		now:      time.Now,
+	}
+}
+
+// WithClock replaces the usecase's clock, for tests
+func (su *SLAUsecase) WithClock(now func() time.Time) *SLAUsecase {
+	su.now = now
+	return su
+}
+
+// DetectBreaches records every WAITING package still waiting past its
+// pickup target and returns how many had not been recorded before
+func (su *SLAUsecase) DetectBreaches(ctx context.Context) (int, error) {
+	now := su.now()
+	var shortest time.Duration
+	for _, priority := range domain.Priorities {
+		if target := su.packages.PickupTargetFor(priority); target > 0 && (shortest == 0 || target < shortest) {
+			shortest = target
+		}
+	}
+	if shortest == 0 {
+		return 0, nil
+	}
+
+	// Every breached package was created before the shortest target's cutoff
+	candidates, err := su.packages.packageRepo.GetExpiredPackages(ctx, now.Add(-shortest))
+	if err != nil {
+		return 0, err
+	}
+
+	recorded := 0
+	for _, pkg := range candidates {
+		target := su.packages.PickupTargetFor(pkg.Priority)
+		if pkg.Status != domain.StatusWaiting || target <= 0 {
+			continue
+		}
+		dueAt := pkg.CreatedAt.Add(target)
+		if !dueAt.Before(now) {
+			continue
+		}
+		stored, err := su.breaches.Record(&domain.SLABreach{
+			PackageID:  pkg.ID,
+			OrderRef:   pkg.OrderRef,
+			DriverCode: pkg.DriverCode,
+			Priority:   pkg.Priority,
+			DueAt:      dueAt,
+			DetectedAt: now,
+		})
+		if err != nil {
+			return recorded, err
+		}
+		if stored {
+			recorded++
+		}
+	}
+	return recorded, nil
+}
+
+// Report lists the breaches detected in [filter.From, filter.To). A zero To
+// means now and a zero From a week before To.
+func (su *SLAUsecase) Report(filter domain.SLABreachFilter) (*domain.SLABreachReport, error) {
+	if filter.To.IsZero() {
+		filter.To = su.now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-DefaultBreachReportPeriod)
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, domain.NewValidationError(domain.FieldError{Field: "from", Code: "before", Message: "from must be before to"})
+	}
+	filter.Priority = domain.Priority(strings.ToUpper(strings.TrimSpace(string(filter.Priority))))
+	if filter.Priority != "" && !filter.Priority.Valid() {
+		return nil, ErrInvalidPriority
+	}
+
+	breaches, err := su.breaches.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.SLABreachReport{
+		From:       filter.From,
+		To:         filter.To,
+		Total:      len(breaches),
+		ByPriority: make(map[domain.Priority]int, len(domain.Priorities)),
+		Breaches:   breaches,
+	}
+	for _, priority := range domain.Priorities {
+		report.ByPriority[priority] = 0
+	}
+	for _, breach := range breaches {
+		report.ByPriority[breach.Priority]++
+	}
+	return report, nil
+}
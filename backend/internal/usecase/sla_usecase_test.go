@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testServiceLevels = map[domain.Priority]usecase.ServiceLevel{
+	domain.PriorityExpress:  {ExpiryWindow: 12 * time.Hour, PickupTarget: 2 * time.Hour, ReminderInterval: 4 * time.Hour},
+	domain.PriorityStandard: {PickupTarget: 8 * time.Hour, ReminderInterval: 24 * time.Hour},
+	domain.PriorityEconomy:  {ExpiryWindow: 72 * time.Hour, PickupTarget: 24 * time.Hour},
+}
+
+func TestPackageUsecase_Priority_HappyPath_DefaultsToStandard(t *testing.T) {
+	// Setup
+	uc := usecase.NewPackageUsecase(repository.NewMemoryPackageRepository())
+
+	// Execute
+	standard, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-001", DriverCode: "DRV-1"})
+	require.NoError(t, err)
+	express, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-002", DriverCode: "DRV-1", Priority: " express "})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, domain.PriorityStandard, standard.Priority)
+	assert.Equal(t, domain.PriorityExpress, express.Priority)
+}
+
+func TestPackageUsecase_Priority_EdgeCase_Invalid(t *testing.T) {
+	// Setup
+	uc := usecase.NewPackageUsecase(repository.NewMemoryPackageRepository())
+
+	// Execute
+	_, err := uc.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ABC-001", DriverCode: "DRV-1", Priority: "URGENT"})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Equal(t, map[string]string{"priority": "oneof"}, fieldCodes(t, err))
+}
+
+func TestPackageUsecase_PreviewExpiredPackages_HappyPath_PerPriority(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	uc := usecase.NewPackageUsecase(repo)
+	uc.SetServiceLevels(testServiceLevels)
+	expressDue := seedPackage(t, repo, "EXP-001", withPriority(domain.PriorityExpress), createdAgo(13*time.Hour))
+	seedPackage(t, repo, "EXP-002", withPriority(domain.PriorityExpress), createdAgo(11*time.Hour))
+	standardDue := seedPackage(t, repo, "STD-001", withPriority(domain.PriorityStandard), createdAgo(25*time.Hour))
+	seedPackage(t, repo, "STD-002", withPriority(domain.PriorityStandard), createdAgo(23*time.Hour))
+	seedPackage(t, repo, "ECO-001", withPriority(domain.PriorityEconomy), createdAgo(48*time.Hour))
+
+	// Execute
+	expired, cutoff, err := uc.PreviewExpiredPackages(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-usecase.DefaultExpiryWindow), cutoff, 5*time.Second)
+	ids := map[uuid.UUID]bool{}
+	for _, pkg := range expired {
+		ids[pkg.ID] = true
+	}
+	assert.Equal(t, map[uuid.UUID]bool{expressDue.ID: true, standardDue.ID: true}, ids)
+	assert.Equal(t, 72*time.Hour, uc.ExpiryWindowFor(domain.PriorityEconomy))
+	assert.Equal(t, usecase.DefaultExpiryWindow, uc.ExpiryWindowFor(domain.PriorityStandard))
+}
+
+func TestSLAUsecase_DetectBreaches_HappyPath(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	packages := usecase.NewPackageUsecase(repo)
+	packages.SetServiceLevels(testServiceLevels)
+	breaches := repository.NewMemorySLABreachRepository()
+	uc := usecase.NewSLAUsecase(breaches, packages)
+
+	late := seedPackage(t, repo, "EXP-001", withPriority(domain.PriorityExpress), createdAgo(3*time.Hour))
+	seedPackage(t, repo, "EXP-002", withPriority(domain.PriorityExpress), createdAgo(time.Hour))
+	seedPackage(t, repo, "STD-001", withPriority(domain.PriorityStandard), createdAgo(3*time.Hour))
+	picked := seedPackage(t, repo, "EXP-003", withPriority(domain.PriorityExpress), createdAgo(3*time.Hour))
+	require.NoError(t, repo.UpdateStatus(picked.ID, domain.StatusPicked))
+
+	// Execute
+	first, err := uc.DetectBreaches(context.Background())
+	require.NoError(t, err)
+	second, err := uc.DetectBreaches(context.Background())
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 0, second)
+	report, err := uc.Report(domain.SLABreachFilter{})
+	require.NoError(t, err)
+	require.Len(t, report.Breaches, 1)
+	assert.Equal(t, late.ID, report.Breaches[0].PackageID)
+	assert.Equal(t, domain.PriorityExpress, report.Breaches[0].Priority)
+	assert.WithinDuration(t, late.CreatedAt.Add(2*time.Hour), report.Breaches[0].DueAt, time.Millisecond)
+	assert.Equal(t, map[domain.Priority]int{domain.PriorityExpress: 1, domain.PriorityStandard: 0, domain.PriorityEconomy: 0}, report.ByPriority)
+}
+
+func TestSLAUsecase_Report_EdgeCase_InvalidFilter(t *testing.T) {
+	// Setup
+	uc := usecase.NewSLAUsecase(repository.NewMemorySLABreachRepository(), usecase.NewPackageUsecase(repository.NewMemoryPackageRepository()))
+	now := time.Now()
+
+	// Execute
+	_, priorityErr := uc.Report(domain.SLABreachFilter{Priority: "URGENT"})
+	_, rangeErr := uc.Report(domain.SLABreachFilter{From: now, To: now.Add(-time.Hour)})
+
+	// Assert
+	assert.ErrorIs(t, priorityErr, usecase.ErrInvalidPriority)
+	assert.Equal(t, map[string]string{"from": "before"}, fieldCodes(t, rangeErr))
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"pickup-queue/internal/domain"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTrackingNotFound covers unknown order references and wrong tokens
+	// alike, so callers cannot probe which order references exist
+	ErrTrackingNotFound = domain.NewError(domain.KindNotFound, "TRACKING_NOT_FOUND", "no parcel matches the order reference and verification code")
+	ErrTrackingLocked   = domain.NewError(domain.KindTooManyRequests, "TRACKING_LOCKED", "too many failed attempts, try again later")
+)
+
+// trackingTokenDigits is how many trailing phone digits the recipient enters
+const trackingTokenDigits = 4
+
+var trackingDescriptions = map[domain.PackageStatus]string{
+	domain.StatusWaiting:       "Your parcel is ready at the pickup point",
+	domain.StatusPicked:        "Your parcel has been picked up",
+	domain.StatusHandedOver:    "Your parcel has been handed over",
+	domain.StatusExpired:       "Your parcel was not collected in time",
+	domain.StatusReturnPending: "Your parcel is waiting to be returned to the sender",
+	domain.StatusReturned:      "Your parcel has been returned to the sender",
+	domain.StatusDisposed:      "Your parcel has been disposed of",
+}
+
+// TrackingUsecase answers public tracking requests from recipients. Wrong
+// verification tokens are counted per order reference, and an order
+// reference with too many failures is locked for a while. The counters live
+// in process memory.
+type TrackingUsecase struct {
+	packages    *PackageUsecase
+	location    domain.PickupLocation
+	maxFailures int
+	lockout     time.Duration
+
+	mu        sync.Mutex
+	failures  map[string]*trackingFailures
+	lastSweep time.Time
+}
+
+type trackingFailures struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+func NewTrackingUsecase(packages *PackageUsecase, location domain.PickupLocation, maxFailures int, lockout time.Duration) *TrackingUsecase {
+	return &TrackingUsecase{
+		packages:    packages,
+		location:    location,
+		maxFailures: maxFailures,
+		lockout:     lockout,
+		failures:    make(map[string]*trackingFailures),
+	}
+}
+
+// Track returns the public view of the package with orderRef when token
+// matches the last 4 digits of its recipient phone number. Packages without a
+// phone number on file cannot be tracked.
+func (tu *TrackingUsecase) Track(orderRef, token string) (*domain.TrackingInfo, error) {
+	orderRef = strings.TrimSpace(orderRef)
+	// Failures are counted against the reference the lookup uses, so
+	// spelling variants of one reference share their attempts
+	if normalized, fieldErr := tu.packages.normalizeOrderRef(orderRef); fieldErr == nil {
+		orderRef = normalized
+	}
+	now := time.Now()
+	if tu.locked(orderRef, now) {
+		return nil, ErrTrackingLocked
+	}
+
+	pkg, err := tu.packages.GetPackageByOrderRef(orderRef)
+	// A reference no rule set accepts is as unknown as a missing one
+	if err != nil && !errors.Is(err, ErrPackageNotFound) && !errors.Is(err, domain.ErrValidation) {
+		return nil, err
+	}
+	if pkg == nil || !tokenMatches(pkg.RecipientPhone, token) {
+		tu.recordFailure(orderRef, now)
+		return nil, ErrTrackingNotFound
+	}
+	tu.clearFailures(orderRef)
+
+	return tu.trackingInfo(pkg), nil
+}
+
+func (tu *TrackingUsecase) trackingInfo(pkg *domain.Package) *domain.TrackingInfo {
+	info := &domain.TrackingInfo{
+		OrderRef:    pkg.OrderRef,
+		Status:      pkg.Status,
+		Description: trackingDescriptions[pkg.Status],
+		Timeline:    []domain.TrackingEvent{{Status: domain.StatusWaiting, Description: "Your parcel arrived at the pickup point", At: pkg.CreatedAt}},
+	}
+	for _, step := range []struct {
+		status domain.PackageStatus
+		at     *time.Time
+	}{
+		{domain.StatusPicked, pkg.PickedUpAt},
+		{domain.StatusHandedOver, pkg.HandedOverAt},
+		{domain.StatusExpired, pkg.ExpiredAt},
+		{domain.StatusReturnPending, pkg.ReturnPendingAt},
+		{domain.StatusReturned, pkg.ReturnedAt},
+		{domain.StatusDisposed, pkg.DisposedAt},
+	} {
+		if step.at != nil {
+			info.Timeline = append(info.Timeline, domain.TrackingEvent{
+				Status:      step.status,
+				Description: trackingDescriptions[step.status],
+				At:          *step.at,
+			})
+		}
+	}
+	sort.SliceStable(info.Timeline, func(i, j int) bool {
+		return info.Timeline[i].At.Before(info.Timeline[j].At)
+	})
+
+	if pkg.Status == domain.StatusWaiting || pkg.Status == domain.StatusPicked {
+		collectBy := pkg.CreatedAt.Add(tu.packages.ExpiryWindowFor(pkg.Priority))
+		info.CollectBy = &collectBy
+		location := tu.location
+		info.PickupLocation = &location
+	}
+
+	return info
+}
+
+// tokenMatches compares token with the last digits of phone in constant time
+func tokenMatches(phone, token string) bool {
+	digits := phoneDigits(phone)
+	token = strings.TrimSpace(token)
+	if len(digits) < trackingTokenDigits || len(token) != trackingTokenDigits {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(digits[len(digits)-trackingTokenDigits:]), []byte(token)) == 1
+}
+
+// phoneDigits strips everything but digits from a phone number
+func phoneDigits(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (tu *TrackingUsecase) locked(orderRef string, now time.Time) bool {
+	tu.mu.Lock()
+	defer tu.mu.Unlock()
+
+	f, ok := tu.failures[orderRef]
+	return ok && now.Before(f.lockedUntil)
+}
+
+func (tu *TrackingUsecase) recordFailure(orderRef string, now time.Time) {
+	tu.mu.Lock()
+	defer tu.mu.Unlock()
+
+	tu.sweep(now)
+
+	f, ok := tu.failures[orderRef]
+	if !ok || now.Sub(f.last) >= tu.lockout {
+		// Failures older than the lockout period are forgotten
+		f = &trackingFailures{}
+		tu.failures[orderRef] = f
+	}
+	f.count++
+	f.last = now
+	if f.count >= tu.maxFailures {
+		f.count = 0
+		f.lockedUntil = now.Add(tu.lockout)
+	}
+}
+
+func (tu *TrackingUsecase) clearFailures(orderRef string) {
+	tu.mu.Lock()
+	defer tu.mu.Unlock()
+
+	delete(tu.failures, orderRef)
+}
+
+// sweep drops stale counters, at most once a minute
+func (tu *TrackingUsecase) sweep(now time.Time) {
+	if now.Sub(tu.lastSweep) < time.Minute {
+		return
+	}
+	tu.lastSweep = now
+
+	for orderRef, f := range tu.failures {
+		if now.Sub(f.last) >= tu.lockout && !now.Before(f.lockedUntil) {
+			delete(tu.failures, orderRef)
+		}
+	}
+}
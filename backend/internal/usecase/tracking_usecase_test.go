@@ -0,0 +1,135 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"pickup-queue/internal/domain"
+	"pickup-queue/internal/repository"
+	"pickup-queue/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTracking(t *testing.T) (*usecase.TrackingUsecase, *usecase.PackageUsecase) {
+	repo := repository.NewMemoryPackageRepository()
+	packages := usecase.NewPackageUsecase(repo)
+	location := domain.PickupLocation{Name: "Central Depot", Hours: []string{"Mon-Fri 08:00-20:00"}}
+	return usecase.NewTrackingUsecase(packages, location, 3, time.Minute), packages
+}
+
+func TestTrackingUsecase_Track_HappyPath(t *testing.T) {
+	// Setup
+	tracking, packages := setupTracking(t)
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "+48 600 100 234"})
+	require.NoError(t, err)
+	_, err = packages.UpdatePackageStatus(pkg.ID, domain.StatusPicked)
+	require.NoError(t, err)
+
+	// Execute
+	info, err := tracking.Track("ORD-001", "0234")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-001", info.OrderRef)
+	assert.Equal(t, domain.StatusPicked, info.Status)
+	require.Len(t, info.Timeline, 2)
+	assert.Equal(t, domain.StatusWaiting, info.Timeline[0].Status)
+	assert.Equal(t, domain.StatusPicked, info.Timeline[1].Status)
+	require.NotNil(t, info.CollectBy)
+	assert.WithinDuration(t, pkg.CreatedAt.Add(usecase.DefaultExpiryWindow), *info.CollectBy, time.Second)
+	require.NotNil(t, info.PickupLocation)
+	assert.Equal(t, "Central Depot", info.PickupLocation.Name)
+}
+
+func TestTrackingUsecase_Track_EdgeCase_WrongTokenLooksLikeUnknownOrder(t *testing.T) {
+	// Setup
+	tracking, packages := setupTracking(t)
+	_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "600100234"})
+	require.NoError(t, err)
+	_, err = packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-002", DriverCode: "DRV-001"})
+	require.NoError(t, err)
+
+	// Execute
+	_, wrongToken := tracking.Track("ORD-001", "9999")
+	_, unknown := tracking.Track("ORD-404", "0234")
+	_, noPhone := tracking.Track("ORD-002", "0000")
+
+	// Assert
+	assert.ErrorIs(t, wrongToken, usecase.ErrTrackingNotFound)
+	assert.ErrorIs(t, unknown, usecase.ErrTrackingNotFound)
+	assert.ErrorIs(t, noPhone, usecase.ErrTrackingNotFound)
+}
+
+func TestTrackingUsecase_Track_EdgeCase_LocksAfterFailedAttempts(t *testing.T) {
+	// Setup
+	tracking, packages := setupTracking(t)
+	_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "600100234"})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = tracking.Track("ORD-001", "1111")
+		require.ErrorIs(t, err, usecase.ErrTrackingNotFound)
+	}
+
+	// Execute - even the right token is refused while locked
+	_, err = tracking.Track("ORD-001", "0234")
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrTrackingLocked)
+}
+
+func TestTrackingUsecase_Track_EdgeCase_CaseVariantsShareLockout(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryPackageRepository()
+	packages := usecase.NewPackageUsecase(repo).WithIdentifierRules(usecase.IdentifierRules{
+		OrderRef: usecase.FieldRule{UpperCase: true},
+	}, nil)
+	tracking := usecase.NewTrackingUsecase(packages, domain.PickupLocation{}, 3, time.Minute)
+	_, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "600100234"})
+	require.NoError(t, err)
+	for _, variant := range []string{"ord-001", "Ord-001", "oRD-001"} {
+		_, err = tracking.Track(variant, "1111")
+		require.ErrorIs(t, err, usecase.ErrTrackingNotFound)
+	}
+
+	// Execute
+	_, err = tracking.Track("ORD-001", "0234")
+
+	// Assert
+	assert.ErrorIs(t, err, usecase.ErrTrackingLocked)
+}
+
+func TestTrackingUsecase_Track_HappyPath_ExpiredHasNoPickupLocation(t *testing.T) {
+	// Setup
+	tracking, packages := setupTracking(t)
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "600100234"})
+	require.NoError(t, err)
+	_, err = packages.UpdatePackageStatus(pkg.ID, domain.StatusExpired)
+	require.NoError(t, err)
+
+	// Execute
+	info, err := tracking.Track("ORD-001", "0234")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Your parcel was not collected in time", info.Description)
+	assert.Nil(t, info.CollectBy)
+	assert.Nil(t, info.PickupLocation)
+}
+
+func TestPackageUsecase_CreatePackage_EdgeCase_InvalidRecipientPhone(t *testing.T) {
+	// Setup
+	_, packages := setupTracking(t)
+
+	// Execute
+	pkg, err := packages.CreatePackage(&domain.CreatePackageRequest{OrderRef: "ORD-001", DriverCode: "DRV-001", RecipientPhone: "12-3"})
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	var domainErr *domain.Error
+	if assert.ErrorAs(t, err, &domainErr) && assert.Len(t, domainErr.Fields, 1) {
+		assert.Equal(t, "recipient_phone", domainErr.Fields[0].Field)
+	}
+	assert.Nil(t, pkg)
+}
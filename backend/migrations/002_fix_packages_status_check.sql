@@ -0,0 +1,4 @@
+-- The application stores PICKED, not PICKED_UP; align the status constraint with domain.PackageStatus
+ALTER TABLE packages DROP CONSTRAINT IF EXISTS packages_status_check;
+ALTER TABLE packages ADD CONSTRAINT packages_status_check
+    CHECK (status IN ('WAITING', 'PICKED', 'HANDED_OVER', 'EXPIRED'));
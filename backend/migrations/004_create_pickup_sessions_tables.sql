@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS pickup_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    driver_code VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'CLOSED')),
+    opened_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    closed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- A driver can have only one open session at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pickup_sessions_open_driver ON pickup_sessions(driver_code) WHERE status = 'OPEN';
+
+CREATE TABLE IF NOT EXISTS pickup_session_items (
+    session_id UUID NOT NULL REFERENCES pickup_sessions(id) ON DELETE CASCADE,
+    order_ref VARCHAR(255) NOT NULL,
+    package_id UUID,
+    expected BOOLEAN NOT NULL DEFAULT FALSE,
+    result VARCHAR(20) CHECK (result IN ('PICKED', 'EXTRA', 'REJECTED')),
+    reason TEXT,
+    scanned_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (session_id, order_ref)
+);
+
+-- Check-in lists the WAITING packages of one driver
+CREATE INDEX IF NOT EXISTS idx_packages_driver_code_status ON packages(driver_code, status);
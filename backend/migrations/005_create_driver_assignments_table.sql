@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS driver_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    package_id UUID NOT NULL REFERENCES packages(id) ON DELETE CASCADE,
+    from_driver VARCHAR(255) NOT NULL,
+    to_driver VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_assignments_package_id ON driver_assignments(package_id, changed_at);
@@ -0,0 +1,4 @@
+-- Soft delete: deleted packages keep their row until the retention job purges them
+ALTER TABLE packages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_packages_deleted_at ON packages(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Terminal packages moved out of packages by the worker's archive job
+CREATE TABLE IF NOT EXISTS packages_archive (
+    id UUID PRIMARY KEY,
+    order_ref VARCHAR(255) UNIQUE NOT NULL,
+    driver_code VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    picked_up_at TIMESTAMP WITH TIME ZONE,
+    handed_over_at TIMESTAMP WITH TIME ZONE,
+    expired_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_packages_archive_archived_at ON packages_archive(archived_at);
+
+-- Driver history outlives the packages row while it sits in the archive
+ALTER TABLE driver_assignments DROP CONSTRAINT IF EXISTS driver_assignments_package_id_fkey;
+
+CREATE INDEX IF NOT EXISTS idx_packages_status_updated_at ON packages(status, updated_at) WHERE deleted_at IS NULL;
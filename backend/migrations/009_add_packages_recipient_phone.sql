@@ -0,0 +1,3 @@
+-- Recipient phone, used to verify the recipient on the public tracking endpoint
+ALTER TABLE packages ADD COLUMN IF NOT EXISTS recipient_phone VARCHAR(32);
+ALTER TABLE packages_archive ADD COLUMN IF NOT EXISTS recipient_phone VARCHAR(32);
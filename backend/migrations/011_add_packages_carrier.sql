@@ -0,0 +1,3 @@
+-- Carrier whose order reference and driver code rules a package was validated against
+ALTER TABLE packages ADD COLUMN IF NOT EXISTS carrier VARCHAR(64);
+ALTER TABLE packages_archive ADD COLUMN IF NOT EXISTS carrier VARCHAR(64);
@@ -0,0 +1,26 @@
+-- Optional physical attributes of a package; size_class is derived from the
+-- dimensions when the package is created
+ALTER TABLE packages
+    ADD COLUMN IF NOT EXISTS length_cm INTEGER CHECK (length_cm > 0),
+    ADD COLUMN IF NOT EXISTS width_cm INTEGER CHECK (width_cm > 0),
+    ADD COLUMN IF NOT EXISTS height_cm INTEGER CHECK (height_cm > 0),
+    ADD COLUMN IF NOT EXISTS weight_grams INTEGER CHECK (weight_grams > 0),
+    ADD COLUMN IF NOT EXISTS size_class VARCHAR(16) NOT NULL DEFAULT 'UNKNOWN',
+    ADD COLUMN IF NOT EXISTS declared_value BIGINT CHECK (declared_value >= 0),
+    ADD COLUMN IF NOT EXISTS fragile BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS temperature VARCHAR(16) NOT NULL DEFAULT 'AMBIENT'
+        CHECK (temperature IN ('AMBIENT', 'CHILLED', 'FROZEN')),
+    ADD COLUMN IF NOT EXISTS parcel_count INTEGER NOT NULL DEFAULT 1 CHECK (parcel_count > 0);
+
+ALTER TABLE packages_archive
+    ADD COLUMN IF NOT EXISTS length_cm INTEGER,
+    ADD COLUMN IF NOT EXISTS width_cm INTEGER,
+    ADD COLUMN IF NOT EXISTS height_cm INTEGER,
+    ADD COLUMN IF NOT EXISTS weight_grams INTEGER,
+    ADD COLUMN IF NOT EXISTS size_class VARCHAR(16) NOT NULL DEFAULT 'UNKNOWN',
+    ADD COLUMN IF NOT EXISTS declared_value BIGINT,
+    ADD COLUMN IF NOT EXISTS fragile BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS temperature VARCHAR(16) NOT NULL DEFAULT 'AMBIENT',
+    ADD COLUMN IF NOT EXISTS parcel_count INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_packages_size_class ON packages(size_class) WHERE deleted_at IS NULL;
@@ -0,0 +1,23 @@
+-- Shipments group the parcels of one order; each parcel is a package with
+-- its own parcel reference pointing back at the shipment
+CREATE TABLE IF NOT EXISTS shipments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_ref VARCHAR(255) NOT NULL UNIQUE,
+    driver_code VARCHAR(255) NOT NULL,
+    carrier VARCHAR(64),
+    parcel_count INTEGER NOT NULL CHECK (parcel_count > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_driver_code ON shipments(driver_code);
+CREATE INDEX IF NOT EXISTS idx_shipments_created_at ON shipments(created_at);
+
+ALTER TABLE packages
+    ADD COLUMN IF NOT EXISTS shipment_id UUID REFERENCES shipments(id),
+    ADD COLUMN IF NOT EXISTS parcel_number INTEGER;
+ALTER TABLE packages_archive
+    ADD COLUMN IF NOT EXISTS shipment_id UUID,
+    ADD COLUMN IF NOT EXISTS parcel_number INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_packages_shipment_id ON packages(shipment_id) WHERE shipment_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Shelf slot a package is stored in; printed on its label
+ALTER TABLE packages ADD COLUMN IF NOT EXISTS slot_location VARCHAR(32);
+ALTER TABLE packages_archive ADD COLUMN IF NOT EXISTS slot_location VARCHAR(32);
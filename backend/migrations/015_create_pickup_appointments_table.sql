@@ -0,0 +1,18 @@
+-- Pickup appointments book a driver into one of the site's daily pickup
+-- windows; the windows themselves and their capacity are configuration
+CREATE TABLE IF NOT EXISTS pickup_appointments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    driver_code VARCHAR(255) NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL CHECK (window_end > window_start),
+    status VARCHAR(20) NOT NULL DEFAULT 'BOOKED' CHECK (status IN ('BOOKED', 'CANCELLED')),
+    booked_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    cancelled_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_appointments_window_start ON pickup_appointments(window_start);
+
+-- A driver books a window at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pickup_appointments_driver_window
+    ON pickup_appointments(driver_code, window_start) WHERE status = 'BOOKED';
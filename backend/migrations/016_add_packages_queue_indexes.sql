@@ -0,0 +1,4 @@
+-- Queue positions rank live WAITING packages by arrival, and pickup pace is
+-- measured from the most recent picked_up_at times
+CREATE INDEX IF NOT EXISTS idx_packages_waiting_queue ON packages(created_at, id) WHERE status = 'WAITING' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_packages_picked_up_at ON packages(picked_up_at DESC) WHERE picked_up_at IS NOT NULL AND deleted_at IS NULL;
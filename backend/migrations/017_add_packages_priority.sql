@@ -0,0 +1,7 @@
+-- Service class of a package: orders lists and the pickup queue and sets the
+-- expiry window and pickup target
+ALTER TABLE packages ADD COLUMN IF NOT EXISTS priority VARCHAR(16) NOT NULL DEFAULT 'STANDARD'
+    CHECK (priority IN ('EXPRESS', 'STANDARD', 'ECONOMY'));
+ALTER TABLE packages_archive ADD COLUMN IF NOT EXISTS priority VARCHAR(16) NOT NULL DEFAULT 'STANDARD';
+
+CREATE INDEX IF NOT EXISTS idx_packages_priority ON packages(priority) WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+-- Packages still waiting past their priority's pickup target, recorded once
+-- by the worker. Rows outlive the package's archiving and purging.
+CREATE TABLE IF NOT EXISTS sla_breaches (
+    package_id UUID PRIMARY KEY,
+    order_ref VARCHAR(255) NOT NULL,
+    driver_code VARCHAR(255) NOT NULL,
+    priority VARCHAR(16) NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sla_breaches_detected_at ON sla_breaches(detected_at);
@@ -0,0 +1,18 @@
+-- Return-to-sender: an expired package stays on the shelf until the hold
+-- period passes, then waits for its carrier (RETURN_PENDING) and is either
+-- RETURNED or DISPOSED
+ALTER TABLE packages DROP CONSTRAINT IF EXISTS packages_status_check;
+ALTER TABLE packages ADD CONSTRAINT packages_status_check
+    CHECK (status IN ('WAITING', 'PICKED', 'HANDED_OVER', 'EXPIRED', 'RETURN_PENDING', 'RETURNED', 'DISPOSED'));
+
+ALTER TABLE packages
+    ADD COLUMN IF NOT EXISTS return_pending_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS returned_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS disposed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE packages_archive
+    ADD COLUMN IF NOT EXISTS return_pending_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS returned_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS disposed_at TIMESTAMP WITH TIME ZONE;
+
+-- The return job looks for packages expired before the hold period
+CREATE INDEX IF NOT EXISTS idx_packages_expired_at ON packages(expired_at) WHERE status = 'EXPIRED' AND deleted_at IS NULL;
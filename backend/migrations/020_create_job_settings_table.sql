@@ -0,0 +1,6 @@
+-- Job settings shared by every worker replica
+CREATE TABLE IF NOT EXISTS job_settings (
+    job VARCHAR(255) PRIMARY KEY,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,6 @@
+-- Reminders sent to the recipients of waiting packages, one row per package
+CREATE TABLE IF NOT EXISTS pickup_reminders (
+    package_id UUID PRIMARY KEY,
+    reminder_count INTEGER NOT NULL,
+    last_sent_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,16 @@
+-- Timestamps are stored as fixed-width UTC text (2006-01-02T15:04:05.000000000Z)
+-- so lexical order matches chronological order for ORDER BY and range filters.
+CREATE TABLE IF NOT EXISTS packages (
+    id TEXT PRIMARY KEY,
+    order_ref TEXT NOT NULL UNIQUE CHECK (length(order_ref) <= 255),
+    driver_code TEXT NOT NULL CHECK (length(driver_code) <= 255),
+    status TEXT NOT NULL DEFAULT 'WAITING' CHECK (status IN ('WAITING', 'PICKED', 'HANDED_OVER', 'EXPIRED')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    picked_up_at TEXT,
+    handed_over_at TEXT,
+    expired_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_packages_status ON packages(status);
+CREATE INDEX IF NOT EXISTS idx_packages_created_at ON packages(created_at);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS pickup_sessions (
+    id TEXT PRIMARY KEY,
+    driver_code TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'CLOSED')),
+    opened_at TEXT NOT NULL,
+    closed_at TEXT
+);
+
+-- A driver can have only one open session at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pickup_sessions_open_driver ON pickup_sessions(driver_code) WHERE status = 'OPEN';
+
+CREATE TABLE IF NOT EXISTS pickup_session_items (
+    session_id TEXT NOT NULL REFERENCES pickup_sessions(id) ON DELETE CASCADE,
+    order_ref TEXT NOT NULL,
+    package_id TEXT,
+    expected INTEGER NOT NULL DEFAULT 0,
+    result TEXT CHECK (result IN ('PICKED', 'EXTRA', 'REJECTED')),
+    reason TEXT,
+    scanned_at TEXT,
+    PRIMARY KEY (session_id, order_ref)
+);
+
+-- Check-in lists the WAITING packages of one driver
+CREATE INDEX IF NOT EXISTS idx_packages_driver_code_status ON packages(driver_code, status);
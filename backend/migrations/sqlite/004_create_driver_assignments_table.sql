@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS driver_assignments (
+    id TEXT PRIMARY KEY,
+    package_id TEXT NOT NULL REFERENCES packages(id) ON DELETE CASCADE,
+    from_driver TEXT NOT NULL,
+    to_driver TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    changed_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_assignments_package_id ON driver_assignments(package_id, changed_at);
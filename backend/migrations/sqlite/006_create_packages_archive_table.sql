@@ -0,0 +1,33 @@
+-- Terminal packages moved out of packages by the worker's archive job
+CREATE TABLE IF NOT EXISTS packages_archive (
+    id TEXT PRIMARY KEY,
+    order_ref TEXT NOT NULL UNIQUE,
+    driver_code TEXT NOT NULL,
+    status TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    picked_up_at TEXT,
+    handed_over_at TEXT,
+    expired_at TEXT,
+    archived_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_packages_archive_archived_at ON packages_archive(archived_at);
+
+-- Driver history outlives the packages row while it sits in the archive;
+-- SQLite cannot drop a foreign key, so the table is rebuilt without it
+CREATE TABLE driver_assignments_new (
+    id TEXT PRIMARY KEY,
+    package_id TEXT NOT NULL,
+    from_driver TEXT NOT NULL,
+    to_driver TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    changed_at TEXT NOT NULL
+);
+INSERT INTO driver_assignments_new SELECT id, package_id, from_driver, to_driver, reason, changed_by, changed_at FROM driver_assignments;
+DROP TABLE driver_assignments;
+ALTER TABLE driver_assignments_new RENAME TO driver_assignments;
+CREATE INDEX IF NOT EXISTS idx_driver_assignments_package_id ON driver_assignments(package_id, changed_at);
+
+CREATE INDEX IF NOT EXISTS idx_packages_status_updated_at ON packages(status, updated_at) WHERE deleted_at IS NULL;
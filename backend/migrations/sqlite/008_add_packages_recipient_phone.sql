@@ -0,0 +1,3 @@
+-- Recipient phone, used to verify the recipient on the public tracking endpoint
+ALTER TABLE packages ADD COLUMN recipient_phone TEXT;
+ALTER TABLE packages_archive ADD COLUMN recipient_phone TEXT;
@@ -0,0 +1,3 @@
+-- Carrier whose order reference and driver code rules a package was validated against
+ALTER TABLE packages ADD COLUMN carrier TEXT;
+ALTER TABLE packages_archive ADD COLUMN carrier TEXT;
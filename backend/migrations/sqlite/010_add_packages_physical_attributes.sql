@@ -0,0 +1,23 @@
+-- Optional physical attributes of a package; size_class is derived from the
+-- dimensions when the package is created
+ALTER TABLE packages ADD COLUMN length_cm INTEGER;
+ALTER TABLE packages ADD COLUMN width_cm INTEGER;
+ALTER TABLE packages ADD COLUMN height_cm INTEGER;
+ALTER TABLE packages ADD COLUMN weight_grams INTEGER;
+ALTER TABLE packages ADD COLUMN size_class TEXT NOT NULL DEFAULT 'UNKNOWN';
+ALTER TABLE packages ADD COLUMN declared_value INTEGER;
+ALTER TABLE packages ADD COLUMN fragile INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE packages ADD COLUMN temperature TEXT NOT NULL DEFAULT 'AMBIENT';
+ALTER TABLE packages ADD COLUMN parcel_count INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE packages_archive ADD COLUMN length_cm INTEGER;
+ALTER TABLE packages_archive ADD COLUMN width_cm INTEGER;
+ALTER TABLE packages_archive ADD COLUMN height_cm INTEGER;
+ALTER TABLE packages_archive ADD COLUMN weight_grams INTEGER;
+ALTER TABLE packages_archive ADD COLUMN size_class TEXT NOT NULL DEFAULT 'UNKNOWN';
+ALTER TABLE packages_archive ADD COLUMN declared_value INTEGER;
+ALTER TABLE packages_archive ADD COLUMN fragile INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE packages_archive ADD COLUMN temperature TEXT NOT NULL DEFAULT 'AMBIENT';
+ALTER TABLE packages_archive ADD COLUMN parcel_count INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_packages_size_class ON packages(size_class);
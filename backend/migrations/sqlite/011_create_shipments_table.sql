@@ -0,0 +1,21 @@
+-- Shipments group the parcels of one order; each parcel is a package with
+-- its own parcel reference pointing back at the shipment
+CREATE TABLE IF NOT EXISTS shipments (
+    id TEXT PRIMARY KEY,
+    order_ref TEXT NOT NULL UNIQUE CHECK (length(order_ref) <= 255),
+    driver_code TEXT NOT NULL CHECK (length(driver_code) <= 255),
+    carrier TEXT,
+    parcel_count INTEGER NOT NULL CHECK (parcel_count > 0),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_driver_code ON shipments(driver_code);
+CREATE INDEX IF NOT EXISTS idx_shipments_created_at ON shipments(created_at);
+
+ALTER TABLE packages ADD COLUMN shipment_id TEXT REFERENCES shipments(id);
+ALTER TABLE packages ADD COLUMN parcel_number INTEGER;
+ALTER TABLE packages_archive ADD COLUMN shipment_id TEXT;
+ALTER TABLE packages_archive ADD COLUMN parcel_number INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_packages_shipment_id ON packages(shipment_id);
@@ -0,0 +1,3 @@
+-- Shelf slot a package is stored in; printed on its label
+ALTER TABLE packages ADD COLUMN slot_location TEXT;
+ALTER TABLE packages_archive ADD COLUMN slot_location TEXT;
@@ -0,0 +1,18 @@
+-- Pickup appointments book a driver into one of the site's daily pickup
+-- windows; the windows themselves and their capacity are configuration
+CREATE TABLE IF NOT EXISTS pickup_appointments (
+    id TEXT PRIMARY KEY,
+    driver_code TEXT NOT NULL CHECK (length(driver_code) <= 255),
+    window_start TEXT NOT NULL,
+    window_end TEXT NOT NULL CHECK (window_end > window_start),
+    status TEXT NOT NULL DEFAULT 'BOOKED' CHECK (status IN ('BOOKED', 'CANCELLED')),
+    booked_by TEXT,
+    created_at TEXT NOT NULL,
+    cancelled_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pickup_appointments_window_start ON pickup_appointments(window_start);
+
+-- A driver books a window at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pickup_appointments_driver_window
+    ON pickup_appointments(driver_code, window_start) WHERE status = 'BOOKED';
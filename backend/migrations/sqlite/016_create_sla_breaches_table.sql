@@ -0,0 +1,12 @@
+-- Packages still waiting past their priority's pickup target, recorded once
+-- by the worker. Rows outlive the package's archiving and purging.
+CREATE TABLE IF NOT EXISTS sla_breaches (
+    package_id TEXT PRIMARY KEY,
+    order_ref TEXT NOT NULL,
+    driver_code TEXT NOT NULL,
+    priority TEXT NOT NULL,
+    due_at TEXT NOT NULL,
+    detected_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sla_breaches_detected_at ON sla_breaches(detected_at);
@@ -0,0 +1,63 @@
+-- Return-to-sender: an expired package stays on the shelf until the hold
+-- period passes, then waits for its carrier (RETURN_PENDING) and is either
+-- RETURNED or DISPOSED. SQLite cannot alter a CHECK constraint, so the
+-- packages table is rebuilt with the new statuses.
+CREATE TABLE packages_new (
+    id TEXT PRIMARY KEY,
+    order_ref TEXT NOT NULL UNIQUE CHECK (length(order_ref) <= 255),
+    driver_code TEXT NOT NULL CHECK (length(driver_code) <= 255),
+    status TEXT NOT NULL DEFAULT 'WAITING'
+        CHECK (status IN ('WAITING', 'PICKED', 'HANDED_OVER', 'EXPIRED', 'RETURN_PENDING', 'RETURNED', 'DISPOSED')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    picked_up_at TEXT,
+    handed_over_at TEXT,
+    expired_at TEXT,
+    deleted_at TEXT,
+    recipient_phone TEXT,
+    carrier TEXT,
+    length_cm INTEGER,
+    width_cm INTEGER,
+    height_cm INTEGER,
+    weight_grams INTEGER,
+    size_class TEXT NOT NULL DEFAULT 'UNKNOWN',
+    declared_value INTEGER,
+    fragile INTEGER NOT NULL DEFAULT 0,
+    temperature TEXT NOT NULL DEFAULT 'AMBIENT',
+    parcel_count INTEGER NOT NULL DEFAULT 1,
+    shipment_id TEXT REFERENCES shipments(id),
+    parcel_number INTEGER,
+    slot_location TEXT,
+    priority TEXT NOT NULL DEFAULT 'STANDARD' CHECK (priority IN ('EXPRESS', 'STANDARD', 'ECONOMY')),
+    return_pending_at TEXT,
+    returned_at TEXT,
+    disposed_at TEXT
+);
+INSERT INTO packages_new (id, order_ref, driver_code, status, created_at, updated_at, picked_up_at, handed_over_at,
+                          expired_at, deleted_at, recipient_phone, carrier, length_cm, width_cm, height_cm,
+                          weight_grams, size_class, declared_value, fragile, temperature, parcel_count,
+                          shipment_id, parcel_number, slot_location, priority)
+SELECT id, order_ref, driver_code, status, created_at, updated_at, picked_up_at, handed_over_at,
+       expired_at, deleted_at, recipient_phone, carrier, length_cm, width_cm, height_cm,
+       weight_grams, size_class, declared_value, fragile, temperature, parcel_count,
+       shipment_id, parcel_number, slot_location, priority
+FROM packages;
+DROP TABLE packages;
+ALTER TABLE packages_new RENAME TO packages;
+
+CREATE INDEX IF NOT EXISTS idx_packages_status ON packages(status);
+CREATE INDEX IF NOT EXISTS idx_packages_created_at ON packages(created_at);
+CREATE INDEX IF NOT EXISTS idx_packages_driver_code_status ON packages(driver_code, status);
+CREATE INDEX IF NOT EXISTS idx_packages_deleted_at ON packages(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_packages_status_updated_at ON packages(status, updated_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_packages_size_class ON packages(size_class);
+CREATE INDEX IF NOT EXISTS idx_packages_shipment_id ON packages(shipment_id);
+CREATE INDEX IF NOT EXISTS idx_packages_waiting_queue ON packages(created_at, id) WHERE status = 'WAITING' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_packages_picked_up_at ON packages(picked_up_at DESC) WHERE picked_up_at IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_packages_priority ON packages(priority);
+-- The return job looks for packages expired before the hold period
+CREATE INDEX IF NOT EXISTS idx_packages_expired_at ON packages(expired_at) WHERE status = 'EXPIRED' AND deleted_at IS NULL;
+
+ALTER TABLE packages_archive ADD COLUMN return_pending_at TEXT;
+ALTER TABLE packages_archive ADD COLUMN returned_at TEXT;
+ALTER TABLE packages_archive ADD COLUMN disposed_at TEXT;
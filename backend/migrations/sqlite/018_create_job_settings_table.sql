@@ -0,0 +1,6 @@
+-- Job settings shared by every worker on the host
+CREATE TABLE IF NOT EXISTS job_settings (
+    job TEXT PRIMARY KEY,
+    paused INTEGER NOT NULL DEFAULT 0 CHECK (paused IN (0, 1)),
+    updated_at TEXT NOT NULL
+);
@@ -0,0 +1,10 @@
+// Package sqlite embeds the SQLite schema migrations so single-box
+// deployments can apply them at startup without extra tooling.
+package sqlite
+
+import "embed"
+
+// FS holds the numbered *.sql migration files, applied in name order
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,167 @@
+package barcode_test
+
+import (
+	"strings"
+	"testing"
+
+	"pickup-queue/pkg/barcode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// widths turns modules back into alternating bar and space widths
+func widths(modules []bool) string {
+	var sb strings.Builder
+	run := 1
+	for i := 1; i <= len(modules); i++ {
+		if i < len(modules) && modules[i] == modules[i-1] {
+			run++
+			continue
+		}
+		sb.WriteByte(byte('0' + run))
+		run = 1
+	}
+	return sb.String()
+}
+
+func TestCode128_HappyPath_SwitchesToCodeC(t *testing.T) {
+	// Execute
+	modules, err := barcode.Code128("HI345678")
+
+	// Assert
+	require.NoError(t, err)
+	// Start B, H, I, Code C, 34, 56, 78, check symbol 68, stop
+	assert.Len(t, modules, 8*11+13)
+	assert.True(t, modules[0])
+	w := widths(modules)
+	assert.Equal(t, "211214", w[:6])
+	assert.Equal(t, "1412212331112", w[len(w)-13:])
+}
+
+func TestCode128_EdgeCase_InvalidInput(t *testing.T) {
+	// Execute
+	_, emptyErr := barcode.Code128("")
+	_, nonASCIIErr := barcode.Code128("ZAŻÓŁĆ")
+
+	// Assert
+	assert.ErrorIs(t, emptyErr, barcode.ErrEmpty)
+	assert.ErrorIs(t, nonASCIIErr, barcode.ErrUnencodable)
+}
+
+// readVersion1 reads the byte-mode payload of a version 1 symbol back out,
+// using the format bits to undo the mask
+func readVersion1(t *testing.T, q *barcode.QRCode) []byte {
+	t.Helper()
+	require.Equal(t, 21, q.Size)
+
+	format := 0
+	for i, xy := range [15][2]int{
+		{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8},
+		{7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8},
+	} {
+		if q.Dark(xy[0], xy[1]) {
+			format |= 1 << i
+		}
+	}
+	format ^= 0x5412
+	require.Equal(t, 0, format>>13, "error correction level M")
+	mask := format >> 10 & 7
+
+	isFunction := func(x, y int) bool {
+		return (x < 9 && y < 9) || (x >= 13 && y < 9) || (x < 9 && y >= 13) || x == 6 || y == 6
+	}
+	masked := func(x, y int) bool {
+		switch mask {
+		case 0:
+			return (x+y)%2 == 0
+		case 1:
+			return y%2 == 0
+		case 2:
+			return x%3 == 0
+		case 3:
+			return (x+y)%3 == 0
+		case 4:
+			return (x/3+y/2)%2 == 0
+		case 5:
+			return x*y%2+x*y%3 == 0
+		case 6:
+			return (x*y%2+x*y%3)%2 == 0
+		default:
+			return ((x+y)%2+x*y%3)%2 == 0
+		}
+	}
+
+	var bits []bool
+	for right := 20; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < 21; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = 20 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !isFunction(x, y) {
+					bits = append(bits, q.Dark(x, y) != masked(x, y))
+				}
+			}
+		}
+	}
+	read := func(from, n int) int {
+		v := 0
+		for _, b := range bits[from : from+n] {
+			v <<= 1
+			if b {
+				v |= 1
+			}
+		}
+		return v
+	}
+
+	require.Equal(t, 0b0100, read(0, 4), "byte mode")
+	out := make([]byte, read(4, 8))
+	for i := range out {
+		out[i] = byte(read(12+8*i, 8))
+	}
+	return out
+}
+
+func TestQR_HappyPath_RoundTrip(t *testing.T) {
+	// Execute
+	q, err := barcode.QR([]byte("ORD-42"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ORD-42"), readVersion1(t, q))
+	// Finder pattern corners and the dark module
+	assert.True(t, q.Dark(0, 0))
+	assert.True(t, q.Dark(20, 0))
+	assert.True(t, q.Dark(0, 20))
+	assert.False(t, q.Dark(7, 7))
+	assert.True(t, q.Dark(8, 13))
+}
+
+func TestQR_HappyPath_PicksVersionByLength(t *testing.T) {
+	// Execute
+	uuid, uuidErr := barcode.QR([]byte("0b8f5a52-3c1e-4a51-9d0f-6c1b2f3a4d5e"))
+	large, largeErr := barcode.QR([]byte(strings.Repeat("x", 200)))
+
+	// Assert
+	require.NoError(t, uuidErr)
+	assert.Equal(t, 29, uuid.Size)
+	require.NoError(t, largeErr)
+	assert.Equal(t, 57, large.Size)
+}
+
+func TestQR_EdgeCase_TooLong(t *testing.T) {
+	// Execute
+	_, err := barcode.QR([]byte(strings.Repeat("x", 300)))
+	_, emptyErr := barcode.QR(nil)
+
+	// Assert
+	assert.ErrorIs(t, err, barcode.ErrTooLong)
+	assert.ErrorIs(t, emptyErr, barcode.ErrEmpty)
+}
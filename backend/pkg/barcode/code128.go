@@ -0,0 +1,115 @@
+// Package barcode encodes Code 128 and QR Code symbols. It only computes
+// which modules are dark; drawing them is left to the caller.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrEmpty is returned when there is nothing to encode
+	ErrEmpty = errors.New("barcode: empty data")
+	// ErrUnencodable is returned when the data holds a byte outside the
+	// Code 128 character set, e.g. non-ASCII letters
+	ErrUnencodable = errors.New("barcode: code 128 cannot encode the data")
+)
+
+// code128Patterns holds the bar and space widths of every Code 128 symbol,
+// indexed by symbol value. Every symbol is 11 modules wide, the stop
+// pattern 13.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 encodes data, which must be printable ASCII, as a Code 128 symbol
+// and returns its modules from left to right, true for a bar. Runs of four
+// or more digits are packed in pairs with code set C; everything else uses
+// code set B. The quiet zone is not included.
+func Code128(data string) ([]bool, error) {
+	if data == "" {
+		return nil, ErrEmpty
+	}
+	for i := 0; i < len(data); i++ {
+		if data[i] < ' ' || data[i] > '~' {
+			return nil, fmt.Errorf("%w: byte %#x at position %d", ErrUnencodable, data[i], i)
+		}
+	}
+
+	var values []int
+	inC := false
+	if digitRun(data, 0) >= 4 {
+		values = append(values, code128StartC)
+		inC = true
+	} else {
+		values = append(values, code128StartB)
+	}
+
+	for i := 0; i < len(data); {
+		run := digitRun(data, i)
+		switch {
+		case inC && run >= 2:
+			values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+			i += 2
+		case inC:
+			values = append(values, code128CodeB)
+			inC = false
+		case run >= 4:
+			// An odd run starts with one digit in code set B
+			if run%2 == 1 {
+				values = append(values, int(data[i]-' '))
+				i++
+			}
+			values = append(values, code128CodeC)
+			inC = true
+		default:
+			values = append(values, int(data[i]-' '))
+			i++
+		}
+	}
+
+	checksum := values[0]
+	for i, v := range values[1:] {
+		checksum += (i + 1) * v
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var modules []bool
+	for _, v := range values {
+		bar := true
+		for _, w := range code128Patterns[v] {
+			for n := 0; n < int(w-'0'); n++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	return modules, nil
+}
+
+// digitRun counts the digits in data starting at i
+func digitRun(data string, i int) int {
+	n := 0
+	for i+n < len(data) && data[i+n] >= '0' && data[i+n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,472 @@
+package barcode
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrTooLong is returned when data does not fit the largest supported QR code
+var ErrTooLong = errors.New("barcode: data too long for a version 10 QR code")
+
+// QRCode is a QR Code symbol of Size×Size modules, without the quiet zone
+type QRCode struct {
+	Size    int
+	modules []bool
+}
+
+// Dark reports whether the module in column x of row y is dark
+func (q *QRCode) Dark(x, y int) bool {
+	return q.modules[y*q.Size+x]
+}
+
+// qrVersion describes the blocks of one QR version at error correction
+// level M
+type qrVersion struct {
+	ecPerBlock int
+	// blocks lists the data codewords of each block
+	blocks    []int
+	alignment []int
+	remainder int
+}
+
+var qrVersions = [...]qrVersion{
+	1:  {10, []int{16}, nil, 0},
+	2:  {16, []int{28}, []int{6, 18}, 7},
+	3:  {26, []int{44}, []int{6, 22}, 7},
+	4:  {18, []int{32, 32}, []int{6, 26}, 7},
+	5:  {24, []int{43, 43}, []int{6, 30}, 7},
+	6:  {16, []int{27, 27, 27, 27}, []int{6, 34}, 7},
+	7:  {18, []int{31, 31, 31, 31}, []int{6, 22, 38}, 0},
+	8:  {22, []int{38, 38, 39, 39}, []int{6, 24, 42}, 0},
+	9:  {22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}, 0},
+	10: {26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}, 0},
+}
+
+func (v qrVersion) dataCodewords() int {
+	n := 0
+	for _, b := range v.blocks {
+		n += b
+	}
+	return n
+}
+
+// QR encodes data in byte mode at error correction level M, using the
+// smallest version from 1 to 10 it fits in and the mask with the lowest
+// penalty.
+func QR(data []byte) (*QRCode, error) {
+	if len(data) == 0 {
+		return nil, ErrEmpty
+	}
+
+	version := 0
+	for v := 1; v < len(qrVersions); v++ {
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*qrVersions[v].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := qrCodewords(data, version)
+	best := math.MaxInt
+	var result *QRCode
+	for mask := 0; mask < 8; mask++ {
+		q := newQRMatrix(version)
+		q.drawFunctionPatterns()
+		q.drawCodewords(codewords)
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); penalty < best {
+			best = penalty
+			result = &QRCode{Size: q.size, modules: q.modules}
+		}
+	}
+	return result, nil
+}
+
+// qrCodewords builds the data codewords of data and interleaves them with
+// their error correction codewords
+func qrCodewords(data []byte, version int) []byte {
+	v := qrVersions[version]
+	capacity := v.dataCodewords()
+
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	if version >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	terminator := capacity*8 - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity*8; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	stream := bits.bytes()
+
+	generator := rsGenerator(v.ecPerBlock)
+	dataBlocks := make([][]byte, len(v.blocks))
+	ecBlocks := make([][]byte, len(v.blocks))
+	for i, n := range v.blocks {
+		dataBlocks[i], stream = stream[:n], stream[n:]
+		ecBlocks[i] = rsRemainder(dataBlocks[i], generator)
+	}
+
+	out := make([]byte, 0, capacity+v.ecPerBlock*len(v.blocks))
+	for i := 0; i < v.blocks[len(v.blocks)-1]; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < v.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+// gfExp and gfLog are the exponent and logarithm tables of GF(256) with the
+// QR polynomial x^8 + x^4 + x^3 + x^2 + 1
+var gfExp, gfLog = func() ([512]byte, [256]byte) {
+	var exp [512]byte
+	var log [256]byte
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// rsGenerator returns the coefficients of the Reed-Solomon generator
+// polynomial of the given degree, highest power first, without its leading 1
+func rsGenerator(degree int) []byte {
+	poly := []byte{1}
+	for i := 0; i < degree; i++ {
+		next := make([]byte, len(poly)+1)
+		for j, c := range poly {
+			next[j] ^= c
+			next[j+1] ^= gfMul(c, gfExp[i])
+		}
+		poly = next
+	}
+	return poly[1:]
+}
+
+// rsRemainder returns the error correction codewords of data
+func rsRemainder(data, generator []byte) []byte {
+	rem := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[len(rem)-1] = 0
+		for i, g := range generator {
+			rem[i] ^= gfMul(g, factor)
+		}
+	}
+	return rem
+}
+
+// qrMatrix is a symbol under construction
+type qrMatrix struct {
+	version    int
+	size       int
+	modules    []bool
+	isFunction []bool
+}
+
+func newQRMatrix(version int) *qrMatrix {
+	size := 17 + 4*version
+	return &qrMatrix{
+		version:    version,
+		size:       size,
+		modules:    make([]bool, size*size),
+		isFunction: make([]bool, size*size),
+	}
+}
+
+func (q *qrMatrix) setFunction(x, y int, dark bool) {
+	q.modules[y*q.size+x] = dark
+	q.isFunction[y*q.size+x] = true
+}
+
+func (q *qrMatrix) drawFunctionPatterns() {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinder(3, 3)
+	q.drawFinder(q.size-4, 3)
+	q.drawFinder(3, q.size-4)
+
+	positions := qrVersions[q.version].alignment
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas; the real bits are drawn once the mask is known
+	q.drawFormatBits(0)
+
+	if q.version >= 7 {
+		rem := q.version
+		for i := 0; i < 12; i++ {
+			rem = rem<<1 ^ (rem>>11)*0x1F25
+		}
+		bits := q.version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := bits>>i&1 == 1
+			a, b := q.size-11+i%3, i/3
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern centred on x, y together with its
+// separator
+func (q *qrMatrix) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			q.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// formatBits returns the 15 format bits for level M and mask
+func formatBits(mask int) int {
+	data := 0b00<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (q *qrMatrix) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// drawCodewords places the codewords in the zigzag order of the standard,
+// skipping function modules
+func (q *qrMatrix) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			y := vert
+			if upward {
+				y = q.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if q.isFunction[y*q.size+x] || i >= len(codewords)*8 {
+					continue
+				}
+				q.modules[y*q.size+x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (q *qrMatrix) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.isFunction[y*q.size+x] {
+				q.modules[y*q.size+x] = !q.modules[y*q.size+x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol with the four rules of the standard; the mask
+// with the lowest score is used
+func (q *qrMatrix) penalty() int {
+	dark := func(x, y int) bool { return q.modules[y*q.size+x] }
+	score := 0
+
+	for _, transposed := range []bool{false, true} {
+		for a := 0; a < q.size; a++ {
+			line := make([]bool, q.size)
+			for b := 0; b < q.size; b++ {
+				if transposed {
+					line[b] = dark(a, b)
+				} else {
+					line[b] = dark(b, a)
+				}
+			}
+
+			run := 1
+			for b := 1; b <= q.size; b++ {
+				if b < q.size && line[b] == line[b-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+
+			for b := 0; b+11 <= q.size; b++ {
+				if matchesFinderLike(line[b : b+11]) {
+					score += 40
+				}
+			}
+		}
+	}
+
+	for y := 0; y+1 < q.size; y++ {
+		for x := 0; x+1 < q.size; x++ {
+			c := dark(x, y)
+			if dark(x+1, y) == c && dark(x, y+1) == c && dark(x+1, y+1) == c {
+				score += 3
+			}
+		}
+	}
+
+	darkCount := 0
+	for _, m := range q.modules {
+		if m {
+			darkCount++
+		}
+	}
+	percent := darkCount * 100 / len(q.modules)
+	score += abs(percent-50) / 5 * 10
+	return score
+}
+
+// matchesFinderLike reports whether 11 modules are 1:1:3:1:1 with four
+// light modules on either side
+func matchesFinderLike(m []bool) bool {
+	core := [7]bool{true, false, true, true, true, false, true}
+	matches := func(offset int) bool {
+		for i, c := range core {
+			if m[offset+i] != c {
+				return false
+			}
+		}
+		return true
+	}
+	light := func(from int) bool {
+		for i := from; i < from+4; i++ {
+			if m[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return (matches(0) && light(7)) || (light(0) && matches(4))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
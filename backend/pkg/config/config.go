@@ -0,0 +1,1189 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pickup-queue/pkg/database"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting used by cmd/api and cmd/worker.
+// Values are layered in this order, later layers winning:
+// defaults, config file (YAML or TOML), environment variables, CLI flags.
+type Config struct {
+	Server     ServerConfig     `yaml:"server" toml:"server"`
+	Database   DatabaseConfig   `yaml:"database" toml:"database"`
+	Worker     WorkerConfig     `yaml:"worker" toml:"worker"`
+	Log        LogConfig        `yaml:"log" toml:"log"`
+	Auth       AuthConfig       `yaml:"auth" toml:"auth"`
+	Tracking   TrackingConfig   `yaml:"tracking" toml:"tracking"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit" toml:"rate_limit"`
+	Validation ValidationConfig `yaml:"validation" toml:"validation"`
+	Packages   PackagesConfig   `yaml:"packages" toml:"packages"`
+	// Appointments holds the pickup windows drivers can book
+	Appointments AppointmentsConfig `yaml:"appointments" toml:"appointments"`
+	// Queue tunes the queue positions and wait estimates
+	Queue QueueConfig `yaml:"queue" toml:"queue"`
+	// SLA holds the service level of each package priority
+	SLA SLAConfig `yaml:"sla" toml:"sla"`
+
+	// File is the config file the values were loaded from, if any
+	File string `yaml:"-" toml:"-"`
+}
+
+// ServerConfig holds HTTP server settings for cmd/api
+type ServerConfig struct {
+	Port              string   `yaml:"port" toml:"port"`
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	MaxHeaderBytes    int      `yaml:"max_header_bytes" toml:"max_header_bytes"`
+	MaxBodyBytes      int64    `yaml:"max_body_bytes" toml:"max_body_bytes"`
+	IdempotencyTTL    Duration `yaml:"idempotency_ttl" toml:"idempotency_ttl"`
+	// TrustedProxies are the IPs or CIDRs of the proxies whose
+	// X-Forwarded-For header names the client. By default none is trusted and
+	// clients are identified by the connection's address.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+// Storage backends selectable with database.storage / STORAGE / -storage
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+// DatabaseConfig holds the storage backend plus Postgres connection, TLS, pool
+// and startup retry settings
+type DatabaseConfig struct {
+	Storage     string `yaml:"storage" toml:"storage"`
+	SQLitePath  string `yaml:"sqlite_path" toml:"sqlite_path"`
+	URL         string `yaml:"url" toml:"url"`
+	Host        string `yaml:"host" toml:"host"`
+	Port        string `yaml:"port" toml:"port"`
+	User        string `yaml:"user" toml:"user"`
+	Password    string `yaml:"password" toml:"password"`
+	DBName      string `yaml:"dbname" toml:"dbname"`
+	SSLMode     string `yaml:"sslmode" toml:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert" toml:"sslrootcert"`
+	SSLCert     string `yaml:"sslcert" toml:"sslcert"`
+	SSLKey      string `yaml:"sslkey" toml:"sslkey"`
+
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+
+	ConnectRetries    int      `yaml:"connect_retries" toml:"connect_retries"`
+	ConnectBackoff    Duration `yaml:"connect_backoff" toml:"connect_backoff"`
+	ConnectMaxBackoff Duration `yaml:"connect_max_backoff" toml:"connect_max_backoff"`
+}
+
+// Connection converts the settings into the form expected by database.NewConnection
+func (d DatabaseConfig) Connection() *database.Config {
+	return &database.Config{
+		URL:               d.URL,
+		Host:              d.Host,
+		Port:              d.Port,
+		User:              d.User,
+		Password:          d.Password,
+		DBName:            d.DBName,
+		SSLMode:           d.SSLMode,
+		SSLRootCert:       d.SSLRootCert,
+		SSLCert:           d.SSLCert,
+		SSLKey:            d.SSLKey,
+		MaxOpenConns:      d.MaxOpenConns,
+		MaxIdleConns:      d.MaxIdleConns,
+		ConnMaxLifetime:   d.ConnMaxLifetime.Duration,
+		ConnMaxIdleTime:   d.ConnMaxIdleTime.Duration,
+		ConnectRetries:    d.ConnectRetries,
+		ConnectBackoff:    d.ConnectBackoff.Duration,
+		ConnectMaxBackoff: d.ConnectMaxBackoff.Duration,
+	}
+}
+
+// WorkerConfig holds background worker settings.
+// Interval, ExpiryWindow, RetentionPeriod, ArchiveAfter and ReturnHoldPeriod
+// can be changed without a restart.
+type WorkerConfig struct {
+	Interval     Duration `yaml:"interval" toml:"interval"`
+	ExpiryWindow Duration `yaml:"expiry_window" toml:"expiry_window"`
+	// RetentionPeriod is how long a deleted package is kept before the
+	// worker purges it for good
+	RetentionPeriod Duration `yaml:"retention_period" toml:"retention_period"`
+	// ArchiveAfter is how long a HANDED_OVER, RETURNED or DISPOSED package
+	// stays in the packages table before the worker moves it to the archive
+	ArchiveAfter Duration `yaml:"archive_after" toml:"archive_after"`
+	// ReturnHoldPeriod is how long an EXPIRED package is held for its
+	// recipient before the worker marks it RETURN_PENDING
+	ReturnHoldPeriod Duration `yaml:"return_hold_period" toml:"return_hold_period"`
+	// JobTimeout bounds every job run unless the job sets its own timeout
+	JobTimeout Duration `yaml:"job_timeout" toml:"job_timeout"`
+	// Jobs overrides the built-in schedule or timeout of a job, by job name
+	Jobs map[string]JobConfig `yaml:"jobs" toml:"jobs"`
+	// AdminAddr is where the worker serves its admin API; empty disables it.
+	// Changing it requires a restart.
+	AdminAddr string `yaml:"admin_addr" toml:"admin_addr"`
+}
+
+// JobConfig overrides how one worker job is scheduled. Schedule is a cron
+// expression ("*/15 * * * *"), a descriptor such as @daily, or "@every 1h".
+type JobConfig struct {
+	Schedule string   `yaml:"schedule" toml:"schedule"`
+	Timeout  Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// LogConfig holds logging settings. Level can be changed without a restart.
+type LogConfig struct {
+	Level string `yaml:"level" toml:"level"`
+}
+
+// TrackingConfig holds settings of the public tracking endpoint and the
+// pickup point details it shows to recipients
+type TrackingConfig struct {
+	LocationName string   `yaml:"location_name" toml:"location_name"`
+	Address      string   `yaml:"address" toml:"address"`
+	Hours        []string `yaml:"hours" toml:"hours"`
+	// RateLimit is how many tracking requests one client IP may make per minute
+	RateLimit int `yaml:"rate_limit" toml:"rate_limit"`
+	// MaxFailedAttempts wrong verification tokens lock an order reference
+	// for LockoutPeriod
+	MaxFailedAttempts int      `yaml:"max_failed_attempts" toml:"max_failed_attempts"`
+	LockoutPeriod     Duration `yaml:"lockout_period" toml:"lockout_period"`
+}
+
+// PackagesConfig holds site rules for the packages themselves
+type PackagesConfig struct {
+	// HighValueThreshold is the declared value, in minor units of the site
+	// currency, from which packages need the recipient's phone on create and
+	// its last 4 digits on handover. 0 disables the checks.
+	HighValueThreshold int64 `yaml:"high_value_threshold" toml:"high_value_threshold"`
+	// LabelDPI is the resolution of the site's label printers; PNG and ZPL
+	// labels are laid out in its dots
+	LabelDPI int `yaml:"label_dpi" toml:"label_dpi"`
+}
+
+// What happens when a driver checks in outside their booked pickup window
+const (
+	EnforcementOff     = "off"
+	EnforcementWarn    = "warn"
+	EnforcementEnforce = "enforce"
+)
+
+// AppointmentsConfig holds the site's daily pickup windows. Each window is
+// "HH:MM-HH:MM" in Timezone, optionally followed by "=capacity"; without
+// windows drivers cannot book appointments.
+type AppointmentsConfig struct {
+	Windows []string `yaml:"windows" toml:"windows"`
+	// Capacity is how many drivers may book a window that sets no capacity
+	// of its own
+	Capacity int `yaml:"capacity" toml:"capacity"`
+	// Enforcement is off, warn (check-in succeeds with a warning) or enforce
+	// (check-in is refused) for drivers without an appointment covering the
+	// time they check in
+	Enforcement string `yaml:"enforcement" toml:"enforcement"`
+	// Grace is how early or late a driver may check in around their window
+	Grace Duration `yaml:"grace" toml:"grace"`
+	// Timezone is the IANA zone the windows are in; empty for the server's
+	Timezone string `yaml:"timezone" toml:"timezone"`
+}
+
+// QueueConfig tunes how wait times are estimated from the counter's recent
+// pickups
+type QueueConfig struct {
+	// SampleSize is how many of the latest pickups the pace is measured over
+	SampleSize int `yaml:"sample_size" toml:"sample_size"`
+	// DefaultPace is the time per package assumed until enough pickups have
+	// been recorded
+	DefaultPace Duration `yaml:"default_pace" toml:"default_pace"`
+	// IdleGap is the longest gap between two pickups still counted as
+	// service time; longer gaps are the counter standing idle
+	IdleGap Duration `yaml:"idle_gap" toml:"idle_gap"`
+}
+
+// SLAConfig holds the service level promised to each package priority
+type SLAConfig struct {
+	Express  ServiceLevelConfig `yaml:"express" toml:"express"`
+	Standard ServiceLevelConfig `yaml:"standard" toml:"standard"`
+	Economy  ServiceLevelConfig `yaml:"economy" toml:"economy"`
+}
+
+// ServiceLevelConfig is the service level of one priority
+type ServiceLevelConfig struct {
+	// ExpiryWindow is how long packages of the priority may stay active;
+	// 0 uses worker.expiry_window
+	ExpiryWindow Duration `yaml:"expiry_window" toml:"expiry_window"`
+	// PickupTarget is how soon packages of the priority should be picked up;
+	// ones still waiting after it are recorded as SLA breaches
+	PickupTarget Duration `yaml:"pickup_target" toml:"pickup_target"`
+	// ReminderInterval is how often recipients of waiting packages of the
+	// priority are reminded, the first time that long after arrival; 0
+	// sends no reminders
+	ReminderInterval Duration `yaml:"reminder_interval" toml:"reminder_interval"`
+}
+
+// PickupWindow is a parsed daily pickup window; Start and End are offsets
+// from local midnight
+type PickupWindow struct {
+	Start    time.Duration
+	End      time.Duration
+	Capacity int
+}
+
+// PickupWindows parses Windows, giving windows without a capacity of their
+// own the default Capacity
+func (a AppointmentsConfig) PickupWindows() ([]PickupWindow, error) {
+	windows := make([]PickupWindow, 0, len(a.Windows))
+	for _, entry := range a.Windows {
+		span, capacity, hasCapacity := strings.Cut(strings.TrimSpace(entry), "=")
+		from, to, ok := strings.Cut(span, "-")
+		if !ok {
+			return nil, fmt.Errorf("pickup window %q must look like HH:MM-HH:MM[=capacity]", entry)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, fmt.Errorf("pickup window %q: %w", entry, err)
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return nil, fmt.Errorf("pickup window %q: %w", entry, err)
+		}
+		window := PickupWindow{Start: start, End: end, Capacity: a.Capacity}
+		if hasCapacity {
+			if window.Capacity, err = strconv.Atoi(strings.TrimSpace(capacity)); err != nil {
+				return nil, fmt.Errorf("pickup window %q: %w", entry, err)
+			}
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// Location loads Timezone
+func (a AppointmentsConfig) Location() (*time.Location, error) {
+	if a.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(a.Timezone)
+}
+
+func (a AppointmentsConfig) validate() []error {
+	var errs []error
+	if a.Capacity <= 0 {
+		errs = append(errs, errors.New("appointments.capacity must be positive"))
+	}
+	switch a.Enforcement {
+	case EnforcementOff, EnforcementWarn, EnforcementEnforce:
+	default:
+		errs = append(errs, fmt.Errorf("appointments.enforcement must be one of off, warn or enforce, got %q", a.Enforcement))
+	}
+	if a.Grace.Duration < 0 {
+		errs = append(errs, errors.New("appointments.grace must not be negative"))
+	}
+	if _, err := a.Location(); err != nil {
+		errs = append(errs, fmt.Errorf("appointments.timezone: %w", err))
+	}
+
+	windows, err := a.PickupWindows()
+	if err != nil {
+		return append(errs, fmt.Errorf("appointments.windows: %w", err))
+	}
+	for i, w := range windows {
+		if w.End <= w.Start {
+			errs = append(errs, fmt.Errorf("appointments.windows: %q must end after it starts", a.Windows[i]))
+		}
+		if w.Capacity <= 0 {
+			errs = append(errs, fmt.Errorf("appointments.windows: %q must have a positive capacity", a.Windows[i]))
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start < windows[j].Start })
+	for i := 1; i < len(windows); i++ {
+		if windows[i].Start < windows[i-1].End {
+			return append(errs, errors.New("appointments.windows must not overlap"))
+		}
+	}
+	return errs
+}
+
+// parseClock reads an HH:MM time of day; 24:00 is the end of the day
+func parseClock(value string) (time.Duration, error) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(value), ":")
+	h, hErr := strconv.Atoi(hours)
+	m, mErr := strconv.Atoi(minutes)
+	if !ok || hErr != nil || mErr != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Rate limiter backends selectable with rate_limit.backend / RATE_LIMIT_BACKEND
+const (
+	RateLimitMemory   = "memory"
+	RateLimitPostgres = "postgres"
+)
+
+// What a route rate limit counts requests by
+const (
+	RateLimitKeyClient = "client"
+	RateLimitKeyDriver = "driver"
+	RateLimitKeyIP     = "ip"
+)
+
+// RateLimitConfig holds the API rate limits. Every limit is in requests per
+// minute and allows bursts of the same size.
+type RateLimitConfig struct {
+	// Backend is memory, where each API replica counts on its own, or
+	// postgres, where replicas share their counts
+	Backend string `yaml:"backend" toml:"backend"`
+	// Default limits each client across the whole API; 0 disables it
+	Default int `yaml:"default" toml:"default"`
+	// Routes adds stricter limits to single routes, on top of Default
+	Routes []RouteRateLimit `yaml:"routes" toml:"routes"`
+}
+
+// RouteRateLimit limits one route. Route is the method and the route pattern,
+// such as "POST /api/v1/pickup-sessions/:id/scans". Key is client (the API
+// key, or the IP without one; the default), driver (the driver code of the
+// request) or ip.
+type RouteRateLimit struct {
+	Route string `yaml:"route" toml:"route"`
+	Limit int    `yaml:"limit" toml:"limit"`
+	Key   string `yaml:"key" toml:"key"`
+}
+
+// Checksums an identifier rule can require. luhn checks the last digit of
+// the identifier's digits; s10 checks UPU S10 parcel numbers such as
+// "RR123456785PL".
+const (
+	ChecksumLuhn = "luhn"
+	ChecksumS10  = "s10"
+)
+
+// MaxIdentifierLength is the size of the order_ref and driver_code columns
+const MaxIdentifierLength = 255
+
+// ValidationConfig holds this site's rules for order references and driver
+// codes. Packages created for a carrier are checked against that carrier's
+// rules, all others against Default.
+type ValidationConfig struct {
+	Default  IdentifierRules `yaml:"default" toml:"default"`
+	Carriers []CarrierRules  `yaml:"carriers" toml:"carriers"`
+}
+
+// IdentifierRules holds the rules for both identifiers of a package
+type IdentifierRules struct {
+	OrderRef   FieldRule `yaml:"order_reference" toml:"order_reference"`
+	DriverCode FieldRule `yaml:"driver_code" toml:"driver_code"`
+}
+
+// CarrierRules are the identifier rules of one carrier
+type CarrierRules struct {
+	Carrier    string    `yaml:"carrier" toml:"carrier"`
+	OrderRef   FieldRule `yaml:"order_reference" toml:"order_reference"`
+	DriverCode FieldRule `yaml:"driver_code" toml:"driver_code"`
+}
+
+// FieldRule checks one identifier. Values are always trimmed; UpperCase also
+// upper-cases them before they are checked and stored. Pattern is a regular
+// expression the whole value must match. A MaxLength of 0 means
+// MaxIdentifierLength.
+type FieldRule struct {
+	Pattern   string `yaml:"pattern" toml:"pattern"`
+	MinLength int    `yaml:"min_length" toml:"min_length"`
+	MaxLength int    `yaml:"max_length" toml:"max_length"`
+	Checksum  string `yaml:"checksum" toml:"checksum"`
+	UpperCase bool   `yaml:"upper_case" toml:"upper_case"`
+}
+
+// Roles an API key can act with, from least to most privileged
+const (
+	RoleClerk      = "clerk"
+	RoleSupervisor = "supervisor"
+	RoleAdmin      = "admin"
+)
+
+// AuthConfig holds the API keys accepted by cmd/api. Requests without a key
+// can still use the unguarded endpoints; role-guarded endpoints need a key.
+type AuthConfig struct {
+	APIKeys []APIKey `yaml:"api_keys" toml:"api_keys"`
+}
+
+// APIKey identifies a caller, for history records, and the role it acts with
+type APIKey struct {
+	Name string `yaml:"name" toml:"name"`
+	Role string `yaml:"role" toml:"role"`
+	Key  string `yaml:"key" toml:"key"`
+}
+
+// Duration is a time.Duration that reads from strings such as "15s" in config files
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+const redacted = "******"
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warning": true, "error": true}
+
+var validRoles = map[string]bool{RoleClerk: true, RoleSupervisor: true, RoleAdmin: true}
+
+// Default returns the built-in configuration used when nothing else is set
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			ReadTimeout:       Duration{15 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			WriteTimeout:      Duration{15 * time.Second},
+			IdleTimeout:       Duration{60 * time.Second},
+			ShutdownTimeout:   Duration{30 * time.Second},
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      1 << 20,
+			IdempotencyTTL:    Duration{24 * time.Hour},
+		},
+		Database: DatabaseConfig{
+			Storage:           StoragePostgres,
+			SQLitePath:        "pickup_queue.db",
+			Host:              "localhost",
+			Port:              "5432",
+			User:              "postgres",
+			DBName:            "pickup_queue",
+			SSLMode:           "disable",
+			MaxOpenConns:      25,
+			MaxIdleConns:      10,
+			ConnMaxLifetime:   Duration{30 * time.Minute},
+			ConnMaxIdleTime:   Duration{5 * time.Minute},
+			ConnectRetries:    10,
+			ConnectBackoff:    Duration{500 * time.Millisecond},
+			ConnectMaxBackoff: Duration{15 * time.Second},
+		},
+		Worker: WorkerConfig{
+			Interval:         Duration{time.Hour},
+			ExpiryWindow:     Duration{24 * time.Hour},
+			RetentionPeriod:  Duration{30 * 24 * time.Hour},
+			ArchiveAfter:     Duration{90 * 24 * time.Hour},
+			ReturnHoldPeriod: Duration{7 * 24 * time.Hour},
+			JobTimeout:       Duration{10 * time.Minute},
+			AdminAddr:        ":8081",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Tracking: TrackingConfig{
+			LocationName:      "Pickup point",
+			RateLimit:         30,
+			MaxFailedAttempts: 5,
+			LockoutPeriod:     Duration{15 * time.Minute},
+		},
+		Packages: PackagesConfig{
+			LabelDPI: 203,
+		},
+		Appointments: AppointmentsConfig{
+			Capacity:    5,
+			Enforcement: EnforcementWarn,
+			Grace:       Duration{15 * time.Minute},
+		},
+		Queue: QueueConfig{
+			SampleSize:  50,
+			DefaultPace: Duration{2 * time.Minute},
+			IdleGap:     Duration{30 * time.Minute},
+		},
+		SLA: SLAConfig{
+			Express:  ServiceLevelConfig{ExpiryWindow: Duration{12 * time.Hour}, PickupTarget: Duration{2 * time.Hour}, ReminderInterval: Duration{4 * time.Hour}},
+			Standard: ServiceLevelConfig{PickupTarget: Duration{8 * time.Hour}, ReminderInterval: Duration{24 * time.Hour}},
+			Economy:  ServiceLevelConfig{ExpiryWindow: Duration{72 * time.Hour}, PickupTarget: Duration{24 * time.Hour}, ReminderInterval: Duration{48 * time.Hour}},
+		},
+		RateLimit: RateLimitConfig{
+			Backend: RateLimitMemory,
+			Default: 600,
+			Routes: []RouteRateLimit{
+				{Route: "POST /api/v1/packages", Limit: 120, Key: RateLimitKeyClient},
+			},
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the config file, environment
+// variables and the given command line arguments, then validates it
+func Load(args []string) (*Config, error) {
+	fs, flags := newFlagSet()
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	path := flags.file
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+		cfg.File = path
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	flags.apply(fs, cfg)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file format: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	var errs []error
+
+	setString(&cfg.Server.Port, "PORT")
+	setList(&cfg.Server.TrustedProxies, "TRUSTED_PROXIES")
+	errs = append(errs,
+		setDuration(&cfg.Server.ReadTimeout, "HTTP_READ_TIMEOUT"),
+		setDuration(&cfg.Server.ReadHeaderTimeout, "HTTP_READ_HEADER_TIMEOUT"),
+		setDuration(&cfg.Server.WriteTimeout, "HTTP_WRITE_TIMEOUT"),
+		setDuration(&cfg.Server.IdleTimeout, "HTTP_IDLE_TIMEOUT"),
+		setDuration(&cfg.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT"),
+		setInt(&cfg.Server.MaxHeaderBytes, "MAX_HEADER_BYTES"),
+		setInt64(&cfg.Server.MaxBodyBytes, "MAX_BODY_BYTES"),
+		setDuration(&cfg.Server.IdempotencyTTL, "IDEMPOTENCY_TTL"),
+	)
+
+	setString(&cfg.Database.Storage, "STORAGE")
+	setString(&cfg.Database.SQLitePath, "SQLITE_PATH")
+	setString(&cfg.Database.Host, "DB_HOST")
+	setString(&cfg.Database.Port, "DB_PORT")
+	setString(&cfg.Database.User, "DB_USER")
+	setString(&cfg.Database.Password, "DB_PASSWORD")
+	setString(&cfg.Database.DBName, "DB_NAME")
+	setString(&cfg.Database.SSLMode, "DB_SSL_MODE")
+	setString(&cfg.Database.URL, "DATABASE_URL")
+	setString(&cfg.Database.SSLRootCert, "DB_SSL_ROOT_CERT")
+	setString(&cfg.Database.SSLCert, "DB_SSL_CERT")
+	setString(&cfg.Database.SSLKey, "DB_SSL_KEY")
+	errs = append(errs,
+		setInt(&cfg.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS"),
+		setInt(&cfg.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS"),
+		setDuration(&cfg.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"),
+		setDuration(&cfg.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"),
+		setInt(&cfg.Database.ConnectRetries, "DB_CONNECT_RETRIES"),
+		setDuration(&cfg.Database.ConnectBackoff, "DB_CONNECT_BACKOFF"),
+		setDuration(&cfg.Database.ConnectMaxBackoff, "DB_CONNECT_MAX_BACKOFF"),
+	)
+
+	errs = append(errs,
+		setDuration(&cfg.Worker.Interval, "WORKER_INTERVAL"),
+		setDuration(&cfg.Worker.ExpiryWindow, "PACKAGE_EXPIRY_WINDOW"),
+		setDuration(&cfg.Worker.RetentionPeriod, "PACKAGE_RETENTION_PERIOD"),
+		setDuration(&cfg.Worker.ArchiveAfter, "PACKAGE_ARCHIVE_AFTER"),
+		setDuration(&cfg.Worker.ReturnHoldPeriod, "PACKAGE_RETURN_HOLD_PERIOD"),
+		setDuration(&cfg.Worker.JobTimeout, "WORKER_JOB_TIMEOUT"),
+		setJobSchedules(&cfg.Worker.Jobs, "JOB_SCHEDULES"),
+	)
+
+	// An empty WORKER_ADMIN_ADDR disables the admin API, so it is honoured even when empty
+	if value, ok := os.LookupEnv("WORKER_ADMIN_ADDR"); ok {
+		cfg.Worker.AdminAddr = value
+	}
+	setString(&cfg.Log.Level, "LOG_LEVEL")
+
+	errs = append(errs, setAPIKeys(&cfg.Auth.APIKeys, "API_KEYS"))
+
+	setString(&cfg.Tracking.LocationName, "PICKUP_LOCATION_NAME")
+	setString(&cfg.Tracking.Address, "PICKUP_LOCATION_ADDRESS")
+	setList(&cfg.Tracking.Hours, "PICKUP_HOURS")
+	errs = append(errs,
+		setInt(&cfg.Tracking.RateLimit, "TRACKING_RATE_LIMIT"),
+		setInt(&cfg.Tracking.MaxFailedAttempts, "TRACKING_MAX_FAILED_ATTEMPTS"),
+		setDuration(&cfg.Tracking.LockoutPeriod, "TRACKING_LOCKOUT"),
+	)
+
+	setString(&cfg.RateLimit.Backend, "RATE_LIMIT_BACKEND")
+	errs = append(errs,
+		setInt(&cfg.RateLimit.Default, "RATE_LIMIT_DEFAULT"),
+		setRouteRateLimits(&cfg.RateLimit.Routes, "RATE_LIMIT_ROUTES"),
+	)
+
+	errs = append(errs,
+		setFieldRule(&cfg.Validation.Default.OrderRef, "ORDER_REF"),
+		setFieldRule(&cfg.Validation.Default.DriverCode, "DRIVER_CODE"),
+	)
+
+	errs = append(errs,
+		setInt64(&cfg.Packages.HighValueThreshold, "HIGH_VALUE_THRESHOLD"),
+		setInt(&cfg.Packages.LabelDPI, "LABEL_DPI"),
+	)
+
+	setList(&cfg.Appointments.Windows, "PICKUP_WINDOWS")
+	setString(&cfg.Appointments.Enforcement, "PICKUP_WINDOW_ENFORCEMENT")
+	setString(&cfg.Appointments.Timezone, "PICKUP_TIMEZONE")
+	errs = append(errs,
+		setInt(&cfg.Appointments.Capacity, "PICKUP_WINDOW_CAPACITY"),
+		setDuration(&cfg.Appointments.Grace, "PICKUP_WINDOW_GRACE"),
+	)
+
+	errs = append(errs,
+		setInt(&cfg.Queue.SampleSize, "QUEUE_SAMPLE_SIZE"),
+		setDuration(&cfg.Queue.DefaultPace, "QUEUE_DEFAULT_PACE"),
+		setDuration(&cfg.Queue.IdleGap, "QUEUE_IDLE_GAP"),
+	)
+
+	errs = append(errs,
+		setDuration(&cfg.SLA.Express.ExpiryWindow, "SLA_EXPRESS_EXPIRY_WINDOW"),
+		setDuration(&cfg.SLA.Express.PickupTarget, "SLA_EXPRESS_PICKUP_TARGET"),
+		setDuration(&cfg.SLA.Standard.ExpiryWindow, "SLA_STANDARD_EXPIRY_WINDOW"),
+		setDuration(&cfg.SLA.Standard.PickupTarget, "SLA_STANDARD_PICKUP_TARGET"),
+		setDuration(&cfg.SLA.Economy.ExpiryWindow, "SLA_ECONOMY_EXPIRY_WINDOW"),
+		setDuration(&cfg.SLA.Economy.PickupTarget, "SLA_ECONOMY_PICKUP_TARGET"),
+		setDuration(&cfg.SLA.Express.ReminderInterval, "SLA_EXPRESS_REMINDER_INTERVAL"),
+		setDuration(&cfg.SLA.Standard.ReminderInterval, "SLA_STANDARD_REMINDER_INTERVAL"),
+		setDuration(&cfg.SLA.Economy.ReminderInterval, "SLA_ECONOMY_REMINDER_INTERVAL"),
+	)
+
+	return errors.Join(errs...)
+}
+
+// setAPIKeys parses a comma separated list of name:role:key entries
+func setAPIKeys(dst *[]APIKey, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var keys []APIKey
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 {
+			return fmt.Errorf("%s: entries must look like name:role:key", key)
+		}
+		keys = append(keys, APIKey{Name: parts[0], Role: parts[1], Key: parts[2]})
+	}
+	*dst = keys
+	return nil
+}
+
+// setJobSchedules parses a semicolon separated list of name=schedule entries;
+// semicolons because cron expressions use commas
+func setJobSchedules(dst *map[string]JobConfig, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	jobs := make(map[string]JobConfig, len(*dst))
+	for name, job := range *dst {
+		jobs[name] = job
+	}
+	for _, entry := range strings.Split(value, ";") {
+		name, schedule, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%s: entries must look like name=schedule", key)
+		}
+		job := jobs[strings.TrimSpace(name)]
+		job.Schedule = strings.TrimSpace(schedule)
+		jobs[strings.TrimSpace(name)] = job
+	}
+	*dst = jobs
+	return nil
+}
+
+// setRouteRateLimits parses a semicolon separated list of
+// "METHOD /path=limit" entries, each optionally followed by ":key"
+func setRouteRateLimits(dst *[]RouteRateLimit, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var routes []RouteRateLimit
+	for _, entry := range strings.Split(value, ";") {
+		route, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("%s: entries must look like METHOD /path=limit[:key]", key)
+		}
+		limit, by, _ := strings.Cut(limit, ":")
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if by = strings.TrimSpace(by); by == "" {
+			by = RateLimitKeyClient
+		}
+		routes = append(routes, RouteRateLimit{Route: strings.TrimSpace(route), Limit: n, Key: by})
+	}
+	*dst = routes
+	return nil
+}
+
+// setFieldRule reads a default identifier rule from the variables named
+// prefix_PATTERN, prefix_MIN_LENGTH, prefix_MAX_LENGTH, prefix_CHECKSUM and
+// prefix_UPPERCASE
+func setFieldRule(dst *FieldRule, prefix string) error {
+	setString(&dst.Pattern, prefix+"_PATTERN")
+	setString(&dst.Checksum, prefix+"_CHECKSUM")
+	return errors.Join(
+		setInt(&dst.MinLength, prefix+"_MIN_LENGTH"),
+		setInt(&dst.MaxLength, prefix+"_MAX_LENGTH"),
+		setBool(&dst.UpperCase, prefix+"_UPPERCASE"),
+	)
+}
+
+// setList parses a semicolon separated list, so entries may contain commas
+func setList(dst *[]string, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+func setString(dst *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*dst = value
+	}
+}
+
+func setDuration(dst *Duration, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	if err := dst.UnmarshalText([]byte(value)); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+func setBool(dst *bool, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = b
+	return nil
+}
+
+func setInt(dst *int, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func setInt64(dst *int64, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+// flagValues holds the raw CLI flags; only flags that were set override the config
+type flagValues struct {
+	file         string
+	port         string
+	storage      string
+	sqlitePath   string
+	databaseURL  string
+	dbHost       string
+	dbPort       string
+	dbUser       string
+	dbName       string
+	dbSSLMode    string
+	interval     time.Duration
+	expiryWindow time.Duration
+	retention    time.Duration
+	archiveAfter time.Duration
+	adminAddr    string
+	logLevel     string
+}
+
+func newFlagSet() (*flag.FlagSet, *flagValues) {
+	fv := &flagValues{}
+	fs := flag.NewFlagSet("pickup-queue", flag.ContinueOnError)
+	fs.StringVar(&fv.file, "config", "", "path to a YAML or TOML config file")
+	fs.StringVar(&fv.port, "port", "", "HTTP port to listen on")
+	fs.StringVar(&fv.storage, "storage", "", "storage backend (postgres, sqlite, memory)")
+	fs.StringVar(&fv.sqlitePath, "sqlite-path", "", "SQLite database file when -storage=sqlite")
+	fs.StringVar(&fv.databaseURL, "database-url", "", "full database connection URL")
+	fs.StringVar(&fv.dbHost, "db-host", "", "database host")
+	fs.StringVar(&fv.dbPort, "db-port", "", "database port")
+	fs.StringVar(&fv.dbUser, "db-user", "", "database user")
+	fs.StringVar(&fv.dbName, "db-name", "", "database name")
+	fs.StringVar(&fv.dbSSLMode, "db-ssl-mode", "", "database SSL mode")
+	fs.DurationVar(&fv.interval, "worker-interval", 0, "how often the worker checks for expired packages")
+	fs.DurationVar(&fv.expiryWindow, "expiry-window", 0, "how long a package may wait before it expires")
+	fs.DurationVar(&fv.retention, "retention-period", 0, "how long deleted packages are kept before they are purged")
+	fs.DurationVar(&fv.archiveAfter, "archive-after", 0, "how long handed-over and expired packages stay before they are archived")
+	fs.StringVar(&fv.adminAddr, "worker-admin-addr", "", "address of the worker admin API, empty to disable")
+	fs.StringVar(&fv.logLevel, "log-level", "", "log level (debug, info, warning, error)")
+	return fs, fv
+}
+
+func (fv *flagValues) apply(fs *flag.FlagSet, cfg *Config) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = fv.port
+		case "storage":
+			cfg.Database.Storage = fv.storage
+		case "sqlite-path":
+			cfg.Database.SQLitePath = fv.sqlitePath
+		case "database-url":
+			cfg.Database.URL = fv.databaseURL
+		case "db-host":
+			cfg.Database.Host = fv.dbHost
+		case "db-port":
+			cfg.Database.Port = fv.dbPort
+		case "db-user":
+			cfg.Database.User = fv.dbUser
+		case "db-name":
+			cfg.Database.DBName = fv.dbName
+		case "db-ssl-mode":
+			cfg.Database.SSLMode = fv.dbSSLMode
+		case "worker-interval":
+			cfg.Worker.Interval = Duration{fv.interval}
+		case "expiry-window":
+			cfg.Worker.ExpiryWindow = Duration{fv.expiryWindow}
+		case "retention-period":
+			cfg.Worker.RetentionPeriod = Duration{fv.retention}
+		case "archive-after":
+			cfg.Worker.ArchiveAfter = Duration{fv.archiveAfter}
+		case "worker-admin-addr":
+			cfg.Worker.AdminAddr = fv.adminAddr
+		case "log-level":
+			cfg.Log.Level = fv.logLevel
+		}
+	})
+}
+
+// Validate checks that the configuration is usable
+func (c *Config) Validate() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %q", c.Server.Port))
+	}
+	for name, d := range map[string]Duration{
+		"server.read_timeout":        c.Server.ReadTimeout,
+		"server.read_header_timeout": c.Server.ReadHeaderTimeout,
+		"server.write_timeout":       c.Server.WriteTimeout,
+		"server.idle_timeout":        c.Server.IdleTimeout,
+		"server.shutdown_timeout":    c.Server.ShutdownTimeout,
+		"server.idempotency_ttl":     c.Server.IdempotencyTTL,
+		"worker.interval":            c.Worker.Interval,
+		"worker.expiry_window":       c.Worker.ExpiryWindow,
+		"worker.retention_period":    c.Worker.RetentionPeriod,
+		"worker.archive_after":       c.Worker.ArchiveAfter,
+		"worker.return_hold_period":  c.Worker.ReturnHoldPeriod,
+		"worker.job_timeout":         c.Worker.JobTimeout,
+		"tracking.lockout_period":    c.Tracking.LockoutPeriod,
+		"queue.default_pace":         c.Queue.DefaultPace,
+		"queue.idle_gap":             c.Queue.IdleGap,
+		"sla.express.pickup_target":  c.SLA.Express.PickupTarget,
+		"sla.standard.pickup_target": c.SLA.Standard.PickupTarget,
+		"sla.economy.pickup_target":  c.SLA.Economy.PickupTarget,
+	} {
+		if d.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", name))
+		}
+	}
+	for name, job := range c.Worker.Jobs {
+		if job.Timeout.Duration < 0 {
+			errs = append(errs, fmt.Errorf("worker.jobs.%s.timeout must not be negative", name))
+		}
+	}
+	for i, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("server.trusted_proxies[%d] must be an IP or CIDR, got %q", i, proxy))
+			}
+		}
+	}
+	if c.Worker.AdminAddr != "" {
+		if _, _, err := net.SplitHostPort(c.Worker.AdminAddr); err != nil {
+			errs = append(errs, fmt.Errorf("worker.admin_addr must be host:port, got %q", c.Worker.AdminAddr))
+		}
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		errs = append(errs, errors.New("server.max_header_bytes must be positive"))
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		errs = append(errs, errors.New("server.max_body_bytes must be positive"))
+	}
+	if c.Tracking.RateLimit <= 0 {
+		errs = append(errs, errors.New("tracking.rate_limit must be positive"))
+	}
+	if c.Tracking.MaxFailedAttempts <= 0 {
+		errs = append(errs, errors.New("tracking.max_failed_attempts must be positive"))
+	}
+	if c.Packages.HighValueThreshold < 0 {
+		errs = append(errs, errors.New("packages.high_value_threshold must not be negative"))
+	}
+	switch c.Packages.LabelDPI {
+	case 152, 203, 300, 600:
+	default:
+		errs = append(errs, fmt.Errorf("packages.label_dpi must be one of 152, 203, 300 or 600, got %d", c.Packages.LabelDPI))
+	}
+	errs = append(errs, c.Appointments.validate()...)
+	if c.Queue.SampleSize < 2 {
+		errs = append(errs, fmt.Errorf("queue.sample_size must be at least 2, got %d", c.Queue.SampleSize))
+	}
+	for name, d := range map[string]Duration{
+		"sla.express.expiry_window":      c.SLA.Express.ExpiryWindow,
+		"sla.standard.expiry_window":     c.SLA.Standard.ExpiryWindow,
+		"sla.economy.expiry_window":      c.SLA.Economy.ExpiryWindow,
+		"sla.express.reminder_interval":  c.SLA.Express.ReminderInterval,
+		"sla.standard.reminder_interval": c.SLA.Standard.ReminderInterval,
+		"sla.economy.reminder_interval":  c.SLA.Economy.ReminderInterval,
+	} {
+		if d.Duration < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", name))
+		}
+	}
+
+	switch c.Database.Storage {
+	case StoragePostgres:
+		errs = append(errs, c.Database.validatePostgres()...)
+	case StorageSQLite:
+		if c.Database.SQLitePath == "" {
+			errs = append(errs, errors.New("database.sqlite_path is required for sqlite storage"))
+		}
+	case StorageMemory:
+		// Nothing to connect to
+	default:
+		errs = append(errs, fmt.Errorf("database.storage must be postgres, sqlite or memory, got %q", c.Database.Storage))
+	}
+
+	if !validLogLevels[strings.ToLower(c.Log.Level)] {
+		errs = append(errs, fmt.Errorf("log.level must be one of debug, info, warning, error, got %q", c.Log.Level))
+	}
+
+	errs = append(errs, c.Auth.validate()...)
+	errs = append(errs, c.RateLimit.validate(c.Database.Storage)...)
+	errs = append(errs, c.Validation.validate()...)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (d DatabaseConfig) validatePostgres() []error {
+	var errs []error
+	if d.URL != "" {
+		if u, err := url.Parse(d.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			errs = append(errs, errors.New("database.url must be a postgres:// or postgresql:// URL"))
+		}
+	} else {
+		if d.Host == "" {
+			errs = append(errs, errors.New("database.host is required"))
+		}
+		if d.User == "" {
+			errs = append(errs, errors.New("database.user is required"))
+		}
+		if d.DBName == "" {
+			errs = append(errs, errors.New("database.dbname is required"))
+		}
+	}
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database connection limits must not be negative"))
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns must not exceed database.max_open_conns"))
+	}
+	if d.ConnectRetries < 1 {
+		errs = append(errs, errors.New("database.connect_retries must be at least 1"))
+	}
+	return errs
+}
+
+func (a AuthConfig) validate() []error {
+	var errs []error
+	seen := make(map[string]bool, len(a.APIKeys))
+	for i, k := range a.APIKeys {
+		if k.Name == "" || k.Key == "" {
+			errs = append(errs, fmt.Errorf("auth.api_keys[%d] needs a name and a key", i))
+		}
+		if !validRoles[k.Role] {
+			errs = append(errs, fmt.Errorf("auth.api_keys[%d].role must be one of clerk, supervisor, admin, got %q", i, k.Role))
+		}
+		if seen[k.Key] {
+			errs = append(errs, fmt.Errorf("auth.api_keys[%d] reuses another entry's key", i))
+		}
+		seen[k.Key] = true
+	}
+	return errs
+}
+
+var validRateLimitKeys = map[string]bool{RateLimitKeyClient: true, RateLimitKeyDriver: true, RateLimitKeyIP: true}
+
+func (r RateLimitConfig) validate(storage string) []error {
+	var errs []error
+	switch r.Backend {
+	case RateLimitMemory:
+	case RateLimitPostgres:
+		if storage != StoragePostgres {
+			errs = append(errs, errors.New("rate_limit.backend postgres requires postgres storage"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("rate_limit.backend must be memory or postgres, got %q", r.Backend))
+	}
+	if r.Default < 0 {
+		errs = append(errs, errors.New("rate_limit.default must not be negative"))
+	}
+	for i, route := range r.Routes {
+		method, path, ok := strings.Cut(route.Route, " ")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf("rate_limit.routes[%d].route must look like \"POST /api/v1/packages\", got %q", i, route.Route))
+		}
+		if route.Limit <= 0 {
+			errs = append(errs, fmt.Errorf("rate_limit.routes[%d].limit must be positive", i))
+		}
+		if route.Key != "" && !validRateLimitKeys[route.Key] {
+			errs = append(errs, fmt.Errorf("rate_limit.routes[%d].key must be one of client, driver, ip, got %q", i, route.Key))
+		}
+	}
+	return errs
+}
+
+func (v ValidationConfig) validate() []error {
+	errs := v.Default.OrderRef.validate("validation.default.order_reference")
+	errs = append(errs, v.Default.DriverCode.validate("validation.default.driver_code")...)
+
+	seen := make(map[string]bool, len(v.Carriers))
+	for i, carrier := range v.Carriers {
+		name := fmt.Sprintf("validation.carriers[%d]", i)
+		if carrier.Carrier == "" {
+			errs = append(errs, fmt.Errorf("%s.carrier is required", name))
+		}
+		if seen[carrier.Carrier] {
+			errs = append(errs, fmt.Errorf("%s repeats carrier %q", name, carrier.Carrier))
+		}
+		seen[carrier.Carrier] = true
+		errs = append(errs, carrier.OrderRef.validate(name+".order_reference")...)
+		errs = append(errs, carrier.DriverCode.validate(name+".driver_code")...)
+	}
+	return errs
+}
+
+func (f FieldRule) validate(name string) []error {
+	var errs []error
+	if _, err := regexp.Compile(f.Pattern); err != nil {
+		errs = append(errs, fmt.Errorf("%s.pattern: %w", name, err))
+	}
+	if f.MinLength < 0 || f.MaxLength < 0 {
+		errs = append(errs, fmt.Errorf("%s lengths must not be negative", name))
+	}
+	if f.MaxLength > MaxIdentifierLength {
+		errs = append(errs, fmt.Errorf("%s.max_length must not exceed %d", name, MaxIdentifierLength))
+	}
+	if f.MaxLength > 0 && f.MinLength > f.MaxLength {
+		errs = append(errs, fmt.Errorf("%s.min_length must not exceed max_length", name))
+	}
+	if f.Checksum != "" && f.Checksum != ChecksumLuhn && f.Checksum != ChecksumS10 {
+		errs = append(errs, fmt.Errorf("%s.checksum must be luhn or s10, got %q", name, f.Checksum))
+	}
+	return errs
+}
+
+// Redacted returns a copy of the configuration with secrets masked
+func (c *Config) Redacted() *Config {
+	out := *c
+	out.Auth.APIKeys = make([]APIKey, len(c.Auth.APIKeys))
+	for i, k := range c.Auth.APIKeys {
+		k.Key = redacted
+		out.Auth.APIKeys[i] = k
+	}
+	if out.Database.Password != "" {
+		out.Database.Password = redacted
+	}
+	if u, err := url.Parse(out.Database.URL); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			out.Database.URL = u.String()
+		}
+	}
+	return &out
+}
+
+// String renders the configuration as YAML with secrets masked, so it is safe to log
+func (c *Config) String() string {
+	data, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("<config: %v>", err)
+	}
+	return string(data)
+}
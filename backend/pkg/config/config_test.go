@@ -0,0 +1,484 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pickup-queue/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_HappyPath_Defaults(t *testing.T) {
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "8080", cfg.Server.Port)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Empty(t, cfg.Database.Password)
+	assert.Equal(t, 24*time.Hour, cfg.Worker.ExpiryWindow.Duration)
+	assert.Equal(t, 30*24*time.Hour, cfg.Worker.RetentionPeriod.Duration)
+	assert.Equal(t, 90*24*time.Hour, cfg.Worker.ArchiveAfter.Duration)
+	assert.Equal(t, 7*24*time.Hour, cfg.Worker.ReturnHoldPeriod.Duration)
+	assert.Equal(t, ":8081", cfg.Worker.AdminAddr)
+}
+
+func TestLoad_HappyPath_Precedence(t *testing.T) {
+	// Setup - file < env < flags
+	path := writeFile(t, "config.yaml", `
+server:
+  port: "9000"
+database:
+  host: file-host
+  user: file-user
+worker:
+  expiry_window: 12h
+log:
+  level: warning
+`)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("LOG_LEVEL", "error")
+
+	// Execute
+	cfg, err := config.Load([]string{"-config", path, "-log-level", "debug"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "9000", cfg.Server.Port)
+	assert.Equal(t, "env-host", cfg.Database.Host)
+	assert.Equal(t, "file-user", cfg.Database.User)
+	assert.Equal(t, 12*time.Hour, cfg.Worker.ExpiryWindow.Duration)
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, path, cfg.File)
+}
+
+func TestLoad_HappyPath_TOML(t *testing.T) {
+	// Setup
+	path := writeFile(t, "config.toml", `
+[worker]
+interval = "15m"
+`)
+
+	// Execute
+	cfg, err := config.Load([]string{"-config", path})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, cfg.Worker.Interval.Duration)
+}
+
+func TestLoad_EdgeCase_InvalidValues(t *testing.T) {
+	// Setup
+	t.Setenv("PORT", "not-a-port")
+	t.Setenv("LOG_LEVEL", "verbose")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	assert.Nil(t, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.port")
+	assert.Contains(t, err.Error(), "log.level")
+}
+
+func TestConfig_String_RedactsSecrets(t *testing.T) {
+	// Setup
+	cfg := config.Default()
+	cfg.Database.Password = "super-secret"
+
+	// Execute
+	out := cfg.String()
+
+	// Assert
+	assert.NotContains(t, out, "super-secret")
+	assert.Contains(t, out, "******")
+	assert.Equal(t, "super-secret", cfg.Database.Password)
+}
+
+func TestWatcher_Reload_AppliesOnlyReloadableSettings(t *testing.T) {
+	// Setup
+	path := writeFile(t, "config.yaml", "server:\n  port: \"9000\"\nworker:\n  expiry_window: 12h\n")
+	args := []string{"-config", path}
+	cfg, err := config.Load(args)
+	require.NoError(t, err)
+
+	watcher := config.NewWatcher(args, cfg)
+	var reloaded *config.Config
+	watcher.OnReload(func(c *config.Config) { reloaded = c })
+
+	require.NoError(t, os.WriteFile(path, []byte("server:\n  port: \"9100\"\nworker:\n  expiry_window: 6h\n"), 0o600))
+
+	// Execute
+	err = watcher.Reload()
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, reloaded)
+	assert.Equal(t, 6*time.Hour, reloaded.Worker.ExpiryWindow.Duration)
+	assert.Equal(t, "9000", reloaded.Server.Port)
+	assert.Equal(t, reloaded, watcher.Current())
+}
+
+func TestWatcher_Reload_AppliesServiceLevels(t *testing.T) {
+	// Setup
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+
+	watcher := config.NewWatcher(nil, cfg)
+	var reloaded *config.Config
+	watcher.OnReload(func(c *config.Config) { reloaded = c })
+
+	t.Setenv("SLA_EXPRESS_PICKUP_TARGET", "90m")
+
+	// Execute
+	err = watcher.Reload()
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, reloaded)
+	assert.Equal(t, 90*time.Minute, reloaded.SLA.Express.PickupTarget.Duration)
+	assert.Equal(t, 90*time.Minute, watcher.Current().SLA.Express.PickupTarget.Duration)
+}
+
+func TestConfig_String_RedactsDatabaseURL(t *testing.T) {
+	// Setup
+	cfg := config.Default()
+	cfg.Database.URL = "postgres://app:super-secret@db:5432/pickup_queue"
+
+	// Execute
+	out := cfg.String()
+
+	// Assert
+	assert.NotContains(t, out, "super-secret")
+	assert.Contains(t, out, "postgres://app:")
+}
+
+func TestLoad_HappyPath_APIKeysFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("API_KEYS", "alice:supervisor:key-1, ops:admin:key-2")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []config.APIKey{
+		{Name: "alice", Role: config.RoleSupervisor, Key: "key-1"},
+		{Name: "ops", Role: config.RoleAdmin, Key: "key-2"},
+	}, cfg.Auth.APIKeys)
+	assert.NotContains(t, cfg.String(), "key-1")
+	assert.Equal(t, "key-1", cfg.Auth.APIKeys[0].Key)
+}
+
+func TestLoad_EdgeCase_InvalidAPIKeyRole(t *testing.T) {
+	// Setup
+	t.Setenv("API_KEYS", "alice:owner:key-1")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.api_keys[0].role")
+}
+
+func TestLoad_HappyPath_JobSchedules(t *testing.T) {
+	// Setup - the file sets a timeout, the environment overrides the schedule
+	path := writeFile(t, "config.yaml", `
+worker:
+  jobs:
+    archive-packages:
+      schedule: "0 3 * * *"
+      timeout: 30m
+`)
+	t.Setenv("JOB_SCHEDULES", "archive-packages=0 4 * * 1,3; expire-packages=@every 5m")
+
+	// Execute
+	cfg, err := config.Load([]string{"-config", path})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, config.JobConfig{Schedule: "0 4 * * 1,3", Timeout: config.Duration{Duration: 30 * time.Minute}}, cfg.Worker.Jobs["archive-packages"])
+	assert.Equal(t, "@every 5m", cfg.Worker.Jobs["expire-packages"].Schedule)
+	assert.Equal(t, 10*time.Minute, cfg.Worker.JobTimeout.Duration)
+}
+
+func TestLoad_HappyPath_WorkerAdminAddr(t *testing.T) {
+	// Setup - an empty variable disables the admin API
+	t.Setenv("WORKER_ADMIN_ADDR", "")
+
+	// Execute
+	disabled, err := config.Load(nil)
+	require.NoError(t, err)
+	custom, err := config.Load([]string{"-worker-admin-addr", "127.0.0.1:9090"})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "", disabled.Worker.AdminAddr)
+	assert.Equal(t, "127.0.0.1:9090", custom.Worker.AdminAddr)
+}
+
+func TestLoad_EdgeCase_InvalidWorkerAdminAddr(t *testing.T) {
+	// Setup
+	t.Setenv("WORKER_ADMIN_ADDR", "8081")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "worker.admin_addr")
+}
+
+func TestLoad_HappyPath_TrackingFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("PICKUP_LOCATION_NAME", "Central Depot")
+	t.Setenv("PICKUP_HOURS", "Mon-Fri 08:00-20:00; Sat 09:00-14:00")
+	t.Setenv("TRACKING_RATE_LIMIT", "10")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Central Depot", cfg.Tracking.LocationName)
+	assert.Equal(t, []string{"Mon-Fri 08:00-20:00", "Sat 09:00-14:00"}, cfg.Tracking.Hours)
+	assert.Equal(t, 10, cfg.Tracking.RateLimit)
+	assert.Equal(t, 5, cfg.Tracking.MaxFailedAttempts)
+	assert.Equal(t, 15*time.Minute, cfg.Tracking.LockoutPeriod.Duration)
+}
+
+func TestLoad_HappyPath_TrustedProxiesFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8; 192.0.2.10")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.10"}, cfg.Server.TrustedProxies)
+}
+
+func TestLoad_EdgeCase_InvalidTrustedProxy(t *testing.T) {
+	// Setup
+	t.Setenv("TRUSTED_PROXIES", "load-balancer")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.trusted_proxies[0]")
+}
+
+func TestLoad_EdgeCase_InvalidTrackingRateLimit(t *testing.T) {
+	// Setup
+	t.Setenv("TRACKING_RATE_LIMIT", "0")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tracking.rate_limit")
+}
+
+func TestLoad_HappyPath_RateLimitRoutesFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("RATE_LIMIT_DEFAULT", "0")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /api/v1/packages=60; POST /api/v1/pickup-sessions/:id/scans=120:driver")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, config.RateLimitMemory, cfg.RateLimit.Backend)
+	assert.Equal(t, 0, cfg.RateLimit.Default)
+	assert.Equal(t, []config.RouteRateLimit{
+		{Route: "POST /api/v1/packages", Limit: 60, Key: config.RateLimitKeyClient},
+		{Route: "POST /api/v1/pickup-sessions/:id/scans", Limit: 120, Key: config.RateLimitKeyDriver},
+	}, cfg.RateLimit.Routes)
+}
+
+func TestLoad_EdgeCase_InvalidRateLimit(t *testing.T) {
+	// Setup - the shared limiter needs Postgres storage
+	t.Setenv("STORAGE", "sqlite")
+	t.Setenv("RATE_LIMIT_BACKEND", "postgres")
+	t.Setenv("RATE_LIMIT_ROUTES", "/api/v1/packages=60:device")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate_limit.backend postgres requires postgres storage")
+	assert.Contains(t, err.Error(), "rate_limit.routes[0].route")
+	assert.Contains(t, err.Error(), "rate_limit.routes[0].key")
+}
+
+func TestLoad_HappyPath_ValidationRulesFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("ORDER_REF_PATTERN", "[A-Z]{3}-[0-9]{3}")
+	t.Setenv("ORDER_REF_MAX_LENGTH", "7")
+	t.Setenv("ORDER_REF_UPPERCASE", "true")
+	t.Setenv("DRIVER_CODE_CHECKSUM", "luhn")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, config.FieldRule{Pattern: "[A-Z]{3}-[0-9]{3}", MaxLength: 7, UpperCase: true}, cfg.Validation.Default.OrderRef)
+	assert.Equal(t, config.ChecksumLuhn, cfg.Validation.Default.DriverCode.Checksum)
+}
+
+func TestLoad_EdgeCase_InvalidValidationRules(t *testing.T) {
+	// Setup
+	t.Setenv("ORDER_REF_PATTERN", "[A-Z")
+	t.Setenv("DRIVER_CODE_MAX_LENGTH", "300")
+	t.Setenv("DRIVER_CODE_CHECKSUM", "crc")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validation.default.order_reference.pattern")
+	assert.Contains(t, err.Error(), "validation.default.driver_code.max_length")
+	assert.Contains(t, err.Error(), "validation.default.driver_code.checksum")
+}
+
+func TestLoad_HappyPath_HighValueThresholdFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("HIGH_VALUE_THRESHOLD", "50000")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(50000), cfg.Packages.HighValueThreshold)
+}
+
+func TestLoad_EdgeCase_NegativeHighValueThreshold(t *testing.T) {
+	// Setup
+	t.Setenv("HIGH_VALUE_THRESHOLD", "-1")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "packages.high_value_threshold")
+}
+
+func TestLoad_EdgeCase_UnsupportedLabelDPI(t *testing.T) {
+	// Setup
+	t.Setenv("LABEL_DPI", "250")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "packages.label_dpi")
+}
+
+func TestLoad_HappyPath_PickupWindowsFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("PICKUP_WINDOWS", "08:00-09:30; 09:30-11:00=2")
+	t.Setenv("PICKUP_WINDOW_CAPACITY", "4")
+	t.Setenv("PICKUP_WINDOW_ENFORCEMENT", "enforce")
+	t.Setenv("PICKUP_TIMEZONE", "UTC")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, config.EnforcementEnforce, cfg.Appointments.Enforcement)
+	windows, err := cfg.Appointments.PickupWindows()
+	require.NoError(t, err)
+	assert.Equal(t, []config.PickupWindow{
+		{Start: 8 * time.Hour, End: 9*time.Hour + 30*time.Minute, Capacity: 4},
+		{Start: 9*time.Hour + 30*time.Minute, End: 11 * time.Hour, Capacity: 2},
+	}, windows)
+}
+
+func TestLoad_EdgeCase_InvalidPickupWindows(t *testing.T) {
+	// Setup
+	t.Setenv("PICKUP_WINDOWS", "08:00-10:00;09:00-09:30;12:00-11:00")
+	t.Setenv("PICKUP_WINDOW_ENFORCEMENT", "strict")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not overlap")
+	assert.Contains(t, err.Error(), `"12:00-11:00" must end after it starts`)
+	assert.Contains(t, err.Error(), "appointments.enforcement")
+}
+
+func TestLoad_EdgeCase_InvalidQueueSettings(t *testing.T) {
+	// Setup
+	t.Setenv("QUEUE_SAMPLE_SIZE", "1")
+	t.Setenv("QUEUE_DEFAULT_PACE", "0s")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "queue.sample_size must be at least 2, got 1")
+	assert.Contains(t, err.Error(), "queue.default_pace must be positive")
+}
+
+func TestLoad_HappyPath_SLAFromEnv(t *testing.T) {
+	// Setup
+	t.Setenv("SLA_EXPRESS_PICKUP_TARGET", "90m")
+	t.Setenv("SLA_STANDARD_EXPIRY_WINDOW", "36h")
+	t.Setenv("SLA_ECONOMY_REMINDER_INTERVAL", "0s")
+
+	// Execute
+	cfg, err := config.Load(nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, cfg.SLA.Express.PickupTarget.Duration)
+	assert.Equal(t, 12*time.Hour, cfg.SLA.Express.ExpiryWindow.Duration)
+	assert.Equal(t, 36*time.Hour, cfg.SLA.Standard.ExpiryWindow.Duration)
+	assert.Equal(t, 24*time.Hour, cfg.SLA.Economy.PickupTarget.Duration)
+	assert.Equal(t, 4*time.Hour, cfg.SLA.Express.ReminderInterval.Duration)
+	assert.Zero(t, cfg.SLA.Economy.ReminderInterval.Duration)
+}
+
+func TestLoad_EdgeCase_InvalidSLASettings(t *testing.T) {
+	// Setup
+	t.Setenv("SLA_ECONOMY_EXPIRY_WINDOW", "-1h")
+	t.Setenv("SLA_EXPRESS_PICKUP_TARGET", "0s")
+	t.Setenv("SLA_STANDARD_REMINDER_INTERVAL", "-24h")
+
+	// Execute
+	_, err := config.Load(nil)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sla.economy.expiry_window must not be negative")
+	assert.Contains(t, err.Error(), "sla.express.pickup_target must be positive")
+	assert.Contains(t, err.Error(), "sla.standard.reminder_interval must not be negative")
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Watcher reloads the configuration on SIGHUP or when the config file changes.
+// Only non-structural settings (worker interval, expiry window, log level) are
+// applied at runtime; other changes are reported and require a restart.
+type Watcher struct {
+	args      []string
+	mu        sync.RWMutex
+	current   *Config
+	modTime   time.Time
+	listeners []func(*Config)
+}
+
+// NewWatcher creates a watcher seeded with the configuration loaded at startup.
+// args must be the same command line arguments that were passed to Load.
+func NewWatcher(args []string, initial *Config) *Watcher {
+	w := &Watcher{args: args, current: initial}
+	if initial.File != "" {
+		if info, err := os.Stat(initial.File); err == nil {
+			w.modTime = info.ModTime()
+		}
+	}
+	return w
+}
+
+// Current returns the active configuration
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// OnReload registers a callback invoked with the new configuration after each successful reload
+func (w *Watcher) OnReload(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// Reload re-reads every configuration layer and applies the reloadable settings.
+// The running configuration is left untouched if the new one is invalid.
+func (w *Watcher) Reload() error {
+	loaded, err := Load(w.args)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	next := *w.current
+	next.Worker = loaded.Worker
+	next.Log = loaded.Log
+	next.SLA = loaded.SLA
+
+	if !reflect.DeepEqual(loaded.Server, w.current.Server) || loaded.Database != w.current.Database || !reflect.DeepEqual(loaded.Auth, w.current.Auth) {
+		log.Println("Config reload: server, database and auth settings changed but require a restart to take effect")
+	}
+
+	w.current = &next
+	listeners := append([]func(*Config){}, w.listeners...)
+	w.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(&next)
+	}
+	return nil
+}
+
+// Run reloads on SIGHUP and polls the config file for changes until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context, pollInterval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.reloadAndLog("SIGHUP received")
+		case <-ticker.C:
+			if w.fileChanged() {
+				w.reloadAndLog("config file changed")
+			}
+		}
+	}
+}
+
+func (w *Watcher) fileChanged() bool {
+	path := w.Current().File
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if info.ModTime().Equal(w.modTime) {
+		return false
+	}
+	w.modTime = info.ModTime()
+	return true
+}
+
+func (w *Watcher) reloadAndLog(reason string) {
+	if err := w.Reload(); err != nil {
+		log.Printf("Config reload (%s) failed, keeping previous settings: %v", reason, err)
+		return
+	}
+	log.Printf("Config reloaded (%s)", reason)
+}
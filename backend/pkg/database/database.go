@@ -1,57 +1,180 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
+	"math/rand"
+	"net/url"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
 type Config struct {
+	// URL is a full connection string (DATABASE_URL); when set it takes
+	// precedence over the individual connection fields below
+	URL string
+
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// TLS files passed through to the driver as sslrootcert, sslcert and sslkey
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	// Connection pool tuning; zero values keep the database/sql defaults
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// Startup retry: how many times to ping before giving up and the backoff bounds
+	ConnectRetries    int
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
 }
 
 func NewConnection(config *Config) (*sql.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
+	dsn, err := config.DSN()
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Test the connection
-	if err := db.Ping(); err != nil {
+	if config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	}
+
+	// Test the connection, retrying while the database is still starting up
+	if err := pingWithRetry(db, config); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	return db, nil
 }
 
-func GetConfigFromEnv() *Config {
-	return &Config{
-		Host:     getEnv("DB_HOST", "172.18.125.255"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "vini"),
-		DBName:   getEnv("DB_NAME", "pickup_queue"),
-		SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+// DSN builds the driver connection string from the URL or the individual fields
+func (c *Config) DSN() (string, error) {
+	tls := map[string]string{
+		"sslrootcert": c.SSLRootCert,
+		"sslcert":     c.SSLCert,
+		"sslkey":      c.SSLKey,
+	}
+
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid database URL: %w", err)
+		}
+		query := u.Query()
+		for key, value := range tls {
+			if value != "" && query.Get(key) == "" {
+				query.Set(key, value)
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	parts := []string{
+		"host=" + quoteDSNValue(c.Host),
+		"port=" + quoteDSNValue(c.Port),
+		"user=" + quoteDSNValue(c.User),
+		"password=" + quoteDSNValue(c.Password),
+		"dbname=" + quoteDSNValue(c.DBName),
+		"sslmode=" + quoteDSNValue(c.SSLMode),
+	}
+	for _, key := range []string{"sslrootcert", "sslcert", "sslkey"} {
+		if tls[key] != "" {
+			parts = append(parts, key+"="+quoteDSNValue(tls[key]))
+		}
 	}
+	return strings.Join(parts, " "), nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// quoteDSNValue quotes a key/value DSN value so spaces and quotes survive parsing
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
 		return value
 	}
-	return defaultValue
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
+func pingWithRetry(db *sql.DB, config *Config) error {
+	attempts := config.ConnectRetries
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := Backoff{Base: config.ConnectBackoff, Max: config.ConnectMaxBackoff}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		wait := backoff.Duration(attempt)
+		log.Printf("[DB] Ping attempt %d/%d failed: %v, retrying in %v", attempt, attempts, err, wait)
+		time.Sleep(wait)
+	}
+	return err
+}
+
+// Backoff computes exponential backoff delays with jitter
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Duration returns the delay before the given retry attempt (starting at 1)
+func (b Backoff) Duration(attempt int) time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	max := b.Max
+	if max <= 0 {
+		max = 10 * time.Second
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	// Jitter keeps replicas that start together from retrying in lockstep
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
 }
 
 // LogQuery logs SQL queries with execution time
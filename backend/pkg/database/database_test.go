@@ -0,0 +1,109 @@
+package database_test
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"pickup-queue/pkg/database"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_DSN_HappyPath_KeyValue(t *testing.T) {
+	// Setup
+	cfg := &database.Config{
+		Host:        "db",
+		Port:        "5432",
+		User:        "app",
+		Password:    "it's secret",
+		DBName:      "pickup_queue",
+		SSLMode:     "verify-full",
+		SSLRootCert: "/etc/ssl/root.crt",
+	}
+
+	// Execute
+	dsn, err := cfg.DSN()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, dsn, `password='it\'s secret'`)
+	assert.Contains(t, dsn, "sslrootcert=/etc/ssl/root.crt")
+	assert.NotContains(t, dsn, "sslcert=")
+}
+
+func TestConfig_DSN_HappyPath_URL(t *testing.T) {
+	// Setup - URL wins over individual fields, TLS files are appended
+	cfg := &database.Config{
+		URL:         "postgres://app:pw@db:5432/pickup_queue?sslmode=require",
+		Host:        "ignored",
+		SSLRootCert: "/etc/ssl/root.crt",
+	}
+
+	// Execute
+	dsn, err := cfg.DSN()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "postgres://app:pw@db:5432/pickup_queue?")
+	assert.Contains(t, dsn, "sslmode=require")
+	assert.Contains(t, dsn, "sslrootcert=%2Fetc%2Fssl%2Froot.crt")
+	assert.NotContains(t, dsn, "ignored")
+}
+
+func TestBackoff_Duration_StaysWithinBounds(t *testing.T) {
+	backoff := database.Backoff{Base: 100 * time.Millisecond, Max: time.Second}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := backoff.Duration(attempt)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, database.IsTransient(&pq.Error{Code: "40001"}))
+	assert.True(t, database.IsTransient(&pq.Error{Code: "08006"}))
+	assert.True(t, database.IsTransient(syscall.ECONNRESET))
+	assert.False(t, database.IsTransient(&pq.Error{Code: "23505"}))
+	assert.False(t, database.IsTransient(errors.New("boom")))
+	assert.False(t, database.IsRolledBack(syscall.ECONNRESET))
+}
+
+func TestRetryPolicy_Do_RetriesTransientErrors(t *testing.T) {
+	// Setup
+	policy := database.RetryPolicy{MaxAttempts: 3, Backoff: database.Backoff{Base: time.Millisecond, Max: time.Millisecond}}
+	calls := 0
+
+	// Execute
+	err := policy.Do(func() error {
+		calls++
+		if calls < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryPolicy_DoWrite_EdgeCase_DoesNotRetryConnectionReset(t *testing.T) {
+	// Setup
+	policy := database.RetryPolicy{MaxAttempts: 3, Backoff: database.Backoff{Base: time.Millisecond, Max: time.Millisecond}}
+	calls := 0
+
+	// Execute
+	err := policy.DoWrite(func() error {
+		calls++
+		return syscall.ECONNRESET
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RetryPolicy retries statements that failed with a transient database error
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     Backoff
+}
+
+// DefaultRetryPolicy is used by the repositories unless configured otherwise
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     Backoff{Base: 50 * time.Millisecond, Max: time.Second},
+}
+
+// Do runs fn and retries it on any transient error. Only use it for reads and
+// idempotent writes: after a connection reset the statement may have been applied.
+func (p RetryPolicy) Do(fn func() error) error {
+	return p.run(fn, IsTransient)
+}
+
+// DoWrite runs fn and retries it only when the server guarantees the statement
+// was rolled back (serialization failures and deadlocks), so it is safe for inserts.
+func (p RetryPolicy) DoWrite(fn func() error) error {
+	return p.run(fn, IsRolledBack)
+}
+
+func (p RetryPolicy) run(fn func() error, retryable func(error) bool) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = fn()
+		if err == nil || !retryable(err) || attempt == attempts {
+			return err
+		}
+
+		wait := p.Backoff.Duration(attempt)
+		log.Printf("[DB] Transient error on attempt %d/%d: %v, retrying in %v", attempt, attempts, err, wait)
+		time.Sleep(wait)
+	}
+	return err
+}
+
+// IsRolledBack reports whether err is a serialization failure or deadlock,
+// after which Postgres has rolled the transaction back and it can be retried
+func IsRolledBack(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", "40P01": // serialization_failure, deadlock_detected
+			return true
+		}
+	}
+	return errors.Is(err, driver.ErrBadConn)
+}
+
+// IsTransient reports whether err is worth retrying: rolled-back transactions,
+// lost connections and server shutdowns
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsRolledBack(err) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Class 08 is connection exceptions, 57P01-57P03 are shutdowns and startup
+		return pqErr.Code.Class() == "08" ||
+			pqErr.Code == "57P01" || pqErr.Code == "57P02" || pqErr.Code == "57P03"
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,260 @@
+// Package label lays out 4×6 inch package labels and renders them as PDF,
+// PNG or ZPL for thermal printers. Everything is drawn in Go: barcodes come
+// from pkg/barcode and text uses the standard PDF Courier font, a built-in
+// bitmap font or the printer's own font.
+package label
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pickup-queue/pkg/barcode"
+)
+
+// Format is an output format of Render
+type Format string
+
+const (
+	FormatPDF Format = "pdf"
+	FormatPNG Format = "png"
+	FormatZPL Format = "zpl"
+)
+
+// Formats lists every supported format
+var Formats = []Format{FormatPDF, FormatPNG, FormatZPL}
+
+// ParseFormat resolves a case-insensitive format name
+func ParseFormat(name string) (Format, bool) {
+	f := Format(strings.ToLower(strings.TrimSpace(name)))
+	for _, known := range Formats {
+		if f == known {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// ContentType is the media type of labels rendered in f
+func (f Format) ContentType() string {
+	switch f {
+	case FormatPDF:
+		return "application/pdf"
+	case FormatPNG:
+		return "image/png"
+	default:
+		return "application/zpl"
+	}
+}
+
+// Batchable reports whether f can hold several labels in one document
+func (f Format) Batchable() bool {
+	return f != FormatPNG
+}
+
+// DefaultDPI is the resolution of most thermal label printers
+const DefaultDPI = 203
+
+var (
+	// ErrNoLabels is returned when Render is given nothing to render
+	ErrNoLabels = errors.New("label: no labels")
+	// ErrNotBatchable is returned when several labels are rendered as PNG
+	ErrNotBatchable = errors.New("label: format holds a single label")
+	// ErrBarcodeTooWide is returned when the order reference makes a
+	// Code 128 barcode too wide for the label at its resolution
+	ErrBarcodeTooWide = errors.New("label: order reference too long for the barcode")
+)
+
+// Label is what gets printed for one package
+type Label struct {
+	// Site is printed in the header, e.g. the pickup point's name
+	Site string
+	// OrderRef is printed large and encoded as Code 128
+	OrderRef string
+	// PackageID is printed small and encoded as a QR code
+	PackageID  string
+	DriverCode string
+	Slot       string
+	Carrier    string
+	// Parcel is the parcel's position in its shipment, e.g. "2/3"
+	Parcel string
+	// Marks are handling instructions such as FRAGILE or CHILLED
+	Marks     []string
+	CreatedAt time.Time
+}
+
+// Options control rendering
+type Options struct {
+	// DPI is the printer resolution; PNG and ZPL output is in its dots.
+	// Zero means DefaultDPI.
+	DPI int
+}
+
+func (o Options) dpi() int {
+	if o.DPI <= 0 {
+		return DefaultDPI
+	}
+	return o.DPI
+}
+
+// Render renders labels in format, one page per label
+func Render(format Format, labels []Label, opts Options) ([]byte, error) {
+	if len(labels) == 0 {
+		return nil, ErrNoLabels
+	}
+	if len(labels) > 1 && !format.Batchable() {
+		return nil, ErrNotBatchable
+	}
+
+	pages := make([]*page, len(labels))
+	for i, l := range labels {
+		p, err := layout(l, opts.dpi())
+		if err != nil {
+			return nil, fmt.Errorf("label %d: %w", i+1, err)
+		}
+		pages[i] = p
+	}
+
+	switch format {
+	case FormatPDF:
+		return renderPDF(pages), nil
+	case FormatPNG:
+		return renderPNG(pages[0])
+	case FormatZPL:
+		return renderZPL(pages), nil
+	}
+	return nil, fmt.Errorf("label: unknown format %q", format)
+}
+
+// box is a filled black rectangle, in dots from the top left corner
+type box struct{ x, y, w, h int }
+
+// text is a line of text whose em square has its top left corner at x, y;
+// characters advance 0.6 em
+type text struct {
+	x, y, size int
+	s          string
+}
+
+// page is a laid out label. bars holds the modules of both barcodes as
+// boxes; code128 and qr describe them again for printers that draw their own.
+type page struct {
+	dpi, width, height int
+	rules              []box
+	bars               []box
+	texts              []text
+	code128            symbol
+	qr                 symbol
+}
+
+// symbol is a barcode placed at x, y with modules of the given size in dots
+type symbol struct {
+	x, y, module, height int
+	data                 string
+}
+
+// layout places the parts of l on a 4×6 inch page at dpi
+func layout(l Label, dpi int) (*page, error) {
+	in := func(inches float64) int { return int(inches*float64(dpi) + 0.5) }
+	p := &page{dpi: dpi, width: in(4), height: in(6)}
+	margin := in(0.15)
+	contentW := p.width - 2*margin
+	rule := max(2, in(0.02))
+
+	addText := func(x, y, size int, s string) {
+		p.texts = append(p.texts, text{x: x, y: y, size: size, s: printable(s)})
+	}
+	// fit shrinks text to width, never below a legible size
+	fit := func(s string, width, size int) int {
+		if n := len([]rune(s)); n > 0 && size*6*n > width*10 {
+			size = width * 10 / (6 * n)
+		}
+		return max(size, in(0.08))
+	}
+
+	y := margin
+	addText(margin, y, in(0.16), l.Site)
+	y += in(0.26)
+	p.rules = append(p.rules, box{margin, y, contentW, rule})
+	y += in(0.14)
+
+	size := fit(l.OrderRef, contentW, in(0.4))
+	addText(margin, y, size, l.OrderRef)
+	y += size + in(0.1)
+
+	modules, err := barcode.Code128(l.OrderRef)
+	if err != nil {
+		return nil, err
+	}
+	// Ten modules of quiet zone on either side
+	module := min(contentW/(len(modules)+20), in(0.02))
+	if module < 1 {
+		return nil, ErrBarcodeTooWide
+	}
+	barH := in(1.0)
+	x := margin + (contentW-module*len(modules))/2
+	p.code128 = symbol{x: x, y: y, module: module, height: barH, data: l.OrderRef}
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		p.bars = append(p.bars, box{x + start*module, y, (i - start) * module, barH})
+	}
+	y += barH + in(0.15)
+	p.rules = append(p.rules, box{margin, y, contentW, rule})
+	y += in(0.15)
+
+	qr, err := barcode.QR([]byte(l.PackageID))
+	if err != nil {
+		return nil, err
+	}
+	qrModule := max(1, in(1.5)/(qr.Size+8))
+	qrX := p.width - margin - qrModule*(qr.Size+4)
+	p.qr = symbol{x: qrX, y: y, module: qrModule, height: qrModule * qr.Size, data: l.PackageID}
+	for row := 0; row < qr.Size; row++ {
+		for col := 0; col < qr.Size; col++ {
+			if qr.Dark(col, row) {
+				p.bars = append(p.bars, box{qrX + col*qrModule, y + row*qrModule, qrModule, qrModule})
+			}
+		}
+	}
+
+	columnW := qrX - margin - in(0.2)
+	for _, field := range []struct{ caption, value string }{
+		{"DRIVER", l.DriverCode},
+		{"SLOT", l.Slot},
+		{"CARRIER", l.Carrier},
+		{"PARCEL", l.Parcel},
+	} {
+		if field.value == "" {
+			continue
+		}
+		addText(margin, y, in(0.11), field.caption)
+		y += in(0.15)
+		size := fit(field.value, columnW, in(0.3))
+		addText(margin, y, size, field.value)
+		y += size + in(0.12)
+	}
+
+	if len(l.Marks) > 0 {
+		y = max(y, p.qr.y+p.qr.height+in(0.15))
+		marks := strings.Join(l.Marks, "  ")
+		size := fit(marks, contentW, in(0.24))
+		addText(margin, y, size, marks)
+	}
+
+	footer := in(0.1)
+	footerY := p.height - margin - footer
+	addText(margin, footerY, footer, l.PackageID)
+	if !l.CreatedAt.IsZero() {
+		date := l.CreatedAt.UTC().Format("2006-01-02")
+		addText(p.width-margin-footer*6*len(date)/10, footerY, footer, date)
+	}
+	return p, nil
+}
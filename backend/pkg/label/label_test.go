@@ -0,0 +1,116 @@
+package label_test
+
+import (
+	"bytes"
+	"image/png"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"pickup-queue/pkg/label"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleLabel(orderRef string) label.Label {
+	return label.Label{
+		Site:       "Pickup Point Central",
+		OrderRef:   orderRef,
+		PackageID:  "0b8f5a52-3c1e-4a51-9d0f-6c1b2f3a4d5e",
+		DriverCode: "DRV-001",
+		Slot:       "A-03-2",
+		Marks:      []string{"FRAGILE", "CHILLED"},
+		CreatedAt:  time.Date(2025, 8, 24, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRender_HappyPath_PDF(t *testing.T) {
+	// Execute
+	out, err := label.Render(label.FormatPDF, []label.Label{sampleLabel("ORD-1"), sampleLabel("ORD-2")}, label.Options{})
+
+	// Assert
+	require.NoError(t, err)
+	doc := string(out)
+	assert.True(t, strings.HasPrefix(doc, "%PDF-1.4"))
+	assert.Contains(t, doc, "/Count 2")
+	assert.Contains(t, doc, "/MediaBox [0 0 288.00 432.00]")
+	assert.Contains(t, doc, "(ORD-2) Tj")
+	assert.True(t, strings.HasSuffix(doc, "%%EOF\n"))
+
+	// Every xref entry must point at the object it names
+	xref := regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`).FindAllStringSubmatch(doc, -1)
+	require.Len(t, xref, 7)
+	for i, entry := range xref {
+		offset, err := strconv.Atoi(entry[1])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(doc[offset:], strconv.Itoa(i+1)+" 0 obj"), "object %d", i+1)
+	}
+	start := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(doc)
+	require.NotNil(t, start)
+	offset, _ := strconv.Atoi(start[1])
+	assert.True(t, strings.HasPrefix(doc[offset:], "xref"))
+}
+
+func TestRender_HappyPath_PNG(t *testing.T) {
+	// Execute
+	out, err := label.Render(label.FormatPNG, []label.Label{sampleLabel("ORD-20250824-001")}, label.Options{DPI: 300})
+
+	// Assert
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, 1200, img.Bounds().Dx())
+	assert.Equal(t, 1800, img.Bounds().Dy())
+
+	dark := 0
+	for y := 0; y < 1800; y++ {
+		for x := 0; x < 1200; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r == 0 {
+				dark++
+			}
+		}
+	}
+	assert.Greater(t, dark, 1200*1800/50)
+}
+
+func TestRender_HappyPath_ZPL(t *testing.T) {
+	// Execute
+	out, err := label.Render(label.FormatZPL, []label.Label{sampleLabel("ORD^1"), sampleLabel("ORD-2")}, label.Options{})
+
+	// Assert
+	require.NoError(t, err)
+	zpl := string(out)
+	assert.Equal(t, 2, strings.Count(zpl, "^XA"))
+	assert.Equal(t, 2, strings.Count(zpl, "^XZ"))
+	assert.Contains(t, zpl, "^PW812\n^LL1218")
+	assert.Contains(t, zpl, "^BCN,203,N,N,N,A^FH^FDORD_5E1^FS")
+	assert.Contains(t, zpl, "^FDMA,0b8f5a52-3c1e-4a51-9d0f-6c1b2f3a4d5e^FS")
+	assert.Contains(t, zpl, "^FDFRAGILE  CHILLED^FS")
+}
+
+func TestRender_EdgeCase_Rejected(t *testing.T) {
+	// Execute
+	_, batchErr := label.Render(label.FormatPNG, []label.Label{sampleLabel("ORD-1"), sampleLabel("ORD-2")}, label.Options{})
+	_, emptyErr := label.Render(label.FormatPDF, nil, label.Options{})
+	_, wideErr := label.Render(label.FormatZPL, []label.Label{sampleLabel(strings.Repeat("AB", 40))}, label.Options{})
+
+	// Assert
+	assert.ErrorIs(t, batchErr, label.ErrNotBatchable)
+	assert.ErrorIs(t, emptyErr, label.ErrNoLabels)
+	assert.ErrorIs(t, wideErr, label.ErrBarcodeTooWide)
+}
+
+func TestParseFormat_EdgeCase_CaseInsensitive(t *testing.T) {
+	// Execute
+	zpl, zplOK := label.ParseFormat(" ZPL ")
+	_, unknownOK := label.ParseFormat("svg")
+
+	// Assert
+	assert.True(t, zplOK)
+	assert.Equal(t, label.FormatZPL, zpl)
+	assert.Equal(t, "application/zpl", zpl.ContentType())
+	assert.False(t, unknownOK)
+}
@@ -0,0 +1,66 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// renderPDF writes a PDF 1.4 document with one page per label. Bars and
+// rules are filled rectangles and text uses the standard Courier-Bold font,
+// so nothing needs to be embedded.
+func renderPDF(pages []*page) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objects 1 to 3 are the catalog, the page tree and the font; each page
+	// then takes a page object followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, p := range pages {
+		// PDF units are points, 72 per inch, from the bottom left corner
+		scale := 72 / float64(p.dpi)
+		width, height := float64(p.width)*scale, float64(p.height)*scale
+
+		var content strings.Builder
+		content.WriteString("0 g\n")
+		for _, b := range append(append([]box{}, p.rules...), p.bars...) {
+			fmt.Fprintf(&content, "%.2f %.2f %.2f %.2f re f\n",
+				float64(b.x)*scale, height-float64(b.y+b.h)*scale, float64(b.w)*scale, float64(b.h)*scale)
+		}
+		for _, t := range p.texts {
+			size := float64(t.size) * scale
+			fmt.Fprintf(&content, "BT /F1 %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+				size, float64(t.x)*scale, height-float64(t.y)*scale-0.7*size, pdfEscape(t.s))
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			width, height, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfEscape escapes the characters with a meaning inside a PDF string
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
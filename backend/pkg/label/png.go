@@ -0,0 +1,55 @@
+package label
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// renderPNG rasterizes p in black and white at its resolution
+func renderPNG(p *page) ([]byte, error) {
+	img := image.NewGray(image.Rect(0, 0, p.width, p.height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+
+	fill := func(b box) {
+		r := image.Rect(b.x, b.y, b.x+b.w, b.y+b.h).Intersect(img.Rect)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	}
+
+	for _, b := range p.rules {
+		fill(b)
+	}
+	for _, b := range p.bars {
+		fill(b)
+	}
+	for _, t := range p.texts {
+		// A font pixel is a tenth of the em square; glyphs advance six
+		for i, c := range []byte(t.s) {
+			glyph := font5x7[c-' ']
+			left := t.x + i*6*t.size/10
+			for col, bits := range glyph {
+				for row := 0; row < 7; row++ {
+					if bits>>row&1 == 0 {
+						continue
+					}
+					x0, x1 := left+col*t.size/10, left+(col+1)*t.size/10
+					y0, y1 := t.y+row*t.size/10, t.y+(row+1)*t.size/10
+					fill(box{x0, y0, max(1, x1-x0), max(1, y1-y0)})
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
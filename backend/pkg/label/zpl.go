@@ -0,0 +1,35 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// renderZPL writes one ^XA…^XZ format per label. The printer draws the
+// barcodes and text itself, so only their positions come from the layout.
+func renderZPL(pages []*page) []byte {
+	var buf bytes.Buffer
+	for _, p := range pages {
+		fmt.Fprintf(&buf, "^XA\n^CI28\n^PW%d\n^LL%d\n", p.width, p.height)
+		for _, b := range p.rules {
+			fmt.Fprintf(&buf, "^FO%d,%d^GB%d,%d,%d^FS\n", b.x, b.y, b.w, b.h, min(b.w, b.h))
+		}
+		for _, t := range p.texts {
+			fmt.Fprintf(&buf, "^FO%d,%d^A0N,%d,%d^FH^FD%s^FS\n", t.x, t.y, t.size, t.size*6/10, zplEscape(t.s))
+		}
+		c := p.code128
+		fmt.Fprintf(&buf, "^FO%d,%d^BY%d^BCN,%d,N,N,N,A^FH^FD%s^FS\n", c.x, c.y, c.module, c.height, zplEscape(c.data))
+		// ^BQ leaves a quiet zone of its own above the symbol
+		q := p.qr
+		fmt.Fprintf(&buf, "^FO%d,%d^BQN,2,%d^FH^FDMA,%s^FS\n", q.x, q.y, min(q.module, 10), zplEscape(q.data))
+		buf.WriteString("^XZ\n")
+	}
+	return buf.Bytes()
+}
+
+// zplEscape hex-escapes the characters that would end or alter a ^FD field;
+// fields are sent with ^FH, whose escape character is the underscore
+func zplEscape(s string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(s)
+}
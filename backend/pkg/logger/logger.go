@@ -3,30 +3,65 @@ package logger
 import (
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Log levels in increasing order of severity
+const (
+	LevelDebug int32 = iota
+	LevelInfo
+	LevelWarning
+	LevelError
 )
 
 type Logger struct {
 	*log.Logger
+	level atomic.Int32
 }
 
 func New() *Logger {
-	return &Logger{
+	l := &Logger{
 		Logger: log.New(os.Stdout, "PICKUP-QUEUE: ", log.LstdFlags|log.Lshortfile),
 	}
+	l.level.Store(LevelDebug)
+	return l
+}
+
+// SetLevel changes the minimum level that is written; it is safe to call while logging
+func (l *Logger) SetLevel(level string) {
+	switch strings.ToLower(level) {
+	case "debug":
+		l.level.Store(LevelDebug)
+	case "info":
+		l.level.Store(LevelInfo)
+	case "warning", "warn":
+		l.level.Store(LevelWarning)
+	case "error":
+		l.level.Store(LevelError)
+	}
 }
 
 func (l *Logger) Info(v ...interface{}) {
-	l.Println("[INFO]", v)
+	if l.level.Load() <= LevelInfo {
+		l.Println("[INFO]", v)
+	}
 }
 
 func (l *Logger) Error(v ...interface{}) {
-	l.Println("[ERROR]", v)
+	if l.level.Load() <= LevelError {
+		l.Println("[ERROR]", v)
+	}
 }
 
 func (l *Logger) Warning(v ...interface{}) {
-	l.Println("[WARNING]", v)
+	if l.level.Load() <= LevelWarning {
+		l.Println("[WARNING]", v)
+	}
 }
 
 func (l *Logger) Debug(v ...interface{}) {
-	l.Println("[DEBUG]", v)
+	if l.level.Load() <= LevelDebug {
+		l.Println("[DEBUG]", v)
+	}
 }